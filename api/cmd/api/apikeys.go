@@ -0,0 +1,113 @@
+package main
+
+import (
+	"net/http"
+
+	httptools "github.com/XDoubleU/essentia/pkg/communication/http"
+	"github.com/XDoubleU/essentia/pkg/context"
+	"github.com/XDoubleU/essentia/pkg/parse"
+
+	"check-in/api/internal/constants"
+	"check-in/api/internal/dtos"
+	"check-in/api/internal/models"
+)
+
+func (app *Application) apiKeysRoutes(mux *http.ServeMux) {
+	mux.HandleFunc(
+		"GET /api-keys",
+		app.authAccess(models.APIKeysReadPermission, app.getAPIKeysHandler),
+	)
+	mux.HandleFunc(
+		"POST /api-keys",
+		app.authAccess(models.APIKeysWritePermission, app.createAPIKeyHandler),
+	)
+	mux.HandleFunc(
+		"DELETE /api-keys/{id}",
+		app.authAccess(models.APIKeysWritePermission, app.deleteAPIKeyHandler),
+	)
+}
+
+// @Summary	Get all API keys
+// @Tags		api-keys
+// @Success	200	{object}	[]APIKey
+// @Failure	401	{object}	ErrorDto
+// @Failure	500	{object}	ErrorDto
+// @Router		/api-keys [get].
+func (app *Application) getAPIKeysHandler(w http.ResponseWriter, r *http.Request) {
+	user := context.GetValue[models.User](r.Context(), constants.UserContextKey)
+	apiKeys, err := app.services.APIKeys.GetAll(r.Context(), user)
+	if err != nil {
+		httptools.ServerErrorResponse(w, r, err)
+		return
+	}
+
+	err = httptools.WriteJSON(w, http.StatusOK, apiKeys, nil)
+	if err != nil {
+		httptools.ServerErrorResponse(w, r, err)
+	}
+}
+
+// @Summary	Create API key, the key is only returned once
+// @Tags		api-keys
+// @Param		createAPIKeyDto	body		CreateAPIKeyDto	true	"CreateAPIKeyDto"
+// @Success	201				{object}	APIKey
+// @Failure	400				{object}	ErrorDto
+// @Failure	401				{object}	ErrorDto
+// @Failure	422				{object}	ErrorDto
+// @Failure	500				{object}	ErrorDto
+// @Router		/api-keys [post].
+func (app *Application) createAPIKeyHandler(w http.ResponseWriter, r *http.Request) {
+	var createAPIKeyDto dtos.CreateAPIKeyDto
+
+	err := httptools.ReadJSON(r.Body, &createAPIKeyDto)
+	if err != nil {
+		httptools.BadRequestResponse(w, r, err)
+		return
+	}
+
+	if v, validationErrors := createAPIKeyDto.Validate(); !v {
+		httptools.FailedValidationResponse(w, r, validationErrors)
+		return
+	}
+
+	user := context.GetValue[models.User](r.Context(), constants.UserContextKey)
+	apiKey, err := app.services.APIKeys.Create(r.Context(), user, createAPIKeyDto)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	err = httptools.WriteJSON(w, http.StatusCreated, apiKey, nil)
+	if err != nil {
+		httptools.ServerErrorResponse(w, r, err)
+	}
+}
+
+// @Summary	Revoke API key
+// @Tags		api-keys
+// @Param		id	path		string	true	"API key ID"
+// @Success	200	{object}	APIKey
+// @Failure	400	{object}	ErrorDto
+// @Failure	401	{object}	ErrorDto
+// @Failure	404	{object}	ErrorDto
+// @Failure	500	{object}	ErrorDto
+// @Router		/api-keys/{id} [delete].
+func (app *Application) deleteAPIKeyHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := parse.URLParam(r, "id", parse.UUID)
+	if err != nil {
+		httptools.BadRequestResponse(w, r, err)
+		return
+	}
+
+	user := context.GetValue[models.User](r.Context(), constants.UserContextKey)
+	apiKey, err := app.services.APIKeys.Delete(r.Context(), user, id)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	err = httptools.WriteJSON(w, http.StatusOK, apiKey, nil)
+	if err != nil {
+		httptools.ServerErrorResponse(w, r, err)
+	}
+}
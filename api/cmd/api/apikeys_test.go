@@ -0,0 +1,230 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	httptools "github.com/XDoubleU/essentia/pkg/communication/http"
+	"github.com/XDoubleU/essentia/pkg/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"check-in/api/internal/dtos"
+	"check-in/api/internal/models"
+)
+
+func (env *TestEnv) createAPIKey(scopes ...models.Permission) *models.APIKey {
+	apiKey, err := env.app.services.APIKeys.Create(
+		env.ctx,
+		env.fixtures.AdminUser,
+		dtos.CreateAPIKeyDto{
+			Name:   "Dashboard",
+			Scopes: scopes,
+			Expiry: nil,
+		},
+	)
+	if err != nil {
+		panic(err)
+	}
+
+	return apiKey
+}
+
+func doWithAPIKey(
+	handler http.Handler,
+	method string,
+	path string,
+	key string,
+) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, nil)
+	req.Header.Set("Authorization", "Bearer "+key)
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	return rr
+}
+
+func TestGetAPIKeys(t *testing.T) {
+	testEnv, testApp := setup(t)
+	defer testEnv.teardown()
+
+	apiKey := testEnv.createAPIKey(models.LocationsListPermission)
+
+	tReq := test.CreateRequestTester(
+		testApp.routes(),
+		http.MethodGet,
+		"/api-keys",
+	)
+	tReq.AddCookie(testEnv.fixtures.Tokens.AdminAccessToken)
+
+	rs := tReq.Do(t)
+
+	var rsData []models.APIKey
+	err := httptools.ReadJSON(rs.Body, &rsData)
+	require.Nil(t, err)
+
+	assert.Equal(t, http.StatusOK, rs.StatusCode)
+	assert.Equal(t, 1, len(rsData))
+	assert.Equal(t, apiKey.ID, rsData[0].ID)
+	assert.Equal(t, apiKey.Name, rsData[0].Name)
+	assert.Equal(t, apiKey.Scopes, rsData[0].Scopes)
+	assert.Equal(t, "", rsData[0].Key)
+}
+
+func TestGetAPIKeysAccess(t *testing.T) {
+	testEnv, testApp := setup(t)
+	defer testEnv.teardown()
+
+	tReqBase := test.CreateRequestTester(
+		testApp.routes(),
+		http.MethodGet,
+		"/api-keys",
+	)
+
+	mt := test.CreateMatrixTester()
+
+	mt.AddTestCase(tReqBase, test.NewCaseResponse(http.StatusUnauthorized, nil, nil))
+
+	tReq2 := tReqBase.Copy()
+	tReq2.AddCookie(testEnv.fixtures.Tokens.ManagerAccessToken)
+
+	mt.AddTestCase(tReq2, test.NewCaseResponse(http.StatusForbidden, nil, nil))
+
+	mt.Do(t)
+}
+
+func TestCreateAPIKey(t *testing.T) {
+	testEnv, testApp := setup(t)
+	defer testEnv.teardown()
+
+	expiry := testApp.getTimeNowUTC().Add(24 * time.Hour)
+
+	data := dtos.CreateAPIKeyDto{
+		Name: "BI tool",
+		Scopes: []models.Permission{
+			models.LocationsListPermission,
+			models.StatsReadPermission,
+		},
+		Expiry: &expiry,
+	}
+
+	tReq := test.CreateRequestTester(
+		testApp.routes(),
+		http.MethodPost,
+		"/api-keys",
+	)
+	tReq.AddCookie(testEnv.fixtures.Tokens.AdminAccessToken)
+	tReq.SetData(data)
+
+	rs := tReq.Do(t)
+
+	var rsData models.APIKey
+	err := httptools.ReadJSON(rs.Body, &rsData)
+	require.Nil(t, err)
+
+	assert.Equal(t, http.StatusCreated, rs.StatusCode)
+	assert.Equal(t, data.Name, rsData.Name)
+	assert.Equal(t, data.Scopes, rsData.Scopes)
+	assert.Equal(t, true, rsData.Expiry.Valid)
+	assert.Equal(t, true, strings.HasPrefix(rsData.Key, "ck_"))
+}
+
+func TestCreateAPIKeyNotGrantable(t *testing.T) {
+	testEnv, testApp := setup(t)
+	defer testEnv.teardown()
+
+	tReq := test.CreateRequestTester(
+		testApp.routes(),
+		http.MethodPost,
+		"/api-keys",
+	)
+	tReq.AddCookie(testEnv.fixtures.Tokens.AdminAccessToken)
+	tReq.SetData(dtos.CreateAPIKeyDto{
+		Name: "BI tool",
+		Scopes: []models.Permission{
+			models.OrganisationsWritePermission,
+		},
+		Expiry: nil,
+	})
+
+	rs := tReq.Do(t)
+
+	assert.Equal(t, http.StatusBadRequest, rs.StatusCode)
+}
+
+func TestCreateAPIKeyExpiryInPast(t *testing.T) {
+	testEnv, testApp := setup(t)
+	defer testEnv.teardown()
+
+	expiry := testApp.getTimeNowUTC().Add(-time.Hour)
+
+	tReq := test.CreateRequestTester(
+		testApp.routes(),
+		http.MethodPost,
+		"/api-keys",
+	)
+	tReq.AddCookie(testEnv.fixtures.Tokens.AdminAccessToken)
+	tReq.SetData(dtos.CreateAPIKeyDto{
+		Name: "BI tool",
+		Scopes: []models.Permission{
+			models.LocationsListPermission,
+		},
+		Expiry: &expiry,
+	})
+
+	rs := tReq.Do(t)
+
+	assert.Equal(t, http.StatusBadRequest, rs.StatusCode)
+}
+
+func TestAuthWithAPIKey(t *testing.T) {
+	testEnv, testApp := setup(t)
+	defer testEnv.teardown()
+
+	apiKey := testEnv.createAPIKey(models.LocationsListPermission)
+
+	rs1 := doWithAPIKey(testApp.routes(), http.MethodGet, "/locations", apiKey.Key)
+	assert.Equal(t, http.StatusOK, rs1.Code)
+
+	rs2 := doWithAPIKey(testApp.routes(), http.MethodGet, "/schools", apiKey.Key)
+	assert.Equal(t, http.StatusForbidden, rs2.Code)
+
+	rs3 := doWithAPIKey(testApp.routes(), http.MethodGet, "/locations", "ck_unknown")
+	assert.Equal(t, http.StatusUnauthorized, rs3.Code)
+
+	apiKeys, err := testApp.services.APIKeys.GetAll(
+		testEnv.ctx,
+		testEnv.fixtures.AdminUser,
+	)
+	require.Nil(t, err)
+
+	assert.Equal(t, true, apiKeys[0].LastUsedAt.Valid)
+}
+
+func TestDeleteAPIKey(t *testing.T) {
+	testEnv, testApp := setup(t)
+	defer testEnv.teardown()
+
+	apiKey := testEnv.createAPIKey(models.LocationsListPermission)
+
+	tReq := test.CreateRequestTester(
+		testApp.routes(),
+		http.MethodDelete,
+		"/api-keys/%s",
+		apiKey.ID,
+	)
+	tReq.AddCookie(testEnv.fixtures.Tokens.AdminAccessToken)
+
+	rs1 := tReq.Do(t)
+	assert.Equal(t, http.StatusOK, rs1.StatusCode)
+
+	rs2 := tReq.Do(t)
+	assert.Equal(t, http.StatusNotFound, rs2.StatusCode)
+
+	rs3 := doWithAPIKey(testApp.routes(), http.MethodGet, "/locations", apiKey.Key)
+	assert.Equal(t, http.StatusUnauthorized, rs3.Code)
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"math"
+	"net/http"
+	"time"
+
+	httptools "github.com/XDoubleU/essentia/pkg/communication/http"
+	contexttools "github.com/XDoubleU/essentia/pkg/context"
+	"github.com/XDoubleU/essentia/pkg/parse"
+
+	"check-in/api/internal/constants"
+	"check-in/api/internal/dtos"
+	"check-in/api/internal/models"
+)
+
+func (app *Application) auditRoutes(mux *http.ServeMux) {
+	mux.HandleFunc(
+		"GET /audit",
+		app.authAccess(models.AuditReadPermission, app.getPaginatedAuditEventsHandler),
+	)
+}
+
+// @Summary	Get all audit events paginated, the most recent first
+// @Tags		audit
+// @Param		page		query		int		false	"Page to fetch"
+// @Param		actorId		query		string	false	"ID of the user who made the change"
+// @Param		action		query		string	false	"Action"
+// @Param		entityType	query		string	false	"Type of the changed entity"
+// @Param		entityId	query		string	false	"ID of the changed entity"
+// @Param		startDate	query		string	false	"StartDate in format 'yyyy-MM-dd'"
+// @Param		endDate		query		string	false	"EndDate in format 'yyyy-MM-dd'"
+// @Success	200			{object}	PaginatedAuditEventsDto
+// @Failure	400			{object}	ErrorDto
+// @Failure	401			{object}	ErrorDto
+// @Failure	500			{object}	ErrorDto
+// @Router		/audit [get].
+func (app *Application) getPaginatedAuditEventsHandler(w http.ResponseWriter,
+	r *http.Request) {
+	var pageSize int64 = 25
+
+	page, err := parse.QueryParam(r, "page", 1, parse.Int64(true, false))
+	if err != nil {
+		httptools.BadRequestResponse(w, r, err)
+		return
+	}
+
+	filter, err := parseAuditEventFilter(r)
+	if err != nil {
+		httptools.BadRequestResponse(w, r, err)
+		return
+	}
+
+	user := contexttools.GetValue[models.User](r.Context(), constants.UserContextKey)
+	events, err := app.services.Audit.GetAllPaginated(
+		r.Context(),
+		user,
+		*filter,
+		pageSize,
+		(page-1)*pageSize,
+	)
+	if err != nil {
+		httptools.ServerErrorResponse(w, r, err)
+		return
+	}
+
+	total, err := app.services.Audit.GetTotalCount(r.Context(), user, *filter)
+	if err != nil {
+		httptools.ServerErrorResponse(w, r, err)
+		return
+	}
+
+	result := dtos.PaginatedResultDto[models.AuditEvent]{
+		Data: events,
+		Pagination: dtos.Pagination{
+			Current: page,
+			Total:   int64(math.Ceil(float64(*total) / float64(pageSize))),
+		},
+	}
+
+	err = httptools.WriteJSON(w, http.StatusOK, result, nil)
+	if err != nil {
+		httptools.ServerErrorResponse(w, r, err)
+	}
+}
+
+func parseAuditEventFilter(r *http.Request) (*models.AuditEventFilter, error) {
+	actorID, err := parse.QueryParam(r, "actorId", "", parse.UUID)
+	if err != nil {
+		return nil, err
+	}
+
+	action, err := parse.QueryParam(r, "action", "", parse.String)
+	if err != nil {
+		return nil, err
+	}
+
+	entityType, err := parse.QueryParam(r, "entityType", "", parse.String)
+	if err != nil {
+		return nil, err
+	}
+
+	entityID, err := parse.QueryParam(r, "entityId", "", parse.String)
+	if err != nil {
+		return nil, err
+	}
+
+	startDate, err := parse.QueryParam(
+		r,
+		"startDate",
+		time.Time{},
+		parse.Date(constants.DateFormat),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	endDate, err := parse.QueryParam(
+		r,
+		"endDate",
+		time.Time{},
+		parse.Date(constants.DateFormat),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if !endDate.IsZero() {
+		endDate = endDate.AddDate(0, 0, 1).Add(-time.Nanosecond)
+	}
+
+	return &models.AuditEventFilter{
+		ActorID:    actorID,
+		Action:     models.AuditAction(action),
+		EntityType: entityType,
+		EntityID:   entityID,
+		Start:      startDate,
+		End:        endDate,
+	}, nil
+}
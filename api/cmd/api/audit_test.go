@@ -0,0 +1,160 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"testing"
+
+	httptools "github.com/XDoubleU/essentia/pkg/communication/http"
+	"github.com/XDoubleU/essentia/pkg/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"check-in/api/internal/dtos"
+	"check-in/api/internal/models"
+)
+
+func TestGetPaginatedAuditEventsUpdate(t *testing.T) {
+	testEnv, testApp := setup(t)
+	defer testEnv.teardown()
+
+	school := testEnv.createSchools(1)[0]
+
+	tReqUpdate := test.CreateRequestTester(
+		testApp.routes(),
+		http.MethodPatch,
+		"/schools/%d",
+		school.ID,
+	)
+	tReqUpdate.SetData(dtos.SchoolDto{
+		Name: "UpdatedSchool",
+	})
+	tReqUpdate.AddCookie(testEnv.fixtures.Tokens.ManagerAccessToken)
+
+	rs := tReqUpdate.Do(t)
+	assert.Equal(t, http.StatusOK, rs.StatusCode)
+
+	tReq := test.CreateRequestTester(
+		testApp.routes(),
+		http.MethodGet,
+		"/audit",
+	)
+	tReq.SetQuery(map[string][]string{
+		"entityType": {"school"},
+		"entityId":   {strconv.FormatInt(school.ID, 10)},
+		"action":     {string(models.AuditUpdate)},
+	})
+	tReq.AddCookie(testEnv.fixtures.Tokens.AdminAccessToken)
+
+	rs = tReq.Do(t)
+
+	var rsData dtos.PaginatedAuditEventsDto
+	err := httptools.ReadJSON(rs.Body, &rsData)
+	require.Nil(t, err)
+
+	assert.Equal(t, http.StatusOK, rs.StatusCode)
+	assert.EqualValues(t, 1, rsData.Pagination.Current)
+	assert.EqualValues(t, 1, rsData.Pagination.Total)
+	require.Equal(t, 1, len(rsData.Data))
+
+	event := rsData.Data[0]
+	assert.Equal(t, testEnv.fixtures.ManagerUser.ID, event.ActorID.String)
+	assert.Equal(t, string(models.ManagerRole), event.ActorRole.String)
+	assert.Equal(t, models.AuditUpdate, event.Action)
+
+	var before, after map[string]any
+	require.Nil(t, json.Unmarshal(event.Before, &before))
+	require.Nil(t, json.Unmarshal(event.After, &after))
+
+	assert.Equal(t, map[string]any{"name": school.Name}, before)
+	assert.Equal(t, map[string]any{"name": "UpdatedSchool"}, after)
+}
+
+func TestGetPaginatedAuditEventsDeleteCheckIn(t *testing.T) {
+	testEnv, testApp := setup(t)
+	defer testEnv.teardown()
+
+	checkIn := testEnv.createCheckIns(testEnv.fixtures.DefaultLocation, 1, 1)[0]
+
+	tReqDelete := test.CreateRequestTester(
+		testApp.routes(),
+		http.MethodDelete,
+		"/locations/%s/checkins/%d",
+		testEnv.fixtures.DefaultLocation.ID,
+		checkIn.ID,
+	)
+	tReqDelete.AddCookie(testEnv.fixtures.Tokens.AdminAccessToken)
+
+	rs := tReqDelete.Do(t)
+	assert.Equal(t, http.StatusOK, rs.StatusCode)
+
+	tReq := test.CreateRequestTester(
+		testApp.routes(),
+		http.MethodGet,
+		"/audit",
+	)
+	tReq.SetQuery(map[string][]string{
+		"entityType": {"checkIn"},
+		"entityId":   {strconv.FormatInt(checkIn.ID, 10)},
+		"actorId":    {testEnv.fixtures.AdminUser.ID},
+	})
+	tReq.AddCookie(testEnv.fixtures.Tokens.AdminAccessToken)
+
+	rs = tReq.Do(t)
+
+	var rsData dtos.PaginatedAuditEventsDto
+	err := httptools.ReadJSON(rs.Body, &rsData)
+	require.Nil(t, err)
+
+	assert.Equal(t, http.StatusOK, rs.StatusCode)
+	require.Equal(t, 1, len(rsData.Data))
+
+	event := rsData.Data[0]
+	assert.Equal(t, models.AuditDelete, event.Action)
+	assert.NotEqual(t, "null", string(event.Before))
+	assert.Equal(t, "null", string(event.After))
+}
+
+func TestGetPaginatedAuditEventsFailValidation(t *testing.T) {
+	testEnv, testApp := setup(t)
+	defer testEnv.teardown()
+
+	tReq := test.CreateRequestTester(
+		testApp.routes(),
+		http.MethodGet,
+		"/audit",
+	)
+	tReq.SetQuery(map[string][]string{
+		"actorId": {"8000"},
+	})
+	tReq.AddCookie(testEnv.fixtures.Tokens.AdminAccessToken)
+
+	rs := tReq.Do(t)
+	assert.Equal(t, http.StatusBadRequest, rs.StatusCode)
+}
+
+func TestGetPaginatedAuditEventsAccess(t *testing.T) {
+	testEnv, testApp := setup(t)
+	defer testEnv.teardown()
+
+	tReqBase := test.CreateRequestTester(
+		testApp.routes(),
+		http.MethodGet,
+		"/audit",
+	)
+
+	mt := test.CreateMatrixTester()
+
+	mt.AddTestCase(tReqBase, test.NewCaseResponse(http.StatusUnauthorized, nil, nil))
+
+	tReq2 := tReqBase.Copy()
+	tReq2.AddCookie(testEnv.fixtures.Tokens.DefaultAccessToken)
+	mt.AddTestCase(tReq2, test.NewCaseResponse(http.StatusForbidden, nil, nil))
+
+	tReq3 := tReqBase.Copy()
+	tReq3.AddCookie(testEnv.fixtures.Tokens.ManagerAccessToken)
+	mt.AddTestCase(tReq3, test.NewCaseResponse(http.StatusForbidden, nil, nil))
+
+	mt.Do(t)
+}
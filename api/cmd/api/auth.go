@@ -1,7 +1,11 @@
 package main
 
 import (
+	"errors"
+	"math"
+	"net"
 	"net/http"
+	"strconv"
 
 	httptools "github.com/XDoubleU/essentia/pkg/communication/http"
 	"github.com/XDoubleU/essentia/pkg/config"
@@ -10,13 +14,16 @@ import (
 	"check-in/api/internal/constants"
 	"check-in/api/internal/dtos"
 	"check-in/api/internal/models"
+	"check-in/api/internal/services"
 )
 
 func (app *Application) authRoutes(mux *http.ServeMux) {
 	mux.HandleFunc("POST /auth/signin", app.signInHandler)
+	mux.HandleFunc("POST /auth/devices/signin", app.deviceSignInHandler)
+	mux.HandleFunc("POST /auth/pair", app.pairHandler)
 	mux.HandleFunc(
 		"GET /auth/signout",
-		app.authAccess(allRoles, app.signOutHandler),
+		app.authAccess(signedIn, app.signOutHandler),
 	)
 	mux.HandleFunc(
 		"GET /auth/refresh",
@@ -24,12 +31,14 @@ func (app *Application) authRoutes(mux *http.ServeMux) {
 	)
 }
 
-// @Summary	Sign in a user
+// @Summary	Sign in a user, with a code when two-factor authentication is enabled
 // @Tags		auth
 // @Param		signInDto	body		SignInDto	true	"SignInDto"
 // @Success	200			{object}	User
+// @Success	202			{object}	SignInPendingDto
 // @Failure	400			{object}	ErrorDto
 // @Failure	401			{object}	ErrorDto
+// @Failure	429			{object}	ErrorDto
 // @Failure	500			{object}	ErrorDto
 // @Router		/auth/signin [post].
 func (app *Application) signInHandler(w http.ResponseWriter, r *http.Request) {
@@ -46,70 +55,221 @@ func (app *Application) signInHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	user, err := app.services.Auth.SignInUser(r.Context(), signInDto)
+	user, err := app.services.Auth.SignInUser(
+		r.Context(),
+		signInDto,
+		clientIP(r),
+	)
+	if errors.Is(err, services.ErrTwoFactorPending) {
+		err = httptools.WriteJSON(
+			w,
+			http.StatusAccepted,
+			dtos.SignInPendingDto{TwoFactorPending: true},
+			nil,
+		)
+		if err != nil {
+			httptools.ServerErrorResponse(w, r, err)
+		}
+		return
+	}
+	if err != nil {
+		signInErrorResponse(w, r, err)
+		return
+	}
+
+	app.signIn(w, r, user, signInDto.RememberMe)
+}
+
+// @Summary	Sign in a kiosk with the credentials of its device
+// @Tags		auth
+// @Param		signInDto	body		SignInDto	true	"SignInDto"
+// @Success	200			{object}	User
+// @Failure	400			{object}	ErrorDto
+// @Failure	401			{object}	ErrorDto
+// @Failure	429			{object}	ErrorDto
+// @Failure	500			{object}	ErrorDto
+// @Router		/auth/devices/signin [post].
+func (app *Application) deviceSignInHandler(w http.ResponseWriter, r *http.Request) {
+	var signInDto dtos.SignInDto
+
+	err := httptools.ReadJSON(r.Body, &signInDto)
+	if err != nil {
+		httptools.BadRequestResponse(w, r, err)
+		return
+	}
+
+	if v, validationErrors := signInDto.Validate(); !v {
+		httptools.FailedValidationResponse(w, r, validationErrors)
+		return
+	}
+
+	user, err := app.services.Auth.SignInDevice(
+		r.Context(),
+		signInDto,
+		clientIP(r),
+	)
+	if err != nil {
+		signInErrorResponse(w, r, err)
+		return
+	}
+
+	app.signIn(w, r, user, signInDto.RememberMe)
+}
+
+// @Summary	Pair a kiosk as a new device by exchanging a pairing code
+// @Tags		auth
+// @Param		pairDto	body		PairDto	true	"PairDto"
+// @Success	200		{object}	User
+// @Failure	400		{object}	ErrorDto
+// @Failure	401		{object}	ErrorDto
+// @Failure	429		{object}	ErrorDto
+// @Failure	500		{object}	ErrorDto
+// @Router		/auth/pair [post].
+func (app *Application) pairHandler(w http.ResponseWriter, r *http.Request) {
+	var pairDto dtos.PairDto
+
+	err := httptools.ReadJSON(r.Body, &pairDto)
+	if err != nil {
+		httptools.BadRequestResponse(w, r, err)
+		return
+	}
+
+	if v, validationErrors := pairDto.Validate(); !v {
+		httptools.FailedValidationResponse(w, r, validationErrors)
+		return
+	}
+
+	user, err := app.services.Auth.SignInWithPairingCode(
+		r.Context(),
+		pairDto,
+		clientIP(r),
+	)
 	if err != nil {
+		signInErrorResponse(w, r, err)
+		return
+	}
+
+	app.signIn(w, r, user, true)
+}
+
+// signInErrorResponse responds with 429 and Retry-After while locked out.
+func signInErrorResponse(w http.ResponseWriter, r *http.Request, err error) {
+	var lockedOutError services.LockedOutError
+	if !errors.As(err, &lockedOutError) {
 		httptools.HandleError(w, r, err)
 		return
 	}
 
+	w.Header().Set(
+		"Retry-After",
+		strconv.Itoa(int(math.Ceil(lockedOutError.RetryAfter.Seconds()))),
+	)
+	httptools.ErrorResponse(w, r, http.StatusTooManyRequests, lockedOutError.Error())
+}
+
+// clientIP is the IP failed sign in attempts are counted for.
+func clientIP(r *http.Request) string {
+	ip, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+
+	return ip
+}
+
+func (app *Application) signIn(
+	w http.ResponseWriter,
+	r *http.Request,
+	user *models.User,
+	rememberMe bool,
+) {
+	err := app.setAuthCookies(w, r, user, rememberMe)
+	if err != nil {
+		httptools.ServerErrorResponse(w, r, err)
+		return
+	}
+
+	err = httptools.WriteJSON(w, http.StatusOK, user, nil)
+	if err != nil {
+		httptools.ServerErrorResponse(w, r, err)
+	}
+}
+
+func (app *Application) setAuthCookies(
+	w http.ResponseWriter,
+	r *http.Request,
+	user *models.User,
+	rememberMe bool,
+) error {
+	err := app.services.Sessions.Start(r.Context(), user, r.UserAgent(), clientIP(r))
+	if err != nil {
+		return err
+	}
+
 	secure := app.config.Env == config.ProdEnv
 	accessTokenCookie, err := app.services.Auth.CreateCookie(
 		r.Context(),
 		models.AccessScope,
-		user.ID,
+		user,
 		app.config.AccessExpiry,
 		secure,
 	)
 	if err != nil {
-		httptools.ServerErrorResponse(w, r, err)
-		return
+		return err
 	}
 
 	http.SetCookie(w, accessTokenCookie)
 
-	if user.Role != models.AdminRole && signInDto.RememberMe {
-		var refreshTokenCookie *http.Cookie
-		refreshTokenCookie, err = app.services.Auth.CreateCookie(
-			r.Context(),
-			models.RefreshScope,
-			user.ID,
-			app.config.RefreshExpiry,
-			secure,
-		)
-		if err != nil {
-			httptools.ServerErrorResponse(w, r, err)
-			return
-		}
-
-		http.SetCookie(w, refreshTokenCookie)
+	if user.Role.IsAdmin() || !rememberMe {
+		return nil
 	}
 
-	err = httptools.WriteJSON(w, http.StatusOK, user, nil)
+	refreshTokenCookie, err := app.services.Auth.CreateCookie(
+		r.Context(),
+		models.RefreshScope,
+		user,
+		app.refreshExpiry(user),
+		secure,
+	)
 	if err != nil {
-		httptools.ServerErrorResponse(w, r, err)
+		return err
 	}
+
+	http.SetCookie(w, refreshTokenCookie)
+
+	return nil
 }
 
 // @Summary	Sign out a user
 // @Tags		auth
 // @Success	200	{object}	nil
 // @Failure	401	{object}	ErrorDto
+// @Failure	403	{object}	ErrorDto
 // @Router		/auth/signout [get].
 func (app *Application) signOutHandler(w http.ResponseWriter, r *http.Request) {
+	// API keys are revoked instead of signed out
+	user := contexttools.GetValue[models.User](r.Context(), constants.UserContextKey)
+	if user.Role == models.APIKeyRole {
+		httptools.ForbiddenResponse(w, r)
+		return
+	}
+
 	accessToken, _ := r.Cookie("accessToken")
 	refreshToken, _ := r.Cookie("refreshToken")
 
-	deleteAccessToken, err := app.services.Auth.DeleteCookie(
-		r.Context(),
-		models.AccessScope,
-		accessToken.Value,
-	)
-	if err != nil {
-		httptools.ServerErrorResponse(w, r, err)
-		return
-	}
+	if accessToken != nil {
+		deleteAccessToken, err := app.services.Auth.DeleteCookie(
+			r.Context(),
+			models.AccessScope,
+			accessToken.Value,
+		)
+		if err != nil {
+			httptools.ServerErrorResponse(w, r, err)
+			return
+		}
 
-	http.SetCookie(w, deleteAccessToken)
+		http.SetCookie(w, deleteAccessToken)
+	}
 
 	if refreshToken == nil {
 		return
@@ -141,7 +301,7 @@ func (app *Application) refreshHandler(w http.ResponseWriter, r *http.Request) {
 	accessTokenCookie, err := app.services.Auth.CreateCookie(
 		r.Context(),
 		models.AccessScope,
-		user.ID,
+		user,
 		app.config.AccessExpiry,
 		secure,
 	)
@@ -155,8 +315,8 @@ func (app *Application) refreshHandler(w http.ResponseWriter, r *http.Request) {
 	refreshTokenCookie, err := app.services.Auth.CreateCookie(
 		r.Context(),
 		models.RefreshScope,
-		user.ID,
-		app.config.RefreshExpiry,
+		user,
+		app.refreshExpiry(user),
 		secure,
 	)
 	if err != nil {
@@ -169,5 +329,20 @@ func (app *Application) refreshHandler(w http.ResponseWriter, r *http.Request) {
 	err = app.services.Auth.DeleteExpiredTokens(r.Context())
 	if err != nil {
 		httptools.ServerErrorResponse(w, r, err)
+		return
 	}
+
+	err = app.services.Sessions.DeleteExpired(r.Context())
+	if err != nil {
+		httptools.ServerErrorResponse(w, r, err)
+	}
+}
+
+// refreshExpiry keeps devices signed in longer than users.
+func (app *Application) refreshExpiry(user *models.User) string {
+	if user.DeviceID.Valid {
+		return app.config.DeviceRefreshExpiry
+	}
+
+	return app.config.RefreshExpiry
 }
@@ -27,6 +27,7 @@ func TestSignInUser(t *testing.T) {
 		Username:   "Default",
 		Password:   "testpassword",
 		RememberMe: true,
+		Code:       "",
 	}
 	tReq.SetData(data)
 
@@ -61,6 +62,7 @@ func TestSignInUserNoRefresh(t *testing.T) {
 		Username:   "Default",
 		Password:   "testpassword",
 		RememberMe: false,
+		Code:       "",
 	}
 	tReq.SetData(data)
 
@@ -94,6 +96,7 @@ func TestSignInAdmin(t *testing.T) {
 		Username:   "Admin",
 		Password:   "testpassword",
 		RememberMe: true,
+		Code:       "",
 	}
 	tReq.SetData(data)
 
@@ -127,6 +130,7 @@ func TestSignInInexistentUser(t *testing.T) {
 		Username:   "inexistentuser",
 		Password:   "testpassword",
 		RememberMe: true,
+		Code:       "",
 	}
 	tReq.SetData(data)
 
@@ -153,6 +157,7 @@ func TestSignInWrongPassword(t *testing.T) {
 		Username:   "Default",
 		Password:   "wrongpassword",
 		RememberMe: true,
+		Code:       "",
 	}
 	tReq.SetData(data)
 
@@ -180,6 +185,7 @@ func TestSignInFailValidation(t *testing.T) {
 		Username:   "",
 		Password:   "",
 		RememberMe: true,
+		Code:       "",
 	})
 
 	tRes := test.NewCaseResponse(http.StatusUnprocessableEntity, nil,
@@ -230,6 +236,18 @@ func TestSignOutNoRefresh(t *testing.T) {
 	assert.Contains(t, rs.Header.Values("set-cookie")[0], "accessToken=;")
 }
 
+func TestSignOutAPIKey(t *testing.T) {
+	testEnv, testApp := setup(t)
+	defer testEnv.teardown()
+
+	apiKey := testEnv.createAPIKey(models.LocationsListPermission)
+
+	rs := doWithAPIKey(testApp.routes(), http.MethodGet, "/auth/signout", apiKey.Key)
+
+	assert.Equal(t, http.StatusForbidden, rs.Code)
+	assert.Equal(t, 0, len(rs.Header().Values("set-cookie")))
+}
+
 func TestSignOutNotLoggedIn(t *testing.T) {
 	testEnv, testApp := setup(t)
 	defer testEnv.teardown()
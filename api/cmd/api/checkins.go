@@ -5,6 +5,7 @@ import (
 
 	httptools "github.com/XDoubleU/essentia/pkg/communication/http"
 	"github.com/XDoubleU/essentia/pkg/context"
+	"github.com/XDoubleU/essentia/pkg/parse"
 
 	"check-in/api/internal/constants"
 	"check-in/api/internal/dtos"
@@ -14,11 +15,35 @@ import (
 func (app *Application) checkInsRoutes(mux *http.ServeMux) {
 	mux.HandleFunc(
 		"GET /checkins/schools",
-		app.authAccess(defaultRole, app.getSortedSchoolsHandler),
+		app.authAccess(models.CheckInsWritePermission, app.getSortedSchoolsHandler),
 	)
 	mux.HandleFunc(
 		"POST /checkins",
-		app.authAccess(defaultRole, app.createCheckInHandler),
+		app.authAccess(models.CheckInsWritePermission, app.createCheckInHandler),
+	)
+	mux.HandleFunc(
+		"POST /checkins/batch",
+		app.authAccess(models.CheckInsWritePermission, app.createCheckInBatchHandler),
+	)
+	mux.HandleFunc(
+		"POST /checkins/checkout",
+		app.authAccess(models.CheckInsWritePermission, app.checkOutAnonymousHandler),
+	)
+	mux.HandleFunc(
+		"POST /checkins/{checkInId}/checkout",
+		app.authAccess(models.CheckInsWritePermission, app.checkOutHandler),
+	)
+	mux.HandleFunc(
+		"GET /checkins/waitlist",
+		app.authAccess(models.CheckInsWritePermission, app.getWaitlistHandler),
+	)
+	mux.HandleFunc(
+		"POST /checkins/waitlist",
+		app.authAccess(models.CheckInsWritePermission, app.joinWaitlistHandler),
+	)
+	mux.HandleFunc(
+		"DELETE /checkins/waitlist/{entryId}",
+		app.authAccess(models.CheckInsWritePermission, app.leaveWaitlistHandler),
 	)
 }
 
@@ -88,3 +113,196 @@ func (app *Application) createCheckInHandler(w http.ResponseWriter, r *http.Requ
 		httptools.ServerErrorResponse(w, r, err)
 	}
 }
+
+// @Summary	Create check-ins that were queued offline at location of logged in user
+// @Tags		checkins
+// @Param		createCheckInBatchDto	body		CreateCheckInBatchDto	true	"CreateCheckInBatchDto"
+// @Success	200						{object}	CheckInBatchResultDto
+// @Failure	400						{object}	ErrorDto
+// @Failure	401						{object}	ErrorDto
+// @Failure	422						{object}	ErrorDto
+// @Failure	500						{object}	ErrorDto
+// @Router		/checkins/batch [post].
+func (app *Application) createCheckInBatchHandler(
+	w http.ResponseWriter,
+	r *http.Request,
+) {
+	var createCheckInBatchDto dtos.CreateCheckInBatchDto
+
+	err := httptools.ReadJSON(r.Body, &createCheckInBatchDto)
+	if err != nil {
+		httptools.BadRequestResponse(w, r, err)
+		return
+	}
+
+	if v, validationErrors := createCheckInBatchDto.Validate(); !v {
+		httptools.FailedValidationResponse(w, r, validationErrors)
+		return
+	}
+
+	user := context.GetValue[models.User](r.Context(), constants.UserContextKey)
+	result, err := app.services.CheckInsWriter.CreateBatch(
+		r.Context(),
+		createCheckInBatchDto,
+		user,
+	)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	err = httptools.WriteJSON(w, http.StatusOK, result, nil)
+	if err != nil {
+		httptools.ServerErrorResponse(w, r, err)
+	}
+}
+
+// @Summary	Check out a check-in at location of logged in user
+// @Tags		checkins
+// @Param		checkInId	path		int	true	"Check-In ID"
+// @Success	200			{object}	CheckInDto
+// @Failure	400			{object}	ErrorDto
+// @Failure	401			{object}	ErrorDto
+// @Failure	404			{object}	ErrorDto
+// @Failure	500			{object}	ErrorDto
+// @Router		/checkins/{checkInId}/checkout [post].
+func (app *Application) checkOutHandler(w http.ResponseWriter, r *http.Request) {
+	checkInID, err := parse.URLParam(r, "checkInId", parse.Int64(true, false))
+	if err != nil {
+		httptools.BadRequestResponse(w, r, err)
+		return
+	}
+
+	user := context.GetValue[models.User](r.Context(), constants.UserContextKey)
+	checkInDto, err := app.services.CheckInsWriter.CheckOut(
+		r.Context(),
+		user,
+		checkInID,
+	)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	err = httptools.WriteJSON(w, http.StatusOK, checkInDto, nil)
+	if err != nil {
+		httptools.ServerErrorResponse(w, r, err)
+	}
+}
+
+// @Summary	Check out the longest present visitor at location of logged in user
+// @Tags		checkins
+// @Success	200	{object}	CheckInDto
+// @Failure	400	{object}	ErrorDto
+// @Failure	401	{object}	ErrorDto
+// @Failure	500	{object}	ErrorDto
+// @Router		/checkins/checkout [post].
+func (app *Application) checkOutAnonymousHandler(
+	w http.ResponseWriter,
+	r *http.Request,
+) {
+	user := context.GetValue[models.User](r.Context(), constants.UserContextKey)
+	checkInDto, err := app.services.CheckInsWriter.CheckOutAnonymous(
+		r.Context(),
+		user,
+	)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	err = httptools.WriteJSON(w, http.StatusOK, checkInDto, nil)
+	if err != nil {
+		httptools.ServerErrorResponse(w, r, err)
+	}
+}
+
+// @Summary	Get the waitlist of today at location of logged in user
+// @Tags		checkins
+// @Success	200	{object}	[]WaitlistEntryDto
+// @Failure	401	{object}	ErrorDto
+// @Failure	500	{object}	ErrorDto
+// @Router		/checkins/waitlist [get].
+func (app *Application) getWaitlistHandler(w http.ResponseWriter, r *http.Request) {
+	user := context.GetValue[models.User](r.Context(), constants.UserContextKey)
+	entries, err := app.services.CheckInsWriter.GetWaitlist(r.Context(), user)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	err = httptools.WriteJSON(w, http.StatusOK, entries, nil)
+	if err != nil {
+		httptools.ServerErrorResponse(w, r, err)
+	}
+}
+
+// @Summary	Join the waitlist at location of logged in user
+// @Tags		checkins
+// @Param		createWaitlistEntryDto	body		CreateWaitlistEntryDto	true	"CreateWaitlistEntryDto"
+// @Success	201						{object}	WaitlistEntryDto
+// @Failure	400						{object}	ErrorDto
+// @Failure	401						{object}	ErrorDto
+// @Failure	404						{object}	ErrorDto
+// @Failure	422						{object}	ErrorDto
+// @Failure	500						{object}	ErrorDto
+// @Router		/checkins/waitlist [post].
+func (app *Application) joinWaitlistHandler(w http.ResponseWriter, r *http.Request) {
+	var createWaitlistEntryDto dtos.CreateWaitlistEntryDto
+
+	err := httptools.ReadJSON(r.Body, &createWaitlistEntryDto)
+	if err != nil {
+		httptools.BadRequestResponse(w, r, err)
+		return
+	}
+
+	if v, validationErrors := createWaitlistEntryDto.Validate(); !v {
+		httptools.FailedValidationResponse(w, r, validationErrors)
+		return
+	}
+
+	user := context.GetValue[models.User](r.Context(), constants.UserContextKey)
+	entry, err := app.services.CheckInsWriter.JoinWaitlist(
+		r.Context(),
+		createWaitlistEntryDto,
+		user,
+	)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	err = httptools.WriteJSON(w, http.StatusCreated, entry, nil)
+	if err != nil {
+		httptools.ServerErrorResponse(w, r, err)
+	}
+}
+
+// @Summary	Leave the waitlist at location of logged in user
+// @Tags		checkins
+// @Param		entryId	path		int	true	"Waitlist entry ID"
+// @Success	200		{object}	WaitlistEntryDto
+// @Failure	400		{object}	ErrorDto
+// @Failure	401		{object}	ErrorDto
+// @Failure	404		{object}	ErrorDto
+// @Failure	500		{object}	ErrorDto
+// @Router		/checkins/waitlist/{entryId} [delete].
+func (app *Application) leaveWaitlistHandler(w http.ResponseWriter, r *http.Request) {
+	entryID, err := parse.URLParam(r, "entryId", parse.Int64(true, false))
+	if err != nil {
+		httptools.BadRequestResponse(w, r, err)
+		return
+	}
+
+	user := context.GetValue[models.User](r.Context(), constants.UserContextKey)
+	entry, err := app.services.CheckInsWriter.LeaveWaitlist(r.Context(), user, entryID)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	err = httptools.WriteJSON(w, http.StatusOK, entry, nil)
+	if err != nil {
+		httptools.ServerErrorResponse(w, r, err)
+	}
+}
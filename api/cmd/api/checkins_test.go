@@ -1,9 +1,15 @@
 package main
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
 	"fmt"
 	"net/http"
+	"net/http/httptest"
+	"sync"
 	"testing"
+	"time"
 
 	httptools "github.com/XDoubleU/essentia/pkg/communication/http"
 	errortools "github.com/XDoubleU/essentia/pkg/errors"
@@ -168,6 +174,141 @@ func TestCreateCheckInAboveCap(t *testing.T) {
 	assert.Equal(t, "location has no available spots", rsData.Message)
 }
 
+func TestCreateCheckInClosed(t *testing.T) {
+	testEnv, testApp := setup(t)
+	defer testEnv.teardown()
+
+	loc, _ := time.LoadLocation(testEnv.fixtures.DefaultLocation.TimeZone)
+	today := testApp.getTimeNowUTC().In(loc)
+
+	//nolint:exhaustruct //other fields are optional
+	_, err := testApp.services.Locations.UpdateOpeningHours(
+		context.Background(),
+		testEnv.fixtures.AdminUser,
+		testEnv.fixtures.DefaultLocation.ID,
+		dtos.OpeningHoursDto{
+			ClosingDays: []models.ClosingDay{
+				{Date: today.Format(constants.DateFormat), Reason: "holiday"},
+			},
+		},
+	)
+	require.Nil(t, err)
+
+	tReq := test.CreateRequestTester(
+		testApp.routes(),
+		http.MethodPost,
+		"/checkins",
+	)
+	tReq.SetData(dtos.CreateCheckInDto{
+		SchoolID: 1,
+	})
+	tReq.AddCookie(testEnv.fixtures.Tokens.DefaultAccessToken)
+
+	rs := tReq.Do(t)
+
+	var rsData errortools.ErrorDto
+	err = httptools.ReadJSON(rs.Body, &rsData)
+	require.Nil(t, err)
+
+	assert.Equal(t, http.StatusBadRequest, rs.StatusCode)
+	assert.Equal(
+		t,
+		"location is closed, check-ins aren't allowed outside opening hours",
+		rsData.Message,
+	)
+}
+
+func TestCreateCheckInConcurrentAboveCap(t *testing.T) {
+	testEnv, testApp := setup(t)
+	defer testEnv.teardown()
+
+	remaining := 5
+	amountOfRequests := 200
+
+	testEnv.createCheckIns(
+		testEnv.fixtures.DefaultLocation,
+		1,
+		int(testEnv.fixtures.DefaultLocation.Capacity)-remaining,
+	)
+
+	ts := httptest.NewServer(testApp.routes())
+	defer ts.Close()
+
+	body, err := json.Marshal(dtos.CreateCheckInDto{
+		SchoolID: 1,
+	})
+	require.Nil(t, err)
+
+	type result struct {
+		statusCode int
+		err        error
+	}
+
+	var wg sync.WaitGroup
+	results := make(chan result, amountOfRequests)
+
+	// t mustn't be used outside of the test goroutine,
+	// so the responses are only checked after all requests are done
+	for i := 0; i < amountOfRequests; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			req, reqErr := http.NewRequest(
+				http.MethodPost,
+				ts.URL+"/checkins",
+				bytes.NewReader(body),
+			)
+			if reqErr != nil {
+				results <- result{statusCode: 0, err: reqErr}
+				return
+			}
+			req.AddCookie(testEnv.fixtures.Tokens.DefaultAccessToken)
+
+			rs, reqErr := ts.Client().Do(req)
+			if reqErr != nil {
+				results <- result{statusCode: 0, err: reqErr}
+				return
+			}
+			defer rs.Body.Close()
+
+			results <- result{statusCode: rs.StatusCode, err: nil}
+		}()
+	}
+
+	wg.Wait()
+	close(results)
+
+	created := 0
+	for result := range results {
+		require.Nil(t, result.err)
+
+		if result.statusCode == http.StatusCreated {
+			created++
+			continue
+		}
+
+		assert.Equal(t, http.StatusBadRequest, result.statusCode)
+	}
+
+	checkIns, _, err := testApp.services.Locations.GetAllCheckInsOfDay(
+		context.Background(),
+		testEnv.fixtures.Organisation.ID,
+		testEnv.fixtures.AdminUser,
+		false,
+		[]string{testEnv.fixtures.DefaultLocation.ID},
+		testApp.getTimeNowUTC(),
+	)
+	require.Nil(t, err)
+
+	assert.Equal(t, remaining, created)
+	assert.Equal(
+		t,
+		int(testEnv.fixtures.DefaultLocation.Capacity),
+		len(checkIns),
+	)
+}
+
 func TestCreateCheckInSchoolNotFound(t *testing.T) {
 	testEnv, testApp := setup(t)
 	defer testEnv.teardown()
@@ -249,3 +390,856 @@ func TestCreateCheckInAccess(t *testing.T) {
 
 	mt.Do(t)
 }
+
+func TestCreateCheckInBatch(t *testing.T) {
+	testEnv, testApp := setup(t)
+	defer testEnv.teardown()
+
+	school := testEnv.createSchools(1)[0]
+	now := testApp.getTimeNowUTC()
+
+	data := dtos.CreateCheckInBatchDto{
+		CheckIns: []dtos.BatchCheckInDto{
+			{
+				IdempotencyKey: "key-1",
+				SchoolID:       school.ID,
+				CreatedAt:      now.Add(-2 * time.Minute),
+			},
+			{
+				IdempotencyKey: "key-2",
+				SchoolID:       1,
+				CreatedAt:      now.Add(-time.Minute),
+			},
+			{
+				IdempotencyKey: "key-2",
+				SchoolID:       1,
+				CreatedAt:      now.Add(-time.Minute),
+			},
+		},
+	}
+
+	tReq := test.CreateRequestTester(
+		testApp.routes(),
+		http.MethodPost,
+		"/checkins/batch",
+	)
+	tReq.SetData(data)
+	tReq.AddCookie(testEnv.fixtures.Tokens.DefaultAccessToken)
+
+	rs := tReq.Do(t)
+
+	var rsData dtos.CheckInBatchResultDto
+	err := httptools.ReadJSON(rs.Body, &rsData)
+	require.Nil(t, err)
+
+	assert.Equal(t, http.StatusOK, rs.StatusCode)
+	require.Equal(t, 2, len(rsData.Created))
+	assert.Equal(t, school.Name, rsData.Created[0].SchoolName)
+	assert.Equal(t, "Andere", rsData.Created[1].SchoolName)
+	assert.Equal(
+		t,
+		data.CheckIns[0].CreatedAt.Unix(),
+		rsData.Created[0].CreatedAt.Time.Unix(),
+	)
+	assert.Equal(t, []string{"key-2"}, rsData.Duplicates)
+	assert.Equal(t, 0, len(rsData.Rejected))
+
+	// retrying kiosk resends the same batch
+	rs = tReq.Do(t)
+
+	err = httptools.ReadJSON(rs.Body, &rsData)
+	require.Nil(t, err)
+
+	assert.Equal(t, http.StatusOK, rs.StatusCode)
+	assert.Equal(t, 0, len(rsData.Created))
+	assert.Equal(t, []string{"key-1", "key-2", "key-2"}, rsData.Duplicates)
+}
+
+func TestCreateCheckInBatchRejected(t *testing.T) {
+	testEnv, testApp := setup(t)
+	defer testEnv.teardown()
+
+	testEnv.createCheckIns(
+		testEnv.fixtures.DefaultLocation,
+		1,
+		int(testEnv.fixtures.DefaultLocation.Capacity),
+	)
+	now := testApp.getTimeNowUTC()
+
+	tReq := test.CreateRequestTester(
+		testApp.routes(),
+		http.MethodPost,
+		"/checkins/batch",
+	)
+	tReq.SetData(dtos.CreateCheckInBatchDto{
+		CheckIns: []dtos.BatchCheckInDto{
+			{
+				IdempotencyKey: "full",
+				SchoolID:       1,
+				CreatedAt:      now,
+			},
+			{
+				IdempotencyKey: "future",
+				SchoolID:       1,
+				CreatedAt:      now.Add(time.Hour),
+			},
+			{
+				IdempotencyKey: "school",
+				SchoolID:       8000,
+				CreatedAt:      now,
+			},
+		},
+	})
+	tReq.AddCookie(testEnv.fixtures.Tokens.DefaultAccessToken)
+
+	rs := tReq.Do(t)
+
+	var rsData dtos.CheckInBatchResultDto
+	err := httptools.ReadJSON(rs.Body, &rsData)
+	require.Nil(t, err)
+
+	loc, _ := time.LoadLocation(testEnv.fixtures.DefaultLocation.TimeZone)
+
+	assert.Equal(t, http.StatusOK, rs.StatusCode)
+	assert.Equal(t, 0, len(rsData.Created))
+	assert.Equal(
+		t,
+		fmt.Sprintf(
+			"location had no available spots on %s",
+			now.In(loc).Format(constants.DateFormat),
+		),
+		rsData.Rejected["full"],
+	)
+	assert.Equal(t, "createdAt can't be in the future", rsData.Rejected["future"])
+	assert.Equal(
+		t,
+		"school with schoolId '8000' doesn't exist",
+		rsData.Rejected["school"],
+	)
+}
+
+func TestCreateCheckInBatchBeforeLaterCheckIns(t *testing.T) {
+	testEnv, testApp := setup(t)
+	defer testEnv.teardown()
+
+	// the kiosk was offline while the location filled up
+	offlineAt := testApp.getTimeNowUTC()
+	testEnv.createCheckIns(
+		testEnv.fixtures.DefaultLocation,
+		1,
+		int(testEnv.fixtures.DefaultLocation.Capacity),
+	)
+
+	tReq := test.CreateRequestTester(
+		testApp.routes(),
+		http.MethodPost,
+		"/checkins/batch",
+	)
+	tReq.SetData(dtos.CreateCheckInBatchDto{
+		CheckIns: []dtos.BatchCheckInDto{
+			{
+				IdempotencyKey: "offline",
+				SchoolID:       1,
+				CreatedAt:      offlineAt,
+			},
+		},
+	})
+	tReq.AddCookie(testEnv.fixtures.Tokens.DefaultAccessToken)
+
+	rs := tReq.Do(t)
+
+	var rsData dtos.CheckInBatchResultDto
+	err := httptools.ReadJSON(rs.Body, &rsData)
+	require.Nil(t, err)
+
+	assert.Equal(t, http.StatusOK, rs.StatusCode)
+	assert.Equal(t, 1, len(rsData.Created))
+	assert.Equal(t, 0, len(rsData.Rejected))
+}
+
+func TestCreateCheckInBatchTooOld(t *testing.T) {
+	testEnv, testApp := setup(t)
+	defer testEnv.teardown()
+
+	now := testApp.getTimeNowUTC()
+
+	tReq := test.CreateRequestTester(
+		testApp.routes(),
+		http.MethodPost,
+		"/checkins/batch",
+	)
+	tReq.SetData(dtos.CreateCheckInBatchDto{
+		CheckIns: []dtos.BatchCheckInDto{
+			{
+				IdempotencyKey: "yesterday",
+				SchoolID:       1,
+				CreatedAt:      now.AddDate(0, 0, -1),
+			},
+			{
+				IdempotencyKey: "old",
+				SchoolID:       1,
+				CreatedAt:      now.AddDate(0, 0, -3),
+			},
+		},
+	})
+	tReq.AddCookie(testEnv.fixtures.Tokens.DefaultAccessToken)
+
+	rs := tReq.Do(t)
+
+	var rsData dtos.CheckInBatchResultDto
+	err := httptools.ReadJSON(rs.Body, &rsData)
+	require.Nil(t, err)
+
+	assert.Equal(t, http.StatusOK, rs.StatusCode)
+	assert.Equal(t, 1, len(rsData.Created))
+	assert.Equal(
+		t,
+		"createdAt can't be before the previous business day",
+		rsData.Rejected["old"],
+	)
+}
+
+func TestCreateCheckInBatchClosed(t *testing.T) {
+	testEnv, testApp := setup(t)
+	defer testEnv.teardown()
+
+	now := testApp.getTimeNowUTC()
+
+	loc, _ := time.LoadLocation(testEnv.fixtures.DefaultLocation.TimeZone)
+	yesterday := now.In(loc).AddDate(0, 0, -1)
+
+	//nolint:exhaustruct //other fields are optional
+	_, err := testApp.services.Locations.UpdateOpeningHours(
+		context.Background(),
+		testEnv.fixtures.AdminUser,
+		testEnv.fixtures.DefaultLocation.ID,
+		dtos.OpeningHoursDto{
+			ClosingDays: []models.ClosingDay{
+				{Date: yesterday.Format(constants.DateFormat), Reason: "holiday"},
+			},
+		},
+	)
+	require.Nil(t, err)
+
+	tReq := test.CreateRequestTester(
+		testApp.routes(),
+		http.MethodPost,
+		"/checkins/batch",
+	)
+	tReq.SetData(dtos.CreateCheckInBatchDto{
+		CheckIns: []dtos.BatchCheckInDto{
+			{
+				IdempotencyKey: "today",
+				SchoolID:       1,
+				CreatedAt:      now,
+			},
+			{
+				IdempotencyKey: "closed",
+				SchoolID:       1,
+				CreatedAt:      yesterday,
+			},
+		},
+	})
+	tReq.AddCookie(testEnv.fixtures.Tokens.DefaultAccessToken)
+
+	rs := tReq.Do(t)
+
+	var rsData dtos.CheckInBatchResultDto
+	err = httptools.ReadJSON(rs.Body, &rsData)
+	require.Nil(t, err)
+
+	assert.Equal(t, http.StatusOK, rs.StatusCode)
+	assert.Equal(t, 1, len(rsData.Created))
+	assert.Equal(
+		t,
+		"location was closed, check-ins aren't allowed outside opening hours",
+		rsData.Rejected["closed"],
+	)
+}
+
+func TestCreateCheckInBatchFailValidation(t *testing.T) {
+	testEnv, testApp := setup(t)
+	defer testEnv.teardown()
+	tReq := test.CreateRequestTester(
+		testApp.routes(),
+		http.MethodPost,
+		"/checkins/batch",
+	)
+	tReq.AddCookie(testEnv.fixtures.Tokens.DefaultAccessToken)
+
+	//nolint:exhaustruct //other fields are optional
+	tReq.SetData(dtos.CreateCheckInBatchDto{
+		CheckIns: []dtos.BatchCheckInDto{
+			{
+				IdempotencyKey: "",
+				SchoolID:       0,
+			},
+		},
+	})
+
+	mt := test.CreateMatrixTester()
+
+	tRes := test.NewCaseResponse(http.StatusUnprocessableEntity, nil,
+		errortools.NewErrorDto(http.StatusUnprocessableEntity, map[string]interface{}{
+			"checkIns[0].idempotencyKey": "must be provided",
+			"checkIns[0].schoolId":       "must be greater than 0",
+			"checkIns[0].createdAt":      "must be provided",
+		}))
+
+	mt.AddTestCase(tReq, tRes)
+
+	mt.Do(t)
+}
+
+func TestCreateCheckInBatchAccess(t *testing.T) {
+	testEnv, testApp := setup(t)
+	defer testEnv.teardown()
+	tReqBase := test.CreateRequestTester(
+		testApp.routes(),
+		http.MethodPost,
+		"/checkins/batch",
+	)
+
+	mt := test.CreateMatrixTester()
+
+	mt.AddTestCase(tReqBase, test.NewCaseResponse(http.StatusUnauthorized, nil, nil))
+
+	tReq2 := tReqBase.Copy()
+	tReq2.AddCookie(testEnv.fixtures.Tokens.ManagerAccessToken)
+	mt.AddTestCase(tReq2, test.NewCaseResponse(http.StatusForbidden, nil, nil))
+
+	tReq3 := tReqBase.Copy()
+	tReq3.AddCookie(testEnv.fixtures.Tokens.AdminAccessToken)
+	mt.AddTestCase(tReq3, test.NewCaseResponse(http.StatusForbidden, nil, nil))
+
+	mt.Do(t)
+}
+
+func TestCheckOut(t *testing.T) {
+	testEnv, testApp := setup(t)
+	defer testEnv.teardown()
+
+	checkIns := testEnv.createCheckIns(testEnv.fixtures.DefaultLocation, 1, 3)
+
+	tReq := test.CreateRequestTester(
+		testApp.routes(),
+		http.MethodPost,
+		"/checkins/%d/checkout",
+		checkIns[0].ID,
+	)
+	tReq.AddCookie(testEnv.fixtures.Tokens.DefaultAccessToken)
+
+	rs := tReq.Do(t)
+
+	var rsData dtos.CheckInDto
+	err := httptools.ReadJSON(rs.Body, &rsData)
+	require.Nil(t, err)
+
+	assert.Equal(t, http.StatusOK, rs.StatusCode)
+	assert.Equal(t, checkIns[0].ID, rsData.ID)
+	assert.True(t, rsData.CheckedOutAt.Valid)
+
+	location, err := testApp.services.Locations.GetByID(
+		context.Background(),
+		testEnv.fixtures.AdminUser,
+		testEnv.fixtures.DefaultLocation.ID,
+	)
+	require.Nil(t, err)
+
+	assert.EqualValues(t, 2, location.Occupancy)
+	assert.EqualValues(t, 3, location.CheckInsToday)
+	assert.Equal(t, location.Capacity-2, location.Available)
+
+	rs = tReq.Do(t)
+
+	var rsErr errortools.ErrorDto
+	err = httptools.ReadJSON(rs.Body, &rsErr)
+	require.Nil(t, err)
+
+	assert.Equal(t, http.StatusBadRequest, rs.StatusCode)
+	assert.Equal(t, "checkIn is already checked out", rsErr.Message)
+}
+
+func TestCheckOutNotFound(t *testing.T) {
+	testEnv, testApp := setup(t)
+	defer testEnv.teardown()
+
+	tReq := test.CreateRequestTester(
+		testApp.routes(),
+		http.MethodPost,
+		"/checkins/%d/checkout",
+		8000,
+	)
+	tReq.AddCookie(testEnv.fixtures.Tokens.DefaultAccessToken)
+
+	rs := tReq.Do(t)
+
+	var rsData errortools.ErrorDto
+	err := httptools.ReadJSON(rs.Body, &rsData)
+	require.Nil(t, err)
+
+	assert.Equal(t, http.StatusNotFound, rs.StatusCode)
+	assert.Equal(
+		t,
+		"checkIn with id '8000' doesn't exist",
+		rsData.Message.(map[string]interface{})["id"].(string),
+	)
+}
+
+func TestCheckOutAnonymous(t *testing.T) {
+	testEnv, testApp := setup(t)
+	defer testEnv.teardown()
+
+	checkIns := testEnv.createCheckIns(testEnv.fixtures.DefaultLocation, 1, 2)
+
+	tReq := test.CreateRequestTester(
+		testApp.routes(),
+		http.MethodPost,
+		"/checkins/checkout",
+	)
+	tReq.AddCookie(testEnv.fixtures.Tokens.DefaultAccessToken)
+
+	for _, checkIn := range checkIns {
+		rs := tReq.Do(t)
+
+		var rsData dtos.CheckInDto
+		err := httptools.ReadJSON(rs.Body, &rsData)
+		require.Nil(t, err)
+
+		assert.Equal(t, http.StatusOK, rs.StatusCode)
+		assert.Equal(t, checkIn.ID, rsData.ID)
+		assert.True(t, rsData.CheckedOutAt.Valid)
+	}
+
+	rs := tReq.Do(t)
+
+	var rsData errortools.ErrorDto
+	err := httptools.ReadJSON(rs.Body, &rsData)
+	require.Nil(t, err)
+
+	assert.Equal(t, http.StatusBadRequest, rs.StatusCode)
+	assert.Equal(t, "location has no checked in visitors", rsData.Message)
+}
+
+func TestCheckOutFreesSpot(t *testing.T) {
+	testEnv, testApp := setup(t)
+	defer testEnv.teardown()
+
+	testEnv.createCheckIns(
+		testEnv.fixtures.DefaultLocation,
+		1,
+		int(testEnv.fixtures.DefaultLocation.Capacity),
+	)
+
+	tReqCheckIn := test.CreateRequestTester(
+		testApp.routes(),
+		http.MethodPost,
+		"/checkins",
+	)
+	tReqCheckIn.SetData(dtos.CreateCheckInDto{
+		SchoolID: 1,
+	})
+	tReqCheckIn.AddCookie(testEnv.fixtures.Tokens.DefaultAccessToken)
+
+	rs := tReqCheckIn.Do(t)
+	assert.Equal(t, http.StatusBadRequest, rs.StatusCode)
+
+	tReqCheckOut := test.CreateRequestTester(
+		testApp.routes(),
+		http.MethodPost,
+		"/checkins/checkout",
+	)
+	tReqCheckOut.AddCookie(testEnv.fixtures.Tokens.DefaultAccessToken)
+
+	rs = tReqCheckOut.Do(t)
+	assert.Equal(t, http.StatusOK, rs.StatusCode)
+
+	rs = tReqCheckIn.Do(t)
+	assert.Equal(t, http.StatusCreated, rs.StatusCode)
+}
+
+func TestCheckOutAccess(t *testing.T) {
+	testEnv, testApp := setup(t)
+	defer testEnv.teardown()
+
+	mt := test.CreateMatrixTester()
+
+	for _, path := range []string{"/checkins/checkout", "/checkins/1/checkout"} {
+		tReqBase := test.CreateRequestTester(
+			testApp.routes(),
+			http.MethodPost,
+			path,
+		)
+
+		mt.AddTestCase(
+			tReqBase,
+			test.NewCaseResponse(http.StatusUnauthorized, nil, nil),
+		)
+
+		tReq2 := tReqBase.Copy()
+		tReq2.AddCookie(testEnv.fixtures.Tokens.ManagerAccessToken)
+		mt.AddTestCase(tReq2, test.NewCaseResponse(http.StatusForbidden, nil, nil))
+
+		tReq3 := tReqBase.Copy()
+		tReq3.AddCookie(testEnv.fixtures.Tokens.AdminAccessToken)
+		mt.AddTestCase(tReq3, test.NewCaseResponse(http.StatusForbidden, nil, nil))
+	}
+
+	mt.Do(t)
+}
+
+func TestJoinWaitlist(t *testing.T) {
+	testEnv, testApp := setup(t)
+	defer testEnv.teardown()
+
+	testEnv.createCheckIns(
+		testEnv.fixtures.DefaultLocation,
+		1,
+		int(testEnv.fixtures.DefaultLocation.Capacity),
+	)
+
+	shortCode := "A12"
+
+	tReq := test.CreateRequestTester(
+		testApp.routes(),
+		http.MethodPost,
+		"/checkins/waitlist",
+	)
+	tReq.SetData(dtos.CreateWaitlistEntryDto{
+		SchoolID:  1,
+		ShortCode: &shortCode,
+	})
+	tReq.AddCookie(testEnv.fixtures.Tokens.DefaultAccessToken)
+
+	rs := tReq.Do(t)
+
+	var rsData dtos.WaitlistEntryDto
+	err := httptools.ReadJSON(rs.Body, &rsData)
+	require.Nil(t, err)
+
+	assert.Equal(t, http.StatusCreated, rs.StatusCode)
+	assert.Equal(t, testEnv.fixtures.DefaultLocation.ID, rsData.LocationID)
+	assert.Equal(t, "Andere", rsData.SchoolName)
+	assert.Equal(t, shortCode, rsData.ShortCode.String)
+	assert.EqualValues(t, 1, rsData.Position)
+	assert.False(t, rsData.PromotedAt.Valid)
+
+	tReqGet := test.CreateRequestTester(
+		testApp.routes(),
+		http.MethodGet,
+		"/checkins/waitlist",
+	)
+	tReqGet.AddCookie(testEnv.fixtures.Tokens.DefaultAccessToken)
+
+	rs = tReqGet.Do(t)
+
+	var rsGetData []dtos.WaitlistEntryDto
+	err = httptools.ReadJSON(rs.Body, &rsGetData)
+	require.Nil(t, err)
+
+	assert.Equal(t, http.StatusOK, rs.StatusCode)
+	assert.Equal(t, 1, len(rsGetData))
+	assert.Equal(t, rsData.ID, rsGetData[0].ID)
+
+	location, err := testApp.services.Locations.GetByID(
+		context.Background(),
+		testEnv.fixtures.AdminUser,
+		testEnv.fixtures.DefaultLocation.ID,
+	)
+	require.Nil(t, err)
+
+	assert.EqualValues(t, 1, location.WaitlistLength)
+}
+
+func TestJoinWaitlistAvailableSpots(t *testing.T) {
+	testEnv, testApp := setup(t)
+	defer testEnv.teardown()
+
+	tReq := test.CreateRequestTester(
+		testApp.routes(),
+		http.MethodPost,
+		"/checkins/waitlist",
+	)
+	tReq.SetData(dtos.CreateWaitlistEntryDto{
+		SchoolID:  1,
+		ShortCode: nil,
+	})
+	tReq.AddCookie(testEnv.fixtures.Tokens.DefaultAccessToken)
+
+	rs := tReq.Do(t)
+
+	var rsData errortools.ErrorDto
+	err := httptools.ReadJSON(rs.Body, &rsData)
+	require.Nil(t, err)
+
+	assert.Equal(t, http.StatusBadRequest, rs.StatusCode)
+	assert.Equal(t, "location has available spots, check in instead", rsData.Message)
+}
+
+func TestWaitlistPromotedOnCheckOut(t *testing.T) {
+	testEnv, testApp := setup(t)
+	defer testEnv.teardown()
+
+	testEnv.createCheckIns(
+		testEnv.fixtures.DefaultLocation,
+		1,
+		int(testEnv.fixtures.DefaultLocation.Capacity),
+	)
+
+	tReqJoin := test.CreateRequestTester(
+		testApp.routes(),
+		http.MethodPost,
+		"/checkins/waitlist",
+	)
+	tReqJoin.SetData(dtos.CreateWaitlistEntryDto{
+		SchoolID:  1,
+		ShortCode: nil,
+	})
+	tReqJoin.AddCookie(testEnv.fixtures.Tokens.DefaultAccessToken)
+
+	rs := tReqJoin.Do(t)
+	assert.Equal(t, http.StatusCreated, rs.StatusCode)
+
+	rs = tReqJoin.Do(t)
+	assert.Equal(t, http.StatusCreated, rs.StatusCode)
+
+	tReqCheckOut := test.CreateRequestTester(
+		testApp.routes(),
+		http.MethodPost,
+		"/checkins/checkout",
+	)
+	tReqCheckOut.AddCookie(testEnv.fixtures.Tokens.DefaultAccessToken)
+
+	rs = tReqCheckOut.Do(t)
+	assert.Equal(t, http.StatusOK, rs.StatusCode)
+
+	location, err := testApp.services.Locations.GetByID(
+		context.Background(),
+		testEnv.fixtures.AdminUser,
+		testEnv.fixtures.DefaultLocation.ID,
+	)
+	require.Nil(t, err)
+
+	assert.EqualValues(t, 1, location.WaitlistLength)
+	assert.EqualValues(t, 0, location.Available)
+	assert.Equal(t, location.Capacity, location.Occupancy)
+	assert.Equal(t, location.Capacity+1, location.CheckInsToday)
+}
+
+func TestWaitlistPromotedOnScheduledCapacity(t *testing.T) {
+	testEnv, testApp := setup(t)
+	defer testEnv.teardown()
+
+	testEnv.createCheckIns(
+		testEnv.fixtures.DefaultLocation,
+		1,
+		int(testEnv.fixtures.DefaultLocation.Capacity),
+	)
+
+	tReqJoin := test.CreateRequestTester(
+		testApp.routes(),
+		http.MethodPost,
+		"/checkins/waitlist",
+	)
+	tReqJoin.SetData(dtos.CreateWaitlistEntryDto{
+		SchoolID:  1,
+		ShortCode: nil,
+	})
+	tReqJoin.AddCookie(testEnv.fixtures.Tokens.DefaultAccessToken)
+
+	rs := tReqJoin.Do(t)
+	assert.Equal(t, http.StatusCreated, rs.StatusCode)
+
+	rs = tReqJoin.Do(t)
+	assert.Equal(t, http.StatusCreated, rs.StatusCode)
+
+	change, err := testApp.services.Locations.ScheduleCapacityChange(
+		context.Background(),
+		testEnv.fixtures.AdminUser,
+		testEnv.fixtures.DefaultLocation.ID,
+		dtos.CapacityChangeDto{
+			Capacity:      testEnv.fixtures.DefaultLocation.Capacity + 1,
+			EffectiveFrom: testApp.getTimeNowUTC().Add(time.Hour),
+		},
+	)
+	require.Nil(t, err)
+
+	// nothing changes before the capacity change is in force
+	err = testApp.services.Locations.ApplyDueCapacityChanges(context.Background())
+	require.Nil(t, err)
+
+	location, err := testApp.services.Locations.GetByID(
+		context.Background(),
+		testEnv.fixtures.AdminUser,
+		testEnv.fixtures.DefaultLocation.ID,
+	)
+	require.Nil(t, err)
+	assert.EqualValues(t, 2, location.WaitlistLength)
+
+	_, err = postgresDB.Exec(
+		context.Background(),
+		"UPDATE capacity_changes SET effective_from = $1 WHERE id = $2",
+		testApp.getTimeNowUTC().Add(-time.Minute),
+		change.ID,
+	)
+	require.Nil(t, err)
+
+	err = testApp.services.Locations.ApplyDueCapacityChanges(context.Background())
+	require.Nil(t, err)
+
+	location, err = testApp.services.Locations.GetByID(
+		context.Background(),
+		testEnv.fixtures.AdminUser,
+		testEnv.fixtures.DefaultLocation.ID,
+	)
+	require.Nil(t, err)
+
+	assert.Equal(t, testEnv.fixtures.DefaultLocation.Capacity+1, location.Capacity)
+	assert.EqualValues(t, 1, location.WaitlistLength)
+	assert.EqualValues(t, 0, location.Available)
+	assert.Equal(t, location.Capacity, location.CheckInsToday)
+
+	// the promotion has no actor but is visible to the organisation
+	tReqAudit := test.CreateRequestTester(
+		testApp.routes(),
+		http.MethodGet,
+		"/audit",
+	)
+	tReqAudit.SetQuery(map[string][]string{
+		"entityType": {"waitlistEntry"},
+		"action":     {string(models.AuditUpdate)},
+	})
+	tReqAudit.AddCookie(testEnv.fixtures.Tokens.AdminAccessToken)
+
+	rs = tReqAudit.Do(t)
+
+	var rsData dtos.PaginatedAuditEventsDto
+	err = httptools.ReadJSON(rs.Body, &rsData)
+	require.Nil(t, err)
+
+	assert.Equal(t, http.StatusOK, rs.StatusCode)
+	assert.Equal(t, 1, len(rsData.Data))
+}
+
+func TestLeaveWaitlist(t *testing.T) {
+	testEnv, testApp := setup(t)
+	defer testEnv.teardown()
+
+	testEnv.createCheckIns(
+		testEnv.fixtures.DefaultLocation,
+		1,
+		int(testEnv.fixtures.DefaultLocation.Capacity),
+	)
+
+	tReqJoin := test.CreateRequestTester(
+		testApp.routes(),
+		http.MethodPost,
+		"/checkins/waitlist",
+	)
+	tReqJoin.SetData(dtos.CreateWaitlistEntryDto{
+		SchoolID:  1,
+		ShortCode: nil,
+	})
+	tReqJoin.AddCookie(testEnv.fixtures.Tokens.DefaultAccessToken)
+
+	rs := tReqJoin.Do(t)
+
+	var entry dtos.WaitlistEntryDto
+	err := httptools.ReadJSON(rs.Body, &entry)
+	require.Nil(t, err)
+
+	tReq := test.CreateRequestTester(
+		testApp.routes(),
+		http.MethodDelete,
+		"/checkins/waitlist/%d",
+		entry.ID,
+	)
+	tReq.AddCookie(testEnv.fixtures.Tokens.DefaultAccessToken)
+
+	rs = tReq.Do(t)
+
+	var rsData dtos.WaitlistEntryDto
+	err = httptools.ReadJSON(rs.Body, &rsData)
+	require.Nil(t, err)
+
+	assert.Equal(t, http.StatusOK, rs.StatusCode)
+	assert.Equal(t, entry.ID, rsData.ID)
+
+	rs = tReq.Do(t)
+	assert.Equal(t, http.StatusNotFound, rs.StatusCode)
+}
+
+func TestJoinWaitlistFailValidation(t *testing.T) {
+	testEnv, testApp := setup(t)
+	defer testEnv.teardown()
+
+	shortCode := "ABCDEFGHIJKLMNOPQ"
+
+	tReq := test.CreateRequestTester(
+		testApp.routes(),
+		http.MethodPost,
+		"/checkins/waitlist",
+	)
+	tReq.SetData(dtos.CreateWaitlistEntryDto{
+		SchoolID:  0,
+		ShortCode: &shortCode,
+	})
+	tReq.AddCookie(testEnv.fixtures.Tokens.DefaultAccessToken)
+
+	rs := tReq.Do(t)
+
+	var rsData errortools.ErrorDto
+	err := httptools.ReadJSON(rs.Body, &rsData)
+	require.Nil(t, err)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, rs.StatusCode)
+	assert.Equal(
+		t,
+		"must be greater than 0",
+		rsData.Message.(map[string]interface{})["schoolId"],
+	)
+	assert.Equal(
+		t,
+		"must be at most 16 characters long",
+		rsData.Message.(map[string]interface{})["shortCode"],
+	)
+}
+
+func TestWaitlistAccess(t *testing.T) {
+	testEnv, testApp := setup(t)
+	defer testEnv.teardown()
+
+	mt := test.CreateMatrixTester()
+
+	requests := []struct {
+		method string
+		path   string
+	}{
+		{http.MethodGet, "/checkins/waitlist"},
+		{http.MethodPost, "/checkins/waitlist"},
+		{http.MethodDelete, "/checkins/waitlist/1"},
+	}
+
+	for _, request := range requests {
+		tReqBase := test.CreateRequestTester(
+			testApp.routes(),
+			request.method,
+			request.path,
+		)
+
+		mt.AddTestCase(
+			tReqBase,
+			test.NewCaseResponse(http.StatusUnauthorized, nil, nil),
+		)
+
+		tReq2 := tReqBase.Copy()
+		tReq2.AddCookie(testEnv.fixtures.Tokens.ManagerAccessToken)
+		mt.AddTestCase(tReq2, test.NewCaseResponse(http.StatusForbidden, nil, nil))
+
+		tReq3 := tReqBase.Copy()
+		tReq3.AddCookie(testEnv.fixtures.Tokens.AdminAccessToken)
+		mt.AddTestCase(tReq3, test.NewCaseResponse(http.StatusForbidden, nil, nil))
+	}
+
+	mt.Do(t)
+}
@@ -0,0 +1,264 @@
+package main
+
+import (
+	"net/http"
+
+	httptools "github.com/XDoubleU/essentia/pkg/communication/http"
+	"github.com/XDoubleU/essentia/pkg/context"
+	"github.com/XDoubleU/essentia/pkg/parse"
+
+	"check-in/api/internal/constants"
+	"check-in/api/internal/dtos"
+	"check-in/api/internal/models"
+)
+
+func (app *Application) devicesRoutes(mux *http.ServeMux) {
+	mux.HandleFunc(
+		"GET /locations/{locationId}/devices",
+		app.authAccess(models.DevicesReadPermission, app.getDevicesHandler),
+	)
+	mux.HandleFunc(
+		"POST /locations/{locationId}/devices",
+		app.authAccess(models.DevicesWritePermission, app.createDeviceHandler),
+	)
+	mux.HandleFunc(
+		"POST /locations/{locationId}/devices/pairing-codes",
+		app.authAccess(models.DevicesWritePermission, app.createPairingCodeHandler),
+	)
+	mux.HandleFunc(
+		"PATCH /locations/{locationId}/devices/{deviceId}",
+		app.authAccess(models.DevicesWritePermission, app.updateDeviceHandler),
+	)
+	mux.HandleFunc(
+		"DELETE /locations/{locationId}/devices/{deviceId}",
+		app.authAccess(models.DevicesWritePermission, app.revokeDeviceHandler),
+	)
+}
+
+// @Summary	Get all devices of location
+// @Tags		devices
+// @Param		locationId	path		string	true	"Location ID"
+// @Success	200			{object}	[]Device
+// @Failure	400			{object}	ErrorDto
+// @Failure	401			{object}	ErrorDto
+// @Failure	404			{object}	ErrorDto
+// @Failure	500			{object}	ErrorDto
+// @Router		/locations/{locationId}/devices [get].
+func (app *Application) getDevicesHandler(w http.ResponseWriter, r *http.Request) {
+	locationID, err := parse.URLParam(r, "locationId", parse.UUID)
+	if err != nil {
+		httptools.BadRequestResponse(w, r, err)
+		return
+	}
+
+	user := context.GetValue[models.User](r.Context(), constants.UserContextKey)
+	devices, err := app.services.Devices.GetAll(r.Context(), user, locationID)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	err = httptools.WriteJSON(w, http.StatusOK, devices, nil)
+	if err != nil {
+		httptools.ServerErrorResponse(w, r, err)
+	}
+}
+
+// @Summary	Create device for location
+// @Tags		devices
+// @Param		locationId		path		string			true	"Location ID"
+// @Param		createDeviceDto	body		CreateDeviceDto	true	"CreateDeviceDto"
+// @Success	201				{object}	Device
+// @Failure	400				{object}	ErrorDto
+// @Failure	401				{object}	ErrorDto
+// @Failure	404				{object}	ErrorDto
+// @Failure	409				{object}	ErrorDto
+// @Failure	422				{object}	ErrorDto
+// @Failure	500				{object}	ErrorDto
+// @Router		/locations/{locationId}/devices [post].
+func (app *Application) createDeviceHandler(w http.ResponseWriter, r *http.Request) {
+	var createDeviceDto dtos.CreateDeviceDto
+
+	locationID, err := parse.URLParam(r, "locationId", parse.UUID)
+	if err != nil {
+		httptools.BadRequestResponse(w, r, err)
+		return
+	}
+
+	err = httptools.ReadJSON(r.Body, &createDeviceDto)
+	if err != nil {
+		httptools.BadRequestResponse(w, r, err)
+		return
+	}
+
+	if v, validationErrors := createDeviceDto.Validate(); !v {
+		httptools.FailedValidationResponse(w, r, validationErrors)
+		return
+	}
+
+	user := context.GetValue[models.User](r.Context(), constants.UserContextKey)
+	device, err := app.services.Devices.Create(
+		r.Context(),
+		user,
+		locationID,
+		createDeviceDto,
+	)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	err = httptools.WriteJSON(w, http.StatusCreated, device, nil)
+	if err != nil {
+		httptools.ServerErrorResponse(w, r, err)
+	}
+}
+
+// @Summary	Create pairing code for a new device of location
+// @Tags		devices
+// @Param		locationId				path		string					true	"Location ID"
+// @Param		createPairingCodeDto	body		CreatePairingCodeDto	true	"CreatePairingCodeDto"
+// @Success	201						{object}	PairingCode
+// @Failure	400						{object}	ErrorDto
+// @Failure	401						{object}	ErrorDto
+// @Failure	404						{object}	ErrorDto
+// @Failure	422						{object}	ErrorDto
+// @Failure	500						{object}	ErrorDto
+// @Router		/locations/{locationId}/devices/pairing-codes [post].
+func (app *Application) createPairingCodeHandler(w http.ResponseWriter,
+	r *http.Request) {
+	var createPairingCodeDto dtos.CreatePairingCodeDto
+
+	locationID, err := parse.URLParam(r, "locationId", parse.UUID)
+	if err != nil {
+		httptools.BadRequestResponse(w, r, err)
+		return
+	}
+
+	err = httptools.ReadJSON(r.Body, &createPairingCodeDto)
+	if err != nil {
+		httptools.BadRequestResponse(w, r, err)
+		return
+	}
+
+	if v, validationErrors := createPairingCodeDto.Validate(); !v {
+		httptools.FailedValidationResponse(w, r, validationErrors)
+		return
+	}
+
+	user := context.GetValue[models.User](r.Context(), constants.UserContextKey)
+	pairingCode, err := app.services.Devices.CreatePairingCode(
+		r.Context(),
+		user,
+		locationID,
+		createPairingCodeDto,
+		app.config.PairingCodeExpiry,
+	)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	err = httptools.WriteJSON(w, http.StatusCreated, pairingCode, nil)
+	if err != nil {
+		httptools.ServerErrorResponse(w, r, err)
+	}
+}
+
+// @Summary	Rename device of location
+// @Tags		devices
+// @Param		locationId		path		string			true	"Location ID"
+// @Param		deviceId		path		string			true	"Device ID"
+// @Param		updateDeviceDto	body		UpdateDeviceDto	true	"UpdateDeviceDto"
+// @Success	200				{object}	Device
+// @Failure	400				{object}	ErrorDto
+// @Failure	401				{object}	ErrorDto
+// @Failure	404				{object}	ErrorDto
+// @Failure	422				{object}	ErrorDto
+// @Failure	500				{object}	ErrorDto
+// @Router		/locations/{locationId}/devices/{deviceId} [patch].
+func (app *Application) updateDeviceHandler(w http.ResponseWriter, r *http.Request) {
+	var updateDeviceDto dtos.UpdateDeviceDto
+
+	locationID, err := parse.URLParam(r, "locationId", parse.UUID)
+	if err != nil {
+		httptools.BadRequestResponse(w, r, err)
+		return
+	}
+
+	deviceID, err := parse.URLParam(r, "deviceId", parse.UUID)
+	if err != nil {
+		httptools.BadRequestResponse(w, r, err)
+		return
+	}
+
+	err = httptools.ReadJSON(r.Body, &updateDeviceDto)
+	if err != nil {
+		httptools.BadRequestResponse(w, r, err)
+		return
+	}
+
+	if v, validationErrors := updateDeviceDto.Validate(); !v {
+		httptools.FailedValidationResponse(w, r, validationErrors)
+		return
+	}
+
+	user := context.GetValue[models.User](r.Context(), constants.UserContextKey)
+	device, err := app.services.Devices.Rename(
+		r.Context(),
+		user,
+		locationID,
+		deviceID,
+		updateDeviceDto,
+	)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	err = httptools.WriteJSON(w, http.StatusOK, device, nil)
+	if err != nil {
+		httptools.ServerErrorResponse(w, r, err)
+	}
+}
+
+// @Summary	Revoke device of location, this signs out the device
+// @Tags		devices
+// @Param		locationId	path		string	true	"Location ID"
+// @Param		deviceId	path		string	true	"Device ID"
+// @Success	200			{object}	Device
+// @Failure	400			{object}	ErrorDto
+// @Failure	401			{object}	ErrorDto
+// @Failure	404			{object}	ErrorDto
+// @Failure	500			{object}	ErrorDto
+// @Router		/locations/{locationId}/devices/{deviceId} [delete].
+func (app *Application) revokeDeviceHandler(w http.ResponseWriter, r *http.Request) {
+	locationID, err := parse.URLParam(r, "locationId", parse.UUID)
+	if err != nil {
+		httptools.BadRequestResponse(w, r, err)
+		return
+	}
+
+	deviceID, err := parse.URLParam(r, "deviceId", parse.UUID)
+	if err != nil {
+		httptools.BadRequestResponse(w, r, err)
+		return
+	}
+
+	user := context.GetValue[models.User](r.Context(), constants.UserContextKey)
+	device, err := app.services.Devices.Revoke(
+		r.Context(),
+		user,
+		locationID,
+		deviceID,
+	)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	err = httptools.WriteJSON(w, http.StatusOK, device, nil)
+	if err != nil {
+		httptools.ServerErrorResponse(w, r, err)
+	}
+}
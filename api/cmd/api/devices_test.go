@@ -0,0 +1,564 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	httptools "github.com/XDoubleU/essentia/pkg/communication/http"
+	errortools "github.com/XDoubleU/essentia/pkg/errors"
+	"github.com/XDoubleU/essentia/pkg/test"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"check-in/api/internal/dtos"
+	"check-in/api/internal/models"
+)
+
+func (env *TestEnv) createDevices(amount int) []*models.Device {
+	devices := []*models.Device{}
+
+	for i := 0; i < amount; i++ {
+		device, err := env.app.services.Devices.Create(
+			env.ctx,
+			env.fixtures.AdminUser,
+			env.fixtures.DefaultLocation.ID,
+			dtos.CreateDeviceDto{
+				Name:     fmt.Sprintf("Tablet %d", i),
+				Username: fmt.Sprintf("tablet%d", i),
+				Password: "testpassword",
+			},
+		)
+		if err != nil {
+			panic(err)
+		}
+
+		devices = append(devices, device)
+	}
+
+	return devices
+}
+
+func (env *TestEnv) createDeviceTokens(
+	device *models.Device,
+) (*http.Cookie, *http.Cookie) {
+	user := *env.fixtures.DefaultUser
+	user.DeviceID = pgtype.Text{String: device.ID, Valid: true}
+
+	return env.createAccessToken(user), env.createRefreshToken(user)
+}
+
+func TestGetDevices(t *testing.T) {
+	testEnv, testApp := setup(t)
+	defer testEnv.teardown()
+
+	devices := testEnv.createDevices(2)
+
+	tReq := test.CreateRequestTester(
+		testApp.routes(),
+		http.MethodGet,
+		"/locations/%s/devices",
+		testEnv.fixtures.DefaultLocation.ID,
+	)
+	tReq.AddCookie(testEnv.fixtures.Tokens.ManagerAccessToken)
+
+	rs := tReq.Do(t)
+
+	var rsData []models.Device
+	err := httptools.ReadJSON(rs.Body, &rsData)
+	require.Nil(t, err)
+
+	assert.Equal(t, http.StatusOK, rs.StatusCode)
+	assert.Equal(t, 2, len(rsData))
+	assert.Equal(t, devices[0].ID, rsData[0].ID)
+	assert.Equal(t, devices[0].Name, rsData[0].Name)
+	assert.Equal(t, 0, len(rsData[0].PasswordHash))
+	assert.Equal(t, false, rsData[0].RevokedAt.Valid)
+}
+
+func TestGetDevicesAccess(t *testing.T) {
+	testEnv, testApp := setup(t)
+	defer testEnv.teardown()
+
+	tReqBase := test.CreateRequestTester(
+		testApp.routes(),
+		http.MethodGet,
+		"/locations/%s/devices",
+		testEnv.fixtures.DefaultLocation.ID,
+	)
+
+	mt := test.CreateMatrixTester()
+
+	mt.AddTestCase(tReqBase, test.NewCaseResponse(http.StatusUnauthorized, nil, nil))
+
+	tReq2 := tReqBase.Copy()
+	tReq2.AddCookie(testEnv.fixtures.Tokens.DefaultAccessToken)
+
+	mt.AddTestCase(tReq2, test.NewCaseResponse(http.StatusForbidden, nil, nil))
+
+	mt.Do(t)
+}
+
+func TestCreateDevice(t *testing.T) {
+	testEnv, testApp := setup(t)
+	defer testEnv.teardown()
+
+	data := dtos.CreateDeviceDto{
+		Name:     "Entrance",
+		Username: "entrance",
+		Password: "testpassword",
+	}
+
+	tReq := test.CreateRequestTester(
+		testApp.routes(),
+		http.MethodPost,
+		"/locations/%s/devices",
+		testEnv.fixtures.DefaultLocation.ID,
+	)
+	tReq.AddCookie(testEnv.fixtures.Tokens.ManagerAccessToken)
+	tReq.SetData(data)
+
+	rs := tReq.Do(t)
+
+	var rsData models.Device
+	err := httptools.ReadJSON(rs.Body, &rsData)
+	require.Nil(t, err)
+
+	assert.Equal(t, http.StatusCreated, rs.StatusCode)
+	assert.Equal(t, data.Name, rsData.Name)
+	assert.Equal(t, data.Username, rsData.Username.String)
+	assert.Equal(t, testEnv.fixtures.DefaultLocation.ID, rsData.LocationID)
+}
+
+func TestCreateDeviceUsernameExists(t *testing.T) {
+	testEnv, testApp := setup(t)
+	defer testEnv.teardown()
+
+	device := testEnv.createDevices(1)[0]
+
+	tReq := test.CreateRequestTester(
+		testApp.routes(),
+		http.MethodPost,
+		"/locations/%s/devices",
+		testEnv.fixtures.DefaultLocation.ID,
+	)
+	tReq.AddCookie(testEnv.fixtures.Tokens.ManagerAccessToken)
+	tReq.SetData(dtos.CreateDeviceDto{
+		Name:     "Entrance",
+		Username: device.Username.String,
+		Password: "testpassword",
+	})
+
+	rs := tReq.Do(t)
+
+	assert.Equal(t, http.StatusConflict, rs.StatusCode)
+}
+
+func TestCreateDeviceFailValidation(t *testing.T) {
+	testEnv, testApp := setup(t)
+	defer testEnv.teardown()
+
+	tReq := test.CreateRequestTester(
+		testApp.routes(),
+		http.MethodPost,
+		"/locations/%s/devices",
+		testEnv.fixtures.DefaultLocation.ID,
+	)
+	tReq.AddCookie(testEnv.fixtures.Tokens.ManagerAccessToken)
+	tReq.SetData(dtos.CreateDeviceDto{
+		Name:     "",
+		Username: "",
+		Password: "",
+	})
+
+	mt := test.CreateMatrixTester()
+
+	mt.AddTestCase(tReq, test.NewCaseResponse(http.StatusUnprocessableEntity, nil,
+		errortools.NewErrorDto(http.StatusUnprocessableEntity, map[string]interface{}{
+			"name":     "must be provided",
+			"username": "must be provided",
+			"password": "must be provided",
+		})))
+
+	mt.Do(t)
+}
+
+func TestUpdateDevice(t *testing.T) {
+	testEnv, testApp := setup(t)
+	defer testEnv.teardown()
+
+	device := testEnv.createDevices(1)[0]
+
+	data := dtos.UpdateDeviceDto{
+		Name: "Exit",
+	}
+
+	tReq := test.CreateRequestTester(
+		testApp.routes(),
+		http.MethodPatch,
+		"/locations/%s/devices/%s",
+		testEnv.fixtures.DefaultLocation.ID,
+		device.ID,
+	)
+	tReq.AddCookie(testEnv.fixtures.Tokens.ManagerAccessToken)
+	tReq.SetData(data)
+
+	rs := tReq.Do(t)
+
+	var rsData models.Device
+	err := httptools.ReadJSON(rs.Body, &rsData)
+	require.Nil(t, err)
+
+	assert.Equal(t, http.StatusOK, rs.StatusCode)
+	assert.Equal(t, device.ID, rsData.ID)
+	assert.Equal(t, data.Name, rsData.Name)
+}
+
+func TestUpdateDeviceNotFound(t *testing.T) {
+	testEnv, testApp := setup(t)
+	defer testEnv.teardown()
+
+	tReq := test.CreateRequestTester(
+		testApp.routes(),
+		http.MethodPatch,
+		"/locations/%s/devices/%s",
+		testEnv.fixtures.DefaultLocation.ID,
+		"00000000-0000-0000-0000-000000000000",
+	)
+	tReq.AddCookie(testEnv.fixtures.Tokens.ManagerAccessToken)
+	tReq.SetData(dtos.UpdateDeviceDto{
+		Name: "Exit",
+	})
+
+	rs := tReq.Do(t)
+
+	assert.Equal(t, http.StatusNotFound, rs.StatusCode)
+}
+
+func TestRevokeDevice(t *testing.T) {
+	testEnv, testApp := setup(t)
+	defer testEnv.teardown()
+
+	devices := testEnv.createDevices(2)
+	accessToken1, _ := testEnv.createDeviceTokens(devices[0])
+	accessToken2, _ := testEnv.createDeviceTokens(devices[1])
+
+	tReq := test.CreateRequestTester(
+		testApp.routes(),
+		http.MethodDelete,
+		"/locations/%s/devices/%s",
+		testEnv.fixtures.DefaultLocation.ID,
+		devices[0].ID,
+	)
+	tReq.AddCookie(testEnv.fixtures.Tokens.ManagerAccessToken)
+
+	rs1 := tReq.Do(t)
+
+	var rsData models.Device
+	err := httptools.ReadJSON(rs1.Body, &rsData)
+	require.Nil(t, err)
+
+	assert.Equal(t, http.StatusOK, rs1.StatusCode)
+	assert.Equal(t, true, rsData.RevokedAt.Valid)
+
+	rs2 := tReq.Do(t)
+	assert.Equal(t, http.StatusBadRequest, rs2.StatusCode)
+
+	tReqCurrentUser := test.CreateRequestTester(
+		testApp.routes(),
+		http.MethodGet,
+		"/current-user",
+	)
+
+	tReq3 := tReqCurrentUser.Copy()
+	tReq3.AddCookie(accessToken1)
+
+	rs3 := tReq3.Do(t)
+	assert.Equal(t, http.StatusUnauthorized, rs3.StatusCode)
+
+	tReq4 := tReqCurrentUser.Copy()
+	tReq4.AddCookie(accessToken2)
+
+	rs4 := tReq4.Do(t)
+	assert.Equal(t, http.StatusOK, rs4.StatusCode)
+
+	tReq5 := test.CreateRequestTester(
+		testApp.routes(),
+		http.MethodPost,
+		"/auth/devices/signin",
+	)
+	tReq5.SetData(dtos.SignInDto{
+		Username:   devices[0].Username.String,
+		Password:   "testpassword",
+		RememberMe: true,
+		Code:       "",
+	})
+
+	rs5 := tReq5.Do(t)
+	assert.Equal(t, http.StatusUnauthorized, rs5.StatusCode)
+}
+
+func TestSignInDevice(t *testing.T) {
+	testEnv, testApp := setup(t)
+	defer testEnv.teardown()
+
+	device := testEnv.createDevices(1)[0]
+
+	tReq := test.CreateRequestTester(
+		testApp.routes(),
+		http.MethodPost,
+		"/auth/devices/signin",
+	)
+	tReq.SetData(dtos.SignInDto{
+		Username:   device.Username.String,
+		Password:   "testpassword",
+		RememberMe: true,
+		Code:       "",
+	})
+
+	rs := tReq.Do(t)
+
+	var rsData models.User
+	err := httptools.ReadJSON(rs.Body, &rsData)
+	require.Nil(t, err)
+
+	assert.Equal(t, http.StatusOK, rs.StatusCode)
+
+	assert.Equal(t, 2, len(rs.Header.Values("set-cookie")))
+	assert.Contains(t, rs.Header.Values("set-cookie")[0], "accessToken")
+	assert.Contains(t, rs.Header.Values("set-cookie")[1], "refreshToken")
+
+	assert.Equal(t, testEnv.fixtures.DefaultUser.ID, rsData.ID)
+	assert.Equal(t, device.ID, rsData.DeviceID.String)
+	assert.Equal(t, testEnv.fixtures.DefaultLocation.ID, rsData.Location.ID)
+}
+
+func TestSignInDeviceWrongPassword(t *testing.T) {
+	testEnv, testApp := setup(t)
+	defer testEnv.teardown()
+
+	device := testEnv.createDevices(1)[0]
+
+	tReq := test.CreateRequestTester(
+		testApp.routes(),
+		http.MethodPost,
+		"/auth/devices/signin",
+	)
+	tReq.SetData(dtos.SignInDto{
+		Username:   device.Username.String,
+		Password:   "wrongpassword",
+		RememberMe: true,
+		Code:       "",
+	})
+
+	rs := tReq.Do(t)
+
+	assert.Equal(t, http.StatusUnauthorized, rs.StatusCode)
+}
+
+func TestRefreshReusedDeviceToken(t *testing.T) {
+	testEnv, testApp := setup(t)
+	defer testEnv.teardown()
+
+	devices := testEnv.createDevices(2)
+	_, refreshToken1 := testEnv.createDeviceTokens(devices[0])
+	accessToken2, _ := testEnv.createDeviceTokens(devices[1])
+
+	tReq := test.CreateRequestTester(
+		testApp.routes(),
+		http.MethodGet,
+		"/auth/refresh",
+	)
+	tReq.AddCookie(refreshToken1)
+
+	rs1 := tReq.Do(t)
+	rs2 := tReq.Do(t)
+
+	assert.Equal(t, http.StatusOK, rs1.StatusCode)
+	assert.Equal(t, http.StatusUnauthorized, rs2.StatusCode)
+
+	tReqCurrentUser := test.CreateRequestTester(
+		testApp.routes(),
+		http.MethodGet,
+		"/current-user",
+	)
+
+	tReq3 := tReqCurrentUser.Copy()
+	tReq3.AddCookie(accessToken2)
+
+	rs3 := tReq3.Do(t)
+	assert.Equal(t, http.StatusOK, rs3.StatusCode)
+
+	tReq4 := tReqCurrentUser.Copy()
+	tReq4.AddCookie(testEnv.fixtures.Tokens.DefaultAccessToken)
+
+	rs4 := tReq4.Do(t)
+	assert.Equal(t, http.StatusOK, rs4.StatusCode)
+}
+
+func TestCreateCheckInRecordsDevice(t *testing.T) {
+	testEnv, testApp := setup(t)
+	defer testEnv.teardown()
+
+	device := testEnv.createDevices(1)[0]
+	accessToken, _ := testEnv.createDeviceTokens(device)
+
+	tReq := test.CreateRequestTester(
+		testApp.routes(),
+		http.MethodPost,
+		"/checkins",
+	)
+	tReq.SetData(dtos.CreateCheckInDto{
+		SchoolID: 1,
+	})
+	tReq.AddCookie(accessToken)
+
+	rs := tReq.Do(t)
+
+	var rsData dtos.CheckInDto
+	err := httptools.ReadJSON(rs.Body, &rsData)
+	require.Nil(t, err)
+
+	assert.Equal(t, http.StatusCreated, rs.StatusCode)
+
+	var deviceID pgtype.Text
+	err = postgresDB.QueryRow(
+		testEnv.ctx,
+		"SELECT device_id::text FROM check_ins WHERE id = $1",
+		rsData.ID,
+	).Scan(&deviceID)
+	require.Nil(t, err)
+
+	assert.Equal(t, device.ID, deviceID.String)
+}
+
+func TestCreatePairingCode(t *testing.T) {
+	testEnv, testApp := setup(t)
+	defer testEnv.teardown()
+
+	data := dtos.CreatePairingCodeDto{
+		Name: "Entrance",
+	}
+
+	tReq := test.CreateRequestTester(
+		testApp.routes(),
+		http.MethodPost,
+		"/locations/%s/devices/pairing-codes",
+		testEnv.fixtures.DefaultLocation.ID,
+	)
+	tReq.AddCookie(testEnv.fixtures.Tokens.ManagerAccessToken)
+	tReq.SetData(data)
+
+	rs := tReq.Do(t)
+
+	var rsData models.PairingCode
+	err := httptools.ReadJSON(rs.Body, &rsData)
+	require.Nil(t, err)
+
+	assert.Equal(t, http.StatusCreated, rs.StatusCode)
+	assert.Equal(t, 8, len(rsData.Code))
+	assert.Contains(t, rsData.QRPayload, rsData.Code)
+	assert.Equal(t, data.Name, rsData.DeviceName)
+	assert.Equal(t, testEnv.fixtures.DefaultLocation.ID, rsData.LocationID)
+	assert.Equal(t, true, rsData.Expiry.After(testApp.getTimeNowUTC()))
+}
+
+func TestCreatePairingCodeAccess(t *testing.T) {
+	testEnv, testApp := setup(t)
+	defer testEnv.teardown()
+
+	tReqBase := test.CreateRequestTester(
+		testApp.routes(),
+		http.MethodPost,
+		"/locations/%s/devices/pairing-codes",
+		testEnv.fixtures.DefaultLocation.ID,
+	)
+	tReqBase.SetData(dtos.CreatePairingCodeDto{
+		Name: "Entrance",
+	})
+
+	mt := test.CreateMatrixTester()
+
+	mt.AddTestCase(tReqBase, test.NewCaseResponse(http.StatusUnauthorized, nil, nil))
+
+	tReq2 := tReqBase.Copy()
+	tReq2.AddCookie(testEnv.fixtures.Tokens.DefaultAccessToken)
+
+	mt.AddTestCase(tReq2, test.NewCaseResponse(http.StatusForbidden, nil, nil))
+
+	mt.Do(t)
+}
+
+func TestPair(t *testing.T) {
+	testEnv, testApp := setup(t)
+	defer testEnv.teardown()
+
+	pairingCode, err := testApp.services.Devices.CreatePairingCode(
+		testEnv.ctx,
+		testEnv.fixtures.AdminUser,
+		testEnv.fixtures.DefaultLocation.ID,
+		dtos.CreatePairingCodeDto{
+			Name: "Entrance",
+		},
+		testApp.config.PairingCodeExpiry,
+	)
+	require.Nil(t, err)
+
+	tReq := test.CreateRequestTester(
+		testApp.routes(),
+		http.MethodPost,
+		"/auth/pair",
+	)
+	tReq.SetData(dtos.PairDto{
+		Code: pairingCode.Code,
+	})
+
+	rs1 := tReq.Do(t)
+
+	var rsData models.User
+	err = httptools.ReadJSON(rs1.Body, &rsData)
+	require.Nil(t, err)
+
+	assert.Equal(t, http.StatusOK, rs1.StatusCode)
+
+	assert.Equal(t, 2, len(rs1.Header.Values("set-cookie")))
+	assert.Contains(t, rs1.Header.Values("set-cookie")[0], "accessToken")
+	assert.Contains(t, rs1.Header.Values("set-cookie")[1], "refreshToken")
+
+	assert.Equal(t, testEnv.fixtures.DefaultUser.ID, rsData.ID)
+	assert.Equal(t, true, rsData.DeviceID.Valid)
+
+	devices, err := testApp.services.Devices.GetAll(
+		testEnv.ctx,
+		testEnv.fixtures.AdminUser,
+		testEnv.fixtures.DefaultLocation.ID,
+	)
+	require.Nil(t, err)
+
+	assert.Equal(t, 1, len(devices))
+	assert.Equal(t, rsData.DeviceID.String, devices[0].ID)
+	assert.Equal(t, "Entrance", devices[0].Name)
+	assert.Equal(t, false, devices[0].Username.Valid)
+
+	rs2 := tReq.Do(t)
+	assert.Equal(t, http.StatusUnauthorized, rs2.StatusCode)
+}
+
+func TestPairInvalidCode(t *testing.T) {
+	testEnv, testApp := setup(t)
+	defer testEnv.teardown()
+
+	tReq := test.CreateRequestTester(
+		testApp.routes(),
+		http.MethodPost,
+		"/auth/pair",
+	)
+	tReq.SetData(dtos.PairDto{
+		Code: "AAAAAAAA",
+	})
+
+	rs := tReq.Do(t)
+
+	assert.Equal(t, http.StatusUnauthorized, rs.StatusCode)
+}
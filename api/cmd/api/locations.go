@@ -2,8 +2,11 @@ package main
 
 import (
 	"fmt"
+	"maps"
 	"net/http"
+	"slices"
 	"strconv"
+	"time"
 
 	httptools "github.com/XDoubleU/essentia/pkg/communication/http"
 	"github.com/XDoubleU/essentia/pkg/context"
@@ -14,46 +17,87 @@ import (
 	"check-in/api/internal/models"
 )
 
+const hoursPerDay = 24
+
+// maxComparisonDays is the longest range which can be compared,
+// enough to compare a year with the previous one.
+const maxComparisonDays = 366
+
 func (app *Application) locationsRoutes(mux *http.ServeMux) {
 	mux.HandleFunc(
 		"GET /all-locations/checkins/range",
-		app.authAccess(allRoles, app.getLocationCheckInsRangeHandler),
+		app.authAccess(models.StatsReadPermission, app.getLocationCheckInsRangeHandler),
+	)
+	mux.HandleFunc(
+		"GET /all-locations/checkins/heatmap",
+		app.authAccess(models.StatsReadPermission, app.getLocationCheckInsHeatmapHandler),
+	)
+	mux.HandleFunc(
+		"GET /all-locations/checkins/comparison",
+		app.authAccess(
+			models.StatsReadPermission,
+			app.getLocationCheckInsComparisonHandler,
+		),
 	)
 	mux.HandleFunc(
 		"GET /all-locations/checkins/day",
-		app.authAccess(allRoles, app.getLocationCheckInsDayHandler),
+		app.authAccess(models.StatsReadPermission, app.getLocationCheckInsDayHandler),
 	)
 	mux.HandleFunc(
 		"GET /locations/{locationId}/checkins",
-		app.authAccess(allRoles, app.getAllCheckInsTodayHandler),
+		app.authAccess(models.StatsReadPermission, app.getAllCheckInsTodayHandler),
 	)
 	mux.HandleFunc(
 		"DELETE /locations/{locationId}/checkins/{checkInId}",
-		app.authAccess(managerAndAdminRole, app.deleteLocationCheckInHandler),
+		app.authAccess(models.CheckInsDeletePermission, app.deleteLocationCheckInHandler),
 	)
 	mux.HandleFunc(
 		"GET /locations/{locationId}",
-		app.authAccess(allRoles, app.getLocationHandler),
+		app.authAccess(models.LocationsReadPermission, app.getLocationHandler),
 	)
 	mux.HandleFunc(
 		"GET /all-locations",
-		app.authAccess(managerAndAdminRole, app.getAllLocationsHandler),
+		app.authAccess(models.LocationsListPermission, app.getAllLocationsHandler),
 	)
 	mux.HandleFunc(
 		"GET /locations",
-		app.authAccess(managerAndAdminRole, app.getPaginatedLocationsHandler),
+		app.authAccess(models.LocationsListPermission, app.getPaginatedLocationsHandler),
 	)
 	mux.HandleFunc(
 		"POST /locations",
-		app.authAccess(managerAndAdminRole, app.createLocationHandler),
+		app.authAccess(models.LocationsWritePermission, app.createLocationHandler),
 	)
 	mux.HandleFunc(
 		"PATCH /locations/{locationId}",
-		app.authAccess(allRoles, app.updateLocationHandler),
+		app.authAccess(models.LocationsUpdatePermission, app.updateLocationHandler),
+	)
+	mux.HandleFunc(
+		"GET /locations/{locationId}/opening-hours",
+		app.authAccess(models.LocationsReadPermission, app.getOpeningHoursHandler),
+	)
+	mux.HandleFunc(
+		"PUT /locations/{locationId}/opening-hours",
+		app.authAccess(models.LocationsWritePermission, app.updateOpeningHoursHandler),
+	)
+	mux.HandleFunc(
+		"GET /locations/{locationId}/capacity-history",
+		app.authAccess(models.LocationsReadPermission, app.getCapacityHistoryHandler),
+	)
+	mux.HandleFunc(
+		"POST /locations/{locationId}/capacity-history",
+		app.authAccess(models.LocationsWritePermission, app.scheduleCapacityChangeHandler),
+	)
+	mux.HandleFunc(
+		"DELETE /locations/{locationId}/capacity-history/{capacityChangeId}",
+		app.authAccess(models.LocationsWritePermission, app.deleteCapacityChangeHandler),
 	)
 	mux.HandleFunc(
 		"DELETE /locations/{locationId}",
-		app.authAccess(managerAndAdminRole, app.deleteLocationHandler),
+		app.authAccess(models.LocationsDeletePermission, app.deleteLocationHandler),
+	)
+	mux.HandleFunc(
+		"POST /locations/{locationId}/restore",
+		app.authAccess(models.LocationsRestorePermission, app.restoreLocationHandler),
 	)
 }
 
@@ -65,6 +109,7 @@ func (app *Application) locationsRoutes(mux *http.ServeMux) {
 // @Success	200			{object}	CheckInsGraphDto
 // @Failure	400			{object}	ErrorDto
 // @Failure	401			{object}	ErrorDto
+// @Failure	403			{object}	ErrorDto
 // @Failure	404			{object}	ErrorDto
 // @Failure	500			{object}	ErrorDto
 // @Router		/all-locations/checkins/day [get].
@@ -93,6 +138,11 @@ func (app *Application) getLocationCheckInsDayHandler(w http.ResponseWriter,
 	}
 
 	user := context.GetValue[models.User](r.Context(), constants.UserContextKey)
+	if returnType == "csv" && !user.HasPermission(models.StatsExportPermission) {
+		httptools.ForbiddenResponse(w, r)
+		return
+	}
+
 	dateStrings, capacities, valueMap, err := app.services.Locations.GetCheckInsEntriesDay(
 		r.Context(),
 		user,
@@ -113,10 +163,11 @@ func (app *Application) getLocationCheckInsDayHandler(w http.ResponseWriter,
 		err = httptools.WriteCSV(
 			w,
 			filename,
-			getCSVHeaders(valueMap),
-			getCSVData(dateStrings, capacities, valueMap),
+			getCSVHeaders(valueMap, nil),
+			getCSVData(dateStrings, capacities, valueMap, nil),
 		)
 	} else {
+		//nolint:exhaustruct //other fields are optional
 		err = httptools.WriteJSON(w, http.StatusOK, dtos.CheckInsGraphDto{
 			Dates:                 dateStrings,
 			CapacitiesPerLocation: capacities,
@@ -135,9 +186,11 @@ func (app *Application) getLocationCheckInsDayHandler(w http.ResponseWriter,
 // @Param		returnType	query		string		true	"ReturnType ('raw' or 'csv')"
 // @Param		startDate	query		string		true	"StartDate (format: 'yyyy-MM-dd')"
 // @Param		endDate		query		string		true	"EndDate (format: 'yyyy-MM-dd')"
+// @Param		granularity	query		string		false	"Granularity (day/week/month/year)"
 // @Success	200			{object}	CheckInsGraphDto
 // @Failure	400			{object}	ErrorDto
 // @Failure	401			{object}	ErrorDto
+// @Failure	403			{object}	ErrorDto
 // @Failure	404			{object}	ErrorDto
 // @Failure	500			{object}	ErrorDto
 // @Router		/all-locations/checkins/range [get].
@@ -177,14 +230,31 @@ func (app *Application) getLocationCheckInsRangeHandler(
 		return
 	}
 
+	granularity, err := parse.QueryParam(
+		r,
+		"granularity",
+		models.DayGranularity,
+		parseGranularity,
+	)
+	if err != nil {
+		httptools.BadRequestResponse(w, r, err)
+		return
+	}
+
 	user := context.GetValue[models.User](r.Context(), constants.UserContextKey)
+	if returnType == "csv" && !user.HasPermission(models.StatsExportPermission) {
+		httptools.ForbiddenResponse(w, r)
+		return
+	}
+
 	//nolint:lll //it is what it is
-	dateStrings, capacities, valueMap, err := app.services.Locations.GetCheckInsEntriesRange(
+	dateStrings, capacities, valueMap, closedMap, err := app.services.Locations.GetCheckInsEntriesRange(
 		r.Context(),
 		user,
 		ids,
 		startDate,
 		endDate,
+		granularity,
 	)
 	if err != nil {
 		httptools.HandleError(w, r, err)
@@ -200,14 +270,15 @@ func (app *Application) getLocationCheckInsRangeHandler(
 		err = httptools.WriteCSV(
 			w,
 			filename,
-			getCSVHeaders(valueMap),
-			getCSVData(dateStrings, capacities, valueMap),
+			getCSVHeaders(valueMap, closedMap),
+			getCSVData(dateStrings, capacities, valueMap, closedMap),
 		)
 	} else {
 		err = httptools.WriteJSON(w, http.StatusOK, dtos.CheckInsGraphDto{
 			Dates:                 dateStrings,
 			CapacitiesPerLocation: capacities,
 			ValuesPerSchool:       valueMap,
+			ClosedPerLocation:     closedMap,
 		}, nil)
 	}
 
@@ -216,14 +287,454 @@ func (app *Application) getLocationCheckInsRangeHandler(
 	}
 }
 
+func validateComparisonRange(
+	startName string,
+	endName string,
+	startDate time.Time,
+	endDate time.Time,
+) error {
+	if startDate.After(endDate) {
+		return fmt.Errorf("%s can't be after %s", startName, endName)
+	}
+
+	if endDate.After(startDate.AddDate(0, 0, maxComparisonDays-1)) {
+		return fmt.Errorf(
+			"range from %s to %s can't be longer than %d days",
+			startName,
+			endName,
+			maxComparisonDays,
+		)
+	}
+
+	return nil
+}
+
+func parseGranularity(
+	paramType string,
+	paramName string,
+	value string,
+) (models.Granularity, error) {
+	granularity := models.Granularity(value)
+	if !granularity.IsValid() {
+		return "", fmt.Errorf(
+			"invalid %s param '%s' with value '%s', should be one of "+
+				"'day', 'week', 'month' or 'year'",
+			paramType,
+			paramName,
+			value,
+		)
+	}
+
+	return granularity, nil
+}
+
+// @Summary	Get check-ins per weekday and hour at locations for a specified range
+// @Tags		locations
+// @Param		ids			query		[]string	true	"Location IDs"
+// @Param		returnType	query		string		true	"ReturnType ('raw' or 'csv')"
+// @Param		startDate	query		string		true	"StartDate (format: 'yyyy-MM-dd')"
+// @Param		endDate		query		string		true	"EndDate (format: 'yyyy-MM-dd')"
+// @Success	200			{object}	CheckInsHeatmapDto
+// @Failure	400			{object}	ErrorDto
+// @Failure	401			{object}	ErrorDto
+// @Failure	403			{object}	ErrorDto
+// @Failure	404			{object}	ErrorDto
+// @Failure	500			{object}	ErrorDto
+// @Router		/all-locations/checkins/heatmap [get].
+func (app *Application) getLocationCheckInsHeatmapHandler(
+	w http.ResponseWriter,
+	r *http.Request,
+) {
+	ids, err := parse.RequiredArrayQueryParam(r, "ids", parse.UUID)
+	if err != nil {
+		httptools.BadRequestResponse(w, r, err)
+		return
+	}
+
+	returnType, err := parse.RequiredQueryParam[string](r, "returnType", nil)
+	if err != nil {
+		httptools.BadRequestResponse(w, r, err)
+		return
+	}
+
+	startDate, err := parse.RequiredQueryParam(
+		r,
+		"startDate",
+		parse.Date(constants.DateFormat),
+	)
+	if err != nil {
+		httptools.BadRequestResponse(w, r, err)
+		return
+	}
+
+	endDate, err := parse.RequiredQueryParam(
+		r,
+		"endDate",
+		parse.Date(constants.DateFormat),
+	)
+	if err != nil {
+		httptools.BadRequestResponse(w, r, err)
+		return
+	}
+
+	err = validateComparisonRange("startDate", "endDate", startDate, endDate)
+	if err != nil {
+		httptools.BadRequestResponse(w, r, err)
+		return
+	}
+
+	user := context.GetValue[models.User](r.Context(), constants.UserContextKey)
+	if returnType == "csv" && !user.HasPermission(models.StatsExportPermission) {
+		httptools.ForbiddenResponse(w, r)
+		return
+	}
+
+	locations, valuesPerLocation, err := app.services.Locations.GetCheckInsHeatmap(
+		r.Context(),
+		user,
+		ids,
+		startDate,
+		endDate,
+	)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	if returnType == "csv" {
+		filename := app.getTimeNowUTC().
+			In(startDate.Location()).
+			Format(constants.CSVFileNameFormat)
+		filename = "Heatmap-" + filename
+
+		err = httptools.WriteCSV(
+			w,
+			filename,
+			getHeatmapCSVHeaders(),
+			getHeatmapCSVData(locations, valuesPerLocation),
+		)
+	} else {
+		err = httptools.WriteJSON(w, http.StatusOK, dtos.CheckInsHeatmapDto{
+			ValuesPerLocation: valuesPerLocation,
+		}, nil)
+	}
+
+	if err != nil {
+		httptools.ServerErrorResponse(w, r, err)
+	}
+}
+
+func getHeatmapCSVHeaders() []string {
+	headers := []string{
+		"location",
+		"weekday",
+	}
+
+	for hour := range hoursPerDay {
+		headers = append(headers, fmt.Sprintf("%02d", hour))
+	}
+
+	return headers
+}
+
+// getHeatmapCSVData has a row per location and weekday,
+// weekdays start at monday as in spreadsheets.
+func getHeatmapCSVData(
+	locations []*models.Location,
+	valuesPerLocation map[string][][]int,
+) [][]string {
+	var output [][]string
+
+	for _, location := range locations {
+		values := valuesPerLocation[location.ID]
+
+		for i := range values {
+			weekday := time.Weekday((i + 1) % len(values))
+
+			entry := []string{location.Name, weekday.String()}
+			for _, value := range values[weekday] {
+				entry = append(entry, strconv.Itoa(value))
+			}
+
+			output = append(output, entry)
+		}
+	}
+
+	return output
+}
+
+// @Summary	Compare check-ins at locations in a range with another range
+// @Tags		locations
+// @Param		ids					query		[]string	true	"Location IDs"
+// @Param		returnType			query		string		true	"ReturnType ('raw' or 'csv')"
+// @Param		startDate			query		string		true	"StartDate (format: 'yyyy-MM-dd')"
+// @Param		endDate				query		string		true	"EndDate (format: 'yyyy-MM-dd')"
+// @Param		compareStartDate	query		string		true	"CompareStartDate ('yyyy-MM-dd')"
+// @Param		compareEndDate		query		string		true	"CompareEndDate ('yyyy-MM-dd')"
+// @Success	200					{object}	CheckInsComparisonDto
+// @Failure	400					{object}	ErrorDto
+// @Failure	401					{object}	ErrorDto
+// @Failure	403					{object}	ErrorDto
+// @Failure	404					{object}	ErrorDto
+// @Failure	500					{object}	ErrorDto
+// @Router		/all-locations/checkins/comparison [get].
+func (app *Application) getLocationCheckInsComparisonHandler(
+	w http.ResponseWriter,
+	r *http.Request,
+) {
+	ids, err := parse.RequiredArrayQueryParam(r, "ids", parse.UUID)
+	if err != nil {
+		httptools.BadRequestResponse(w, r, err)
+		return
+	}
+
+	returnType, err := parse.RequiredQueryParam[string](r, "returnType", nil)
+	if err != nil {
+		httptools.BadRequestResponse(w, r, err)
+		return
+	}
+
+	startDate, err := parse.RequiredQueryParam(
+		r,
+		"startDate",
+		parse.Date(constants.DateFormat),
+	)
+	if err != nil {
+		httptools.BadRequestResponse(w, r, err)
+		return
+	}
+
+	endDate, err := parse.RequiredQueryParam(
+		r,
+		"endDate",
+		parse.Date(constants.DateFormat),
+	)
+	if err != nil {
+		httptools.BadRequestResponse(w, r, err)
+		return
+	}
+
+	compareStartDate, err := parse.RequiredQueryParam(
+		r,
+		"compareStartDate",
+		parse.Date(constants.DateFormat),
+	)
+	if err != nil {
+		httptools.BadRequestResponse(w, r, err)
+		return
+	}
+
+	compareEndDate, err := parse.RequiredQueryParam(
+		r,
+		"compareEndDate",
+		parse.Date(constants.DateFormat),
+	)
+	if err != nil {
+		httptools.BadRequestResponse(w, r, err)
+		return
+	}
+
+	err = validateComparisonRange("startDate", "endDate", startDate, endDate)
+	if err != nil {
+		httptools.BadRequestResponse(w, r, err)
+		return
+	}
+
+	err = validateComparisonRange(
+		"compareStartDate",
+		"compareEndDate",
+		compareStartDate,
+		compareEndDate,
+	)
+	if err != nil {
+		httptools.BadRequestResponse(w, r, err)
+		return
+	}
+
+	user := context.GetValue[models.User](r.Context(), constants.UserContextKey)
+	if returnType == "csv" && !user.HasPermission(models.StatsExportPermission) {
+		httptools.ForbiddenResponse(w, r)
+		return
+	}
+
+	locations, comparison, err := app.services.Locations.GetCheckInsComparison(
+		r.Context(),
+		user,
+		ids,
+		startDate,
+		endDate,
+		compareStartDate,
+		compareEndDate,
+	)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	if returnType == "csv" {
+		filename := app.getTimeNowUTC().
+			In(startDate.Location()).
+			Format(constants.CSVFileNameFormat)
+		filename = "Comparison-" + filename
+
+		err = httptools.WriteCSV(
+			w,
+			filename,
+			getComparisonCSVHeaders(),
+			getComparisonCSVData(locations, comparison),
+		)
+	} else {
+		err = httptools.WriteJSON(w, http.StatusOK, comparison, nil)
+	}
+
+	if err != nil {
+		httptools.ServerErrorResponse(w, r, err)
+	}
+}
+
+func getComparisonCSVHeaders() []string {
+	return []string{
+		"type",
+		"name",
+		"day",
+		"date",
+		"compareDate",
+		"count",
+		"compareCount",
+		"delta",
+		"percentageChange",
+	}
+}
+
+// getComparisonCSVData has a row for the total, a row per school and
+// a row per location, followed by a row per school for every day.
+// Cells of days after the end of the shorter range are empty.
+func getComparisonCSVData(
+	locations []*models.Location,
+	comparison *dtos.CheckInsComparisonDto,
+) [][]string {
+	output := [][]string{
+		getComparisonCSVEntry("total", "", "", "", "", comparison.Total),
+	}
+
+	schoolNames := slices.Sorted(maps.Keys(comparison.TotalsPerSchool))
+	for _, schoolName := range schoolNames {
+		output = append(output, getComparisonCSVEntry(
+			"school",
+			schoolName,
+			"",
+			"",
+			"",
+			comparison.TotalsPerSchool[schoolName],
+		))
+	}
+
+	for _, location := range locations {
+		output = append(output, getComparisonCSVEntry(
+			"location",
+			location.Name,
+			"",
+			"",
+			"",
+			comparison.TotalsPerLocation[location.ID],
+		))
+	}
+
+	days := max(len(comparison.Dates), len(comparison.CompareDates))
+	for day := range days {
+		date := valueAt(comparison.Dates, day, "")
+		compareDate := valueAt(comparison.CompareDates, day, "")
+
+		for _, schoolName := range schoolNames {
+			values := comparison.ValuesPerSchool[schoolName]
+			compareValues := comparison.CompareValuesPerSchool[schoolName]
+
+			if date == "" || compareDate == "" {
+				output = append(output, []string{
+					"day",
+					schoolName,
+					strconv.Itoa(day + 1),
+					date,
+					compareDate,
+					countAt(values, day),
+					countAt(compareValues, day),
+					"",
+					"",
+				})
+				continue
+			}
+
+			output = append(output, getComparisonCSVEntry(
+				"day",
+				schoolName,
+				strconv.Itoa(day+1),
+				date,
+				compareDate,
+				dtos.NewComparisonTotalDto(values[day], compareValues[day]),
+			))
+		}
+	}
+
+	return output
+}
+
+func getComparisonCSVEntry(
+	entryType string,
+	name string,
+	day string,
+	date string,
+	compareDate string,
+	total dtos.ComparisonTotalDto,
+) []string {
+	percentageChange := ""
+	if total.PercentageChange != nil {
+		percentageChange = strconv.FormatFloat(*total.PercentageChange, 'f', 2, 64)
+	}
+
+	return []string{
+		entryType,
+		name,
+		day,
+		date,
+		compareDate,
+		strconv.Itoa(total.Count),
+		strconv.Itoa(total.CompareCount),
+		strconv.Itoa(total.Delta),
+		percentageChange,
+	}
+}
+
+// valueAt returns the i-th value of values,
+// or the fallback if values has no i-th value.
+func valueAt[T any](values []T, i int, fallback T) T {
+	if i >= len(values) {
+		return fallback
+	}
+
+	return values[i]
+}
+
+func countAt(values []int, i int) string {
+	if i >= len(values) {
+		return ""
+	}
+
+	return strconv.Itoa(values[i])
+}
+
 func getCSVHeaders(
 	valueMap map[string][]int,
+	closedPerLocation map[string][]bool,
 ) []string {
 	headers := []string{
 		"datetime",
 		"capacity",
 	}
 
+	for _, locationID := range slices.Sorted(maps.Keys(closedPerLocation)) {
+		headers = append(headers, "closed "+locationID)
+	}
+
 	for schoolName := range valueMap {
 		headers = append(headers, schoolName)
 	}
@@ -235,9 +746,12 @@ func getCSVData(
 	dateStrings []string,
 	capacities map[string][]int,
 	valuesPerSchool map[string][]int,
+	closedPerLocation map[string][]bool,
 ) [][]string {
 	var output [][]string
 
+	closedLocationIDs := slices.Sorted(maps.Keys(closedPerLocation))
+
 	for i, dateString := range dateStrings {
 		for _, values := range valuesPerSchool {
 			var entry []string
@@ -249,6 +763,12 @@ func getCSVData(
 
 			entry = append(entry, dateString)
 			entry = append(entry, fmt.Sprintf("%d", totalCapacity))
+			for _, locationID := range closedLocationIDs {
+				entry = append(
+					entry,
+					strconv.FormatBool(closedPerLocation[locationID][i]),
+				)
+			}
 			entry = append(entry, strconv.Itoa(values[i]))
 			output = append(output, entry)
 		}
@@ -275,6 +795,7 @@ func (app *Application) getAllCheckInsTodayHandler(w http.ResponseWriter,
 	user := context.GetValue[models.User](r.Context(), constants.UserContextKey)
 	_, checkIns, err := app.services.Locations.GetAllCheckInsOfDay(
 		r.Context(),
+		user.OrganisationID,
 		user,
 		false,
 		[]string{id},
@@ -512,6 +1033,220 @@ func (app *Application) updateLocationHandler(w http.ResponseWriter,
 	}
 }
 
+// @Summary	Get opening hours and closing days of location
+// @Tags		locations
+// @Param		id	path		string	true	"Location ID"
+// @Success	200	{object}	models.OpeningHours
+// @Failure	400	{object}	ErrorDto
+// @Failure	401	{object}	ErrorDto
+// @Failure	404	{object}	ErrorDto
+// @Failure	500	{object}	ErrorDto
+// @Router		/locations/{id}/opening-hours [get].
+func (app *Application) getOpeningHoursHandler(w http.ResponseWriter,
+	r *http.Request) {
+	id, err := parse.URLParam(r, "locationId", parse.UUID)
+	if err != nil {
+		httptools.BadRequestResponse(w, r, err)
+		return
+	}
+
+	user := context.GetValue[models.User](r.Context(), constants.UserContextKey)
+	openingHours, err := app.services.Locations.GetOpeningHours(
+		r.Context(),
+		user,
+		id,
+	)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	err = httptools.WriteJSON(w, http.StatusOK, openingHours, nil)
+	if err != nil {
+		httptools.ServerErrorResponse(w, r, err)
+	}
+}
+
+// @Summary	Replace opening hours and closing days of location
+// @Tags		locations
+// @Param		id				path		string			true	"Location ID"
+// @Param		openingHoursDto	body		OpeningHoursDto	true	"OpeningHoursDto"
+// @Success	200				{object}	models.OpeningHours
+// @Failure	400				{object}	ErrorDto
+// @Failure	401				{object}	ErrorDto
+// @Failure	404				{object}	ErrorDto
+// @Failure	422				{object}	ErrorDto
+// @Failure	500				{object}	ErrorDto
+// @Router		/locations/{id}/opening-hours [put].
+func (app *Application) updateOpeningHoursHandler(w http.ResponseWriter,
+	r *http.Request) {
+	var openingHoursDto dtos.OpeningHoursDto
+
+	id, err := parse.URLParam(r, "locationId", parse.UUID)
+	if err != nil {
+		httptools.BadRequestResponse(w, r, err)
+		return
+	}
+
+	err = httptools.ReadJSON(r.Body, &openingHoursDto)
+	if err != nil {
+		httptools.BadRequestResponse(w, r, err)
+		return
+	}
+
+	if v, validationErrors := openingHoursDto.Validate(); !v {
+		httptools.FailedValidationResponse(w, r, validationErrors)
+		return
+	}
+
+	user := context.GetValue[models.User](r.Context(), constants.UserContextKey)
+	openingHours, err := app.services.Locations.UpdateOpeningHours(
+		r.Context(),
+		user,
+		id,
+		openingHoursDto,
+	)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	err = httptools.WriteJSON(w, http.StatusOK, openingHours, nil)
+	if err != nil {
+		httptools.ServerErrorResponse(w, r, err)
+	}
+}
+
+// @Summary	Get all past and scheduled capacity changes of location
+// @Tags		locations
+// @Param		id	path		string	true	"Location ID"
+// @Success	200	{object}	[]models.CapacityChange
+// @Failure	400	{object}	ErrorDto
+// @Failure	401	{object}	ErrorDto
+// @Failure	404	{object}	ErrorDto
+// @Failure	500	{object}	ErrorDto
+// @Router		/locations/{id}/capacity-history [get].
+func (app *Application) getCapacityHistoryHandler(w http.ResponseWriter,
+	r *http.Request) {
+	id, err := parse.URLParam(r, "locationId", parse.UUID)
+	if err != nil {
+		httptools.BadRequestResponse(w, r, err)
+		return
+	}
+
+	user := context.GetValue[models.User](r.Context(), constants.UserContextKey)
+	capacityChanges, err := app.services.Locations.GetCapacityHistory(
+		r.Context(),
+		user,
+		id,
+	)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	err = httptools.WriteJSON(w, http.StatusOK, capacityChanges, nil)
+	if err != nil {
+		httptools.ServerErrorResponse(w, r, err)
+	}
+}
+
+// @Summary	Schedule a capacity change for location
+// @Tags		locations
+// @Param		id					path		string				true	"Location ID"
+// @Param		capacityChangeDto	body		CapacityChangeDto	true	"CapacityChangeDto"
+// @Success	201					{object}	models.CapacityChange
+// @Failure	400					{object}	ErrorDto
+// @Failure	401					{object}	ErrorDto
+// @Failure	404					{object}	ErrorDto
+// @Failure	422					{object}	ErrorDto
+// @Failure	500					{object}	ErrorDto
+// @Router		/locations/{id}/capacity-history [post].
+func (app *Application) scheduleCapacityChangeHandler(w http.ResponseWriter,
+	r *http.Request) {
+	var capacityChangeDto dtos.CapacityChangeDto
+
+	id, err := parse.URLParam(r, "locationId", parse.UUID)
+	if err != nil {
+		httptools.BadRequestResponse(w, r, err)
+		return
+	}
+
+	err = httptools.ReadJSON(r.Body, &capacityChangeDto)
+	if err != nil {
+		httptools.BadRequestResponse(w, r, err)
+		return
+	}
+
+	if v, validationErrors := capacityChangeDto.Validate(); !v {
+		httptools.FailedValidationResponse(w, r, validationErrors)
+		return
+	}
+
+	user := context.GetValue[models.User](r.Context(), constants.UserContextKey)
+	capacityChange, err := app.services.Locations.ScheduleCapacityChange(
+		r.Context(),
+		user,
+		id,
+		capacityChangeDto,
+	)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	err = httptools.WriteJSON(w, http.StatusCreated, capacityChange, nil)
+	if err != nil {
+		httptools.ServerErrorResponse(w, r, err)
+	}
+}
+
+// @Summary	Delete a scheduled capacity change of location
+// @Tags		locations
+// @Param		locationId			path		string	true	"Location ID"
+// @Param		capacityChangeId	path		int		true	"Capacity change ID"
+// @Success	200					{object}	models.CapacityChange
+// @Failure	400					{object}	ErrorDto
+// @Failure	401					{object}	ErrorDto
+// @Failure	404					{object}	ErrorDto
+// @Failure	500					{object}	ErrorDto
+// @Router		/locations/{locationId}/capacity-history/{capacityChangeId} [delete].
+func (app *Application) deleteCapacityChangeHandler(w http.ResponseWriter,
+	r *http.Request) {
+	locationID, err := parse.URLParam(r, "locationId", parse.UUID)
+	if err != nil {
+		httptools.BadRequestResponse(w, r, err)
+		return
+	}
+
+	capacityChangeID, err := parse.URLParam(
+		r,
+		"capacityChangeId",
+		parse.Int64(true, false),
+	)
+	if err != nil {
+		httptools.BadRequestResponse(w, r, err)
+		return
+	}
+
+	user := context.GetValue[models.User](r.Context(), constants.UserContextKey)
+	capacityChange, err := app.services.Locations.DeleteCapacityChange(
+		r.Context(),
+		user,
+		locationID,
+		capacityChangeID,
+	)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	err = httptools.WriteJSON(w, http.StatusOK, capacityChange, nil)
+	if err != nil {
+		httptools.ServerErrorResponse(w, r, err)
+	}
+}
+
 // @Summary	Delete location
 // @Tags		locations
 // @Param		id	path		string	true	"Location ID"
@@ -540,3 +1275,32 @@ func (app *Application) deleteLocationHandler(w http.ResponseWriter, r *http.Req
 		httptools.ServerErrorResponse(w, r, err)
 	}
 }
+
+// @Summary	Restore deleted location
+// @Tags		locations
+// @Param		id	path		string	true	"Location ID"
+// @Success	200	{object}	models.Location
+// @Failure	400	{object}	ErrorDto
+// @Failure	401	{object}	ErrorDto
+// @Failure	404	{object}	ErrorDto
+// @Failure	500	{object}	ErrorDto
+// @Router		/locations/{id}/restore [post].
+func (app *Application) restoreLocationHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := parse.URLParam(r, "locationId", parse.UUID)
+	if err != nil {
+		httptools.BadRequestResponse(w, r, err)
+		return
+	}
+
+	user := context.GetValue[models.User](r.Context(), constants.UserContextKey)
+	location, err := app.services.Locations.Restore(r.Context(), user, id)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	err = httptools.WriteJSON(w, http.StatusOK, location, nil)
+	if err != nil {
+		httptools.ServerErrorResponse(w, r, err)
+	}
+}
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/XDoubleU/essentia/pkg/grapher"
+	timetools "github.com/XDoubleU/essentia/pkg/time"
+
+	"check-in/api/internal/models"
+	"check-in/api/internal/repositories"
+)
+
+const benchmarkCheckInsAmount = 1_000_000
+
+// BenchmarkCheckInsRange compares counting the check-ins of a year
+// from the raw rows, grouping them per business date in the database
+// and reading the daily rollups.
+func BenchmarkCheckInsRange(b *testing.B) {
+	testEnv, testApp := setupSpecificTimeProvider(time.Now)
+	defer testEnv.teardown()
+
+	location := testEnv.fixtures.DefaultLocation
+	endDate := timetools.StartOfDay(testApp.getTimeNowUTC())
+	startDate := endDate.AddDate(-1, 0, 0)
+
+	_, err := postgresDB.Exec(
+		testEnv.ctx,
+		`
+			INSERT INTO check_ins (location_id, school_id, capacity, created_at)
+			SELECT $1, 1, $2, $3::timestamptz - random() * interval '365 days'
+			FROM generate_series(1, $4::int4)
+		`,
+		location.ID,
+		location.Capacity,
+		endDate,
+		benchmarkCheckInsAmount,
+	)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	repos := repositories.New(postgresDB, testApp.getTimeNowUTC)
+
+	err = repos.Rollups.Rebuild(testEnv.ctx)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	schoolIDNameMap, err := testApp.services.Schools.SchoolIDNameMap(
+		testEnv.ctx,
+		location.OrganisationID,
+	)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	newGrapher := func() *grapher.Grapher[int] {
+		g := grapher.New[int](
+			grapher.CumulativeSameDate,
+			grapher.None,
+			time.RFC3339,
+			time.Second,
+		)
+
+		for i := startDate; !i.After(endDate); i = i.AddDate(0, 0, 1) {
+			for _, schoolName := range schoolIDNameMap {
+				g.AddPoint(i, 0, schoolName)
+			}
+		}
+
+		return g
+	}
+
+	b.Run("raw", func(b *testing.B) {
+		for range b.N {
+			start, _ := location.DayBounds(startDate)
+			_, end := location.DayBounds(endDate)
+
+			checkIns, queryErr := repos.CheckIns.GetAllInRange(
+				testEnv.ctx,
+				location.ID,
+				start,
+				end,
+			)
+			if queryErr != nil {
+				b.Fatal(queryErr)
+			}
+
+			g := newGrapher()
+			for _, checkIn := range checkIns {
+				g.AddPoint(
+					location.BusinessDate(checkIn.CreatedAt.Time.UTC()),
+					1,
+					schoolIDNameMap[checkIn.SchoolID],
+				)
+			}
+		}
+	})
+
+	b.Run("grouped", func(b *testing.B) {
+		for range b.N {
+			start, _ := location.DayBounds(startDate)
+			_, end := location.DayBounds(endDate)
+
+			rows, queryErr := postgresDB.Query(
+				testEnv.ctx,
+				`
+					SELECT check_ins.school_id,
+					 ((check_ins.created_at AT TIME ZONE locations.time_zone) -
+					 locations.business_day_start::interval)::date AS business_date,
+					 COUNT(*)
+					FROM check_ins
+					INNER JOIN locations
+					ON locations.id = check_ins.location_id
+					WHERE check_ins.location_id = $1
+					AND check_ins.created_at BETWEEN $2 AND $3
+					GROUP BY check_ins.school_id, business_date
+				`,
+				location.ID,
+				start,
+				end,
+			)
+			if queryErr != nil {
+				b.Fatal(queryErr)
+			}
+
+			g := newGrapher()
+			for rows.Next() {
+				var schoolID int64
+				var date time.Time
+				var count int
+
+				queryErr = rows.Scan(&schoolID, &date, &count)
+				if queryErr != nil {
+					b.Fatal(queryErr)
+				}
+
+				g.AddPoint(
+					models.DayGranularity.Start(date),
+					count,
+					schoolIDNameMap[schoolID],
+				)
+			}
+
+			if rows.Err() != nil {
+				b.Fatal(rows.Err())
+			}
+		}
+	})
+
+	b.Run("rollups", func(b *testing.B) {
+		for range b.N {
+			checkInCounts, queryErr := repos.CheckIns.CountPerDayInRange(
+				testEnv.ctx,
+				[]string{location.ID},
+				startDate,
+				endDate,
+			)
+			if queryErr != nil {
+				b.Fatal(queryErr)
+			}
+
+			g := newGrapher()
+			for _, checkInCount := range checkInCounts {
+				g.AddPoint(
+					models.DayGranularity.Start(checkInCount.Date),
+					int(checkInCount.Count),
+					schoolIDNameMap[checkInCount.SchoolID],
+				)
+			}
+		}
+	})
+}
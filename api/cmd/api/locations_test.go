@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"encoding/csv"
 	"fmt"
 	"math"
 	"net/http"
@@ -20,6 +21,7 @@ import (
 	"check-in/api/internal/constants"
 	"check-in/api/internal/dtos"
 	"check-in/api/internal/models"
+	"check-in/api/internal/repositories"
 )
 
 func TestYesterdayFullAt(t *testing.T) {
@@ -83,9 +85,9 @@ func GetCheckInsLocationRangeRawSingle(
 ) {
 	testEnv.createCheckIns(testEnv.fixtures.DefaultLocation, int64(1), 10)
 
-	now := testApp.getTimeNowUTC()
-	startDate := timetools.StartOfDay(now.Add(-24 * time.Hour))
-	endDate := timetools.StartOfDay(now.Add(24 * time.Hour))
+	today := testEnv.fixtures.DefaultLocation.BusinessDate(testApp.getTimeNowUTC())
+	startDate := today.AddDate(0, 0, -1)
+	endDate := today.AddDate(0, 0, 1)
 
 	users := []*http.Cookie{
 		testEnv.fixtures.Tokens.AdminAccessToken,
@@ -117,7 +119,7 @@ func GetCheckInsLocationRangeRawSingle(
 
 		assert.Equal(
 			t,
-			0,
+			int(testEnv.fixtures.DefaultLocation.Capacity),
 			rsData.CapacitiesPerLocation[testEnv.fixtures.DefaultLocation.ID][0],
 		)
 		assert.Equal(t, 0, rsData.ValuesPerSchool["Andere"][0])
@@ -131,7 +133,7 @@ func GetCheckInsLocationRangeRawSingle(
 
 		assert.Equal(
 			t,
-			0,
+			int(testEnv.fixtures.DefaultLocation.Capacity),
 			rsData.CapacitiesPerLocation[testEnv.fixtures.DefaultLocation.ID][2],
 		)
 		assert.Equal(t, 0, rsData.ValuesPerSchool["Andere"][2])
@@ -152,9 +154,9 @@ func GetCheckInsLocationRangeRawMultiple(
 	testEnv.createCheckIns(testEnv.fixtures.DefaultLocation, int64(1), 10)
 	testEnv.createCheckIns(location, int64(1), 10)
 
-	now := testApp.getTimeNowUTC()
-	startDate := timetools.StartOfDay(now.Add(-24 * time.Hour))
-	endDate := timetools.StartOfDay(now.Add(24 * time.Hour))
+	today := testEnv.fixtures.DefaultLocation.BusinessDate(testApp.getTimeNowUTC())
+	startDate := today.AddDate(0, 0, -1)
+	endDate := today.AddDate(0, 0, 1)
 
 	users := []*http.Cookie{
 		testEnv.fixtures.Tokens.AdminAccessToken,
@@ -191,7 +193,7 @@ func GetCheckInsLocationRangeRawMultiple(
 
 		assert.Equal(
 			t,
-			0,
+			int(testEnv.fixtures.DefaultLocation.Capacity),
 			rsData.CapacitiesPerLocation[testEnv.fixtures.DefaultLocation.ID][0],
 		)
 		assert.Equal(t, 0, rsData.ValuesPerSchool["Andere"][0])
@@ -210,7 +212,7 @@ func GetCheckInsLocationRangeRawMultiple(
 
 		assert.Equal(
 			t,
-			0,
+			int(testEnv.fixtures.DefaultLocation.Capacity),
 			rsData.CapacitiesPerLocation[testEnv.fixtures.DefaultLocation.ID][2],
 		)
 		assert.Equal(t, 0, rsData.ValuesPerSchool["Andere"][2])
@@ -224,8 +226,9 @@ func TestGetCheckInsLocationRangeCSV(t *testing.T) {
 	amount := 10
 	testEnv.createCheckIns(testEnv.fixtures.DefaultLocation, 1, amount)
 
-	startDate := testApp.getTimeNowUTC().AddDate(0, 0, -1).Format(constants.DateFormat)
-	endDate := testApp.getTimeNowUTC().AddDate(0, 0, 1).Format(constants.DateFormat)
+	today := testEnv.fixtures.DefaultLocation.BusinessDate(testApp.getTimeNowUTC())
+	startDate := today.AddDate(0, 0, -1).Format(constants.DateFormat)
+	endDate := today.AddDate(0, 0, 1).Format(constants.DateFormat)
 
 	users := []*http.Cookie{
 		testEnv.fixtures.Tokens.AdminAccessToken,
@@ -269,7 +272,7 @@ func TestGetCheckInsLocationRangeCSV(t *testing.T) {
 		fetchedTimeToday, _ := time.Parse(time.RFC3339, rsData[2][0])
 		assert.Equal(
 			t,
-			testApp.getTimeNowUTC().Format(constants.DateFormat),
+			today.Format(constants.DateFormat),
 			fetchedTimeToday.Format(constants.DateFormat),
 		)
 		assert.Equal(
@@ -492,259 +495,426 @@ func TestGetCheckInsLocationRangeNotUUID(t *testing.T) {
 	assert.Contains(t, rsData.Message.(string), "should be a UUID")
 }
 
-func TestGetCheckInsLocationRangeAccess(t *testing.T) {
+func TestGetCheckInsLocationRangeWeekGranularity(t *testing.T) {
+	runForAllTimes(t, GetCheckInsLocationRangeWeekGranularity)
+}
+
+func GetCheckInsLocationRangeWeekGranularity(
+	t *testing.T,
+	testEnv TestEnv,
+	testApp Application,
+) {
+	testEnv.createCheckIns(testEnv.fixtures.DefaultLocation, int64(1), 10)
+
+	today := testEnv.fixtures.DefaultLocation.BusinessDate(testApp.getTimeNowUTC())
+	startDate := models.WeekGranularity.Start(today).AddDate(0, 0, -7)
+	endDate := startDate.AddDate(0, 0, 13)
+
+	tReq := test.CreateRequestTester(
+		testApp.routes(),
+		http.MethodGet,
+		"/all-locations/checkins/range",
+	)
+	tReq.AddCookie(testEnv.fixtures.Tokens.AdminAccessToken)
+
+	tReq.SetQuery(map[string][]string{
+		"ids":         {testEnv.fixtures.DefaultLocation.ID},
+		"startDate":   {startDate.Format(constants.DateFormat)},
+		"endDate":     {endDate.Format(constants.DateFormat)},
+		"returnType":  {"raw"},
+		"granularity": {"week"},
+	})
+
+	rs := tReq.Do(t)
+
+	var rsData dtos.CheckInsGraphDto
+	assert.Equal(t, http.StatusOK, rs.StatusCode)
+	err := httptools.ReadJSON(rs.Body, &rsData)
+	require.Nil(t, err)
+
+	capacity := int(testEnv.fixtures.DefaultLocation.Capacity)
+	locationID := testEnv.fixtures.DefaultLocation.ID
+
+	assert.Equal(
+		t,
+		[]string{
+			startDate.Format(time.RFC3339),
+			startDate.AddDate(0, 0, 7).Format(time.RFC3339),
+		},
+		rsData.Dates,
+	)
+	assert.Equal(t, []int{0, 10}, rsData.ValuesPerSchool["Andere"])
+	assert.Equal(
+		t,
+		[]int{7 * capacity, 7 * capacity},
+		rsData.CapacitiesPerLocation[locationID],
+	)
+	assert.Equal(t, []bool{false, false}, rsData.ClosedPerLocation[locationID])
+}
+
+func TestGetCheckInsLocationRangeMonthGranularity(t *testing.T) {
 	testEnv, testApp := setup(t)
 	defer testEnv.teardown()
 
+	testEnv.createCheckIns(testEnv.fixtures.DefaultLocation, int64(1), 10)
+
+	today := testEnv.fixtures.DefaultLocation.BusinessDate(testApp.getTimeNowUTC())
+	startDate := models.MonthGranularity.Start(today).AddDate(0, -1, 0)
+	endDate := startDate.AddDate(0, 2, -1)
+	daysInPreviousMonth := startDate.AddDate(0, 1, -1).Day()
+	daysInMonth := endDate.Day()
+
 	tReq := test.CreateRequestTester(
 		testApp.routes(),
 		http.MethodGet,
 		"/all-locations/checkins/range",
 	)
+	tReq.AddCookie(testEnv.fixtures.Tokens.AdminAccessToken)
 
-	mt := test.CreateMatrixTester()
-	mt.AddTestCase(tReq, test.NewCaseResponse(http.StatusUnauthorized, nil, nil))
+	tReq.SetQuery(map[string][]string{
+		"ids":         {testEnv.fixtures.DefaultLocation.ID},
+		"startDate":   {startDate.Format(constants.DateFormat)},
+		"endDate":     {endDate.Format(constants.DateFormat)},
+		"returnType":  {"raw"},
+		"granularity": {"month"},
+	})
 
-	mt.Do(t)
+	rs := tReq.Do(t)
+
+	var rsData dtos.CheckInsGraphDto
+	assert.Equal(t, http.StatusOK, rs.StatusCode)
+	err := httptools.ReadJSON(rs.Body, &rsData)
+	require.Nil(t, err)
+
+	capacity := int(testEnv.fixtures.DefaultLocation.Capacity)
+
+	assert.Equal(t, 2, len(rsData.Dates))
+	assert.Equal(t, []int{0, 10}, rsData.ValuesPerSchool["Andere"])
+	assert.Equal(
+		t,
+		[]int{daysInPreviousMonth * capacity, daysInMonth * capacity},
+		rsData.CapacitiesPerLocation[testEnv.fixtures.DefaultLocation.ID],
+	)
 }
 
-func TestGetCheckInsLocationDayRawSingle(t *testing.T) {
-	runForAllTimes(t, GetCheckInsLocationDayRawSingle)
+func TestGetCheckInsLocationRangeInvalidGranularity(t *testing.T) {
+	testEnv, testApp := setup(t)
+	defer testEnv.teardown()
+
+	startDate := testApp.getTimeNowUTC().Format(constants.DateFormat)
+	endDate := testApp.getTimeNowUTC().AddDate(0, 0, 1).Format(constants.DateFormat)
+
+	tReq := test.CreateRequestTester(
+		testApp.routes(),
+		http.MethodGet,
+		"/all-locations/checkins/range",
+	)
+	tReq.AddCookie(testEnv.fixtures.Tokens.AdminAccessToken)
+
+	tReq.SetQuery(map[string][]string{
+		"ids":         {testEnv.fixtures.DefaultLocation.ID},
+		"startDate":   {startDate},
+		"endDate":     {endDate},
+		"returnType":  {"raw"},
+		"granularity": {"quarter"},
+	})
+
+	rs := tReq.Do(t)
+
+	var rsData errortools.ErrorDto
+	err := httptools.ReadJSON(rs.Body, &rsData)
+	require.Nil(t, err)
+
+	assert.Equal(t, http.StatusBadRequest, rs.StatusCode)
+	//nolint:errcheck //not needed
+	assert.Contains(t, rsData.Message.(string), "granularity")
 }
 
-func GetCheckInsLocationDayRawSingle(
-	t *testing.T,
-	testEnv TestEnv,
-	testApp Application,
-) {
-	amount := 10
-	testEnv.createCheckIns(testEnv.fixtures.DefaultLocation, int64(1), amount)
+func TestGetCheckInsLocationRangeRollups(t *testing.T) {
+	testEnv, testApp := setup(t)
+	defer testEnv.teardown()
 
-	date := testApp.getTimeNowUTC()
+	now := testApp.getTimeNowUTC()
+	yesterday := now.AddDate(0, 0, -1)
 
-	users := []*http.Cookie{
-		testEnv.fixtures.Tokens.AdminAccessToken,
-		testEnv.fixtures.Tokens.ManagerAccessToken,
-		testEnv.fixtures.Tokens.DefaultAccessToken,
-	}
+	location := testEnv.fixtures.DefaultLocation
+	checkIns := testEnv.at(yesterday).createCheckIns(location, int64(1), 5)
+	testEnv.createCheckIns(location, int64(1), 3)
 
-	for _, user := range users {
+	getValues := func() []int {
 		tReq := test.CreateRequestTester(
 			testApp.routes(),
 			http.MethodGet,
-			"/all-locations/checkins/day",
+			"/all-locations/checkins/range",
 		)
-		tReq.AddCookie(user)
+		tReq.AddCookie(testEnv.fixtures.Tokens.AdminAccessToken)
 
 		tReq.SetQuery(map[string][]string{
-			"ids":        {testEnv.fixtures.DefaultLocation.ID},
-			"date":       {date.Format(constants.DateFormat)},
+			"ids":        {location.ID},
+			"startDate":  {location.BusinessDate(yesterday).Format(constants.DateFormat)},
+			"endDate":    {location.BusinessDate(now).Format(constants.DateFormat)},
 			"returnType": {"raw"},
 		})
 
 		rs := tReq.Do(t)
+		require.Equal(t, http.StatusOK, rs.StatusCode)
 
 		var rsData dtos.CheckInsGraphDto
-		assert.Equal(t, http.StatusOK, rs.StatusCode)
 		err := httptools.ReadJSON(rs.Body, &rsData)
 		require.Nil(t, err)
 
-		capacity, value := 0, 0
+		return rsData.ValuesPerSchool["Andere"]
+	}
 
-		//nolint:lll //it is what it is
-		for _, cap := range rsData.CapacitiesPerLocation[testEnv.fixtures.DefaultLocation.ID] {
-			capacity = int(math.Max(float64(capacity), float64(cap)))
-		}
+	assert.Equal(t, []int{5, 3}, getValues())
 
-		for _, val := range rsData.ValuesPerSchool["Andere"] {
-			value += val
-		}
+	repos := repositories.New(postgresDB, testApp.getTimeNowUTC)
 
-		assert.Equal(t, 20, capacity)
-		assert.Equal(t, amount, value)
-	}
+	err := repos.CheckIns.Delete(context.Background(), checkIns[0].ID)
+	require.Nil(t, err)
+
+	assert.Equal(t, []int{4, 3}, getValues())
+
+	// check-ins added without the rollups are only counted after a rebuild
+	_, err = postgresDB.Exec(
+		context.Background(),
+		`
+			INSERT INTO check_ins (location_id, school_id, capacity, created_at)
+			VALUES ($1, 1, $2, $3), ($1, 1, $2, $3)
+		`,
+		location.ID,
+		location.Capacity,
+		yesterday,
+	)
+	require.Nil(t, err)
+
+	assert.Equal(t, []int{4, 3}, getValues())
+
+	err = repos.Rollups.Rebuild(context.Background())
+	require.Nil(t, err)
+
+	assert.Equal(t, []int{6, 3}, getValues())
 }
 
-func TestGetCheckInsLocationDayRawMultiple(t *testing.T) {
-	runForAllTimes(t, GetCheckInsLocationDayRawMultiple)
+func TestGetCheckInsLocationRangeAccess(t *testing.T) {
+	testEnv, testApp := setup(t)
+	defer testEnv.teardown()
+
+	tReq := test.CreateRequestTester(
+		testApp.routes(),
+		http.MethodGet,
+		"/all-locations/checkins/range",
+	)
+
+	mt := test.CreateMatrixTester()
+	mt.AddTestCase(tReq, test.NewCaseResponse(http.StatusUnauthorized, nil, nil))
+
+	mt.Do(t)
 }
 
-func GetCheckInsLocationDayRawMultiple(
+func TestGetCheckInsLocationHeatmapRaw(t *testing.T) {
+	runForAllTimes(t, GetCheckInsLocationHeatmapRaw)
+}
+
+func GetCheckInsLocationHeatmapRaw(
 	t *testing.T,
 	testEnv TestEnv,
 	testApp Application,
 ) {
 	location := testEnv.createLocations(1)[0]
 
-	amount := 10
-	testEnv.createCheckIns(testEnv.fixtures.DefaultLocation, int64(1), amount)
-	testEnv.createCheckIns(location, int64(1), amount)
-
-	date := testApp.getTimeNowUTC()
+	testEnv.createCheckIns(testEnv.fixtures.DefaultLocation, int64(1), 10)
+	testEnv.createCheckIns(location, int64(1), 5)
 
-	users := []*http.Cookie{
-		testEnv.fixtures.Tokens.AdminAccessToken,
-		testEnv.fixtures.Tokens.ManagerAccessToken,
-	}
+	now := testApp.getTimeNowUTC()
+	startDate := now.AddDate(0, 0, -1).Format(constants.DateFormat)
+	endDate := now.AddDate(0, 0, 1).Format(constants.DateFormat)
 
-	for _, user := range users {
-		tReq := test.CreateRequestTester(
-			testApp.routes(),
-			http.MethodGet,
-			"/all-locations/checkins/day",
-		)
-		tReq.AddCookie(user)
+	tReq := test.CreateRequestTester(
+		testApp.routes(),
+		http.MethodGet,
+		"/all-locations/checkins/heatmap",
+	)
+	tReq.AddCookie(testEnv.fixtures.Tokens.AdminAccessToken)
 
-		id := fmt.Sprintf(
-			"%s,%s",
-			testEnv.fixtures.DefaultLocation.ID,
-			location.ID,
-		)
-		tReq.SetQuery(map[string][]string{
-			"ids":        {id},
-			"date":       {date.Format(constants.DateFormat)},
-			"returnType": {"raw"},
-		})
+	tReq.SetQuery(map[string][]string{
+		"ids": {
+			fmt.Sprintf("%s,%s", testEnv.fixtures.DefaultLocation.ID, location.ID),
+		},
+		"startDate":  {startDate},
+		"endDate":    {endDate},
+		"returnType": {"raw"},
+	})
 
-		rs := tReq.Do(t)
+	rs := tReq.Do(t)
 
-		var rsData dtos.CheckInsGraphDto
-		assert.Equal(t, http.StatusOK, rs.StatusCode)
-		err := httptools.ReadJSON(rs.Body, &rsData)
-		require.Nil(t, err)
+	var rsData dtos.CheckInsHeatmapDto
+	assert.Equal(t, http.StatusOK, rs.StatusCode)
+	err := httptools.ReadJSON(rs.Body, &rsData)
+	require.Nil(t, err)
 
-		capacity0, capacity1, value := 0, 0, 0
+	expected := map[string]int{
+		testEnv.fixtures.DefaultLocation.ID: 10,
+		location.ID:                         5,
+	}
 
-		//nolint:lll //it is what it is
-		for _, cap := range rsData.CapacitiesPerLocation[testEnv.fixtures.DefaultLocation.ID] {
-			capacity0 = int(math.Max(float64(capacity0), float64(cap)))
-		}
+	for _, l := range []*models.Location{testEnv.fixtures.DefaultLocation, location} {
+		tz, _ := time.LoadLocation(l.TimeZone)
+		localNow := now.In(tz)
 
-		for _, cap := range rsData.CapacitiesPerLocation[location.ID] {
-			capacity1 = int(math.Max(float64(capacity1), float64(cap)))
-		}
+		values := rsData.ValuesPerLocation[l.ID]
+		require.Equal(t, 7, len(values))
+		require.Equal(t, 24, len(values[0]))
 
-		for _, val := range rsData.ValuesPerSchool["Andere"] {
-			value += val
+		var total int
+		for _, hours := range values {
+			for _, value := range hours {
+				total += value
+			}
 		}
 
-		assert.Equal(t, 20, capacity0)
+		assert.Equal(t, expected[l.ID], total)
 		assert.Equal(
 			t,
-			int(location.Capacity),
-			capacity1,
+			expected[l.ID],
+			values[localNow.Weekday()][localNow.Hour()],
 		)
-		assert.Equal(t, 2*amount, value)
 	}
 }
 
-func TestGetCheckInsLocationDayCSV(t *testing.T) {
+func TestGetCheckInsLocationHeatmapRollups(t *testing.T) {
 	testEnv, testApp := setup(t)
 	defer testEnv.teardown()
 
-	amount := 10
-	testEnv.createCheckIns(testEnv.fixtures.DefaultLocation, 1, amount)
-
-	date := testApp.getTimeNowUTC().Format(constants.DateFormat)
+	now := testApp.getTimeNowUTC()
+	yesterday := now.AddDate(0, 0, -1)
 
-	users := []*http.Cookie{
-		testEnv.fixtures.Tokens.AdminAccessToken,
-		testEnv.fixtures.Tokens.ManagerAccessToken,
-		testEnv.fixtures.Tokens.DefaultAccessToken,
-	}
+	location := testEnv.fixtures.DefaultLocation
+	checkIns := testEnv.at(yesterday).createCheckIns(location, int64(1), 5)
+	testEnv.createCheckIns(location, int64(1), 3)
 
-	for _, user := range users {
+	getTotal := func() int {
 		tReq := test.CreateRequestTester(
 			testApp.routes(),
 			http.MethodGet,
-			"/all-locations/checkins/day",
+			"/all-locations/checkins/heatmap",
 		)
-		tReq.AddCookie(user)
+		tReq.AddCookie(testEnv.fixtures.Tokens.AdminAccessToken)
 
 		tReq.SetQuery(map[string][]string{
-			"ids":        {testEnv.fixtures.DefaultLocation.ID},
-			"date":       {date},
-			"returnType": {"csv"},
+			"ids":        {location.ID},
+			"startDate":  {now.AddDate(0, 0, -2).Format(constants.DateFormat)},
+			"endDate":    {now.AddDate(0, 0, 1).Format(constants.DateFormat)},
+			"returnType": {"raw"},
 		})
 
 		rs := tReq.Do(t)
-		assert.Equal(t, http.StatusOK, rs.StatusCode)
-		assert.Equal(t, "text/csv", rs.Header.Get("content-type"))
-
-		rsData, _ := httptools.ReadCSV(rs.Body)
+		require.Equal(t, http.StatusOK, rs.StatusCode)
 
-		expectedHeaders := []string{"datetime", "capacity", "Andere"}
-		assert.Equal(t, expectedHeaders, rsData[0])
-
-		capacity, value := 0, 0
-
-		for _, row := range rsData {
-			strCap, _ := strconv.Atoi(row[1])
-			strVal, _ := strconv.Atoi(row[2])
+		var rsData dtos.CheckInsHeatmapDto
+		err := httptools.ReadJSON(rs.Body, &rsData)
+		require.Nil(t, err)
 
-			capacity = int(math.Max(float64(capacity), float64(strCap)))
-			value += strVal
+		var total int
+		for _, hours := range rsData.ValuesPerLocation[location.ID] {
+			for _, value := range hours {
+				total += value
+			}
 		}
 
-		assert.EqualValues(t, testEnv.fixtures.DefaultLocation.Capacity, capacity)
-		assert.Equal(t, amount, value)
+		return total
 	}
-}
 
-func TestGetCheckInsLocationDayNotFound(t *testing.T) {
+	assert.Equal(t, 8, getTotal())
+
+	repos := repositories.New(postgresDB, testApp.getTimeNowUTC)
+
+	err := repos.CheckIns.Delete(context.Background(), checkIns[0].ID)
+	require.Nil(t, err)
+
+	assert.Equal(t, 7, getTotal())
+
+	// check-ins added without the rollups are only counted after a rebuild
+	_, err = postgresDB.Exec(
+		context.Background(),
+		`
+			INSERT INTO check_ins (location_id, school_id, capacity, created_at)
+			VALUES ($1, 1, $2, $3), ($1, 1, $2, $3)
+		`,
+		location.ID,
+		location.Capacity,
+		yesterday,
+	)
+	require.Nil(t, err)
+
+	assert.Equal(t, 7, getTotal())
+
+	err = repos.Rollups.Rebuild(context.Background())
+	require.Nil(t, err)
+
+	assert.Equal(t, 9, getTotal())
+}
+
+func TestGetCheckInsLocationHeatmapCSV(t *testing.T) {
 	testEnv, testApp := setup(t)
 	defer testEnv.teardown()
 
-	date := testApp.getTimeNowUTC().Format(constants.DateFormat)
+	testEnv.createCheckIns(testEnv.fixtures.DefaultLocation, 1, 10)
 
-	id, _ := uuid.NewUUID()
+	now := testApp.getTimeNowUTC()
+	tz, _ := time.LoadLocation(testEnv.fixtures.DefaultLocation.TimeZone)
+	localNow := now.In(tz)
 
 	tReq := test.CreateRequestTester(
 		testApp.routes(),
 		http.MethodGet,
-		"/all-locations/checkins/day",
+		"/all-locations/checkins/heatmap",
 	)
 	tReq.AddCookie(testEnv.fixtures.Tokens.ManagerAccessToken)
 
 	tReq.SetQuery(map[string][]string{
-		"ids":        {id.String()},
-		"date":       {date},
-		"returnType": {"raw"},
+		"ids":        {testEnv.fixtures.DefaultLocation.ID},
+		"startDate":  {now.AddDate(0, 0, -1).Format(constants.DateFormat)},
+		"endDate":    {now.AddDate(0, 0, 1).Format(constants.DateFormat)},
+		"returnType": {"csv"},
 	})
 
 	rs := tReq.Do(t)
 
-	var rsData errortools.ErrorDto
-	err := httptools.ReadJSON(rs.Body, &rsData)
-	require.Nil(t, err)
+	rsData, _ := httptools.ReadCSV(rs.Body)
 
-	assert.Equal(t, http.StatusNotFound, rs.StatusCode)
-	assert.Equal(
-		t,
-		fmt.Sprintf("locations with ids '%s' doesn't exist", id.String()),
-		//nolint:errcheck //not needed
-		rsData.Message.(map[string]interface{})["ids"].(string),
-	)
+	assert.Equal(t, http.StatusOK, rs.StatusCode)
+	assert.Equal(t, "text/csv", rs.Header.Get("content-type"))
+	require.Equal(t, 8, len(rsData))
+	assert.Equal(t, []string{"location", "weekday", "00"}, rsData[0][:3])
+	assert.Equal(t, "23", rsData[0][25])
+
+	// weekdays start at monday
+	assert.Equal(t, time.Monday.String(), rsData[1][1])
+	assert.Equal(t, time.Sunday.String(), rsData[7][1])
+
+	row := (int(localNow.Weekday())+6)%7 + 1
+	assert.Equal(t, testEnv.fixtures.DefaultLocation.Name, rsData[row][0])
+	assert.Equal(t, "10", rsData[row][localNow.Hour()+2])
 }
 
-func TestGetCheckInsLocationDayNotFoundNotOwner(t *testing.T) {
+func TestGetCheckInsLocationHeatmapInvalidRange(t *testing.T) {
 	testEnv, testApp := setup(t)
 	defer testEnv.teardown()
 
-	location := testEnv.createLocations(1)[0]
-
-	date := testApp.getTimeNowUTC().Format(constants.DateFormat)
+	now := testApp.getTimeNowUTC()
 
 	tReq := test.CreateRequestTester(
 		testApp.routes(),
 		http.MethodGet,
-		"/all-locations/checkins/day",
+		"/all-locations/checkins/heatmap",
 	)
-	tReq.AddCookie(testEnv.fixtures.Tokens.DefaultAccessToken)
+	tReq.AddCookie(testEnv.fixtures.Tokens.AdminAccessToken)
 
 	tReq.SetQuery(map[string][]string{
-		"ids":        {location.ID},
-		"date":       {date},
+		"ids":        {testEnv.fixtures.DefaultLocation.ID},
+		"startDate":  {now.Format(constants.DateFormat)},
+		"endDate":    {now.AddDate(0, 0, -1).Format(constants.DateFormat)},
 		"returnType": {"raw"},
 	})
 
@@ -754,175 +924,252 @@ func TestGetCheckInsLocationDayNotFoundNotOwner(t *testing.T) {
 	err := httptools.ReadJSON(rs.Body, &rsData)
 	require.Nil(t, err)
 
-	assert.Equal(t, http.StatusNotFound, rs.StatusCode)
+	assert.Equal(t, http.StatusBadRequest, rs.StatusCode)
+	assert.Equal(t, "startDate can't be after endDate", rsData.Message)
+
+	tReq.SetQuery(map[string][]string{
+		"ids":        {testEnv.fixtures.DefaultLocation.ID},
+		"startDate":  {now.AddDate(0, 0, -366).Format(constants.DateFormat)},
+		"endDate":    {now.Format(constants.DateFormat)},
+		"returnType": {"raw"},
+	})
+
+	rs = tReq.Do(t)
+
+	err = httptools.ReadJSON(rs.Body, &rsData)
+	require.Nil(t, err)
+
+	assert.Equal(t, http.StatusBadRequest, rs.StatusCode)
 	assert.Equal(
 		t,
-		fmt.Sprintf("location with id '%s' doesn't exist", location.ID),
-		//nolint:errcheck //not needed
-		rsData.Message.(map[string]interface{})["id"].(string),
+		"range from startDate to endDate can't be longer than 366 days",
+		rsData.Message,
 	)
 }
 
-func TestGetCheckInsLocationDateMissing(t *testing.T) {
+func TestGetCheckInsLocationHeatmapNotFoundNotOwner(t *testing.T) {
 	testEnv, testApp := setup(t)
 	defer testEnv.teardown()
 
 	location := testEnv.createLocations(1)[0]
 
+	startDate := testApp.getTimeNowUTC().Format(constants.DateFormat)
+
 	tReq := test.CreateRequestTester(
 		testApp.routes(),
 		http.MethodGet,
-		"/all-locations/checkins/day",
+		"/all-locations/checkins/heatmap",
 	)
-	tReq.AddCookie(testEnv.fixtures.Tokens.ManagerAccessToken)
+	tReq.AddCookie(testEnv.fixtures.Tokens.DefaultAccessToken)
 
 	tReq.SetQuery(map[string][]string{
 		"ids":        {location.ID},
+		"startDate":  {startDate},
+		"endDate":    {startDate},
 		"returnType": {"raw"},
 	})
 
 	rs := tReq.Do(t)
 
-	var rsData errortools.ErrorDto
-	err := httptools.ReadJSON(rs.Body, &rsData)
-	require.Nil(t, err)
+	assert.Equal(t, http.StatusNotFound, rs.StatusCode)
+}
 
-	assert.Equal(t, http.StatusBadRequest, rs.StatusCode)
-	//nolint:errcheck //not needed
-	assert.Equal(t, "missing query param 'date'", rsData.Message.(string))
+func TestGetCheckInsLocationHeatmapAccess(t *testing.T) {
+	testEnv, testApp := setup(t)
+	defer testEnv.teardown()
+
+	tReq := test.CreateRequestTester(
+		testApp.routes(),
+		http.MethodGet,
+		"/all-locations/checkins/heatmap",
+	)
+
+	mt := test.CreateMatrixTester()
+	mt.AddTestCase(tReq, test.NewCaseResponse(http.StatusUnauthorized, nil, nil))
+
+	mt.Do(t)
 }
 
-func TestGetCheckInsLocationReturnTypeMissing(t *testing.T) {
+func TestGetCheckInsLocationComparisonRaw(t *testing.T) {
 	testEnv, testApp := setup(t)
 	defer testEnv.teardown()
 
 	location := testEnv.createLocations(1)[0]
 
-	date := testApp.getTimeNowUTC().Format(constants.DateFormat)
+	now := testApp.getTimeNowUTC()
+	lastWeek := now.AddDate(0, 0, -7)
+	today := testEnv.fixtures.DefaultLocation.BusinessDate(now)
+	lastWeekDate := testEnv.fixtures.DefaultLocation.BusinessDate(lastWeek)
+
+	testEnv.at(lastWeek).createCheckIns(testEnv.fixtures.DefaultLocation, int64(1), 4)
+	testEnv.createCheckIns(testEnv.fixtures.DefaultLocation, int64(1), 6)
+	testEnv.createCheckIns(location, int64(1), 2)
 
 	tReq := test.CreateRequestTester(
 		testApp.routes(),
 		http.MethodGet,
-		"/all-locations/checkins/day",
+		"/all-locations/checkins/comparison",
 	)
-	tReq.AddCookie(testEnv.fixtures.Tokens.DefaultAccessToken)
+	tReq.AddCookie(testEnv.fixtures.Tokens.AdminAccessToken)
 
 	tReq.SetQuery(map[string][]string{
-		"ids":  {location.ID},
-		"date": {date},
+		"ids": {
+			fmt.Sprintf("%s,%s", testEnv.fixtures.DefaultLocation.ID, location.ID),
+		},
+		"startDate":        {today.AddDate(0, 0, -1).Format(constants.DateFormat)},
+		"endDate":          {today.Format(constants.DateFormat)},
+		"compareStartDate": {lastWeekDate.AddDate(0, 0, -1).Format(constants.DateFormat)},
+		"compareEndDate":   {lastWeekDate.AddDate(0, 0, 1).Format(constants.DateFormat)},
+		"returnType":       {"raw"},
 	})
 
 	rs := tReq.Do(t)
 
-	var rsData errortools.ErrorDto
+	var rsData dtos.CheckInsComparisonDto
 	err := httptools.ReadJSON(rs.Body, &rsData)
 	require.Nil(t, err)
 
-	assert.Equal(t, http.StatusBadRequest, rs.StatusCode)
-	//nolint:errcheck //not needed
-	assert.Equal(t, "missing query param 'returnType'", rsData.Message.(string))
+	assert.Equal(t, http.StatusOK, rs.StatusCode)
+
+	assert.Equal(t, 8, rsData.Total.Count)
+	assert.Equal(t, 4, rsData.Total.CompareCount)
+	assert.Equal(t, 4, rsData.Total.Delta)
+	require.NotNil(t, rsData.Total.PercentageChange)
+	assert.InDelta(t, 100.0, *rsData.Total.PercentageChange, 0.001)
+
+	assert.Equal(t, 8, rsData.TotalsPerSchool["Andere"].Count)
+	assert.Equal(t, 4, rsData.TotalsPerSchool["Andere"].CompareCount)
+
+	defaultLocationTotal := rsData.TotalsPerLocation[testEnv.fixtures.DefaultLocation.ID]
+	assert.Equal(t, 6, defaultLocationTotal.Count)
+	assert.Equal(t, 4, defaultLocationTotal.CompareCount)
+	assert.Equal(t, 2, defaultLocationTotal.Delta)
+	require.NotNil(t, defaultLocationTotal.PercentageChange)
+	assert.InDelta(t, 50.0, *defaultLocationTotal.PercentageChange, 0.001)
+
+	locationTotal := rsData.TotalsPerLocation[location.ID]
+	assert.Equal(t, 2, locationTotal.Count)
+	assert.Equal(t, 0, locationTotal.CompareCount)
+	assert.Nil(t, locationTotal.PercentageChange)
+
+	assert.Equal(t, 2, len(rsData.Dates))
+	assert.Equal(t, 3, len(rsData.CompareDates))
+	assert.Equal(t, []int{0, 8}, rsData.ValuesPerSchool["Andere"])
+	assert.Equal(t, []int{0, 4, 0}, rsData.CompareValuesPerSchool["Andere"])
 }
 
-func TestGetCheckInsLocationDayNotUUID(t *testing.T) {
+func TestGetCheckInsLocationComparisonCSV(t *testing.T) {
 	testEnv, testApp := setup(t)
 	defer testEnv.teardown()
 
-	date := testApp.getTimeNowUTC().Format(constants.DateFormat)
+	now := testApp.getTimeNowUTC()
+	lastWeek := now.AddDate(0, 0, -7)
+	today := testEnv.fixtures.DefaultLocation.BusinessDate(now)
+	lastWeekDate := testEnv.fixtures.DefaultLocation.BusinessDate(lastWeek)
+
+	testEnv.at(lastWeek).createCheckIns(testEnv.fixtures.DefaultLocation, int64(1), 4)
+	testEnv.createCheckIns(testEnv.fixtures.DefaultLocation, int64(1), 6)
 
 	tReq := test.CreateRequestTester(
 		testApp.routes(),
 		http.MethodGet,
-		"/all-locations/checkins/day",
+		"/all-locations/checkins/comparison",
 	)
-	tReq.AddCookie(testEnv.fixtures.Tokens.DefaultAccessToken)
+	tReq.AddCookie(testEnv.fixtures.Tokens.AdminAccessToken)
 
 	tReq.SetQuery(map[string][]string{
-		"ids":        {"8000"},
-		"date":       {date},
-		"returnType": {"raw"},
+		"ids":              {testEnv.fixtures.DefaultLocation.ID},
+		"startDate":        {today.Format(constants.DateFormat)},
+		"endDate":          {today.AddDate(0, 0, 1).Format(constants.DateFormat)},
+		"compareStartDate": {lastWeekDate.Format(constants.DateFormat)},
+		"compareEndDate":   {lastWeekDate.Format(constants.DateFormat)},
+		"returnType":       {"csv"},
 	})
 
 	rs := tReq.Do(t)
 
-	var rsData errortools.ErrorDto
-	err := httptools.ReadJSON(rs.Body, &rsData)
+	rsData, err := httptools.ReadCSV(rs.Body)
 	require.Nil(t, err)
 
-	assert.Equal(t, http.StatusBadRequest, rs.StatusCode)
-	//nolint:errcheck //not needed
-	assert.Contains(t, rsData.Message.(string), "should be a UUID")
+	assert.Equal(t, http.StatusOK, rs.StatusCode)
+	assert.Equal(t, "text/csv", rs.Header.Get("content-type"))
+	assert.Equal(
+		t,
+		[]string{
+			"type",
+			"name",
+			"day",
+			"date",
+			"compareDate",
+			"count",
+			"compareCount",
+			"delta",
+			"percentageChange",
+		},
+		rsData[0],
+	)
+
+	// header, total, a school, a location and two days of a school
+	require.Equal(t, 6, len(rsData))
+	assert.Equal(t, []string{"total", "", "", "", "", "6", "4", "2", "50.00"}, rsData[1])
+	assert.Equal(t, "school", rsData[2][0])
+	assert.Equal(
+		t,
+		[]string{"location", testEnv.fixtures.DefaultLocation.Name},
+		rsData[3][:2],
+	)
+	assert.Equal(t, []string{"6", "4", "2", "50.00"}, rsData[4][5:])
+	assert.Equal(t, "", rsData[5][4])
+	assert.Equal(t, []string{"0", "", "", ""}, rsData[5][5:])
 }
 
-func TestGetCheckInsLocationDayAccess(t *testing.T) {
+func TestGetCheckInsLocationComparisonNotFoundNotOwner(t *testing.T) {
 	testEnv, testApp := setup(t)
 	defer testEnv.teardown()
 
+	location := testEnv.createLocations(1)[0]
+	date := testApp.getTimeNowUTC().Format(constants.DateFormat)
+
 	tReq := test.CreateRequestTester(
 		testApp.routes(),
 		http.MethodGet,
-		"/all-locations/checkins/day",
+		"/all-locations/checkins/comparison",
 	)
+	tReq.AddCookie(testEnv.fixtures.Tokens.ManagerAccessToken)
 
-	mt := test.CreateMatrixTester()
-	mt.AddTestCase(tReq, test.NewCaseResponse(http.StatusUnauthorized, nil, nil))
-
-	mt.Do(t)
-}
-
-func TestGetAllCheckInsToday(t *testing.T) {
-	runForAllTimes(t, GetAllCheckInsToday)
-}
-
-func GetAllCheckInsToday(t *testing.T, testEnv TestEnv, testApp Application) {
-	amount := 5
-	testEnv.createCheckIns(testEnv.fixtures.DefaultLocation, int64(1), amount)
-
-	users := []*http.Cookie{
-		testEnv.fixtures.Tokens.AdminAccessToken,
-		testEnv.fixtures.Tokens.ManagerAccessToken,
-		testEnv.fixtures.Tokens.DefaultAccessToken,
-	}
-
-	for _, user := range users {
-		tReq := test.CreateRequestTester(
-			testApp.routes(),
-			http.MethodGet,
-			"/locations/%s/checkins",
-			testEnv.fixtures.DefaultLocation.ID,
-		)
-		tReq.AddCookie(user)
-
-		rs := tReq.Do(t)
+	tReq.SetQuery(map[string][]string{
+		"ids":              {location.ID},
+		"startDate":        {date},
+		"endDate":          {date},
+		"compareStartDate": {date},
+		"compareEndDate":   {date},
+		"returnType":       {"raw"},
+	})
 
-		var rsData []dtos.CheckInDto
-		err := httptools.ReadJSON(rs.Body, &rsData)
-		require.Nil(t, err)
+	rs := tReq.Do(t)
 
-		assert.Equal(t, http.StatusOK, rs.StatusCode)
-		assert.Equal(t, amount, len(rsData))
-		assert.Equal(t, testEnv.fixtures.DefaultLocation.ID, rsData[0].LocationID)
-		assert.Equal(t, "Andere", rsData[0].SchoolName)
-		assert.Equal(
-			t,
-			testApp.getTimeNowUTC().Format(constants.DateFormat),
-			rsData[0].CreatedAt.Time.Format(constants.DateFormat),
-		)
-	}
+	assert.Equal(t, http.StatusNotFound, rs.StatusCode)
 }
 
-func TestGetAllCheckInsTodayNotFound(t *testing.T) {
+func TestGetCheckInsLocationComparisonCompareDateMissing(t *testing.T) {
 	testEnv, testApp := setup(t)
 	defer testEnv.teardown()
 
-	id, _ := uuid.NewUUID()
+	date := testApp.getTimeNowUTC().Format(constants.DateFormat)
 
 	tReq := test.CreateRequestTester(
 		testApp.routes(),
 		http.MethodGet,
-		"/locations/%s/checkins",
-		id.String(),
+		"/all-locations/checkins/comparison",
 	)
-	tReq.AddCookie(testEnv.fixtures.Tokens.ManagerAccessToken)
+	tReq.AddCookie(testEnv.fixtures.Tokens.AdminAccessToken)
+
+	tReq.SetQuery(map[string][]string{
+		"ids":        {testEnv.fixtures.DefaultLocation.ID},
+		"startDate":  {date},
+		"endDate":    {date},
+		"returnType": {"raw"},
+	})
 
 	rs := tReq.Do(t)
 
@@ -930,28 +1177,32 @@ func TestGetAllCheckInsTodayNotFound(t *testing.T) {
 	err := httptools.ReadJSON(rs.Body, &rsData)
 	require.Nil(t, err)
 
-	assert.Equal(t, http.StatusNotFound, rs.StatusCode)
-	assert.Equal(
-		t,
-		fmt.Sprintf("locations with ids '%s' doesn't exist", id.String()),
-		//nolint:errcheck //not needed
-		rsData.Message.(map[string]interface{})["ids"].(string),
-	)
+	assert.Equal(t, http.StatusBadRequest, rs.StatusCode)
+	//nolint:errcheck //not needed
+	assert.Contains(t, rsData.Message.(string), "compareStartDate")
 }
 
-func TestGetAllCheckInsTodayNotFoundNotOwner(t *testing.T) {
+func TestGetCheckInsLocationComparisonInvertedRange(t *testing.T) {
 	testEnv, testApp := setup(t)
 	defer testEnv.teardown()
 
-	location := testEnv.createLocations(1)[0]
+	now := testApp.getTimeNowUTC()
 
 	tReq := test.CreateRequestTester(
 		testApp.routes(),
 		http.MethodGet,
-		"/locations/%s/checkins",
-		location.ID,
+		"/all-locations/checkins/comparison",
 	)
-	tReq.AddCookie(testEnv.fixtures.Tokens.DefaultAccessToken)
+	tReq.AddCookie(testEnv.fixtures.Tokens.AdminAccessToken)
+
+	tReq.SetQuery(map[string][]string{
+		"ids":              {testEnv.fixtures.DefaultLocation.ID},
+		"startDate":        {now.Format(constants.DateFormat)},
+		"endDate":          {now.Format(constants.DateFormat)},
+		"compareStartDate": {now.AddDate(0, 0, -1).Format(constants.DateFormat)},
+		"compareEndDate":   {now.AddDate(0, 0, -7).Format(constants.DateFormat)},
+		"returnType":       {"raw"},
+	})
 
 	rs := tReq.Do(t)
 
@@ -959,25 +1210,35 @@ func TestGetAllCheckInsTodayNotFoundNotOwner(t *testing.T) {
 	err := httptools.ReadJSON(rs.Body, &rsData)
 	require.Nil(t, err)
 
-	assert.Equal(t, http.StatusNotFound, rs.StatusCode)
+	assert.Equal(t, http.StatusBadRequest, rs.StatusCode)
 	assert.Equal(
 		t,
-		fmt.Sprintf("location with id '%s' doesn't exist", location.ID),
-		//nolint:errcheck //not needed
-		rsData.Message.(map[string]interface{})["id"].(string),
+		"compareStartDate can't be after compareEndDate",
+		rsData.Message,
 	)
 }
 
-func TestGetAllCheckInsTodayNotUUID(t *testing.T) {
+func TestGetCheckInsLocationComparisonRangeTooLong(t *testing.T) {
 	testEnv, testApp := setup(t)
 	defer testEnv.teardown()
 
+	now := testApp.getTimeNowUTC()
+
 	tReq := test.CreateRequestTester(
 		testApp.routes(),
 		http.MethodGet,
-		"/locations/8000/checkins",
+		"/all-locations/checkins/comparison",
 	)
-	tReq.AddCookie(testEnv.fixtures.Tokens.ManagerAccessToken)
+	tReq.AddCookie(testEnv.fixtures.Tokens.AdminAccessToken)
+
+	tReq.SetQuery(map[string][]string{
+		"ids":              {testEnv.fixtures.DefaultLocation.ID},
+		"startDate":        {now.AddDate(0, 0, -366).Format(constants.DateFormat)},
+		"endDate":          {now.Format(constants.DateFormat)},
+		"compareStartDate": {now.AddDate(-1, 0, 0).Format(constants.DateFormat)},
+		"compareEndDate":   {now.AddDate(-1, 0, 0).Format(constants.DateFormat)},
+		"returnType":       {"raw"},
+	})
 
 	rs := tReq.Do(t)
 
@@ -986,21 +1247,21 @@ func TestGetAllCheckInsTodayNotUUID(t *testing.T) {
 	require.Nil(t, err)
 
 	assert.Equal(t, http.StatusBadRequest, rs.StatusCode)
-	//nolint:errcheck //not needed
-	assert.Contains(t, rsData.Message.(string), "should be a UUID")
+	assert.Equal(
+		t,
+		"range from startDate to endDate can't be longer than 366 days",
+		rsData.Message,
+	)
 }
 
-func TestGetCheckInsTodayAccess(t *testing.T) {
+func TestGetCheckInsLocationComparisonAccess(t *testing.T) {
 	testEnv, testApp := setup(t)
 	defer testEnv.teardown()
 
-	location := testEnv.createLocations(1)[0]
-
 	tReq := test.CreateRequestTester(
 		testApp.routes(),
 		http.MethodGet,
-		"/locations/%s/checkins",
-		location.ID,
+		"/all-locations/checkins/comparison",
 	)
 
 	mt := test.CreateMatrixTester()
@@ -1009,26 +1270,529 @@ func TestGetCheckInsTodayAccess(t *testing.T) {
 	mt.Do(t)
 }
 
-func TestDeleteCheckIn(t *testing.T) {
-	testEnv, testApp := setup(t)
-	defer testEnv.teardown()
+func TestGetCheckInsLocationDayRawSingle(t *testing.T) {
+	runForAllTimes(t, GetCheckInsLocationDayRawSingle)
+}
 
-	checkIns := testEnv.createCheckIns(testEnv.fixtures.DefaultLocation, 1, 10)
+func GetCheckInsLocationDayRawSingle(
+	t *testing.T,
+	testEnv TestEnv,
+	testApp Application,
+) {
+	amount := 10
+	testEnv.createCheckIns(testEnv.fixtures.DefaultLocation, int64(1), amount)
+
+	date := testEnv.fixtures.DefaultLocation.BusinessDate(testApp.getTimeNowUTC())
 
 	users := []*http.Cookie{
 		testEnv.fixtures.Tokens.AdminAccessToken,
 		testEnv.fixtures.Tokens.ManagerAccessToken,
+		testEnv.fixtures.Tokens.DefaultAccessToken,
 	}
 
-	for i, user := range users {
-		id := strconv.FormatInt(
-			checkIns[i].ID,
-			10,
-		)
+	for _, user := range users {
 		tReq := test.CreateRequestTester(
 			testApp.routes(),
-			http.MethodDelete,
-			"/locations/%s/checkins/%s",
+			http.MethodGet,
+			"/all-locations/checkins/day",
+		)
+		tReq.AddCookie(user)
+
+		tReq.SetQuery(map[string][]string{
+			"ids":        {testEnv.fixtures.DefaultLocation.ID},
+			"date":       {date.Format(constants.DateFormat)},
+			"returnType": {"raw"},
+		})
+
+		rs := tReq.Do(t)
+
+		var rsData dtos.CheckInsGraphDto
+		assert.Equal(t, http.StatusOK, rs.StatusCode)
+		err := httptools.ReadJSON(rs.Body, &rsData)
+		require.Nil(t, err)
+
+		capacity, value := 0, 0
+
+		//nolint:lll //it is what it is
+		for _, cap := range rsData.CapacitiesPerLocation[testEnv.fixtures.DefaultLocation.ID] {
+			capacity = int(math.Max(float64(capacity), float64(cap)))
+		}
+
+		for _, val := range rsData.ValuesPerSchool["Andere"] {
+			value += val
+		}
+
+		assert.Equal(t, 20, capacity)
+		assert.Equal(t, amount, value)
+	}
+}
+
+func TestGetCheckInsLocationDayRawMultiple(t *testing.T) {
+	runForAllTimes(t, GetCheckInsLocationDayRawMultiple)
+}
+
+func GetCheckInsLocationDayRawMultiple(
+	t *testing.T,
+	testEnv TestEnv,
+	testApp Application,
+) {
+	location := testEnv.createLocations(1)[0]
+
+	amount := 10
+	testEnv.createCheckIns(testEnv.fixtures.DefaultLocation, int64(1), amount)
+	testEnv.createCheckIns(location, int64(1), amount)
+
+	date := testEnv.fixtures.DefaultLocation.BusinessDate(testApp.getTimeNowUTC())
+
+	users := []*http.Cookie{
+		testEnv.fixtures.Tokens.AdminAccessToken,
+		testEnv.fixtures.Tokens.ManagerAccessToken,
+	}
+
+	for _, user := range users {
+		tReq := test.CreateRequestTester(
+			testApp.routes(),
+			http.MethodGet,
+			"/all-locations/checkins/day",
+		)
+		tReq.AddCookie(user)
+
+		id := fmt.Sprintf(
+			"%s,%s",
+			testEnv.fixtures.DefaultLocation.ID,
+			location.ID,
+		)
+		tReq.SetQuery(map[string][]string{
+			"ids":        {id},
+			"date":       {date.Format(constants.DateFormat)},
+			"returnType": {"raw"},
+		})
+
+		rs := tReq.Do(t)
+
+		var rsData dtos.CheckInsGraphDto
+		assert.Equal(t, http.StatusOK, rs.StatusCode)
+		err := httptools.ReadJSON(rs.Body, &rsData)
+		require.Nil(t, err)
+
+		capacity0, capacity1, value := 0, 0, 0
+
+		//nolint:lll //it is what it is
+		for _, cap := range rsData.CapacitiesPerLocation[testEnv.fixtures.DefaultLocation.ID] {
+			capacity0 = int(math.Max(float64(capacity0), float64(cap)))
+		}
+
+		for _, cap := range rsData.CapacitiesPerLocation[location.ID] {
+			capacity1 = int(math.Max(float64(capacity1), float64(cap)))
+		}
+
+		for _, val := range rsData.ValuesPerSchool["Andere"] {
+			value += val
+		}
+
+		assert.Equal(t, 20, capacity0)
+		assert.Equal(
+			t,
+			int(location.Capacity),
+			capacity1,
+		)
+		assert.Equal(t, 2*amount, value)
+	}
+}
+
+func TestGetCheckInsLocationDayCSV(t *testing.T) {
+	testEnv, testApp := setup(t)
+	defer testEnv.teardown()
+
+	amount := 10
+	testEnv.createCheckIns(testEnv.fixtures.DefaultLocation, 1, amount)
+
+	date := testEnv.fixtures.DefaultLocation.BusinessDate(
+		testApp.getTimeNowUTC(),
+	).Format(constants.DateFormat)
+
+	users := []*http.Cookie{
+		testEnv.fixtures.Tokens.AdminAccessToken,
+		testEnv.fixtures.Tokens.ManagerAccessToken,
+		testEnv.fixtures.Tokens.DefaultAccessToken,
+	}
+
+	for _, user := range users {
+		tReq := test.CreateRequestTester(
+			testApp.routes(),
+			http.MethodGet,
+			"/all-locations/checkins/day",
+		)
+		tReq.AddCookie(user)
+
+		tReq.SetQuery(map[string][]string{
+			"ids":        {testEnv.fixtures.DefaultLocation.ID},
+			"date":       {date},
+			"returnType": {"csv"},
+		})
+
+		rs := tReq.Do(t)
+		assert.Equal(t, http.StatusOK, rs.StatusCode)
+		assert.Equal(t, "text/csv", rs.Header.Get("content-type"))
+
+		rsData, _ := httptools.ReadCSV(rs.Body)
+
+		expectedHeaders := []string{"datetime", "capacity", "Andere"}
+		assert.Equal(t, expectedHeaders, rsData[0])
+
+		capacity, value := 0, 0
+
+		for _, row := range rsData {
+			strCap, _ := strconv.Atoi(row[1])
+			strVal, _ := strconv.Atoi(row[2])
+
+			capacity = int(math.Max(float64(capacity), float64(strCap)))
+			value += strVal
+		}
+
+		assert.EqualValues(t, testEnv.fixtures.DefaultLocation.Capacity, capacity)
+		assert.Equal(t, amount, value)
+	}
+}
+
+func TestGetCheckInsLocationDayNotFound(t *testing.T) {
+	testEnv, testApp := setup(t)
+	defer testEnv.teardown()
+
+	date := testApp.getTimeNowUTC().Format(constants.DateFormat)
+
+	id, _ := uuid.NewUUID()
+
+	tReq := test.CreateRequestTester(
+		testApp.routes(),
+		http.MethodGet,
+		"/all-locations/checkins/day",
+	)
+	tReq.AddCookie(testEnv.fixtures.Tokens.ManagerAccessToken)
+
+	tReq.SetQuery(map[string][]string{
+		"ids":        {id.String()},
+		"date":       {date},
+		"returnType": {"raw"},
+	})
+
+	rs := tReq.Do(t)
+
+	var rsData errortools.ErrorDto
+	err := httptools.ReadJSON(rs.Body, &rsData)
+	require.Nil(t, err)
+
+	assert.Equal(t, http.StatusNotFound, rs.StatusCode)
+	assert.Equal(
+		t,
+		fmt.Sprintf("locations with ids '%s' doesn't exist", id.String()),
+		//nolint:errcheck //not needed
+		rsData.Message.(map[string]interface{})["ids"].(string),
+	)
+}
+
+func TestGetCheckInsLocationDayNotFoundNotOwner(t *testing.T) {
+	testEnv, testApp := setup(t)
+	defer testEnv.teardown()
+
+	location := testEnv.createLocations(1)[0]
+
+	date := testApp.getTimeNowUTC().Format(constants.DateFormat)
+
+	tReq := test.CreateRequestTester(
+		testApp.routes(),
+		http.MethodGet,
+		"/all-locations/checkins/day",
+	)
+	tReq.AddCookie(testEnv.fixtures.Tokens.DefaultAccessToken)
+
+	tReq.SetQuery(map[string][]string{
+		"ids":        {location.ID},
+		"date":       {date},
+		"returnType": {"raw"},
+	})
+
+	rs := tReq.Do(t)
+
+	var rsData errortools.ErrorDto
+	err := httptools.ReadJSON(rs.Body, &rsData)
+	require.Nil(t, err)
+
+	assert.Equal(t, http.StatusNotFound, rs.StatusCode)
+	assert.Equal(
+		t,
+		fmt.Sprintf("location with id '%s' doesn't exist", location.ID),
+		//nolint:errcheck //not needed
+		rsData.Message.(map[string]interface{})["id"].(string),
+	)
+}
+
+func TestGetCheckInsLocationDateMissing(t *testing.T) {
+	testEnv, testApp := setup(t)
+	defer testEnv.teardown()
+
+	location := testEnv.createLocations(1)[0]
+
+	tReq := test.CreateRequestTester(
+		testApp.routes(),
+		http.MethodGet,
+		"/all-locations/checkins/day",
+	)
+	tReq.AddCookie(testEnv.fixtures.Tokens.ManagerAccessToken)
+
+	tReq.SetQuery(map[string][]string{
+		"ids":        {location.ID},
+		"returnType": {"raw"},
+	})
+
+	rs := tReq.Do(t)
+
+	var rsData errortools.ErrorDto
+	err := httptools.ReadJSON(rs.Body, &rsData)
+	require.Nil(t, err)
+
+	assert.Equal(t, http.StatusBadRequest, rs.StatusCode)
+	//nolint:errcheck //not needed
+	assert.Equal(t, "missing query param 'date'", rsData.Message.(string))
+}
+
+func TestGetCheckInsLocationReturnTypeMissing(t *testing.T) {
+	testEnv, testApp := setup(t)
+	defer testEnv.teardown()
+
+	location := testEnv.createLocations(1)[0]
+
+	date := testApp.getTimeNowUTC().Format(constants.DateFormat)
+
+	tReq := test.CreateRequestTester(
+		testApp.routes(),
+		http.MethodGet,
+		"/all-locations/checkins/day",
+	)
+	tReq.AddCookie(testEnv.fixtures.Tokens.DefaultAccessToken)
+
+	tReq.SetQuery(map[string][]string{
+		"ids":  {location.ID},
+		"date": {date},
+	})
+
+	rs := tReq.Do(t)
+
+	var rsData errortools.ErrorDto
+	err := httptools.ReadJSON(rs.Body, &rsData)
+	require.Nil(t, err)
+
+	assert.Equal(t, http.StatusBadRequest, rs.StatusCode)
+	//nolint:errcheck //not needed
+	assert.Equal(t, "missing query param 'returnType'", rsData.Message.(string))
+}
+
+func TestGetCheckInsLocationDayNotUUID(t *testing.T) {
+	testEnv, testApp := setup(t)
+	defer testEnv.teardown()
+
+	date := testApp.getTimeNowUTC().Format(constants.DateFormat)
+
+	tReq := test.CreateRequestTester(
+		testApp.routes(),
+		http.MethodGet,
+		"/all-locations/checkins/day",
+	)
+	tReq.AddCookie(testEnv.fixtures.Tokens.DefaultAccessToken)
+
+	tReq.SetQuery(map[string][]string{
+		"ids":        {"8000"},
+		"date":       {date},
+		"returnType": {"raw"},
+	})
+
+	rs := tReq.Do(t)
+
+	var rsData errortools.ErrorDto
+	err := httptools.ReadJSON(rs.Body, &rsData)
+	require.Nil(t, err)
+
+	assert.Equal(t, http.StatusBadRequest, rs.StatusCode)
+	//nolint:errcheck //not needed
+	assert.Contains(t, rsData.Message.(string), "should be a UUID")
+}
+
+func TestGetCheckInsLocationDayAccess(t *testing.T) {
+	testEnv, testApp := setup(t)
+	defer testEnv.teardown()
+
+	tReq := test.CreateRequestTester(
+		testApp.routes(),
+		http.MethodGet,
+		"/all-locations/checkins/day",
+	)
+
+	mt := test.CreateMatrixTester()
+	mt.AddTestCase(tReq, test.NewCaseResponse(http.StatusUnauthorized, nil, nil))
+
+	mt.Do(t)
+}
+
+func TestGetAllCheckInsToday(t *testing.T) {
+	runForAllTimes(t, GetAllCheckInsToday)
+}
+
+func GetAllCheckInsToday(t *testing.T, testEnv TestEnv, testApp Application) {
+	amount := 5
+	testEnv.createCheckIns(testEnv.fixtures.DefaultLocation, int64(1), amount)
+
+	users := []*http.Cookie{
+		testEnv.fixtures.Tokens.AdminAccessToken,
+		testEnv.fixtures.Tokens.ManagerAccessToken,
+		testEnv.fixtures.Tokens.DefaultAccessToken,
+	}
+
+	for _, user := range users {
+		tReq := test.CreateRequestTester(
+			testApp.routes(),
+			http.MethodGet,
+			"/locations/%s/checkins",
+			testEnv.fixtures.DefaultLocation.ID,
+		)
+		tReq.AddCookie(user)
+
+		rs := tReq.Do(t)
+
+		var rsData []dtos.CheckInDto
+		err := httptools.ReadJSON(rs.Body, &rsData)
+		require.Nil(t, err)
+
+		assert.Equal(t, http.StatusOK, rs.StatusCode)
+		assert.Equal(t, amount, len(rsData))
+		assert.Equal(t, testEnv.fixtures.DefaultLocation.ID, rsData[0].LocationID)
+		assert.Equal(t, "Andere", rsData[0].SchoolName)
+		assert.Equal(
+			t,
+			testApp.getTimeNowUTC().Format(constants.DateFormat),
+			rsData[0].CreatedAt.Time.Format(constants.DateFormat),
+		)
+	}
+}
+
+func TestGetAllCheckInsTodayNotFound(t *testing.T) {
+	testEnv, testApp := setup(t)
+	defer testEnv.teardown()
+
+	id, _ := uuid.NewUUID()
+
+	tReq := test.CreateRequestTester(
+		testApp.routes(),
+		http.MethodGet,
+		"/locations/%s/checkins",
+		id.String(),
+	)
+	tReq.AddCookie(testEnv.fixtures.Tokens.ManagerAccessToken)
+
+	rs := tReq.Do(t)
+
+	var rsData errortools.ErrorDto
+	err := httptools.ReadJSON(rs.Body, &rsData)
+	require.Nil(t, err)
+
+	assert.Equal(t, http.StatusNotFound, rs.StatusCode)
+	assert.Equal(
+		t,
+		fmt.Sprintf("locations with ids '%s' doesn't exist", id.String()),
+		//nolint:errcheck //not needed
+		rsData.Message.(map[string]interface{})["ids"].(string),
+	)
+}
+
+func TestGetAllCheckInsTodayNotFoundNotOwner(t *testing.T) {
+	testEnv, testApp := setup(t)
+	defer testEnv.teardown()
+
+	location := testEnv.createLocations(1)[0]
+
+	tReq := test.CreateRequestTester(
+		testApp.routes(),
+		http.MethodGet,
+		"/locations/%s/checkins",
+		location.ID,
+	)
+	tReq.AddCookie(testEnv.fixtures.Tokens.DefaultAccessToken)
+
+	rs := tReq.Do(t)
+
+	var rsData errortools.ErrorDto
+	err := httptools.ReadJSON(rs.Body, &rsData)
+	require.Nil(t, err)
+
+	assert.Equal(t, http.StatusNotFound, rs.StatusCode)
+	assert.Equal(
+		t,
+		fmt.Sprintf("location with id '%s' doesn't exist", location.ID),
+		//nolint:errcheck //not needed
+		rsData.Message.(map[string]interface{})["id"].(string),
+	)
+}
+
+func TestGetAllCheckInsTodayNotUUID(t *testing.T) {
+	testEnv, testApp := setup(t)
+	defer testEnv.teardown()
+
+	tReq := test.CreateRequestTester(
+		testApp.routes(),
+		http.MethodGet,
+		"/locations/8000/checkins",
+	)
+	tReq.AddCookie(testEnv.fixtures.Tokens.ManagerAccessToken)
+
+	rs := tReq.Do(t)
+
+	var rsData errortools.ErrorDto
+	err := httptools.ReadJSON(rs.Body, &rsData)
+	require.Nil(t, err)
+
+	assert.Equal(t, http.StatusBadRequest, rs.StatusCode)
+	//nolint:errcheck //not needed
+	assert.Contains(t, rsData.Message.(string), "should be a UUID")
+}
+
+func TestGetCheckInsTodayAccess(t *testing.T) {
+	testEnv, testApp := setup(t)
+	defer testEnv.teardown()
+
+	location := testEnv.createLocations(1)[0]
+
+	tReq := test.CreateRequestTester(
+		testApp.routes(),
+		http.MethodGet,
+		"/locations/%s/checkins",
+		location.ID,
+	)
+
+	mt := test.CreateMatrixTester()
+	mt.AddTestCase(tReq, test.NewCaseResponse(http.StatusUnauthorized, nil, nil))
+
+	mt.Do(t)
+}
+
+func TestDeleteCheckIn(t *testing.T) {
+	testEnv, testApp := setup(t)
+	defer testEnv.teardown()
+
+	checkIns := testEnv.createCheckIns(testEnv.fixtures.DefaultLocation, 1, 10)
+
+	users := []*http.Cookie{
+		testEnv.fixtures.Tokens.AdminAccessToken,
+		testEnv.fixtures.Tokens.ManagerAccessToken,
+	}
+
+	for i, user := range users {
+		id := strconv.FormatInt(
+			checkIns[i].ID,
+			10,
+		)
+		tReq := test.CreateRequestTester(
+			testApp.routes(),
+			http.MethodDelete,
+			"/locations/%s/checkins/%s",
 			testEnv.fixtures.DefaultLocation.ID,
 			id,
 		)
@@ -1392,7 +2156,10 @@ func TestGetAllLocations(t *testing.T) {
 	defer testEnv.teardown()
 
 	testEnv.createLocations(20)
-	amount, err := testApp.services.Locations.GetTotalCount(context.Background())
+	amount, err := testApp.services.Locations.GetTotalCount(
+		context.Background(),
+		testEnv.fixtures.AdminUser,
+	)
 	require.Nil(t, err)
 
 	users := []*http.Cookie{
@@ -1650,14 +2417,53 @@ func TestCreateLocation(t *testing.T) {
 		assert.Equal(t, data.Capacity, rsData.CapacityYesterday)
 		assert.Equal(t, false, rsData.YesterdayFullAt.Valid)
 		assert.Equal(t, data.TimeZone, rsData.TimeZone)
+		assert.Equal(t, "00:00", rsData.BusinessDayStart)
 		assert.Nil(t, uuid.Validate(rsData.ID))
 	}
 }
 
-func TestCreateLocationNameExists(t *testing.T) {
+func TestCreateLocationNameExists(t *testing.T) {
+	testEnv, testApp := setup(t)
+	defer testEnv.teardown()
+
+	data := dtos.CreateLocationDto{
+		Name:     testEnv.fixtures.DefaultLocation.Name,
+		Capacity: 10,
+		Username: "test",
+		Password: "testpassword",
+		TimeZone: "Europe/Brussels",
+	}
+
+	tReq := test.CreateRequestTester(
+		testApp.routes(),
+		http.MethodPost,
+		"/locations",
+	)
+	tReq.AddCookie(testEnv.fixtures.Tokens.ManagerAccessToken)
+
+	tReq.SetData(data)
+
+	rs := tReq.Do(t)
+
+	var rsData errortools.ErrorDto
+	err := httptools.ReadJSON(rs.Body, &rsData)
+	require.Nil(t, err)
+
+	assert.Equal(t, http.StatusConflict, rs.StatusCode)
+	assert.Equal(
+		t,
+		fmt.Sprintf("location with name '%s' already exists", data.Name),
+		//nolint:errcheck //not needed
+		rsData.Message.(map[string]interface{})["name"].(string),
+	)
+}
+
+func TestCreateLocationNameExistsOtherOrganisation(t *testing.T) {
 	testEnv, testApp := setup(t)
 	defer testEnv.teardown()
 
+	organisation, otherAdminToken := testEnv.createOrganisation("Other")
+
 	data := dtos.CreateLocationDto{
 		Name:     testEnv.fixtures.DefaultLocation.Name,
 		Capacity: 10,
@@ -1671,22 +2477,23 @@ func TestCreateLocationNameExists(t *testing.T) {
 		http.MethodPost,
 		"/locations",
 	)
-	tReq.AddCookie(testEnv.fixtures.Tokens.ManagerAccessToken)
+	tReq.AddCookie(otherAdminToken)
 
 	tReq.SetData(data)
 
 	rs := tReq.Do(t)
 
-	var rsData errortools.ErrorDto
+	var rsData models.Location
 	err := httptools.ReadJSON(rs.Body, &rsData)
 	require.Nil(t, err)
 
-	assert.Equal(t, http.StatusConflict, rs.StatusCode)
+	assert.Equal(t, http.StatusCreated, rs.StatusCode)
+	assert.Equal(t, data.Name, rsData.Name)
+	assert.Equal(t, organisation.ID, rsData.OrganisationID)
 	assert.Equal(
 		t,
-		fmt.Sprintf("location with name '%s' already exists", data.Name),
-		//nolint:errcheck //not needed
-		rsData.Message.(map[string]interface{})["name"].(string),
+		testEnv.fixtures.DefaultLocation.NormalizedName,
+		rsData.NormalizedName,
 	)
 }
 
@@ -1809,6 +2616,25 @@ func TestCreateLocationFailValidation(t *testing.T) {
 
 	mt.AddTestCase(tReq2, tRes2)
 
+	tReq3 := tReq.Copy()
+
+	businessDayStart := "25:00"
+	tReq3.SetData(dtos.CreateLocationDto{
+		Name:             "test",
+		Capacity:         10,
+		Username:         "test",
+		Password:         "testpassword",
+		TimeZone:         "Europe/Brussels",
+		BusinessDayStart: &businessDayStart,
+	})
+
+	tRes3 := test.NewCaseResponse(http.StatusUnprocessableEntity, nil,
+		errortools.NewErrorDto(http.StatusUnprocessableEntity, map[string]interface{}{
+			"businessDayStart": "must be a time of day (format: 'HH:mm')",
+		}))
+
+	mt.AddTestCase(tReq3, tRes3)
+
 	mt.Do(t)
 }
 
@@ -1896,6 +2722,51 @@ func TestUpdateLocation(t *testing.T) {
 	}
 }
 
+func TestUpdateLocationTimeZoneRebuildsRollups(t *testing.T) {
+	testEnv, testApp := setup(t)
+	defer testEnv.teardown()
+
+	location := testEnv.fixtures.DefaultLocation
+
+	// already the next day in Brussels
+	now := testApp.getTimeNowUTC()
+	createdAt := time.Date(now.Year(), now.Month(), now.Day()-2, 23, 30, 0, 0, time.UTC)
+	testEnv.at(createdAt).createCheckIns(location, int64(1), 1)
+
+	getBusinessDate := func() time.Time {
+		var businessDate time.Time
+
+		err := postgresDB.QueryRow(
+			context.Background(),
+			"SELECT business_date FROM check_in_daily_rollups WHERE location_id = $1",
+			location.ID,
+		).Scan(&businessDate)
+		require.Nil(t, err)
+
+		return businessDate
+	}
+
+	assert.Equal(t, location.BusinessDate(createdAt), getBusinessDate())
+
+	timeZone := "UTC"
+	_, err := testApp.services.Locations.Update(
+		context.Background(),
+		testEnv.fixtures.AdminUser,
+		location.ID,
+		//nolint:exhaustruct //other fields are optional
+		dtos.UpdateLocationDto{
+			TimeZone: &timeZone,
+		},
+	)
+	require.Nil(t, err)
+
+	assert.Equal(
+		t,
+		time.Date(now.Year(), now.Month(), now.Day()-2, 0, 0, 0, 0, time.UTC),
+		getBusinessDate(),
+	)
+}
+
 func TestUpdateLocationNameExists(t *testing.T) {
 	testEnv, testApp := setup(t)
 	defer testEnv.teardown()
@@ -2147,54 +3018,393 @@ func TestUpdateLocationNotFoundNotOwner(t *testing.T) {
 		TimeZone: &timeZone,
 	}
 
-	tReq := test.CreateRequestTester(
+	tReq := test.CreateRequestTester(
+		testApp.routes(),
+		http.MethodPatch,
+		"/locations/%s",
+		location.ID,
+	)
+	tReq.AddCookie(testEnv.fixtures.Tokens.DefaultAccessToken)
+
+	tReq.SetData(data)
+
+	rs := tReq.Do(t)
+
+	var rsData errortools.ErrorDto
+	err := httptools.ReadJSON(rs.Body, &rsData)
+	require.Nil(t, err)
+
+	assert.Equal(t, http.StatusNotFound, rs.StatusCode)
+	assert.Equal(
+		t,
+		fmt.Sprintf("location with id '%s' doesn't exist", location.ID),
+		//nolint:errcheck //not needed
+		rsData.Message.(map[string]interface{})["id"].(string),
+	)
+}
+
+func TestUpdateLocationNotUUID(t *testing.T) {
+	testEnv, testApp := setup(t)
+	defer testEnv.teardown()
+
+	name, username, password, timeZone := "test", "test", "password", "Europe/Brussels"
+	var capacity int64 = 10
+
+	data := dtos.UpdateLocationDto{
+		Name:     &name,
+		Capacity: &capacity,
+		Username: &username,
+		Password: &password,
+		TimeZone: &timeZone,
+	}
+
+	tReq := test.CreateRequestTester(
+		testApp.routes(),
+		http.MethodPatch,
+		"/locations/8000",
+	)
+	tReq.AddCookie(testEnv.fixtures.Tokens.ManagerAccessToken)
+
+	tReq.SetData(data)
+
+	rs := tReq.Do(t)
+
+	var rsData errortools.ErrorDto
+	err := httptools.ReadJSON(rs.Body, &rsData)
+	require.Nil(t, err)
+
+	assert.Equal(t, http.StatusBadRequest, rs.StatusCode)
+	//nolint:errcheck //not needed
+	assert.Contains(t, rsData.Message.(string), "should be a UUID")
+}
+
+func TestUpdateLocationAccess(t *testing.T) {
+	testEnv, testApp := setup(t)
+	defer testEnv.teardown()
+
+	location := testEnv.createLocations(1)[0]
+
+	tReq := test.CreateRequestTester(
+		testApp.routes(),
+		http.MethodPatch,
+		"/locations/%s",
+		location.ID,
+	)
+
+	mt := test.CreateMatrixTester()
+	mt.AddTestCase(tReq, test.NewCaseResponse(http.StatusUnauthorized, nil, nil))
+
+	mt.Do(t)
+}
+
+func TestOpeningHours(t *testing.T) {
+	testEnv, testApp := setup(t)
+	defer testEnv.teardown()
+
+	data := dtos.OpeningHoursDto{
+		Periods: []models.OpeningPeriod{
+			{Weekday: int64(time.Monday), OpensAt: "18:00", ClosesAt: "10:00"},
+		},
+		ClosingDays: []models.ClosingDay{
+			{Date: "2024-12-25", Reason: "Christmas"},
+		},
+	}
+
+	tReqUpdate := test.CreateRequestTester(
+		testApp.routes(),
+		http.MethodPut,
+		"/locations/%s/opening-hours",
+		testEnv.fixtures.DefaultLocation.ID,
+	)
+	tReqUpdate.AddCookie(testEnv.fixtures.Tokens.ManagerAccessToken)
+	tReqUpdate.SetData(data)
+
+	rs := tReqUpdate.Do(t)
+
+	var rsData models.OpeningHours
+	err := httptools.ReadJSON(rs.Body, &rsData)
+	require.Nil(t, err)
+
+	assert.Equal(t, http.StatusOK, rs.StatusCode)
+	assert.Equal(t, data.Periods[0], *rsData.Periods[0])
+	assert.Equal(t, data.ClosingDays[0], *rsData.ClosingDays[0])
+
+	tReqGet := test.CreateRequestTester(
+		testApp.routes(),
+		http.MethodGet,
+		"/locations/%s/opening-hours",
+		testEnv.fixtures.DefaultLocation.ID,
+	)
+	tReqGet.AddCookie(testEnv.fixtures.Tokens.DefaultAccessToken)
+
+	rs = tReqGet.Do(t)
+
+	err = httptools.ReadJSON(rs.Body, &rsData)
+	require.Nil(t, err)
+
+	assert.Equal(t, http.StatusOK, rs.StatusCode)
+	assert.Len(t, rsData.Periods, 1)
+	assert.Len(t, rsData.ClosingDays, 1)
+}
+
+func TestOpeningHoursFailValidation(t *testing.T) {
+	testEnv, testApp := setup(t)
+	defer testEnv.teardown()
+
+	tReq := test.CreateRequestTester(
+		testApp.routes(),
+		http.MethodPut,
+		"/locations/%s/opening-hours",
+		testEnv.fixtures.DefaultLocation.ID,
+	)
+	tReq.AddCookie(testEnv.fixtures.Tokens.ManagerAccessToken)
+
+	tReq.SetData(dtos.OpeningHoursDto{
+		Periods: []models.OpeningPeriod{
+			{Weekday: 7, OpensAt: "09:00", ClosesAt: "9h"},
+		},
+		ClosingDays: []models.ClosingDay{
+			{Date: "2024-12-25", Reason: ""},
+			{Date: "2024-12-25", Reason: ""},
+		},
+	})
+
+	mt := test.CreateMatrixTester()
+
+	mt.AddTestCase(tReq, test.NewCaseResponse(http.StatusUnprocessableEntity, nil,
+		errortools.NewErrorDto(http.StatusUnprocessableEntity, map[string]interface{}{
+			"periods[0].weekday":  "must be lesser than or equal to 6",
+			"periods[0].closesAt": "must be a time of day (format: 'HH:mm')",
+			"closingDays[1].date": "must be unique",
+		})))
+
+	mt.Do(t)
+}
+
+func TestOpeningHoursAccess(t *testing.T) {
+	testEnv, testApp := setup(t)
+	defer testEnv.teardown()
+
+	tReqGet := test.CreateRequestTester(
+		testApp.routes(),
+		http.MethodGet,
+		"/locations/%s/opening-hours",
+		testEnv.fixtures.DefaultLocation.ID,
+	)
+
+	tReqUpdate := test.CreateRequestTester(
+		testApp.routes(),
+		http.MethodPut,
+		"/locations/%s/opening-hours",
+		testEnv.fixtures.DefaultLocation.ID,
+	)
+
+	mt := test.CreateMatrixTester()
+
+	mt.AddTestCase(tReqGet, test.NewCaseResponse(http.StatusUnauthorized, nil, nil))
+	mt.AddTestCase(tReqUpdate, test.NewCaseResponse(http.StatusUnauthorized, nil, nil))
+
+	tReqUpdate2 := tReqUpdate.Copy()
+	tReqUpdate2.AddCookie(testEnv.fixtures.Tokens.DefaultAccessToken)
+	mt.AddTestCase(
+		tReqUpdate2,
+		test.NewCaseResponse(http.StatusForbidden, nil, nil),
+	)
+
+	mt.Do(t)
+}
+
+func TestGetCheckInsLocationRangeClosed(t *testing.T) {
+	testEnv, testApp := setup(t)
+	defer testEnv.teardown()
+
+	now := testApp.getTimeNowUTC()
+	startDate := timetools.StartOfDay(now.Add(-24 * time.Hour))
+	endDate := timetools.StartOfDay(now.Add(24 * time.Hour))
+
+	//nolint:exhaustruct //other fields are optional
+	_, err := testApp.services.Locations.UpdateOpeningHours(
+		context.Background(),
+		testEnv.fixtures.AdminUser,
+		testEnv.fixtures.DefaultLocation.ID,
+		dtos.OpeningHoursDto{
+			ClosingDays: []models.ClosingDay{
+				{Date: startDate.Format(constants.DateFormat), Reason: "holiday"},
+			},
+		},
+	)
+	require.Nil(t, err)
+
+	tReq := test.CreateRequestTester(
+		testApp.routes(),
+		http.MethodGet,
+		"/all-locations/checkins/range",
+	)
+	tReq.AddCookie(testEnv.fixtures.Tokens.AdminAccessToken)
+
+	tReq.SetQuery(map[string][]string{
+		"ids":        {testEnv.fixtures.DefaultLocation.ID},
+		"startDate":  {startDate.Format(constants.DateFormat)},
+		"endDate":    {endDate.Format(constants.DateFormat)},
+		"returnType": {"raw"},
+	})
+
+	rs := tReq.Do(t)
+
+	var rsData dtos.CheckInsGraphDto
+	err = httptools.ReadJSON(rs.Body, &rsData)
+	require.Nil(t, err)
+
+	assert.Equal(t, http.StatusOK, rs.StatusCode)
+	assert.Equal(
+		t,
+		[]bool{true, false, false},
+		rsData.ClosedPerLocation[testEnv.fixtures.DefaultLocation.ID],
+	)
+
+	tReq.SetQuery(map[string][]string{
+		"ids":        {testEnv.fixtures.DefaultLocation.ID},
+		"startDate":  {startDate.Format(constants.DateFormat)},
+		"endDate":    {endDate.Format(constants.DateFormat)},
+		"returnType": {"csv"},
+	})
+
+	rs = tReq.Do(t)
+
+	records, err := csv.NewReader(rs.Body).ReadAll()
+	require.Nil(t, err)
+
+	assert.Equal(t, http.StatusOK, rs.StatusCode)
+	assert.Equal(
+		t,
+		"closed "+testEnv.fixtures.DefaultLocation.ID,
+		records[0][2],
+	)
+	assert.Equal(t, "true", records[1][2])
+}
+
+func TestCapacityHistory(t *testing.T) {
+	testEnv, testApp := setup(t)
+	defer testEnv.teardown()
+
+	effectiveFrom := timetools.StartOfDay(testApp.getTimeNowUTC().AddDate(0, 0, 1))
+
+	tReqSchedule := test.CreateRequestTester(
+		testApp.routes(),
+		http.MethodPost,
+		"/locations/%s/capacity-history",
+		testEnv.fixtures.DefaultLocation.ID,
+	)
+	tReqSchedule.AddCookie(testEnv.fixtures.Tokens.ManagerAccessToken)
+	tReqSchedule.SetData(dtos.CapacityChangeDto{
+		Capacity:      50,
+		EffectiveFrom: effectiveFrom,
+	})
+
+	rs := tReqSchedule.Do(t)
+
+	var scheduled models.CapacityChange
+	err := httptools.ReadJSON(rs.Body, &scheduled)
+	require.Nil(t, err)
+
+	assert.Equal(t, http.StatusCreated, rs.StatusCode)
+	assert.EqualValues(t, 50, scheduled.Capacity)
+	assert.Equal(t, effectiveFrom, scheduled.EffectiveFrom.Time.UTC())
+
+	tReqHistory := test.CreateRequestTester(
+		testApp.routes(),
+		http.MethodGet,
+		"/locations/%s/capacity-history",
+		testEnv.fixtures.DefaultLocation.ID,
+	)
+	tReqHistory.AddCookie(testEnv.fixtures.Tokens.DefaultAccessToken)
+
+	rs = tReqHistory.Do(t)
+
+	var history []models.CapacityChange
+	err = httptools.ReadJSON(rs.Body, &history)
+	require.Nil(t, err)
+
+	assert.Equal(t, http.StatusOK, rs.StatusCode)
+	require.Len(t, history, 2)
+	assert.Equal(t, testEnv.fixtures.DefaultLocation.Capacity, history[0].Capacity)
+	assert.Equal(t, scheduled.ID, history[1].ID)
+
+	// the scheduled capacity isn't in force yet
+	location, err := testApp.services.Locations.GetByID(
+		context.Background(),
+		testEnv.fixtures.AdminUser,
+		testEnv.fixtures.DefaultLocation.ID,
+	)
+	require.Nil(t, err)
+	assert.Equal(t, testEnv.fixtures.DefaultLocation.Capacity, location.Capacity)
+
+	tReqRange := test.CreateRequestTester(
 		testApp.routes(),
-		http.MethodPatch,
-		"/locations/%s",
-		location.ID,
+		http.MethodGet,
+		"/all-locations/checkins/range",
 	)
-	tReq.AddCookie(testEnv.fixtures.Tokens.DefaultAccessToken)
-
-	tReq.SetData(data)
+	tReqRange.AddCookie(testEnv.fixtures.Tokens.AdminAccessToken)
+	tReqRange.SetQuery(map[string][]string{
+		"ids":        {testEnv.fixtures.DefaultLocation.ID},
+		"startDate":  {testApp.getTimeNowUTC().Format(constants.DateFormat)},
+		"endDate":    {effectiveFrom.Format(constants.DateFormat)},
+		"returnType": {"raw"},
+	})
 
-	rs := tReq.Do(t)
+	rs = tReqRange.Do(t)
 
-	var rsData errortools.ErrorDto
-	err := httptools.ReadJSON(rs.Body, &rsData)
+	var rsData dtos.CheckInsGraphDto
+	err = httptools.ReadJSON(rs.Body, &rsData)
 	require.Nil(t, err)
 
-	assert.Equal(t, http.StatusNotFound, rs.StatusCode)
+	assert.Equal(t, http.StatusOK, rs.StatusCode)
 	assert.Equal(
 		t,
-		fmt.Sprintf("location with id '%s' doesn't exist", location.ID),
-		//nolint:errcheck //not needed
-		rsData.Message.(map[string]interface{})["id"].(string),
+		[]int{int(testEnv.fixtures.DefaultLocation.Capacity), 50},
+		rsData.CapacitiesPerLocation[testEnv.fixtures.DefaultLocation.ID],
+	)
+
+	tReqDelete := test.CreateRequestTester(
+		testApp.routes(),
+		http.MethodDelete,
+		"/locations/%s/capacity-history/%d",
+		testEnv.fixtures.DefaultLocation.ID,
+		scheduled.ID,
+	)
+	tReqDelete.AddCookie(testEnv.fixtures.Tokens.ManagerAccessToken)
+
+	rs = tReqDelete.Do(t)
+	assert.Equal(t, http.StatusOK, rs.StatusCode)
+
+	tReqDelete = test.CreateRequestTester(
+		testApp.routes(),
+		http.MethodDelete,
+		"/locations/%s/capacity-history/%d",
+		testEnv.fixtures.DefaultLocation.ID,
+		history[0].ID,
 	)
+	tReqDelete.AddCookie(testEnv.fixtures.Tokens.ManagerAccessToken)
+
+	rs = tReqDelete.Do(t)
+	assert.Equal(t, http.StatusBadRequest, rs.StatusCode)
 }
 
-func TestUpdateLocationNotUUID(t *testing.T) {
+func TestCapacityHistoryInPast(t *testing.T) {
 	testEnv, testApp := setup(t)
 	defer testEnv.teardown()
 
-	name, username, password, timeZone := "test", "test", "password", "Europe/Brussels"
-	var capacity int64 = 10
-
-	data := dtos.UpdateLocationDto{
-		Name:     &name,
-		Capacity: &capacity,
-		Username: &username,
-		Password: &password,
-		TimeZone: &timeZone,
-	}
-
 	tReq := test.CreateRequestTester(
 		testApp.routes(),
-		http.MethodPatch,
-		"/locations/8000",
+		http.MethodPost,
+		"/locations/%s/capacity-history",
+		testEnv.fixtures.DefaultLocation.ID,
 	)
 	tReq.AddCookie(testEnv.fixtures.Tokens.ManagerAccessToken)
-
-	tReq.SetData(data)
+	tReq.SetData(dtos.CapacityChangeDto{
+		Capacity:      50,
+		EffectiveFrom: testApp.getTimeNowUTC().Add(-time.Hour),
+	})
 
 	rs := tReq.Do(t)
 
@@ -2203,25 +3413,48 @@ func TestUpdateLocationNotUUID(t *testing.T) {
 	require.Nil(t, err)
 
 	assert.Equal(t, http.StatusBadRequest, rs.StatusCode)
-	//nolint:errcheck //not needed
-	assert.Contains(t, rsData.Message.(string), "should be a UUID")
+	assert.Equal(
+		t,
+		"capacity changes can only be scheduled in the future",
+		rsData.Message,
+	)
 }
 
-func TestUpdateLocationAccess(t *testing.T) {
+func TestCapacityHistoryAccess(t *testing.T) {
 	testEnv, testApp := setup(t)
 	defer testEnv.teardown()
 
-	location := testEnv.createLocations(1)[0]
+	tReqHistory := test.CreateRequestTester(
+		testApp.routes(),
+		http.MethodGet,
+		"/locations/%s/capacity-history",
+		testEnv.fixtures.DefaultLocation.ID,
+	)
 
-	tReq := test.CreateRequestTester(
+	tReqSchedule := test.CreateRequestTester(
 		testApp.routes(),
-		http.MethodPatch,
-		"/locations/%s",
-		location.ID,
+		http.MethodPost,
+		"/locations/%s/capacity-history",
+		testEnv.fixtures.DefaultLocation.ID,
 	)
 
 	mt := test.CreateMatrixTester()
-	mt.AddTestCase(tReq, test.NewCaseResponse(http.StatusUnauthorized, nil, nil))
+
+	mt.AddTestCase(
+		tReqHistory,
+		test.NewCaseResponse(http.StatusUnauthorized, nil, nil),
+	)
+	mt.AddTestCase(
+		tReqSchedule,
+		test.NewCaseResponse(http.StatusUnauthorized, nil, nil),
+	)
+
+	tReqSchedule2 := tReqSchedule.Copy()
+	tReqSchedule2.AddCookie(testEnv.fixtures.Tokens.DefaultAccessToken)
+	mt.AddTestCase(
+		tReqSchedule2,
+		test.NewCaseResponse(http.StatusForbidden, nil, nil),
+	)
 
 	mt.Do(t)
 }
@@ -2352,3 +3585,259 @@ func TestDeleteLocationAccess(t *testing.T) {
 
 	mt.Do(t)
 }
+
+func TestRestoreLocation(t *testing.T) {
+	testEnv, testApp := setup(t)
+	defer testEnv.teardown()
+
+	location := testEnv.createLocations(1)[0]
+	testEnv.createCheckIns(location, 1, 2)
+
+	tReqDelete := test.CreateRequestTester(
+		testApp.routes(),
+		http.MethodDelete,
+		"/locations/%s",
+		location.ID,
+	)
+	tReqDelete.AddCookie(testEnv.fixtures.Tokens.AdminAccessToken)
+
+	rs := tReqDelete.Do(t)
+	assert.Equal(t, http.StatusOK, rs.StatusCode)
+
+	tReqGet := test.CreateRequestTester(
+		testApp.routes(),
+		http.MethodGet,
+		"/locations/%s",
+		location.ID,
+	)
+	tReqGet.AddCookie(testEnv.fixtures.Tokens.AdminAccessToken)
+
+	rs = tReqGet.Do(t)
+	assert.Equal(t, http.StatusNotFound, rs.StatusCode)
+
+	now := time.Now()
+
+	tReqRange := test.CreateRequestTester(
+		testApp.routes(),
+		http.MethodGet,
+		"/all-locations/checkins/range",
+	)
+	tReqRange.AddCookie(testEnv.fixtures.Tokens.AdminAccessToken)
+	tReqRange.SetQuery(map[string][]string{
+		"ids":        {location.ID},
+		"startDate":  {now.AddDate(0, 0, -1).Format(constants.DateFormat)},
+		"endDate":    {now.AddDate(0, 0, 1).Format(constants.DateFormat)},
+		"returnType": {"raw"},
+	})
+
+	rs = tReqRange.Do(t)
+
+	var rsRangeData dtos.CheckInsGraphDto
+	err := httptools.ReadJSON(rs.Body, &rsRangeData)
+	require.Nil(t, err)
+
+	assert.Equal(t, http.StatusOK, rs.StatusCode)
+	assert.Equal(t, 2, rsRangeData.ValuesPerSchool["Andere"][1])
+
+	tReq := test.CreateRequestTester(
+		testApp.routes(),
+		http.MethodPost,
+		"/locations/%s/restore",
+		location.ID,
+	)
+	tReq.AddCookie(testEnv.fixtures.Tokens.AdminAccessToken)
+
+	rs = tReq.Do(t)
+
+	var rsData models.Location
+	err = httptools.ReadJSON(rs.Body, &rsData)
+	require.Nil(t, err)
+
+	assert.Equal(t, http.StatusOK, rs.StatusCode)
+	assert.Equal(t, location.ID, rsData.ID)
+	assert.Equal(t, location.Name, rsData.Name)
+	assert.EqualValues(t, 2, rsData.CheckInsToday)
+
+	rs = tReqGet.Do(t)
+	assert.Equal(t, http.StatusOK, rs.StatusCode)
+
+	rs = tReq.Do(t)
+	assert.Equal(t, http.StatusNotFound, rs.StatusCode)
+}
+
+func TestRestoreLocationNameInUse(t *testing.T) {
+	testEnv, testApp := setup(t)
+	defer testEnv.teardown()
+
+	location := testEnv.createLocations(1)[0]
+
+	_, err := testApp.services.Locations.Delete(
+		context.Background(),
+		testEnv.fixtures.AdminUser,
+		location.ID,
+	)
+	require.Nil(t, err)
+
+	_, err = testApp.services.Locations.Create(
+		context.Background(),
+		testEnv.fixtures.AdminUser,
+		dtos.CreateLocationDto{
+			Name:     location.Name,
+			Capacity: 10,
+			TimeZone: "Europe/Brussels",
+			Username: "OtherUsername",
+			Password: "testpassword",
+		},
+	)
+	require.Nil(t, err)
+
+	tReq := test.CreateRequestTester(
+		testApp.routes(),
+		http.MethodPost,
+		"/locations/%s/restore",
+		location.ID,
+	)
+	tReq.AddCookie(testEnv.fixtures.Tokens.AdminAccessToken)
+
+	rs := tReq.Do(t)
+
+	var rsData errortools.ErrorDto
+	err = httptools.ReadJSON(rs.Body, &rsData)
+	require.Nil(t, err)
+
+	assert.Equal(t, http.StatusBadRequest, rs.StatusCode)
+	assert.Equal(t, "location can't be restored as its name is in use", rsData.Message)
+}
+
+func TestRestoreLocationAccess(t *testing.T) {
+	testEnv, testApp := setup(t)
+	defer testEnv.teardown()
+
+	tReqBase := test.CreateRequestTester(
+		testApp.routes(),
+		http.MethodPost,
+		"/locations/%s/restore",
+		testEnv.fixtures.DefaultLocation.ID,
+	)
+
+	mt := test.CreateMatrixTester()
+
+	mt.AddTestCase(tReqBase, test.NewCaseResponse(http.StatusUnauthorized, nil, nil))
+
+	tReq2 := tReqBase.Copy()
+	tReq2.AddCookie(testEnv.fixtures.Tokens.DefaultAccessToken)
+	mt.AddTestCase(tReq2, test.NewCaseResponse(http.StatusForbidden, nil, nil))
+
+	tReq3 := tReqBase.Copy()
+	tReq3.AddCookie(testEnv.fixtures.Tokens.ManagerAccessToken)
+	mt.AddTestCase(tReq3, test.NewCaseResponse(http.StatusForbidden, nil, nil))
+
+	mt.Do(t)
+}
+
+func TestManagerUnassignedLocation(t *testing.T) {
+	testEnv, testApp := setup(t)
+	defer testEnv.teardown()
+
+	location, err := testApp.services.Locations.Create(
+		context.Background(),
+		testEnv.fixtures.AdminUser,
+		dtos.CreateLocationDto{
+			Name:     "Unassigned",
+			Capacity: 10,
+			TimeZone: "Europe/Brussels",
+			Username: "UnassignedUser",
+			Password: "testpassword",
+		},
+	)
+	require.Nil(t, err)
+
+	checkIns := testEnv.createCheckIns(location, 1, 1)
+
+	tReq1 := test.CreateRequestTester(
+		testApp.routes(),
+		http.MethodGet,
+		"/all-locations",
+	)
+	tReq1.AddCookie(testEnv.fixtures.Tokens.ManagerAccessToken)
+
+	rs1 := tReq1.Do(t)
+
+	var rsData []models.Location
+	err = httptools.ReadJSON(rs1.Body, &rsData)
+	require.Nil(t, err)
+
+	assert.Equal(t, http.StatusOK, rs1.StatusCode)
+	assert.Equal(t, 1, len(rsData))
+	assert.Equal(t, testEnv.fixtures.DefaultLocation.ID, rsData[0].ID)
+
+	mt := test.CreateMatrixTester()
+
+	tReq2 := test.CreateRequestTester(
+		testApp.routes(),
+		http.MethodGet,
+		"/all-locations/checkins/range",
+	)
+	tReq2.AddCookie(testEnv.fixtures.Tokens.ManagerAccessToken)
+	tReq2.SetQuery(map[string][]string{
+		"ids":        {location.ID},
+		"startDate":  {"2024-01-01"},
+		"endDate":    {"2024-01-02"},
+		"returnType": {"raw"},
+	})
+
+	mt.AddTestCase(tReq2, test.NewCaseResponse(http.StatusNotFound, nil, nil))
+
+	tReq3 := test.CreateRequestTester(
+		testApp.routes(),
+		http.MethodDelete,
+		"/locations/%s/checkins/%d",
+		location.ID,
+		checkIns[0].ID,
+	)
+	tReq3.AddCookie(testEnv.fixtures.Tokens.ManagerAccessToken)
+
+	mt.AddTestCase(tReq3, test.NewCaseResponse(http.StatusNotFound, nil, nil))
+
+	tReq4 := test.CreateRequestTester(
+		testApp.routes(),
+		http.MethodDelete,
+		"/locations/%s",
+		location.ID,
+	)
+	tReq4.AddCookie(testEnv.fixtures.Tokens.ManagerAccessToken)
+
+	mt.AddTestCase(tReq4, test.NewCaseResponse(http.StatusNotFound, nil, nil))
+
+	mt.Do(t)
+}
+
+func TestCreateLocationAssignsManager(t *testing.T) {
+	testEnv, testApp := setup(t)
+	defer testEnv.teardown()
+
+	location, err := testApp.services.Locations.Create(
+		context.Background(),
+		testEnv.fixtures.ManagerUser,
+		dtos.CreateLocationDto{
+			Name:     "OwnSite",
+			Capacity: 10,
+			TimeZone: "Europe/Brussels",
+			Username: "OwnSiteUser",
+			Password: "testpassword",
+		},
+	)
+	require.Nil(t, err)
+
+	tReq := test.CreateRequestTester(
+		testApp.routes(),
+		http.MethodGet,
+		"/locations/%s",
+		location.ID,
+	)
+	tReq.AddCookie(testEnv.fixtures.Tokens.ManagerAccessToken)
+
+	rs := tReq.Do(t)
+
+	assert.Equal(t, http.StatusOK, rs.StatusCode)
+}
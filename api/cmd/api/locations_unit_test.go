@@ -102,6 +102,153 @@ func TestSetCheckInRelatedFields(t *testing.T) {
 	assert.EqualValues(t, location4.CapacityYesterday, 15)
 	//nolint:exhaustruct // other fields are optional
 	assert.Equal(t, location4.YesterdayFullAt, pgtype.Timestamptz{})
+
+	// Case 5: today check-ins, some checked out
+	//nolint:exhaustruct // other fields are optional
+	location5 := models.Location{
+		Capacity: 15,
+	}
+	checkedOutCheckIns := generateCheckIns(5, 15, createdAt)
+	checkedOutCheckIns[0].CheckedOutAt = pgtype.Timestamptz{
+		Time:             createdAt,
+		InfinityModifier: pgtype.Finite,
+		Valid:            true,
+	}
+	location5.SetCheckInRelatedFields(checkedOutCheckIns, noCheckIns)
+	assert.EqualValues(t, location5.Available, 11)
+	assert.EqualValues(t, location5.Occupancy, 4)
+	assert.EqualValues(t, location5.CheckInsToday, 5)
+}
+
+func TestBusinessDayBounds(t *testing.T) {
+	//nolint:exhaustruct // other fields are optional
+	location := models.Location{
+		TimeZone:         "Europe/Brussels",
+		BusinessDayStart: "18:00",
+	}
+
+	// 18:00 in Brussels is 17:00 UTC in winter
+	date := time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC)
+	start, end := location.DayBounds(date)
+	assert.Equal(t, time.Date(2024, 1, 10, 17, 0, 0, 0, time.UTC), start)
+	assert.Equal(t, time.Date(2024, 1, 11, 16, 59, 59, 999999999, time.UTC), end)
+
+	// Before the business day starts, the previous business day is still ongoing
+	morning := time.Date(2024, 1, 11, 9, 30, 0, 0, time.UTC)
+	assert.Equal(t, date, location.BusinessDate(morning))
+	startAt, endAt := location.DayBoundsAt(morning)
+	assert.Equal(t, start, startAt)
+	assert.Equal(t, end, endAt)
+
+	lateAfternoon := time.Date(2024, 1, 11, 16, 59, 0, 0, time.UTC)
+	assert.Equal(t, date, location.BusinessDate(lateAfternoon))
+
+	evening := time.Date(2024, 1, 11, 17, 0, 0, 0, time.UTC)
+	assert.Equal(t, date.AddDate(0, 0, 1), location.BusinessDate(evening))
+
+	// The business day containing the switch to summer time lasts 23 hours
+	dstDate := time.Date(2024, 3, 30, 0, 0, 0, 0, time.UTC)
+	start, end = location.DayBounds(dstDate)
+	assert.Equal(t, time.Date(2024, 3, 30, 17, 0, 0, 0, time.UTC), start)
+	assert.Equal(t, time.Date(2024, 3, 31, 15, 59, 59, 999999999, time.UTC), end)
+
+	afterSwitch := time.Date(2024, 3, 31, 16, 30, 0, 0, time.UTC)
+	assert.Equal(t, dstDate.AddDate(0, 0, 1), location.BusinessDate(afterSwitch))
+
+	// Without a business day start, days start at local midnight
+	//nolint:exhaustruct // other fields are optional
+	midnightLocation := models.Location{
+		TimeZone: "Europe/Brussels",
+	}
+	start, end = midnightLocation.DayBoundsAt(morning)
+	assert.Equal(t, time.Date(2024, 1, 10, 23, 0, 0, 0, time.UTC), start)
+	assert.Equal(t, time.Date(2024, 1, 11, 22, 59, 59, 999999999, time.UTC), end)
+
+	lateEvening := time.Date(2024, 1, 11, 23, 30, 0, 0, time.UTC)
+	assert.Equal(
+		t,
+		time.Date(2024, 1, 12, 0, 0, 0, 0, time.UTC),
+		midnightLocation.BusinessDate(lateEvening),
+	)
+}
+
+func TestGranularityStart(t *testing.T) {
+	//nolint:exhaustruct // other fields are optional
+	location := models.Location{
+		TimeZone: "Europe/Brussels",
+	}
+
+	// sunday 31 march in UTC, but already monday 1 april in Brussels
+	at := time.Date(2024, 3, 31, 22, 30, 0, 0, time.UTC)
+	date := location.BusinessDate(at)
+
+	assert.Equal(
+		t,
+		time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC),
+		models.DayGranularity.Start(date),
+	)
+	assert.Equal(
+		t,
+		time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC),
+		models.WeekGranularity.Start(date),
+	)
+	assert.Equal(
+		t,
+		time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC),
+		models.MonthGranularity.Start(date),
+	)
+	assert.Equal(
+		t,
+		time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		models.YearGranularity.Start(date),
+	)
+
+	// a business date in another time zone keeps its date
+	brussels, err := time.LoadLocation(location.TimeZone)
+	assert.Nil(t, err)
+	assert.Equal(
+		t,
+		time.Date(2024, 3, 25, 0, 0, 0, 0, time.UTC),
+		models.WeekGranularity.Start(time.Date(2024, 3, 31, 0, 0, 0, 0, brussels)),
+	)
+}
+
+func TestOpeningHoursIsOpenAt(t *testing.T) {
+	openingHours := models.OpeningHours{
+		Periods: []*models.OpeningPeriod{
+			{Weekday: int64(time.Monday), OpensAt: "09:00", ClosesAt: "12:00"},
+			{Weekday: int64(time.Friday), OpensAt: "18:00", ClosesAt: "10:00"},
+		},
+		ClosingDays: []*models.ClosingDay{
+			{Date: "2024-01-15", Reason: "holiday"},
+		},
+	}
+
+	// 2024-01-08 is a Monday
+	assert.True(t, openingHours.IsOpenAt(time.Date(2024, 1, 8, 9, 0, 0, 0, time.UTC)))
+	assert.False(t, openingHours.IsOpenAt(time.Date(2024, 1, 8, 12, 0, 0, 0, time.UTC)))
+	assert.False(t, openingHours.IsOpenAt(time.Date(2024, 1, 9, 10, 0, 0, 0, time.UTC)))
+
+	// overnight from Friday to Saturday
+	assert.True(t, openingHours.IsOpenAt(time.Date(2024, 1, 12, 23, 0, 0, 0, time.UTC)))
+	assert.True(t, openingHours.IsOpenAt(time.Date(2024, 1, 13, 3, 0, 0, 0, time.UTC)))
+	assert.False(t, openingHours.IsOpenAt(time.Date(2024, 1, 13, 10, 0, 0, 0, time.UTC)))
+
+	// closing day on a Monday
+	assert.False(t, openingHours.IsOpenAt(time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)))
+
+	assert.False(t, openingHours.IsClosedOn(time.Date(2024, 1, 8, 0, 0, 0, 0, time.UTC)))
+	assert.True(t, openingHours.IsClosedOn(time.Date(2024, 1, 9, 0, 0, 0, 0, time.UTC)))
+	assert.True(t, openingHours.IsClosedOn(time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)))
+
+	// without a weekly schedule a location is always open, except on closing days
+	//nolint:exhaustruct // other fields are optional
+	alwaysOpen := models.OpeningHours{
+		ClosingDays: openingHours.ClosingDays,
+	}
+	assert.True(t, alwaysOpen.IsOpenAt(time.Date(2024, 1, 9, 3, 0, 0, 0, time.UTC)))
+	assert.False(t, alwaysOpen.IsOpenAt(time.Date(2024, 1, 15, 3, 0, 0, 0, time.UTC)))
+	assert.False(t, alwaysOpen.IsClosedOn(time.Date(2024, 1, 9, 0, 0, 0, 0, time.UTC)))
 }
 
 func generateCheckIns(amount int, capacity int, createdAt time.Time) []*models.CheckIn {
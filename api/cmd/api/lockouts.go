@@ -0,0 +1,90 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+
+	httptools "github.com/XDoubleU/essentia/pkg/communication/http"
+	"github.com/XDoubleU/essentia/pkg/context"
+	"github.com/XDoubleU/essentia/pkg/parse"
+
+	"check-in/api/internal/constants"
+	"check-in/api/internal/models"
+)
+
+func (app *Application) lockoutsRoutes(mux *http.ServeMux) {
+	mux.HandleFunc(
+		"GET /lockouts",
+		app.authAccess(models.LockoutsReadPermission, app.getLockoutsHandler),
+	)
+	mux.HandleFunc(
+		"DELETE /lockouts/{kind}/{value}",
+		app.authAccess(models.LockoutsWritePermission, app.deleteLockoutHandler),
+	)
+}
+
+// @Summary	Get all usernames and IPs which are locked out
+// @Tags		lockouts
+// @Success	200	{object}	[]Lockout
+// @Failure	401	{object}	ErrorDto
+// @Failure	500	{object}	ErrorDto
+// @Router		/lockouts [get].
+func (app *Application) getLockoutsHandler(w http.ResponseWriter, r *http.Request) {
+	user := context.GetValue[models.User](r.Context(), constants.UserContextKey)
+	lockouts, err := app.services.Lockouts.GetAll(r.Context(), user)
+	if err != nil {
+		httptools.ServerErrorResponse(w, r, err)
+		return
+	}
+
+	err = httptools.WriteJSON(w, http.StatusOK, lockouts, nil)
+	if err != nil {
+		httptools.ServerErrorResponse(w, r, err)
+	}
+}
+
+// @Summary	Clear the lockout of a username or IP
+// @Tags		lockouts
+// @Param		kind	path		string	true	"Kind of lockout, username or ip"
+// @Param		value	path		string	true	"Username or IP"
+// @Success	200		{object}	Lockout
+// @Failure	400		{object}	ErrorDto
+// @Failure	401		{object}	ErrorDto
+// @Failure	404		{object}	ErrorDto
+// @Failure	500		{object}	ErrorDto
+// @Router		/lockouts/{kind}/{value} [delete].
+func (app *Application) deleteLockoutHandler(w http.ResponseWriter, r *http.Request) {
+	kind, err := parse.URLParam(r, "kind", parse.String)
+	if err != nil {
+		httptools.BadRequestResponse(w, r, err)
+		return
+	}
+
+	value, err := parse.URLParam(r, "value", parse.String)
+	if err != nil {
+		httptools.BadRequestResponse(w, r, err)
+		return
+	}
+
+	key := models.NewLockoutKey(models.LockoutKind(kind), value)
+	if key.Kind != models.UsernameLockout && key.Kind != models.IPLockout {
+		httptools.BadRequestResponse(
+			w,
+			r,
+			errors.New("kind of lockout must be username or ip"),
+		)
+		return
+	}
+
+	user := context.GetValue[models.User](r.Context(), constants.UserContextKey)
+	lockout, err := app.services.Lockouts.Delete(r.Context(), user, key)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	err = httptools.WriteJSON(w, http.StatusOK, lockout, nil)
+	if err != nil {
+		httptools.ServerErrorResponse(w, r, err)
+	}
+}
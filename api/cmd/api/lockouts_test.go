@@ -0,0 +1,343 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	httptools "github.com/XDoubleU/essentia/pkg/communication/http"
+	"github.com/XDoubleU/essentia/pkg/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"check-in/api/internal/dtos"
+	"check-in/api/internal/models"
+)
+
+func failSignIns(t *testing.T, app Application, username string, amount int) {
+	t.Helper()
+
+	for i := 0; i < amount; i++ {
+		tReq := test.CreateRequestTester(
+			app.routes(),
+			http.MethodPost,
+			"/auth/signin",
+		)
+		tReq.SetData(dtos.SignInDto{
+			Username:   username,
+			Password:   "wrongpassword",
+			RememberMe: false,
+			Code:       "",
+		})
+
+		rs := tReq.Do(t)
+		require.Equal(t, http.StatusUnauthorized, rs.StatusCode)
+	}
+}
+
+func TestSignInLockout(t *testing.T) {
+	testEnv, testApp := setup(t)
+	defer testEnv.teardown()
+
+	failSignIns(t, testApp, "Default", 5)
+
+	tReq := test.CreateRequestTester(
+		testApp.routes(),
+		http.MethodPost,
+		"/auth/signin",
+	)
+	tReq.SetData(dtos.SignInDto{
+		Username:   "Default",
+		Password:   "testpassword",
+		RememberMe: false,
+		Code:       "",
+	})
+
+	rs := tReq.Do(t)
+
+	assert.Equal(t, http.StatusTooManyRequests, rs.StatusCode)
+	assert.Equal(t, "30", rs.Header.Get("Retry-After"))
+
+	tReqAudit := test.CreateRequestTester(
+		testApp.routes(),
+		http.MethodGet,
+		"/audit",
+	)
+	tReqAudit.SetQuery(map[string][]string{
+		"entityType": {"lockout"},
+		"entityId":   {"username:Default"},
+		"action":     {string(models.AuditLockout)},
+	})
+	tReqAudit.AddCookie(testEnv.fixtures.Tokens.AdminAccessToken)
+
+	rs = tReqAudit.Do(t)
+
+	var rsData dtos.PaginatedAuditEventsDto
+	err := httptools.ReadJSON(rs.Body, &rsData)
+	require.Nil(t, err)
+
+	assert.Equal(t, http.StatusOK, rs.StatusCode)
+	assert.Equal(t, 1, len(rsData.Data))
+}
+
+func TestSignInLockoutBackoff(t *testing.T) {
+	testEnv, testApp := setup(t)
+	defer testEnv.teardown()
+
+	failSignIns(t, testApp, "Default", 5)
+
+	lockouts, err := testApp.services.Lockouts.GetAll(
+		testEnv.ctx,
+		testEnv.fixtures.AdminUser,
+	)
+	require.Nil(t, err)
+	require.Equal(t, 2, len(lockouts))
+
+	_, err = postgresDB.Exec(
+		testEnv.ctx,
+		"UPDATE lockouts SET locked_until = NULL",
+	)
+	require.Nil(t, err)
+
+	failSignIns(t, testApp, "Default", 1)
+
+	lockouts, err = testApp.services.Lockouts.GetAll(
+		testEnv.ctx,
+		testEnv.fixtures.AdminUser,
+	)
+	require.Nil(t, err)
+	require.Equal(t, 2, len(lockouts))
+
+	for _, lockout := range lockouts {
+		assert.EqualValues(t, 6, lockout.FailedAttempts)
+		assert.WithinDuration(
+			t,
+			lockout.LastFailedAt.Time.Add(time.Minute),
+			lockout.LockedUntil.Time,
+			time.Second,
+		)
+	}
+}
+
+func TestSignInSuccessResetsLockouts(t *testing.T) {
+	testEnv, testApp := setup(t)
+	defer testEnv.teardown()
+
+	failSignIns(t, testApp, "Default", 4)
+
+	tReq := test.CreateRequestTester(
+		testApp.routes(),
+		http.MethodPost,
+		"/auth/signin",
+	)
+	tReq.SetData(dtos.SignInDto{
+		Username:   "Default",
+		Password:   "testpassword",
+		RememberMe: false,
+		Code:       "",
+	})
+
+	rs := tReq.Do(t)
+	assert.Equal(t, http.StatusOK, rs.StatusCode)
+
+	failSignIns(t, testApp, "Default", 1)
+
+	rs = tReq.Do(t)
+	assert.Equal(t, http.StatusOK, rs.StatusCode)
+
+	lockouts, err := testApp.services.Lockouts.GetAll(
+		testEnv.ctx,
+		testEnv.fixtures.AdminUser,
+	)
+	require.Nil(t, err)
+	assert.Equal(t, 0, len(lockouts))
+}
+
+func TestSignInLockoutLongUsername(t *testing.T) {
+	testEnv, testApp := setup(t)
+	defer testEnv.teardown()
+
+	username := strings.Repeat("a", 300)
+
+	failSignIns(t, testApp, username, 5)
+
+	tReq := test.CreateRequestTester(
+		testApp.routes(),
+		http.MethodPost,
+		"/auth/signin",
+	)
+	tReq.SetData(dtos.SignInDto{
+		Username:   username,
+		Password:   "wrongpassword",
+		RememberMe: false,
+		Code:       "",
+	})
+
+	rs := tReq.Do(t)
+	assert.Equal(t, http.StatusTooManyRequests, rs.StatusCode)
+}
+
+func TestGetLockouts(t *testing.T) {
+	testEnv, testApp := setup(t)
+	defer testEnv.teardown()
+
+	failSignIns(t, testApp, "Default", 5)
+
+	tReq := test.CreateRequestTester(
+		testApp.routes(),
+		http.MethodGet,
+		"/lockouts",
+	)
+	tReq.AddCookie(testEnv.fixtures.Tokens.AdminAccessToken)
+
+	rs := tReq.Do(t)
+
+	var rsData []models.Lockout
+	err := httptools.ReadJSON(rs.Body, &rsData)
+	require.Nil(t, err)
+
+	assert.Equal(t, http.StatusOK, rs.StatusCode)
+	require.Equal(t, 2, len(rsData))
+
+	kinds := []models.LockoutKind{rsData[0].Kind, rsData[1].Kind}
+	assert.Contains(t, kinds, models.UsernameLockout)
+	assert.Contains(t, kinds, models.IPLockout)
+
+	for _, lockout := range rsData {
+		assert.EqualValues(t, 5, lockout.FailedAttempts)
+		assert.Equal(t, true, lockout.LockedUntil.Valid)
+	}
+}
+
+func TestGetLockoutsAccess(t *testing.T) {
+	testEnv, testApp := setup(t)
+	defer testEnv.teardown()
+
+	tReqBase := test.CreateRequestTester(
+		testApp.routes(),
+		http.MethodGet,
+		"/lockouts",
+	)
+
+	mt := test.CreateMatrixTester()
+
+	mt.AddTestCase(tReqBase, test.NewCaseResponse(http.StatusUnauthorized, nil, nil))
+
+	tReq2 := tReqBase.Copy()
+	tReq2.AddCookie(testEnv.fixtures.Tokens.ManagerAccessToken)
+
+	mt.AddTestCase(tReq2, test.NewCaseResponse(http.StatusForbidden, nil, nil))
+
+	mt.Do(t)
+}
+
+func TestGetLockoutsUnattributed(t *testing.T) {
+	testEnv, testApp := setup(t)
+	defer testEnv.teardown()
+
+	failSignIns(t, testApp, "Unknown", 5)
+
+	lockouts, err := testApp.services.Lockouts.GetAll(
+		testEnv.ctx,
+		testEnv.fixtures.AdminUser,
+	)
+	require.Nil(t, err)
+	assert.Equal(t, 0, len(lockouts))
+
+	lockouts, err = testApp.services.Lockouts.GetAll(
+		testEnv.ctx,
+		testEnv.fixtures.SuperAdminUser,
+	)
+	require.Nil(t, err)
+	assert.Equal(t, 2, len(lockouts))
+}
+
+func TestDeleteLockoutUnattributed(t *testing.T) {
+	testEnv, testApp := setup(t)
+	defer testEnv.teardown()
+
+	failSignIns(t, testApp, "Unknown", 5)
+
+	tReq := test.CreateRequestTester(
+		testApp.routes(),
+		http.MethodDelete,
+		"/lockouts/%s/%s",
+		models.UsernameLockout,
+		"Unknown",
+	)
+
+	mt := test.CreateMatrixTester()
+
+	tReqAdmin := tReq.Copy()
+	tReqAdmin.AddCookie(testEnv.fixtures.Tokens.AdminAccessToken)
+
+	mt.AddTestCase(tReqAdmin, test.NewCaseResponse(http.StatusNotFound, nil, nil))
+
+	tReqSuperAdmin := tReq.Copy()
+	tReqSuperAdmin.AddCookie(testEnv.fixtures.Tokens.SuperAdminAccessToken)
+
+	mt.AddTestCase(tReqSuperAdmin, test.NewCaseResponse(http.StatusOK, nil, nil))
+
+	mt.Do(t)
+}
+
+func TestDeleteLockout(t *testing.T) {
+	testEnv, testApp := setup(t)
+	defer testEnv.teardown()
+
+	failSignIns(t, testApp, "Default", 5)
+
+	lockouts, err := testApp.services.Lockouts.GetAll(
+		testEnv.ctx,
+		testEnv.fixtures.AdminUser,
+	)
+	require.Nil(t, err)
+
+	for _, lockout := range lockouts {
+		tReq := test.CreateRequestTester(
+			testApp.routes(),
+			http.MethodDelete,
+			"/lockouts/%s/%s",
+			lockout.Kind,
+			lockout.Value,
+		)
+		tReq.AddCookie(testEnv.fixtures.Tokens.AdminAccessToken)
+
+		rs1 := tReq.Do(t)
+		assert.Equal(t, http.StatusOK, rs1.StatusCode)
+
+		rs2 := tReq.Do(t)
+		assert.Equal(t, http.StatusNotFound, rs2.StatusCode)
+	}
+
+	tReqSignIn := test.CreateRequestTester(
+		testApp.routes(),
+		http.MethodPost,
+		"/auth/signin",
+	)
+	tReqSignIn.SetData(dtos.SignInDto{
+		Username:   "Default",
+		Password:   "testpassword",
+		RememberMe: false,
+		Code:       "",
+	})
+
+	rs := tReqSignIn.Do(t)
+	assert.Equal(t, http.StatusOK, rs.StatusCode)
+}
+
+func TestDeleteLockoutInvalidKind(t *testing.T) {
+	testEnv, testApp := setup(t)
+	defer testEnv.teardown()
+
+	tReq := test.CreateRequestTester(
+		testApp.routes(),
+		http.MethodDelete,
+		"/lockouts/email/Default",
+	)
+	tReq.AddCookie(testEnv.fixtures.Tokens.AdminAccessToken)
+
+	rs := tReq.Do(t)
+	assert.Equal(t, http.StatusBadRequest, rs.StatusCode)
+}
@@ -29,21 +29,26 @@ type TestEnv struct {
 }
 
 type Tokens struct {
-	AdminAccessToken    *http.Cookie
-	ManagerAccessToken  *http.Cookie
-	ManagerRefreshToken *http.Cookie
-	DefaultAccessToken  *http.Cookie
-	DefaultRefreshToken *http.Cookie
+	SuperAdminAccessToken *http.Cookie
+	AdminAccessToken      *http.Cookie
+	ManagerAccessToken    *http.Cookie
+	ManagerRefreshToken   *http.Cookie
+	DefaultAccessToken    *http.Cookie
+	DefaultRefreshToken   *http.Cookie
 }
 
 type Fixtures struct {
 	Tokens          Tokens
+	Organisation    *models.Organisation
+	SuperAdminUser  *models.User
 	AdminUser       *models.User
 	ManagerUser     *models.User
 	DefaultUser     *models.User
 	DefaultLocation *models.Location
 }
 
+const defaultOrganisationName = "Default"
+
 var cfg config.Config        //nolint:gochecknoglobals //required
 var postgresDB *pgxpool.Pool //nolint:gochecknoglobals //required
 
@@ -60,16 +65,28 @@ var timesToCheck = []shared.LocalNowTimeProvider{ //nolint:gochecknoglobals //re
 func (env *TestEnv) defaultFixtures() {
 	var err error
 
-	_, err = env.app.services.State.UpdateState(context.Background(), dtos.StateDto{
-		IsMaintenance: false,
-	})
+	env.fixtures.Organisation, err = env.app.services.Organisations.GetByName(
+		env.ctx,
+		defaultOrganisationName,
+	)
+	if err != nil {
+		panic(err)
+	}
+
+	_, err = env.app.services.State.UpdateState(
+		context.Background(),
+		env.fixtures.Organisation.ID,
+		dtos.StateDto{
+			IsMaintenance: false,
+		},
+	)
 	if err != nil {
 		panic(err)
 	}
 
 	password := "testpassword"
 	env.fixtures.AdminUser, err = env.app.services.Users.Create(env.ctx,
-
+		env.fixtures.Organisation.ID,
 		dtos.CreateUserDto{
 			Username: "Admin",
 			Password: password,
@@ -82,8 +99,20 @@ func (env *TestEnv) defaultFixtures() {
 
 	env.ctx = env.app.contextSetUser(env.ctx, *env.fixtures.AdminUser)
 
-	env.fixtures.ManagerUser, err = env.app.services.Users.Create(env.ctx,
+	env.fixtures.SuperAdminUser, err = env.app.services.Users.Create(env.ctx,
+		env.fixtures.Organisation.ID,
+		dtos.CreateUserDto{
+			Username: "SuperAdmin",
+			Password: password,
+		},
+		models.SuperAdminRole,
+	)
+	if err != nil {
+		panic(err)
+	}
 
+	env.fixtures.ManagerUser, err = env.app.services.Users.Create(env.ctx,
+		env.fixtures.Organisation.ID,
 		dtos.CreateUserDto{
 			Username: "Manager",
 			Password: password,
@@ -94,6 +123,10 @@ func (env *TestEnv) defaultFixtures() {
 		panic(err)
 	}
 
+	env.fixtures.Tokens.SuperAdminAccessToken = env.createAccessToken(
+		*env.fixtures.SuperAdminUser,
+	)
+
 	env.fixtures.Tokens.AdminAccessToken = env.createAccessToken(
 		*env.fixtures.AdminUser,
 	)
@@ -121,8 +154,19 @@ func (env *TestEnv) defaultFixtures() {
 		panic(err)
 	}
 
+	_, err = env.app.services.Locations.AssignManager(
+		env.ctx,
+		env.fixtures.AdminUser,
+		env.fixtures.ManagerUser.ID,
+		env.fixtures.DefaultLocation.ID,
+	)
+	if err != nil {
+		panic(err)
+	}
+
 	env.fixtures.DefaultUser, err = env.app.services.Locations.GetDefaultUserByUserID(
 		env.ctx,
+		env.fixtures.Organisation.ID,
 		env.fixtures.DefaultLocation.UserID,
 	)
 	if err != nil {
@@ -143,7 +187,7 @@ func (env *TestEnv) createAccessToken(user models.User) *http.Cookie {
 	accessToken, err := env.app.services.Auth.CreateCookie(
 		env.ctx,
 		models.AccessScope,
-		user.ID,
+		&user,
 		env.app.config.AccessExpiry,
 		false,
 	)
@@ -160,7 +204,7 @@ func (env *TestEnv) createRefreshToken(user models.User) *http.Cookie {
 	refreshToken, err := env.app.services.Auth.CreateCookie(
 		env.ctx,
 		models.RefreshScope,
-		user.ID,
+		&user,
 		env.app.config.RefreshExpiry,
 		false,
 	)
@@ -174,12 +218,21 @@ func (env *TestEnv) createRefreshToken(user models.User) *http.Cookie {
 func (env *TestEnv) clearAllData() {
 	var err error
 
+	organisation, err := env.app.services.Organisations.GetByName(
+		env.ctx,
+		defaultOrganisationName,
+	)
+	if err != nil {
+		panic(err)
+	}
+
 	//nolint:exhaustruct //other fields are optional
 	fakeAdminUser := &models.User{
-		Role: models.AdminRole,
+		Role:           models.AdminRole,
+		OrganisationID: organisation.ID,
 	}
 
-	locations, _ := env.app.services.Locations.GetAll(env.ctx, nil, true)
+	locations, _ := env.app.services.Locations.GetAll(env.ctx, fakeAdminUser, true)
 	for _, location := range locations {
 		_, err = env.app.services.Locations.Delete(
 			env.ctx,
@@ -191,37 +244,99 @@ func (env *TestEnv) clearAllData() {
 		}
 	}
 
-	users, _ := env.app.services.Users.GetAll(env.ctx)
+	users, _ := env.app.services.Users.GetAll(env.ctx, organisation.ID)
 	for _, user := range users {
-		_, err = env.app.services.Users.Delete(env.ctx, user.ID, user.Role)
-		if err != nil {
-			panic(err)
-		}
-	}
-
-	adminUser, _ := env.app.services.Users.GetByUsername(env.ctx, "Admin")
-	if adminUser != nil {
 		_, err = env.app.services.Users.Delete(
 			env.ctx,
-			adminUser.ID,
-			adminUser.Role,
+			organisation.ID,
+			user.ID,
+			user.Role,
 		)
 		if err != nil {
 			panic(err)
 		}
 	}
 
-	schools, _ := env.app.services.Schools.GetAll(env.ctx)
+	for _, username := range []string{"Admin", "SuperAdmin"} {
+		adminUser, _ := env.app.services.Users.GetByUsername(env.ctx, username)
+		if adminUser != nil {
+			_, err = env.app.services.Users.Delete(
+				env.ctx,
+				adminUser.OrganisationID,
+				adminUser.ID,
+				adminUser.Role,
+			)
+			if err != nil {
+				panic(err)
+			}
+		}
+	}
+
+	schools, _ := env.app.services.Schools.GetAll(env.ctx, organisation.ID)
 	for _, school := range schools {
 		if school.ID == 1 {
 			continue
 		}
 
-		_, err = env.app.services.Schools.Delete(env.ctx, school.ID)
+		_, err = env.app.services.Schools.Delete(env.ctx, organisation.ID, school.ID)
 		if err != nil {
 			panic(err)
 		}
 	}
+
+	// deleting only archives, purge so tests don't see each other's rows,
+	// rows of organisations created by tests are purged as well
+	for _, table := range []string{"locations", "users", "schools"} {
+		_, err = postgresDB.Exec(
+			env.ctx,
+			fmt.Sprintf(
+				"DELETE FROM %s WHERE archived_at IS NOT NULL OR organisation_id <> $1",
+				table,
+			),
+			organisation.ID,
+		)
+		if err != nil {
+			panic(err)
+		}
+	}
+
+	_, err = postgresDB.Exec(
+		env.ctx,
+		"DELETE FROM organisations WHERE id <> $1",
+		organisation.ID,
+	)
+	if err != nil {
+		panic(err)
+	}
+
+	// built-in roles are seeded by the migrations and stay
+	_, err = postgresDB.Exec(
+		env.ctx,
+		"DELETE FROM roles WHERE organisation_id IS NOT NULL",
+	)
+	if err != nil {
+		panic(err)
+	}
+
+	_, err = postgresDB.Exec(env.ctx, "DELETE FROM api_keys")
+	if err != nil {
+		panic(err)
+	}
+
+	_, err = postgresDB.Exec(env.ctx, "DELETE FROM lockouts")
+	if err != nil {
+		panic(err)
+	}
+
+	_, err = postgresDB.Exec(env.ctx, "DELETE FROM oidc_logins")
+	if err != nil {
+		panic(err)
+	}
+
+	_, err = postgresDB.Exec(env.ctx, "DELETE FROM users WHERE oidc_subject IS NOT NULL")
+	if err != nil {
+		panic(err)
+	}
 }
 
 func (env *TestEnv) createManagerUsers(amount int) []*models.User {
@@ -232,7 +347,7 @@ func (env *TestEnv) createManagerUsers(amount int) []*models.User {
 	for i := 0; i < amount; i++ {
 		var newUser *models.User
 		newUser, err = env.app.services.Users.Create(env.ctx,
-
+			env.fixtures.Organisation.ID,
 			dtos.CreateUserDto{
 				Username: fmt.Sprintf("TestManagerUser%d", i),
 				Password: password,
@@ -271,12 +386,35 @@ func (env *TestEnv) createLocations(amount int) []*models.Location {
 			panic(err)
 		}
 
+		_, err = env.app.services.Locations.AssignManager(
+			env.ctx,
+			env.fixtures.AdminUser,
+			env.fixtures.ManagerUser.ID,
+			location.ID,
+		)
+		if err != nil {
+			panic(err)
+		}
+
 		locations = append(locations, location)
 	}
 
 	return locations
 }
 
+// at returns a copy of the environment of which the app
+// uses the provided time as the current time.
+func (env TestEnv) at(now time.Time) *TestEnv {
+	env.app = *NewApp(
+		env.app.logger,
+		cfg,
+		postgresDB,
+		func() time.Time { return now },
+	)
+
+	return &env
+}
+
 func (env *TestEnv) createCheckIns(
 	location *models.Location,
 	schoolID int64,
@@ -286,6 +424,7 @@ func (env *TestEnv) createCheckIns(
 
 	defaultUser, err := env.app.services.Locations.GetDefaultUserByUserID(
 		env.ctx,
+		location.OrganisationID,
 		location.UserID,
 	)
 	if err != nil {
@@ -318,13 +457,18 @@ func (env *TestEnv) createSchools(amount int) []*models.School {
 	for i := 0; i < amount; i++ {
 		name := fmt.Sprintf("TestSchool%d", i)
 
-		school, err := env.app.services.Schools.GetByName(env.ctx, name)
+		school, err := env.app.services.Schools.GetByName(
+			env.ctx,
+			env.fixtures.Organisation.ID,
+			name,
+		)
 		if err != nil && !errors.Is(err, database.ErrResourceNotFound) {
 			panic(err)
 		}
 
 		if school == nil {
 			school, err = env.app.services.Schools.Create(env.ctx,
+				env.fixtures.Organisation.ID,
 				dtos.SchoolDto{
 					Name: name,
 				})
@@ -2,70 +2,161 @@ package main
 
 import (
 	"errors"
+	"math"
 	"net/http"
+	"strconv"
+	"strings"
 
 	httptools "github.com/XDoubleU/essentia/pkg/communication/http"
+	contexttools "github.com/XDoubleU/essentia/pkg/context"
 	errortools "github.com/XDoubleU/essentia/pkg/errors"
 
+	"check-in/api/internal/constants"
+	"check-in/api/internal/dtos"
 	"check-in/api/internal/models"
 )
 
-func (app *Application) authAccess(allowedRoles []models.Role,
+func (app *Application) authAccess(requiredPermission models.Permission,
 	next http.HandlerFunc) http.HandlerFunc {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		tokenCookie, err := r.Cookie("accessToken")
-
+		user, err := app.authenticate(r)
 		if err != nil {
-			httptools.UnauthorizedResponse(w, r,
-				errortools.NewUnauthorizedError(errors.New("no token in cookies")))
+			httptools.HandleError(w, r, err)
 			return
 		}
 
-		_, user, err := app.services.Auth.GetToken(
-			r.Context(),
-			models.AccessScope,
-			tokenCookie.Value,
-		)
-		if err != nil {
-			httptools.UnauthorizedResponse(
+		r = r.WithContext(app.contextSetUser(r.Context(), *user))
+
+		if requiredPermission != signedIn &&
+			!user.HasPermission(requiredPermission) {
+			httptools.ForbiddenResponse(w, r)
+			return
+		}
+
+		// users can still enrol when two-factor authentication is required
+		if requiredPermission != signedIn && user.NeedsTwoFactor() {
+			httptools.ErrorResponse(
 				w,
 				r,
-				errortools.NewUnauthorizedError(
-					errors.New("provided token doesn't exist"),
-				),
+				http.StatusForbidden,
+				"two-factor authentication has to be enabled",
 			)
 			return
 		}
 
-		if user.Role == models.DefaultRole {
-			user, err = app.services.Locations.GetDefaultUserByUserID(
-				r.Context(),
-				user.ID,
+		app.maintenanceAccess(next).ServeHTTP(w, r)
+	})
+}
+
+// authenticate uses the API key in the Authorization header if provided,
+// otherwise the access token in the cookies.
+func (app *Application) authenticate(r *http.Request) (*models.User, error) {
+	if key, ok := bearerToken(r); ok {
+		user, err := app.services.APIKeys.GetUserByKey(r.Context(), key)
+		if err != nil {
+			return nil, errortools.NewUnauthorizedError(
+				errors.New("provided API key doesn't exist"),
 			)
-			if err != nil {
-				httptools.ServerErrorResponse(w, r, err)
-			}
 		}
 
-		r = r.WithContext(app.contextSetUser(r.Context(), *user))
+		return user, nil
+	}
+
+	tokenCookie, err := r.Cookie("accessToken")
+	if err != nil {
+		return nil, errortools.NewUnauthorizedError(errors.New("no token in cookies"))
+	}
+
+	_, user, err := app.services.Auth.GetToken(
+		r.Context(),
+		models.AccessScope,
+		tokenCookie.Value,
+	)
+	if err != nil {
+		return nil, errortools.NewUnauthorizedError(
+			errors.New("provided token doesn't exist"),
+		)
+	}
+
+	err = app.services.Sessions.Touch(r.Context(), user, clientIP(r))
+	if err != nil {
+		return nil, err
+	}
+
+	if user.Role != models.DefaultRole {
+		return user, nil
+	}
+
+	permissions := user.Permissions
+	deviceID := user.DeviceID
+	sessionID := user.SessionID
+
+	user, err = app.services.Locations.GetDefaultUserByUserID(
+		r.Context(),
+		user.OrganisationID,
+		user.ID,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	user.Permissions = permissions
+	user.DeviceID = deviceID
+	user.SessionID = sessionID
+
+	return user, nil
+}
 
-		forbidden := true
-		for _, role := range allowedRoles {
-			if user.Role == role {
-				forbidden = false
-				break
-			}
+func bearerToken(r *http.Request) (string, bool) {
+	return strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+}
+
+func (app *Application) maintenanceAccess(next http.HandlerFunc) http.HandlerFunc {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user := contexttools.GetValue[models.User](
+			r.Context(),
+			constants.UserContextKey,
+		)
+
+		state, err := app.services.State.Get(user.OrganisationID)
+		if err != nil {
+			httptools.HandleError(w, r, err)
+			return
 		}
 
-		if forbidden {
-			httptools.ForbiddenResponse(w, r)
+		if !state.IsMaintenance || user.HasPermission(models.StateWritePermission) ||
+			isReadOnlyMethod(r.Method) {
+			next.ServeHTTP(w, r)
 			return
 		}
 
-		next.ServeHTTP(w, r)
+		// the end might have passed before maintenance is turned off
+		retryAfter := state.MaintenanceEnd.Time.Sub(app.getTimeNowUTC())
+		if state.MaintenanceEnd.Valid && retryAfter > 0 {
+			w.Header().Set(
+				"Retry-After",
+				strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))),
+			)
+		}
+
+		httptools.ErrorResponse(
+			w,
+			r,
+			http.StatusServiceUnavailable,
+			dtos.MaintenanceDto{
+				Message:        "the application is in maintenance mode",
+				MaintenanceEnd: state.MaintenanceEnd,
+			},
+		)
 	})
 }
 
+func isReadOnlyMethod(method string) bool {
+	return method == http.MethodGet ||
+		method == http.MethodHead ||
+		method == http.MethodOptions
+}
+
 func (app *Application) authRefresh(next http.HandlerFunc) http.HandlerFunc {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		tokenCookie, err := r.Cookie("refreshToken")
@@ -92,7 +183,15 @@ func (app *Application) authRefresh(next http.HandlerFunc) http.HandlerFunc {
 		r = r.WithContext(app.contextSetUser(r.Context(), *user))
 
 		if token.Used {
-			err = app.services.Auth.DeleteAllTokensForUser(r.Context(), user.ID)
+			// reuse only signs out the device or user the token was issued to
+			if token.DeviceID.Valid {
+				err = app.services.Auth.DeleteAllTokensForDevice(
+					r.Context(),
+					token.DeviceID.String,
+				)
+			} else {
+				err = app.services.Auth.DeleteAllTokensForUser(r.Context(), user.ID)
+			}
 			if err != nil {
 				httptools.ServerErrorResponse(w, r, err)
 			}
@@ -110,6 +209,12 @@ func (app *Application) authRefresh(next http.HandlerFunc) http.HandlerFunc {
 			return
 		}
 
+		err = app.services.Sessions.Touch(r.Context(), user, clientIP(r))
+		if err != nil {
+			httptools.ServerErrorResponse(w, r, err)
+			return
+		}
+
 		next.ServeHTTP(w, r)
 	})
 }
@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+
+ALTER TABLE states DROP CONSTRAINT IF EXISTS states_value_key;
+
+INSERT INTO states (key, value)
+VALUES ('MaintenanceStart', ''), ('MaintenanceEnd', '');
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DELETE FROM states
+WHERE key IN ('MaintenanceStart', 'MaintenanceEnd');
+-- +goose StatementEnd
@@ -0,0 +1,16 @@
+-- +goose Up
+-- +goose StatementBegin
+
+ALTER TABLE check_ins
+ADD COLUMN idempotency_key varchar(255),
+ADD CONSTRAINT check_ins_location_id_idempotency_key_key
+UNIQUE (location_id, idempotency_key);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE check_ins
+DROP CONSTRAINT IF EXISTS check_ins_location_id_idempotency_key_key,
+DROP COLUMN IF EXISTS idempotency_key;
+-- +goose StatementEnd
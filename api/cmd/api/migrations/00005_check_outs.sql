@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+
+ALTER TABLE check_ins
+ADD COLUMN checked_out_at timestamp with time zone;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE check_ins
+DROP COLUMN IF EXISTS checked_out_at;
+-- +goose StatementEnd
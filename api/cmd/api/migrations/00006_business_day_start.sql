@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+
+ALTER TABLE locations
+ADD COLUMN business_day_start time NOT NULL DEFAULT '00:00';
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE locations
+DROP COLUMN IF EXISTS business_day_start;
+-- +goose StatementEnd
@@ -0,0 +1,25 @@
+-- +goose Up
+-- +goose StatementBegin
+
+CREATE TABLE IF NOT EXISTS opening_hours (
+    id serial4 PRIMARY KEY,
+    location_id uuid NOT NULL REFERENCES locations ON DELETE CASCADE,
+    weekday int2 NOT NULL CHECK (weekday BETWEEN 0 AND 6),
+    opens_at time NOT NULL,
+    closes_at time NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS closing_days (
+    location_id uuid NOT NULL REFERENCES locations ON DELETE CASCADE,
+    date date NOT NULL,
+    reason varchar(255) NOT NULL DEFAULT '',
+    PRIMARY KEY (location_id, date)
+);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS closing_days;
+DROP TABLE IF EXISTS opening_hours;
+-- +goose StatementEnd
@@ -0,0 +1,29 @@
+-- +goose Up
+-- +goose StatementBegin
+
+CREATE TABLE IF NOT EXISTS capacity_changes (
+    id serial4 PRIMARY KEY,
+    location_id uuid NOT NULL REFERENCES locations ON DELETE CASCADE,
+    capacity int4 NOT NULL CHECK (capacity > 0),
+    effective_from timestamp with time zone NOT NULL,
+    -- a scheduled capacity change is applied once the waitlist
+    -- of its location was promoted after it came in force
+    applied boolean NOT NULL DEFAULT false,
+    UNIQUE (location_id, effective_from)
+);
+
+CREATE INDEX IF NOT EXISTS capacity_changes_not_applied_idx
+ON capacity_changes (effective_from) WHERE NOT applied;
+
+INSERT INTO capacity_changes (location_id, capacity, effective_from, applied)
+SELECT locations.id, locations.capacity, COALESCE(MIN(check_ins.created_at), now()), true
+FROM locations
+LEFT JOIN check_ins ON check_ins.location_id = locations.id
+GROUP BY locations.id, locations.capacity;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS capacity_changes;
+-- +goose StatementEnd
@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+
+CREATE TABLE IF NOT EXISTS waitlist_entries (
+    id serial4 PRIMARY KEY,
+    location_id uuid NOT NULL REFERENCES locations ON DELETE CASCADE,
+    school_id int4 NOT NULL DEFAULT 1 REFERENCES schools ON DELETE SET DEFAULT,
+    short_code varchar(16),
+    created_at timestamp with time zone NOT NULL DEFAULT now(),
+    promoted_at timestamp with time zone,
+    check_in_id int4 REFERENCES check_ins ON DELETE SET NULL
+);
+
+CREATE INDEX IF NOT EXISTS waitlist_entries_location_id_created_at_idx
+ON waitlist_entries (location_id, created_at)
+WHERE promoted_at IS NULL;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS waitlist_entries;
+-- +goose StatementEnd
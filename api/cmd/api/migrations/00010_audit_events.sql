@@ -0,0 +1,27 @@
+-- +goose Up
+-- +goose StatementBegin
+
+CREATE TABLE IF NOT EXISTS audit_events (
+    id bigserial PRIMARY KEY,
+    actor_id uuid,
+    actor_role varchar(20),
+    action varchar(50) NOT NULL,
+    entity_type varchar(50) NOT NULL,
+    entity_id varchar(255) NOT NULL,
+    before jsonb,
+    after jsonb,
+    created_at timestamp with time zone NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS audit_events_created_at_idx
+ON audit_events (created_at);
+
+CREATE INDEX IF NOT EXISTS audit_events_entity_idx
+ON audit_events (entity_type, entity_id);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS audit_events;
+-- +goose StatementEnd
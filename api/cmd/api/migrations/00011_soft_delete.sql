@@ -0,0 +1,42 @@
+-- +goose Up
+-- +goose StatementBegin
+
+ALTER TABLE locations ADD COLUMN archived_at timestamp with time zone;
+ALTER TABLE schools ADD COLUMN archived_at timestamp with time zone;
+ALTER TABLE users ADD COLUMN archived_at timestamp with time zone;
+
+-- archived rows keep their name, it can be reused by active rows
+ALTER TABLE locations DROP CONSTRAINT IF EXISTS locations_name_key;
+CREATE UNIQUE INDEX IF NOT EXISTS locations_name_idx
+ON locations (name)
+WHERE archived_at IS NULL;
+
+ALTER TABLE schools DROP CONSTRAINT IF EXISTS schools_name_key;
+CREATE UNIQUE INDEX IF NOT EXISTS schools_name_idx
+ON schools (name)
+WHERE archived_at IS NULL;
+
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_username_key;
+CREATE UNIQUE INDEX IF NOT EXISTS users_username_idx
+ON users (username)
+WHERE archived_at IS NULL;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DELETE FROM locations WHERE archived_at IS NOT NULL;
+DELETE FROM schools WHERE archived_at IS NOT NULL;
+DELETE FROM users WHERE archived_at IS NOT NULL;
+
+DROP INDEX IF EXISTS users_username_idx;
+ALTER TABLE users ADD CONSTRAINT users_username_key UNIQUE (username);
+DROP INDEX IF EXISTS schools_name_idx;
+ALTER TABLE schools ADD CONSTRAINT schools_name_key UNIQUE (name);
+DROP INDEX IF EXISTS locations_name_idx;
+ALTER TABLE locations ADD CONSTRAINT locations_name_key UNIQUE (name);
+
+ALTER TABLE users DROP COLUMN archived_at;
+ALTER TABLE schools DROP COLUMN archived_at;
+ALTER TABLE locations DROP COLUMN archived_at;
+-- +goose StatementEnd
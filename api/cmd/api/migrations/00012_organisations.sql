@@ -0,0 +1,84 @@
+-- +goose Up
+-- +goose StatementBegin
+
+CREATE TABLE IF NOT EXISTS organisations (
+    id uuid NOT NULL PRIMARY KEY DEFAULT uuid_generate_v4(),
+    name varchar(255) NOT NULL UNIQUE,
+    created_at timestamp with time zone NOT NULL DEFAULT now()
+);
+
+-- existing data is moved to a first organisation
+INSERT INTO organisations (name)
+VALUES ('Default');
+
+ALTER TABLE users ADD COLUMN organisation_id uuid REFERENCES organisations;
+UPDATE users SET organisation_id = (SELECT id FROM organisations);
+ALTER TABLE users ALTER COLUMN organisation_id SET NOT NULL;
+
+ALTER TABLE locations ADD COLUMN organisation_id uuid REFERENCES organisations;
+UPDATE locations SET organisation_id = (SELECT id FROM organisations);
+ALTER TABLE locations ALTER COLUMN organisation_id SET NOT NULL;
+
+-- the read-only school has no organisation, it's shared by all of them
+ALTER TABLE schools ADD COLUMN organisation_id uuid REFERENCES organisations;
+UPDATE schools SET organisation_id = (SELECT id FROM organisations)
+WHERE read_only = false;
+
+DROP INDEX IF EXISTS schools_name_idx;
+CREATE UNIQUE INDEX IF NOT EXISTS schools_name_idx
+ON schools (organisation_id, name)
+WHERE archived_at IS NULL;
+
+DROP INDEX IF EXISTS locations_name_idx;
+CREATE UNIQUE INDEX IF NOT EXISTS locations_name_idx
+ON locations (organisation_id, name)
+WHERE archived_at IS NULL;
+
+ALTER TABLE states ADD COLUMN organisation_id uuid REFERENCES organisations
+ON DELETE CASCADE;
+UPDATE states SET organisation_id = (SELECT id FROM organisations);
+ALTER TABLE states ALTER COLUMN organisation_id SET NOT NULL;
+ALTER TABLE states DROP CONSTRAINT IF EXISTS states_pkey;
+ALTER TABLE states ADD PRIMARY KEY (organisation_id, key);
+
+ALTER TABLE audit_events ADD COLUMN organisation_id uuid;
+UPDATE audit_events SET organisation_id = (SELECT id FROM organisations);
+
+CREATE INDEX IF NOT EXISTS users_organisation_id_idx
+ON users (organisation_id);
+CREATE INDEX IF NOT EXISTS locations_organisation_id_idx
+ON locations (organisation_id);
+CREATE INDEX IF NOT EXISTS schools_organisation_id_idx
+ON schools (organisation_id);
+CREATE INDEX IF NOT EXISTS audit_events_organisation_id_idx
+ON audit_events (organisation_id);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DELETE FROM states
+WHERE organisation_id <> (SELECT id FROM organisations WHERE name = 'Default');
+ALTER TABLE states DROP CONSTRAINT IF EXISTS states_pkey;
+ALTER TABLE states ADD PRIMARY KEY (key);
+ALTER TABLE states DROP COLUMN organisation_id;
+
+DROP INDEX IF EXISTS schools_name_idx;
+CREATE UNIQUE INDEX IF NOT EXISTS schools_name_idx
+ON schools (name)
+WHERE archived_at IS NULL;
+
+DROP INDEX IF EXISTS locations_name_idx;
+CREATE UNIQUE INDEX IF NOT EXISTS locations_name_idx
+ON locations (name)
+WHERE archived_at IS NULL;
+
+UPDATE users SET role = 'admin' WHERE role = 'superadmin';
+
+ALTER TABLE audit_events DROP COLUMN organisation_id;
+ALTER TABLE schools DROP COLUMN organisation_id;
+ALTER TABLE locations DROP COLUMN organisation_id;
+ALTER TABLE users DROP COLUMN organisation_id;
+
+DROP TABLE IF EXISTS organisations;
+-- +goose StatementEnd
@@ -0,0 +1,25 @@
+-- +goose Up
+-- +goose StatementBegin
+
+CREATE TABLE IF NOT EXISTS manager_locations (
+    user_id uuid NOT NULL REFERENCES users ON DELETE CASCADE,
+    location_id uuid NOT NULL REFERENCES locations ON DELETE CASCADE,
+    PRIMARY KEY (user_id, location_id)
+);
+
+CREATE INDEX IF NOT EXISTS manager_locations_location_id_idx
+ON manager_locations (location_id);
+
+-- existing managers keep managing every location of their organisation
+INSERT INTO manager_locations (user_id, location_id)
+SELECT users.id, locations.id
+FROM users
+JOIN locations ON locations.organisation_id = users.organisation_id
+WHERE users.role = 'manager';
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS manager_locations;
+-- +goose StatementEnd
@@ -0,0 +1,107 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- built-in roles have no organisation, custom roles belong to one
+CREATE TABLE IF NOT EXISTS roles (
+    id uuid NOT NULL PRIMARY KEY DEFAULT uuid_generate_v4(),
+    name varchar(255) NOT NULL,
+    organisation_id uuid REFERENCES organisations ON DELETE CASCADE,
+    permissions text [] NOT NULL DEFAULT '{}'
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS roles_organisation_id_name_idx
+ON roles (organisation_id, name);
+CREATE UNIQUE INDEX IF NOT EXISTS roles_built_in_name_idx
+ON roles (name)
+WHERE organisation_id IS NULL;
+
+INSERT INTO roles (name, permissions)
+VALUES
+(
+    'default',
+    ARRAY[
+        'checkins:write',
+        'stats:read',
+        'stats:export',
+        'locations:read',
+        'locations:update'
+    ]
+),
+(
+    'manager',
+    ARRAY[
+        'checkins:delete',
+        'stats:read',
+        'stats:export',
+        'locations:read',
+        'locations:list',
+        'locations:write',
+        'locations:update',
+        'locations:delete',
+        'schools:read',
+        'schools:write',
+        'users:read'
+    ]
+),
+(
+    'admin',
+    ARRAY[
+        'audit:read',
+        'checkins:delete',
+        'stats:read',
+        'stats:export',
+        'locations:read',
+        'locations:list',
+        'locations:write',
+        'locations:update',
+        'locations:delete',
+        'locations:restore',
+        'schools:read',
+        'schools:write',
+        'schools:restore',
+        'state:write',
+        'users:read',
+        'users:list',
+        'users:write',
+        'roles:read',
+        'roles:write'
+    ]
+),
+(
+    'superadmin',
+    ARRAY[
+        'audit:read',
+        'checkins:delete',
+        'stats:read',
+        'stats:export',
+        'locations:read',
+        'locations:list',
+        'locations:write',
+        'locations:update',
+        'locations:delete',
+        'locations:restore',
+        'schools:read',
+        'schools:write',
+        'schools:restore',
+        'state:write',
+        'users:read',
+        'users:list',
+        'users:write',
+        'roles:read',
+        'roles:write',
+        'organisations:read',
+        'organisations:write'
+    ]
+);
+
+-- a custom role replaces the permissions of the built-in role of a manager
+ALTER TABLE users ADD COLUMN custom_role_id uuid REFERENCES roles
+ON DELETE SET NULL;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE users DROP COLUMN custom_role_id;
+DROP TABLE IF EXISTS roles;
+-- +goose StatementEnd
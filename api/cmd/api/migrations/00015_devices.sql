@@ -0,0 +1,40 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- kiosks of a location sign in as a device,
+-- their tokens still belong to the user of the location
+CREATE TABLE IF NOT EXISTS devices (
+    id uuid NOT NULL PRIMARY KEY DEFAULT uuid_generate_v4(),
+    location_id uuid NOT NULL REFERENCES locations ON DELETE CASCADE,
+    name varchar(255) NOT NULL,
+    username varchar(255) NOT NULL UNIQUE,
+    password_hash bytea NOT NULL,
+    created_at timestamp with time zone NOT NULL DEFAULT now(),
+    revoked_at timestamp with time zone
+);
+
+CREATE INDEX IF NOT EXISTS devices_location_id_idx
+ON devices (location_id);
+
+ALTER TABLE tokens ADD COLUMN device_id uuid REFERENCES devices
+ON DELETE CASCADE;
+ALTER TABLE check_ins ADD COLUMN device_id uuid REFERENCES devices
+ON DELETE SET NULL;
+
+UPDATE roles
+SET permissions = permissions || ARRAY['devices:read', 'devices:write']
+WHERE organisation_id IS NULL AND name IN ('manager', 'admin', 'superadmin');
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+UPDATE roles
+SET permissions = array_remove(
+    array_remove(permissions, 'devices:read'), 'devices:write'
+);
+
+ALTER TABLE check_ins DROP COLUMN device_id;
+ALTER TABLE tokens DROP COLUMN device_id;
+DROP TABLE IF EXISTS devices;
+-- +goose StatementEnd
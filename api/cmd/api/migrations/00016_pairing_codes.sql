@@ -0,0 +1,25 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- paired devices have no credentials, they only use their tokens
+ALTER TABLE devices ALTER COLUMN username DROP NOT NULL;
+ALTER TABLE devices ALTER COLUMN password_hash DROP NOT NULL;
+
+-- a pairing code is deleted when it is exchanged, so it can only be used once
+CREATE TABLE IF NOT EXISTS pairing_codes (
+    hash bytea PRIMARY KEY,
+    location_id uuid NOT NULL REFERENCES locations ON DELETE CASCADE,
+    device_name varchar(255) NOT NULL,
+    expiry timestamptz (0) NOT NULL
+);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS pairing_codes;
+
+DELETE FROM devices WHERE username IS NULL;
+ALTER TABLE devices ALTER COLUMN password_hash SET NOT NULL;
+ALTER TABLE devices ALTER COLUMN username SET NOT NULL;
+-- +goose StatementEnd
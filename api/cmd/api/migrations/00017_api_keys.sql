@@ -0,0 +1,32 @@
+-- +goose Up
+-- +goose StatementBegin
+
+CREATE TABLE IF NOT EXISTS api_keys (
+    id uuid NOT NULL PRIMARY KEY DEFAULT uuid_generate_v4(),
+    hash bytea NOT NULL UNIQUE,
+    organisation_id uuid NOT NULL REFERENCES organisations ON DELETE CASCADE,
+    name varchar(255) NOT NULL,
+    scopes text [] NOT NULL DEFAULT '{}',
+    expiry timestamptz (0),
+    last_used_at timestamp with time zone,
+    created_at timestamp with time zone NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS api_keys_organisation_id_idx
+ON api_keys (organisation_id);
+
+UPDATE roles
+SET permissions = permissions || ARRAY['apikeys:read', 'apikeys:write']
+WHERE organisation_id IS NULL AND name IN ('admin', 'superadmin');
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+UPDATE roles
+SET permissions = array_remove(
+    array_remove(permissions, 'apikeys:read'), 'apikeys:write'
+);
+
+DROP TABLE IF EXISTS api_keys;
+-- +goose StatementEnd
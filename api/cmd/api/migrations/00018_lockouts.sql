@@ -0,0 +1,30 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- failed sign in attempts per username and per IP,
+-- organisation_id is only known for usernames of existing users
+CREATE TABLE IF NOT EXISTS lockouts (
+    kind varchar(20) NOT NULL,
+    value varchar(255) NOT NULL,
+    organisation_id uuid REFERENCES organisations ON DELETE CASCADE,
+    failed_attempts int4 NOT NULL DEFAULT 0,
+    last_failed_at timestamp with time zone NOT NULL,
+    locked_until timestamp with time zone,
+    PRIMARY KEY (kind, value)
+);
+
+UPDATE roles
+SET permissions = permissions || ARRAY['lockouts:read', 'lockouts:write']
+WHERE organisation_id IS NULL AND name IN ('admin', 'superadmin');
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+UPDATE roles
+SET permissions = array_remove(
+    array_remove(permissions, 'lockouts:read'), 'lockouts:write'
+);
+
+DROP TABLE IF EXISTS lockouts;
+-- +goose StatementEnd
@@ -0,0 +1,29 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- the TOTP secret is pending until a code generated with it is verified,
+-- the last used time step prevents a code from being used twice
+ALTER TABLE users ADD COLUMN totp_secret bytea;
+ALTER TABLE users ADD COLUMN totp_enabled_at timestamp with time zone;
+ALTER TABLE users ADD COLUMN totp_last_step int8;
+ALTER TABLE users ADD COLUMN two_factor_required boolean NOT NULL DEFAULT false;
+
+CREATE TABLE IF NOT EXISTS recovery_codes (
+    hash bytea NOT NULL PRIMARY KEY,
+    user_id uuid NOT NULL REFERENCES users ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS recovery_codes_user_id_idx
+ON recovery_codes (user_id);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS recovery_codes;
+
+ALTER TABLE users DROP COLUMN two_factor_required;
+ALTER TABLE users DROP COLUMN totp_last_step;
+ALTER TABLE users DROP COLUMN totp_enabled_at;
+ALTER TABLE users DROP COLUMN totp_secret;
+-- +goose StatementEnd
@@ -0,0 +1,26 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- users signing in with single sign-on have no password
+ALTER TABLE users ALTER COLUMN password_hash DROP NOT NULL;
+ALTER TABLE users ADD COLUMN oidc_subject varchar(255) UNIQUE;
+
+-- sign ins which were redirected to the issuer, only the hash of the
+-- state is stored, the code verifier and nonce are needed on the callback
+CREATE TABLE IF NOT EXISTS oidc_logins (
+    state_hash bytea NOT NULL PRIMARY KEY,
+    code_verifier varchar(255) NOT NULL,
+    nonce varchar(255) NOT NULL,
+    expiry timestamptz (0) NOT NULL
+);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS oidc_logins;
+
+ALTER TABLE users DROP COLUMN oidc_subject;
+DELETE FROM users WHERE password_hash IS NULL;
+ALTER TABLE users ALTER COLUMN password_hash SET NOT NULL;
+-- +goose StatementEnd
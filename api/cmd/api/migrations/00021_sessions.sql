@@ -0,0 +1,29 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- a session is started on sign in, refreshed tokens stay in the session
+-- so a session is a refresh token family
+CREATE TABLE IF NOT EXISTS sessions (
+    id uuid NOT NULL PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id uuid NOT NULL REFERENCES users ON DELETE CASCADE,
+    device_id uuid REFERENCES devices ON DELETE CASCADE,
+    user_agent varchar(512) NOT NULL,
+    ip varchar(45) NOT NULL,
+    created_at timestamp with time zone NOT NULL DEFAULT now(),
+    last_used_at timestamp with time zone NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS sessions_user_id_idx
+ON sessions (user_id);
+
+-- tokens issued before sessions existed have no session
+ALTER TABLE tokens ADD COLUMN session_id uuid REFERENCES sessions
+ON DELETE CASCADE;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE tokens DROP COLUMN session_id;
+DROP TABLE IF EXISTS sessions;
+-- +goose StatementEnd
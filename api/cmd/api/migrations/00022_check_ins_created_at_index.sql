@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- statistics count the check-ins of locations in a range of time
+CREATE INDEX IF NOT EXISTS check_ins_location_id_created_at_idx
+ON check_ins (location_id, created_at);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS check_ins_location_id_created_at_idx;
+-- +goose StatementEnd
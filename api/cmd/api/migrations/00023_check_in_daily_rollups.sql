@@ -0,0 +1,56 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- the amount of check-ins per location, school and business date,
+-- statistics read these for past days instead of the check-ins
+CREATE TABLE IF NOT EXISTS check_in_daily_rollups (
+    location_id uuid NOT NULL REFERENCES locations ON DELETE CASCADE,
+    school_id int4 NOT NULL REFERENCES schools ON DELETE CASCADE,
+    business_date date NOT NULL,
+    count int4 NOT NULL,
+    capacity int4 NOT NULL,
+    PRIMARY KEY (location_id, school_id, business_date)
+);
+
+CREATE INDEX IF NOT EXISTS check_in_daily_rollups_location_id_business_date_idx
+ON check_in_daily_rollups (location_id, business_date);
+
+INSERT INTO check_in_daily_rollups
+(location_id, school_id, business_date, count, capacity)
+SELECT check_ins.location_id, check_ins.school_id,
+ ((check_ins.created_at AT TIME ZONE locations.time_zone) -
+ locations.business_day_start::interval)::date AS business_date,
+ COUNT(*), MAX(check_ins.capacity)
+FROM check_ins
+INNER JOIN locations
+ON locations.id = check_ins.location_id
+GROUP BY check_ins.location_id, check_ins.school_id, business_date;
+
+-- the amount of check-ins per location, local date and hour,
+-- the heatmap reads these for past days instead of the check-ins
+CREATE TABLE IF NOT EXISTS check_in_hourly_rollups (
+    location_id uuid NOT NULL REFERENCES locations ON DELETE CASCADE,
+    date date NOT NULL,
+    hour int2 NOT NULL CHECK (hour BETWEEN 0 AND 23),
+    count int4 NOT NULL,
+    PRIMARY KEY (location_id, date, hour)
+);
+
+INSERT INTO check_in_hourly_rollups (location_id, date, hour, count)
+SELECT check_ins.location_id,
+ (check_ins.created_at AT TIME ZONE locations.time_zone)::date AS date,
+ EXTRACT(HOUR FROM check_ins.created_at AT TIME ZONE
+ locations.time_zone)::int2 AS hour,
+ COUNT(*)
+FROM check_ins
+INNER JOIN locations
+ON locations.id = check_ins.location_id
+GROUP BY check_ins.location_id, date, hour;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS check_in_hourly_rollups;
+DROP TABLE IF EXISTS check_in_daily_rollups;
+-- +goose StatementEnd
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"crypto/subtle"
+	"errors"
+	"net/http"
+
+	httptools "github.com/XDoubleU/essentia/pkg/communication/http"
+	"github.com/XDoubleU/essentia/pkg/config"
+	errortools "github.com/XDoubleU/essentia/pkg/errors"
+)
+
+const (
+	oidcStateCookie = "oidcState"
+	oidcCookiePath  = "/auth/oidc"
+	// oidcStateMaxAge matches the expiry of a sign in at the issuer.
+	oidcStateMaxAge = 10 * 60
+)
+
+var errOIDCDisabled = errors.New("single sign-on isn't enabled")
+
+func (app *Application) oidcRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /auth/oidc/login", app.oidcLoginHandler)
+	mux.HandleFunc("GET /auth/oidc/callback", app.oidcCallbackHandler)
+}
+
+// @Summary	Redirect an admin or manager to the single sign-on issuer
+// @Tags		auth
+// @Success	302	{object}	nil
+// @Failure	404	{object}	ErrorDto
+// @Failure	500	{object}	ErrorDto
+// @Router		/auth/oidc/login [get].
+func (app *Application) oidcLoginHandler(w http.ResponseWriter, r *http.Request) {
+	if !app.services.OIDC.Enabled() {
+		httptools.ErrorResponse(w, r, http.StatusNotFound, errOIDCDisabled.Error())
+		return
+	}
+
+	login, authURL, err := app.services.OIDC.Login(r.Context())
+	if err != nil {
+		httptools.ServerErrorResponse(w, r, err)
+		return
+	}
+
+	http.SetCookie(w, app.oidcStateCookie(login.State, oidcStateMaxAge))
+	http.Redirect(w, r, authURL, http.StatusFound)
+}
+
+// @Summary	Sign in an admin or manager returning from the single sign-on issuer
+// @Tags		auth
+// @Param		state	query		string	true	"State"
+// @Param		code	query		string	true	"Authorization code"
+// @Success	302		{object}	nil
+// @Failure	401		{object}	ErrorDto
+// @Failure	404		{object}	ErrorDto
+// @Failure	409		{object}	ErrorDto
+// @Failure	500		{object}	ErrorDto
+// @Router		/auth/oidc/callback [get].
+func (app *Application) oidcCallbackHandler(
+	w http.ResponseWriter,
+	r *http.Request,
+) {
+	if !app.services.OIDC.Enabled() {
+		httptools.ErrorResponse(w, r, http.StatusNotFound, errOIDCDisabled.Error())
+		return
+	}
+
+	http.SetCookie(w, app.oidcStateCookie("", -1))
+
+	query := r.URL.Query()
+	if issuerError := query.Get("error"); issuerError != "" {
+		httptools.UnauthorizedResponse(
+			w,
+			r,
+			errortools.NewUnauthorizedError(errors.New(issuerError)),
+		)
+		return
+	}
+
+	state := query.Get("state")
+	stateCookie, err := r.Cookie(oidcStateCookie)
+	if err != nil || state == "" ||
+		subtle.ConstantTimeCompare([]byte(stateCookie.Value), []byte(state)) != 1 {
+		httptools.UnauthorizedResponse(
+			w,
+			r,
+			errortools.NewUnauthorizedError(
+				errors.New("invalid single sign-on state"),
+			),
+		)
+		return
+	}
+
+	user, err := app.services.OIDC.Callback(r.Context(), state, query.Get("code"))
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	err = app.setAuthCookies(w, r, user, true)
+	if err != nil {
+		httptools.ServerErrorResponse(w, r, err)
+		return
+	}
+
+	http.Redirect(w, r, app.config.WebURL, http.StatusFound)
+}
+
+// oidcStateCookie binds a sign in to the browser which started it.
+func (app *Application) oidcStateCookie(value string, maxAge int) *http.Cookie {
+	return &http.Cookie{
+		Name:     oidcStateCookie,
+		Value:    value,
+		Path:     oidcCookiePath,
+		MaxAge:   maxAge,
+		Secure:   app.config.Env == config.ProdEnv,
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	}
+}
@@ -0,0 +1,465 @@
+package main
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	httptools "github.com/XDoubleU/essentia/pkg/communication/http"
+	errortools "github.com/XDoubleU/essentia/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"check-in/api/internal/config"
+	"check-in/api/internal/models"
+)
+
+const (
+	stubClientID     = "check-in"
+	stubClientSecret = "secret"
+	stubKeyID        = "stub"
+)
+
+type stubIdentity struct {
+	Subject  string
+	Username string
+	Groups   []string
+}
+
+type stubAuthorization struct {
+	identity  stubIdentity
+	challenge string
+	nonce     string
+}
+
+// stubIssuer is a minimal OpenID Connect issuer which hands out
+// a code for the identity of the next authorization. The ID tokens
+// are signed with keyID and contain claims on top of the usual ones.
+type stubIssuer struct {
+	server *httptest.Server
+	key    *rsa.PrivateKey
+	keyID  string
+	claims map[string]any
+
+	mu             *sync.Mutex
+	authorizations map[string]stubAuthorization
+	jwksRequests   int
+}
+
+func newStubIssuer(t *testing.T) *stubIssuer {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.Nil(t, err)
+
+	issuer := &stubIssuer{
+		server:         nil,
+		key:            key,
+		keyID:          stubKeyID,
+		claims:         map[string]any{},
+		mu:             &sync.Mutex{},
+		authorizations: make(map[string]stubAuthorization),
+		jwksRequests:   0,
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /.well-known/openid-configuration", issuer.discovery)
+	mux.HandleFunc("GET /jwks", issuer.jwks)
+	mux.HandleFunc("POST /token", issuer.token)
+
+	issuer.server = httptest.NewServer(mux)
+	t.Cleanup(issuer.server.Close)
+
+	return issuer
+}
+
+// authorize does what the issuer does when a user signs in:
+// it returns a code for the authorization request in authURL.
+func (issuer *stubIssuer) authorize(
+	t *testing.T,
+	authURL string,
+	identity stubIdentity,
+) string {
+	parsedURL, err := url.Parse(authURL)
+	require.Nil(t, err)
+
+	query := parsedURL.Query()
+	assert.Equal(t, stubClientID, query.Get("client_id"))
+	assert.Equal(t, "S256", query.Get("code_challenge_method"))
+
+	code := "code-" + identity.Subject
+
+	issuer.mu.Lock()
+	defer issuer.mu.Unlock()
+
+	issuer.authorizations[code] = stubAuthorization{
+		identity:  identity,
+		challenge: query.Get("code_challenge"),
+		nonce:     query.Get("nonce"),
+	}
+
+	return code
+}
+
+func (issuer *stubIssuer) discovery(w http.ResponseWriter, _ *http.Request) {
+	_ = json.NewEncoder(w).Encode(map[string]string{
+		"issuer":                 issuer.server.URL,
+		"authorization_endpoint": issuer.server.URL + "/authorize",
+		"token_endpoint":         issuer.server.URL + "/token",
+		"jwks_uri":               issuer.server.URL + "/jwks",
+	})
+}
+
+func (issuer *stubIssuer) jwks(w http.ResponseWriter, _ *http.Request) {
+	issuer.mu.Lock()
+	issuer.jwksRequests++
+	issuer.mu.Unlock()
+
+	publicKey := issuer.key.PublicKey
+
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": stubKeyID,
+			"n":   base64.RawURLEncoding.EncodeToString(publicKey.N.Bytes()),
+			"e": base64.RawURLEncoding.EncodeToString(
+				big.NewInt(int64(publicKey.E)).Bytes(),
+			),
+		}},
+	})
+}
+
+func (issuer *stubIssuer) token(w http.ResponseWriter, r *http.Request) {
+	clientID, clientSecret, ok := r.BasicAuth()
+	if !ok || clientID != stubClientID || clientSecret != stubClientSecret {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	issuer.mu.Lock()
+	authorization, ok := issuer.authorizations[r.PostFormValue("code")]
+	delete(issuer.authorizations, r.PostFormValue("code"))
+	issuer.mu.Unlock()
+
+	challenge := sha256.Sum256([]byte(r.PostFormValue("code_verifier")))
+	if !ok ||
+		base64.RawURLEncoding.EncodeToString(challenge[:]) != authorization.challenge {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	header, _ := json.Marshal(map[string]string{"alg": "RS256", "kid": issuer.keyID})
+
+	claimsMap := map[string]any{
+		"iss":                issuer.server.URL,
+		"aud":                stubClientID,
+		"sub":                authorization.identity.Subject,
+		"exp":                time.Now().Add(time.Minute).Unix(),
+		"iat":                time.Now().Unix(),
+		"nonce":              authorization.nonce,
+		"preferred_username": authorization.identity.Username,
+		"groups":             authorization.identity.Groups,
+	}
+	for name, value := range issuer.claims {
+		claimsMap[name] = value
+	}
+
+	claims, _ := json.Marshal(claimsMap)
+
+	signingInput := base64.RawURLEncoding.EncodeToString(header) + "." +
+		base64.RawURLEncoding.EncodeToString(claims)
+	digest := sha256.Sum256([]byte(signingInput))
+
+	signature, err := rsa.SignPKCS1v15(rand.Reader, issuer.key, crypto.SHA256, digest[:])
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	_ = json.NewEncoder(w).Encode(map[string]string{
+		"id_token": signingInput + "." +
+			base64.RawURLEncoding.EncodeToString(signature),
+	})
+}
+
+func setupOIDC(t *testing.T) (TestEnv, Application, *stubIssuer) {
+	testEnv, testApp := setup(t)
+	issuer := newStubIssuer(t)
+
+	testApp.config.OIDC = config.OIDCConfig{
+		Issuer:        issuer.server.URL,
+		ClientID:      stubClientID,
+		ClientSecret:  stubClientSecret,
+		RedirectURL:   "http://localhost:8000/auth/oidc/callback",
+		Scopes:        []string{"openid", "profile", "groups"},
+		GroupsClaim:   "groups",
+		AdminGroups:   []string{"it"},
+		ManagerGroups: []string{"reception"},
+		Organisation:  defaultOrganisationName,
+	}
+	testApp.setDB(postgresDB)
+
+	return testEnv, testApp, issuer
+}
+
+// oidcLogin starts a sign in and returns the state cookie
+// and the code the issuer returned for identity.
+func oidcLogin(
+	t *testing.T,
+	testApp Application,
+	issuer *stubIssuer,
+	identity stubIdentity,
+) (*http.Cookie, string) {
+	rs := doRequest(testApp.routes(), http.MethodGet, "/auth/oidc/login")
+	require.Equal(t, http.StatusFound, rs.Code)
+
+	stateCookie := findCookie(rs.Result().Cookies(), oidcStateCookie)
+	require.NotNil(t, stateCookie)
+
+	code := issuer.authorize(t, rs.Header().Get("Location"), identity)
+
+	return stateCookie, code
+}
+
+func oidcCallback(
+	testApp Application,
+	stateCookie *http.Cookie,
+	state string,
+	code string,
+) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(
+		http.MethodGet,
+		"/auth/oidc/callback?state="+url.QueryEscape(state)+
+			"&code="+url.QueryEscape(code),
+		nil,
+	)
+	req.AddCookie(stateCookie)
+
+	rr := httptest.NewRecorder()
+	testApp.routes().ServeHTTP(rr, req)
+
+	return rr
+}
+
+func doRequest(
+	handler http.Handler,
+	method string,
+	path string,
+) *httptest.ResponseRecorder {
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(method, path, nil))
+
+	return rr
+}
+
+func findCookie(cookies []*http.Cookie, name string) *http.Cookie {
+	for _, cookie := range cookies {
+		if cookie.Name == name && cookie.Value != "" {
+			return cookie
+		}
+	}
+
+	return nil
+}
+
+func TestOIDCSignIn(t *testing.T) {
+	testEnv, testApp, issuer := setupOIDC(t)
+	defer testEnv.teardown()
+
+	identity := stubIdentity{
+		Subject:  "1234",
+		Username: "jane",
+		Groups:   []string{"staff", "it"},
+	}
+
+	stateCookie, code := oidcLogin(t, testApp, issuer, identity)
+
+	rs := oidcCallback(testApp, stateCookie, stateCookie.Value, code)
+
+	assert.Equal(t, http.StatusFound, rs.Code)
+	assert.Equal(t, cfg.WebURL, rs.Header().Get("Location"))
+
+	accessToken := findCookie(rs.Result().Cookies(), "accessToken")
+	require.NotNil(t, accessToken)
+	assert.Nil(t, findCookie(rs.Result().Cookies(), "refreshToken"))
+
+	user, err := testApp.services.Users.GetByUsername(testEnv.ctx, "jane")
+	require.Nil(t, err)
+	assert.Equal(t, models.AdminRole, user.Role)
+	assert.Equal(t, testEnv.fixtures.Organisation.ID, user.OrganisationID)
+
+	req := httptest.NewRequest(http.MethodGet, "/current-user", nil)
+	req.AddCookie(accessToken)
+	rr := httptest.NewRecorder()
+	testApp.routes().ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+}
+
+func TestOIDCSignInSyncsRole(t *testing.T) {
+	testEnv, testApp, issuer := setupOIDC(t)
+	defer testEnv.teardown()
+
+	identity := stubIdentity{
+		Subject:  "1234",
+		Username: "jane",
+		Groups:   []string{"it"},
+	}
+
+	stateCookie, code := oidcLogin(t, testApp, issuer, identity)
+	rs1 := oidcCallback(testApp, stateCookie, stateCookie.Value, code)
+	require.Equal(t, http.StatusFound, rs1.Code)
+
+	identity.Groups = []string{"reception"}
+
+	stateCookie, code = oidcLogin(t, testApp, issuer, identity)
+	rs2 := oidcCallback(testApp, stateCookie, stateCookie.Value, code)
+	require.Equal(t, http.StatusFound, rs2.Code)
+
+	assert.NotNil(t, findCookie(rs2.Result().Cookies(), "refreshToken"))
+
+	user, err := testApp.services.Users.GetByUsername(testEnv.ctx, "jane")
+	require.Nil(t, err)
+	assert.Equal(t, models.ManagerRole, user.Role)
+}
+
+func TestOIDCSignInNoMatchingGroup(t *testing.T) {
+	testEnv, testApp, issuer := setupOIDC(t)
+	defer testEnv.teardown()
+
+	identity := stubIdentity{
+		Subject:  "1234",
+		Username: "jane",
+		Groups:   []string{"staff"},
+	}
+
+	stateCookie, code := oidcLogin(t, testApp, issuer, identity)
+	rs := oidcCallback(testApp, stateCookie, stateCookie.Value, code)
+
+	assert.Equal(t, http.StatusUnauthorized, rs.Code)
+
+	user, _ := testApp.services.Users.GetByUsername(testEnv.ctx, "jane")
+	assert.Nil(t, user)
+}
+
+func TestOIDCSignInUsernameTaken(t *testing.T) {
+	testEnv, testApp, issuer := setupOIDC(t)
+	defer testEnv.teardown()
+
+	identity := stubIdentity{
+		Subject:  "1234",
+		Username: testEnv.fixtures.ManagerUser.Username,
+		Groups:   []string{"reception"},
+	}
+
+	stateCookie, code := oidcLogin(t, testApp, issuer, identity)
+	rs := oidcCallback(testApp, stateCookie, stateCookie.Value, code)
+
+	assert.Equal(t, http.StatusConflict, rs.Code)
+}
+
+func TestOIDCSignInTokenNotValidYet(t *testing.T) {
+	testEnv, testApp, issuer := setupOIDC(t)
+	defer testEnv.teardown()
+
+	issuer.claims["nbf"] = time.Now().Add(time.Hour).Unix()
+
+	identity := stubIdentity{
+		Subject:  "1234",
+		Username: "jane",
+		Groups:   []string{"it"},
+	}
+
+	stateCookie, code := oidcLogin(t, testApp, issuer, identity)
+	rs := oidcCallback(testApp, stateCookie, stateCookie.Value, code)
+
+	var rsData errortools.ErrorDto
+	err := httptools.ReadJSON(rs.Body, &rsData)
+	require.Nil(t, err)
+
+	// the reason is only logged
+	assert.Equal(t, http.StatusUnauthorized, rs.Code)
+	assert.Equal(t, "invalid or expired single sign-on", rsData.Message)
+}
+
+func TestOIDCSignInTokenIssuedInFuture(t *testing.T) {
+	testEnv, testApp, issuer := setupOIDC(t)
+	defer testEnv.teardown()
+
+	issuer.claims["iat"] = time.Now().Add(time.Hour).Unix()
+
+	identity := stubIdentity{
+		Subject:  "1234",
+		Username: "jane",
+		Groups:   []string{"it"},
+	}
+
+	stateCookie, code := oidcLogin(t, testApp, issuer, identity)
+	rs := oidcCallback(testApp, stateCookie, stateCookie.Value, code)
+
+	assert.Equal(t, http.StatusUnauthorized, rs.Code)
+}
+
+func TestOIDCSignInUnknownKeyRefetchLimited(t *testing.T) {
+	testEnv, testApp, issuer := setupOIDC(t)
+	defer testEnv.teardown()
+
+	issuer.keyID = "unknown"
+
+	for _, subject := range []string{"1234", "5678"} {
+		identity := stubIdentity{
+			Subject:  subject,
+			Username: "jane",
+			Groups:   []string{"it"},
+		}
+
+		stateCookie, code := oidcLogin(t, testApp, issuer, identity)
+		rs := oidcCallback(testApp, stateCookie, stateCookie.Value, code)
+
+		assert.Equal(t, http.StatusUnauthorized, rs.Code)
+	}
+
+	assert.Equal(t, 1, issuer.jwksRequests)
+}
+
+func TestOIDCCallbackInvalidState(t *testing.T) {
+	testEnv, testApp, issuer := setupOIDC(t)
+	defer testEnv.teardown()
+
+	identity := stubIdentity{
+		Subject:  "1234",
+		Username: "jane",
+		Groups:   []string{"it"},
+	}
+
+	stateCookie, code := oidcLogin(t, testApp, issuer, identity)
+
+	rs1 := oidcCallback(testApp, stateCookie, "other", code)
+	assert.Equal(t, http.StatusUnauthorized, rs1.Code)
+
+	rs2 := oidcCallback(testApp, stateCookie, stateCookie.Value, code)
+	assert.Equal(t, http.StatusFound, rs2.Code)
+
+	// the state can only be used once
+	rs3 := oidcCallback(testApp, stateCookie, stateCookie.Value, code)
+	assert.Equal(t, http.StatusUnauthorized, rs3.Code)
+}
+
+func TestOIDCDisabled(t *testing.T) {
+	testEnv, testApp := setup(t)
+	defer testEnv.teardown()
+
+	rs1 := doRequest(testApp.routes(), http.MethodGet, "/auth/oidc/login")
+	assert.Equal(t, http.StatusNotFound, rs1.Code)
+
+	rs2 := doRequest(testApp.routes(), http.MethodGet, "/auth/oidc/callback")
+	assert.Equal(t, http.StatusNotFound, rs2.Code)
+}
@@ -0,0 +1,201 @@
+package main
+
+import (
+	"net/http"
+
+	httptools "github.com/XDoubleU/essentia/pkg/communication/http"
+	"github.com/XDoubleU/essentia/pkg/context"
+	"github.com/XDoubleU/essentia/pkg/parse"
+
+	"check-in/api/internal/constants"
+	"check-in/api/internal/dtos"
+	"check-in/api/internal/models"
+)
+
+func (app *Application) organisationsRoutes(mux *http.ServeMux) {
+	mux.HandleFunc(
+		"GET /organisations",
+		app.authAccess(models.OrganisationsReadPermission, app.getPaginatedOrganisationsHandler),
+	)
+	mux.HandleFunc(
+		"POST /organisations",
+		app.authAccess(models.OrganisationsWritePermission, app.createOrganisationHandler),
+	)
+	mux.HandleFunc(
+		"PATCH /organisations/{id}",
+		app.authAccess(models.OrganisationsWritePermission, app.updateOrganisationHandler),
+	)
+	mux.HandleFunc(
+		"POST /organisations/{id}/admins",
+		app.authAccess(models.OrganisationsWritePermission, app.createOrganisationAdminHandler),
+	)
+}
+
+// @Summary	Get all organisations paginated
+// @Tags		organisations
+// @Param		page	query		int	false	"Page to fetch"
+// @Success	200		{object}	PaginatedOrganisationsDto
+// @Failure	400		{object}	ErrorDto
+// @Failure	401		{object}	ErrorDto
+// @Failure	500		{object}	ErrorDto
+// @Router		/organisations [get].
+func (app *Application) getPaginatedOrganisationsHandler(w http.ResponseWriter,
+	r *http.Request) {
+	var pageSize int64 = 4
+
+	page, err := parse.QueryParam(r, "page", 1, parse.Int64(true, false))
+	if err != nil {
+		httptools.BadRequestResponse(w, r, err)
+		return
+	}
+
+	user := context.GetValue[models.User](r.Context(), constants.UserContextKey)
+	result, err := getAllPaginated(
+		r.Context(),
+		app.services.Organisations,
+		user,
+		page,
+		pageSize,
+	)
+	if err != nil {
+		httptools.ServerErrorResponse(w, r, err)
+		return
+	}
+
+	err = httptools.WriteJSON(w, http.StatusOK, result, nil)
+	if err != nil {
+		httptools.ServerErrorResponse(w, r, err)
+	}
+}
+
+// @Summary	Create organisation
+// @Tags		organisations
+// @Param		organisationDto	body		OrganisationDto	true	"OrganisationDto"
+// @Success	201				{object}	Organisation
+// @Failure	400				{object}	ErrorDto
+// @Failure	401				{object}	ErrorDto
+// @Failure	409				{object}	ErrorDto
+// @Failure	500				{object}	ErrorDto
+// @Router		/organisations [post].
+func (app *Application) createOrganisationHandler(w http.ResponseWriter,
+	r *http.Request) {
+	var organisationDto dtos.OrganisationDto
+
+	err := httptools.ReadJSON(r.Body, &organisationDto)
+	if err != nil {
+		httptools.BadRequestResponse(w, r, err)
+		return
+	}
+
+	if v, validationErrors := organisationDto.Validate(); !v {
+		httptools.FailedValidationResponse(w, r, validationErrors)
+		return
+	}
+
+	organisation, err := app.services.Organisations.Create(r.Context(), organisationDto)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	err = httptools.WriteJSON(w, http.StatusCreated, organisation, nil)
+	if err != nil {
+		httptools.ServerErrorResponse(w, r, err)
+	}
+}
+
+// @Summary	Update organisation
+// @Tags		organisations
+// @Param		id				path		string			true	"Organisation ID"
+// @Param		organisationDto	body		OrganisationDto	true	"OrganisationDto"
+// @Success	200				{object}	Organisation
+// @Failure	400				{object}	ErrorDto
+// @Failure	401				{object}	ErrorDto
+// @Failure	404				{object}	ErrorDto
+// @Failure	409				{object}	ErrorDto
+// @Failure	500				{object}	ErrorDto
+// @Router		/organisations/{id} [patch].
+func (app *Application) updateOrganisationHandler(w http.ResponseWriter,
+	r *http.Request) {
+	var organisationDto dtos.OrganisationDto
+
+	id, err := parse.URLParam(r, "id", parse.UUID)
+	if err != nil {
+		httptools.BadRequestResponse(w, r, err)
+		return
+	}
+
+	err = httptools.ReadJSON(r.Body, &organisationDto)
+	if err != nil {
+		httptools.BadRequestResponse(w, r, err)
+		return
+	}
+
+	if v, validationErrors := organisationDto.Validate(); !v {
+		httptools.FailedValidationResponse(w, r, validationErrors)
+		return
+	}
+
+	organisation, err := app.services.Organisations.Update(
+		r.Context(),
+		id,
+		organisationDto,
+	)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	err = httptools.WriteJSON(w, http.StatusOK, organisation, nil)
+	if err != nil {
+		httptools.ServerErrorResponse(w, r, err)
+	}
+}
+
+// @Summary	Create admin user of organisation
+// @Tags		organisations
+// @Param		id				path		string			true	"Organisation ID"
+// @Param		createUserDto	body		CreateUserDto	true	"CreateUserDto"
+// @Success	201				{object}	User
+// @Failure	400				{object}	ErrorDto
+// @Failure	401				{object}	ErrorDto
+// @Failure	404				{object}	ErrorDto
+// @Failure	409				{object}	ErrorDto
+// @Failure	500				{object}	ErrorDto
+// @Router		/organisations/{id}/admins [post].
+func (app *Application) createOrganisationAdminHandler(w http.ResponseWriter,
+	r *http.Request) {
+	var createUserDto dtos.CreateUserDto
+
+	id, err := parse.URLParam(r, "id", parse.UUID)
+	if err != nil {
+		httptools.BadRequestResponse(w, r, err)
+		return
+	}
+
+	err = httptools.ReadJSON(r.Body, &createUserDto)
+	if err != nil {
+		httptools.BadRequestResponse(w, r, err)
+		return
+	}
+
+	if v, validationErrors := createUserDto.Validate(); !v {
+		httptools.FailedValidationResponse(w, r, validationErrors)
+		return
+	}
+
+	user, err := app.services.Organisations.CreateAdmin(
+		r.Context(),
+		id,
+		createUserDto,
+	)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	err = httptools.WriteJSON(w, http.StatusCreated, user, nil)
+	if err != nil {
+		httptools.ServerErrorResponse(w, r, err)
+	}
+}
@@ -0,0 +1,329 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	httptools "github.com/XDoubleU/essentia/pkg/communication/http"
+	errortools "github.com/XDoubleU/essentia/pkg/errors"
+	"github.com/XDoubleU/essentia/pkg/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"check-in/api/internal/dtos"
+	"check-in/api/internal/models"
+)
+
+func (env *TestEnv) createOrganisation(name string) (*models.Organisation,
+	*http.Cookie) {
+	organisation, err := env.app.services.Organisations.Create(
+		env.ctx,
+		dtos.OrganisationDto{
+			Name: name,
+		},
+	)
+	if err != nil {
+		panic(err)
+	}
+
+	admin, err := env.app.services.Organisations.CreateAdmin(
+		env.ctx,
+		organisation.ID,
+		dtos.CreateUserDto{
+			Username: fmt.Sprintf("%sAdmin", name),
+			Password: "testpassword",
+		},
+	)
+	if err != nil {
+		panic(err)
+	}
+
+	return organisation, env.createAccessToken(*admin)
+}
+
+func TestGetPaginatedOrganisations(t *testing.T) {
+	testEnv, testApp := setup(t)
+	defer testEnv.teardown()
+
+	tReq := test.CreateRequestTester(
+		testApp.routes(),
+		http.MethodGet,
+		"/organisations",
+	)
+	tReq.AddCookie(testEnv.fixtures.Tokens.SuperAdminAccessToken)
+
+	rs := tReq.Do(t)
+
+	var rsData dtos.PaginatedOrganisationsDto
+	err := httptools.ReadJSON(rs.Body, &rsData)
+	require.Nil(t, err)
+
+	assert.Equal(t, http.StatusOK, rs.StatusCode)
+	assert.EqualValues(t, 1, rsData.Pagination.Current)
+	assert.EqualValues(t, 1, rsData.Pagination.Total)
+	assert.Equal(t, 1, len(rsData.Data))
+	assert.Equal(t, testEnv.fixtures.Organisation.ID, rsData.Data[0].ID)
+}
+
+func TestGetPaginatedOrganisationsAccess(t *testing.T) {
+	testEnv, testApp := setup(t)
+	defer testEnv.teardown()
+
+	tReqBase := test.CreateRequestTester(
+		testApp.routes(),
+		http.MethodGet,
+		"/organisations",
+	)
+
+	mt := test.CreateMatrixTester()
+
+	mt.AddTestCase(tReqBase, test.NewCaseResponse(http.StatusUnauthorized, nil, nil))
+
+	tReq2 := tReqBase.Copy()
+	tReq2.AddCookie(testEnv.fixtures.Tokens.AdminAccessToken)
+
+	mt.AddTestCase(tReq2, test.NewCaseResponse(http.StatusForbidden, nil, nil))
+
+	mt.Do(t)
+}
+
+func TestCreateOrganisation(t *testing.T) {
+	testEnv, testApp := setup(t)
+	defer testEnv.teardown()
+
+	data := dtos.OrganisationDto{
+		Name: "Other",
+	}
+
+	tReq := test.CreateRequestTester(
+		testApp.routes(),
+		http.MethodPost,
+		"/organisations",
+	)
+	tReq.AddCookie(testEnv.fixtures.Tokens.SuperAdminAccessToken)
+	tReq.SetData(data)
+
+	rs := tReq.Do(t)
+
+	var rsData models.Organisation
+	err := httptools.ReadJSON(rs.Body, &rsData)
+	require.Nil(t, err)
+
+	assert.Equal(t, http.StatusCreated, rs.StatusCode)
+	assert.Equal(t, data.Name, rsData.Name)
+
+	state, err := testApp.services.State.Get(rsData.ID)
+	require.Nil(t, err)
+	assert.Equal(t, false, state.IsMaintenance)
+}
+
+func TestCreateOrganisationNameExists(t *testing.T) {
+	testEnv, testApp := setup(t)
+	defer testEnv.teardown()
+
+	data := dtos.OrganisationDto{
+		Name: defaultOrganisationName,
+	}
+
+	tReq := test.CreateRequestTester(
+		testApp.routes(),
+		http.MethodPost,
+		"/organisations",
+	)
+	tReq.AddCookie(testEnv.fixtures.Tokens.SuperAdminAccessToken)
+	tReq.SetData(data)
+
+	rs := tReq.Do(t)
+
+	var rsData errortools.ErrorDto
+	err := httptools.ReadJSON(rs.Body, &rsData)
+	require.Nil(t, err)
+
+	assert.Equal(t, http.StatusConflict, rs.StatusCode)
+	assert.Equal(
+		t,
+		fmt.Sprintf("organisation with name '%s' already exists", data.Name),
+		//nolint:errcheck //not needed
+		rsData.Message.(map[string]interface{})["name"].(string),
+	)
+}
+
+func TestCreateOrganisationAccess(t *testing.T) {
+	testEnv, testApp := setup(t)
+	defer testEnv.teardown()
+
+	tReqBase := test.CreateRequestTester(
+		testApp.routes(),
+		http.MethodPost,
+		"/organisations",
+	)
+
+	mt := test.CreateMatrixTester()
+
+	mt.AddTestCase(tReqBase, test.NewCaseResponse(http.StatusUnauthorized, nil, nil))
+
+	tReq2 := tReqBase.Copy()
+	tReq2.AddCookie(testEnv.fixtures.Tokens.AdminAccessToken)
+
+	mt.AddTestCase(tReq2, test.NewCaseResponse(http.StatusForbidden, nil, nil))
+
+	mt.Do(t)
+}
+
+func TestUpdateOrganisation(t *testing.T) {
+	testEnv, testApp := setup(t)
+	defer testEnv.teardown()
+
+	organisation, _ := testEnv.createOrganisation("Other")
+
+	data := dtos.OrganisationDto{
+		Name: "Renamed",
+	}
+
+	tReq := test.CreateRequestTester(
+		testApp.routes(),
+		http.MethodPatch,
+		"/organisations/%s",
+		organisation.ID,
+	)
+	tReq.AddCookie(testEnv.fixtures.Tokens.SuperAdminAccessToken)
+	tReq.SetData(data)
+
+	rs := tReq.Do(t)
+
+	var rsData models.Organisation
+	err := httptools.ReadJSON(rs.Body, &rsData)
+	require.Nil(t, err)
+
+	assert.Equal(t, http.StatusOK, rs.StatusCode)
+	assert.Equal(t, organisation.ID, rsData.ID)
+	assert.Equal(t, data.Name, rsData.Name)
+}
+
+func TestUpdateOrganisationNotFound(t *testing.T) {
+	testEnv, testApp := setup(t)
+	defer testEnv.teardown()
+
+	data := dtos.OrganisationDto{
+		Name: "Renamed",
+	}
+
+	tReq := test.CreateRequestTester(
+		testApp.routes(),
+		http.MethodPatch,
+		"/organisations/%s",
+		"00000000-0000-0000-0000-000000000000",
+	)
+	tReq.AddCookie(testEnv.fixtures.Tokens.SuperAdminAccessToken)
+	tReq.SetData(data)
+
+	rs := tReq.Do(t)
+
+	assert.Equal(t, http.StatusNotFound, rs.StatusCode)
+}
+
+func TestCreateOrganisationAdmin(t *testing.T) {
+	testEnv, testApp := setup(t)
+	defer testEnv.teardown()
+
+	organisation, err := testApp.services.Organisations.Create(
+		testEnv.ctx,
+		dtos.OrganisationDto{
+			Name: "Other",
+		},
+	)
+	require.Nil(t, err)
+
+	data := dtos.CreateUserDto{
+		Username: "OtherAdmin",
+		Password: "testpassword",
+	}
+
+	tReq := test.CreateRequestTester(
+		testApp.routes(),
+		http.MethodPost,
+		"/organisations/%s/admins",
+		organisation.ID,
+	)
+	tReq.AddCookie(testEnv.fixtures.Tokens.SuperAdminAccessToken)
+	tReq.SetData(data)
+
+	rs := tReq.Do(t)
+
+	var rsData models.User
+	err = httptools.ReadJSON(rs.Body, &rsData)
+	require.Nil(t, err)
+
+	assert.Equal(t, http.StatusCreated, rs.StatusCode)
+	assert.Equal(t, data.Username, rsData.Username)
+	assert.Equal(t, models.AdminRole, rsData.Role)
+	assert.Equal(t, organisation.ID, rsData.OrganisationID)
+
+	tReqAudit := test.CreateRequestTester(
+		testApp.routes(),
+		http.MethodGet,
+		"/audit",
+	)
+	tReqAudit.SetQuery(map[string][]string{
+		"entityType": {"organisationAdmin"},
+		"entityId":   {rsData.ID},
+	})
+	tReqAudit.AddCookie(testEnv.createAccessToken(rsData))
+
+	rs = tReqAudit.Do(t)
+
+	var rsAuditData dtos.PaginatedAuditEventsDto
+	err = httptools.ReadJSON(rs.Body, &rsAuditData)
+	require.Nil(t, err)
+
+	assert.Equal(t, http.StatusOK, rs.StatusCode)
+	require.Equal(t, 1, len(rsAuditData.Data))
+	assert.Equal(t, models.AuditCreate, rsAuditData.Data[0].Action)
+}
+
+func TestOrganisationIsolation(t *testing.T) {
+	testEnv, testApp := setup(t)
+	defer testEnv.teardown()
+
+	_, otherAdminToken := testEnv.createOrganisation("Other")
+
+	tReq1 := test.CreateRequestTester(
+		testApp.routes(),
+		http.MethodGet,
+		"/locations/%s",
+		testEnv.fixtures.DefaultLocation.ID,
+	)
+	tReq1.AddCookie(otherAdminToken)
+
+	rs1 := tReq1.Do(t)
+	assert.Equal(t, http.StatusNotFound, rs1.StatusCode)
+
+	tReq2 := test.CreateRequestTester(
+		testApp.routes(),
+		http.MethodGet,
+		"/users/%s",
+		testEnv.fixtures.ManagerUser.ID,
+	)
+	tReq2.AddCookie(otherAdminToken)
+
+	rs2 := tReq2.Do(t)
+	assert.Equal(t, http.StatusNotFound, rs2.StatusCode)
+
+	tReq3 := test.CreateRequestTester(
+		testApp.routes(),
+		http.MethodGet,
+		"/schools",
+	)
+	tReq3.AddCookie(otherAdminToken)
+
+	rs3 := tReq3.Do(t)
+
+	var rsData dtos.PaginatedSchoolsDto
+	err := httptools.ReadJSON(rs3.Body, &rsData)
+	require.Nil(t, err)
+
+	assert.Equal(t, http.StatusOK, rs3.StatusCode)
+	assert.Equal(t, 1, len(rsData.Data))
+	assert.Equal(t, true, rsData.Data[0].ReadOnly)
+}
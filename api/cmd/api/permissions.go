@@ -0,0 +1,6 @@
+package main
+
+import "check-in/api/internal/models"
+
+// signedIn is used for routes which only require a signed in user.
+const signedIn models.Permission = ""
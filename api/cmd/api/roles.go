@@ -1,23 +1,232 @@
-//nolint:gochecknoglobals //global var for access
 package main
 
-import "check-in/api/internal/models"
+import (
+	"net/http"
 
-var allRoles = []models.Role{
-	models.AdminRole,
-	models.ManagerRole,
-	models.DefaultRole,
+	httptools "github.com/XDoubleU/essentia/pkg/communication/http"
+	"github.com/XDoubleU/essentia/pkg/context"
+	"github.com/XDoubleU/essentia/pkg/parse"
+
+	"check-in/api/internal/constants"
+	"check-in/api/internal/dtos"
+	"check-in/api/internal/models"
+)
+
+func (app *Application) rolesRoutes(mux *http.ServeMux) {
+	mux.HandleFunc(
+		"GET /permissions",
+		app.authAccess(models.RolesReadPermission, app.getPermissionsHandler),
+	)
+	mux.HandleFunc(
+		"GET /roles",
+		app.authAccess(models.RolesReadPermission, app.getRolesHandler),
+	)
+	mux.HandleFunc(
+		"POST /roles",
+		app.authAccess(models.RolesWritePermission, app.createRoleHandler),
+	)
+	mux.HandleFunc(
+		"PATCH /roles/{id}",
+		app.authAccess(models.RolesWritePermission, app.updateRoleHandler),
+	)
+	mux.HandleFunc(
+		"DELETE /roles/{id}",
+		app.authAccess(models.RolesWritePermission, app.deleteRoleHandler),
+	)
+	mux.HandleFunc(
+		"PUT /users/{id}/role",
+		app.authAccess(models.RolesWritePermission, app.setManagerRoleHandler),
+	)
 }
 
-var adminRole = []models.Role{
-	models.AdminRole,
+// @Summary	Get all permissions
+// @Tags		roles
+// @Success	200	{object}	[]Permission
+// @Failure	401	{object}	ErrorDto
+// @Failure	500	{object}	ErrorDto
+// @Router		/permissions [get].
+func (app *Application) getPermissionsHandler(w http.ResponseWriter,
+	r *http.Request) {
+	err := httptools.WriteJSON(w, http.StatusOK, models.AllPermissions, nil)
+	if err != nil {
+		httptools.ServerErrorResponse(w, r, err)
+	}
 }
 
-var defaultRole = []models.Role{
-	models.DefaultRole,
+// @Summary	Get built-in and custom roles
+// @Tags		roles
+// @Success	200	{object}	[]RoleDefinition
+// @Failure	401	{object}	ErrorDto
+// @Failure	500	{object}	ErrorDto
+// @Router		/roles [get].
+func (app *Application) getRolesHandler(w http.ResponseWriter, r *http.Request) {
+	user := context.GetValue[models.User](r.Context(), constants.UserContextKey)
+	roles, err := app.services.Roles.GetAll(r.Context(), user)
+	if err != nil {
+		httptools.ServerErrorResponse(w, r, err)
+		return
+	}
+
+	err = httptools.WriteJSON(w, http.StatusOK, roles, nil)
+	if err != nil {
+		httptools.ServerErrorResponse(w, r, err)
+	}
 }
 
-var managerAndAdminRole = []models.Role{
-	models.ManagerRole,
-	models.AdminRole,
+// @Summary	Create custom role
+// @Tags		roles
+// @Param		roleDto	body		RoleDto	true	"RoleDto"
+// @Success	201		{object}	RoleDefinition
+// @Failure	400		{object}	ErrorDto
+// @Failure	401		{object}	ErrorDto
+// @Failure	409		{object}	ErrorDto
+// @Failure	500		{object}	ErrorDto
+// @Router		/roles [post].
+func (app *Application) createRoleHandler(w http.ResponseWriter, r *http.Request) {
+	var roleDto dtos.RoleDto
+
+	err := httptools.ReadJSON(r.Body, &roleDto)
+	if err != nil {
+		httptools.BadRequestResponse(w, r, err)
+		return
+	}
+
+	if v, validationErrors := roleDto.Validate(); !v {
+		httptools.FailedValidationResponse(w, r, validationErrors)
+		return
+	}
+
+	user := context.GetValue[models.User](r.Context(), constants.UserContextKey)
+	role, err := app.services.Roles.Create(r.Context(), user, roleDto)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	err = httptools.WriteJSON(w, http.StatusCreated, role, nil)
+	if err != nil {
+		httptools.ServerErrorResponse(w, r, err)
+	}
+}
+
+// @Summary	Update custom role
+// @Tags		roles
+// @Param		id		path		string	true	"Role ID"
+// @Param		roleDto	body		RoleDto	true	"RoleDto"
+// @Success	200		{object}	RoleDefinition
+// @Failure	400		{object}	ErrorDto
+// @Failure	401		{object}	ErrorDto
+// @Failure	404		{object}	ErrorDto
+// @Failure	409		{object}	ErrorDto
+// @Failure	500		{object}	ErrorDto
+// @Router		/roles/{id} [patch].
+func (app *Application) updateRoleHandler(w http.ResponseWriter, r *http.Request) {
+	var roleDto dtos.RoleDto
+
+	id, err := parse.URLParam(r, "id", parse.UUID)
+	if err != nil {
+		httptools.BadRequestResponse(w, r, err)
+		return
+	}
+
+	err = httptools.ReadJSON(r.Body, &roleDto)
+	if err != nil {
+		httptools.BadRequestResponse(w, r, err)
+		return
+	}
+
+	if v, validationErrors := roleDto.Validate(); !v {
+		httptools.FailedValidationResponse(w, r, validationErrors)
+		return
+	}
+
+	user := context.GetValue[models.User](r.Context(), constants.UserContextKey)
+	role, err := app.services.Roles.Update(r.Context(), user, id, roleDto)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	err = httptools.WriteJSON(w, http.StatusOK, role, nil)
+	if err != nil {
+		httptools.ServerErrorResponse(w, r, err)
+	}
+}
+
+// @Summary	Delete custom role
+// @Tags		roles
+// @Param		id	path		string	true	"Role ID"
+// @Success	200	{object}	RoleDefinition
+// @Failure	400	{object}	ErrorDto
+// @Failure	401	{object}	ErrorDto
+// @Failure	404	{object}	ErrorDto
+// @Failure	500	{object}	ErrorDto
+// @Router		/roles/{id} [delete].
+func (app *Application) deleteRoleHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := parse.URLParam(r, "id", parse.UUID)
+	if err != nil {
+		httptools.BadRequestResponse(w, r, err)
+		return
+	}
+
+	user := context.GetValue[models.User](r.Context(), constants.UserContextKey)
+	role, err := app.services.Roles.Delete(r.Context(), user, id)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	err = httptools.WriteJSON(w, http.StatusOK, role, nil)
+	if err != nil {
+		httptools.ServerErrorResponse(w, r, err)
+	}
+}
+
+// @Summary	Assign custom role to manager
+// @Tags		roles
+// @Param		id			path		string		true	"User ID"
+// @Param		userRoleDto	body		UserRoleDto	true	"UserRoleDto"
+// @Success	200			{object}	User
+// @Failure	400			{object}	ErrorDto
+// @Failure	401			{object}	ErrorDto
+// @Failure	404			{object}	ErrorDto
+// @Failure	500			{object}	ErrorDto
+// @Router		/users/{id}/role [put].
+func (app *Application) setManagerRoleHandler(w http.ResponseWriter,
+	r *http.Request) {
+	var userRoleDto dtos.UserRoleDto
+
+	id, err := parse.URLParam(r, "id", parse.UUID)
+	if err != nil {
+		httptools.BadRequestResponse(w, r, err)
+		return
+	}
+
+	err = httptools.ReadJSON(r.Body, &userRoleDto)
+	if err != nil {
+		httptools.BadRequestResponse(w, r, err)
+		return
+	}
+
+	if v, validationErrors := userRoleDto.Validate(); !v {
+		httptools.FailedValidationResponse(w, r, validationErrors)
+		return
+	}
+
+	user := context.GetValue[models.User](r.Context(), constants.UserContextKey)
+	manager, err := app.services.Roles.SetManagerRole(
+		r.Context(),
+		user,
+		id,
+		userRoleDto,
+	)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	err = httptools.WriteJSON(w, http.StatusOK, manager, nil)
+	if err != nil {
+		httptools.ServerErrorResponse(w, r, err)
+	}
 }
@@ -0,0 +1,411 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	httptools "github.com/XDoubleU/essentia/pkg/communication/http"
+	errortools "github.com/XDoubleU/essentia/pkg/errors"
+	"github.com/XDoubleU/essentia/pkg/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"check-in/api/internal/dtos"
+	"check-in/api/internal/models"
+)
+
+func (env *TestEnv) createRole(
+	permissions ...models.Permission,
+) *models.RoleDefinition {
+	user := *env.fixtures.AdminUser
+	user.Permissions = models.AllPermissions
+
+	role, err := env.app.services.Roles.Create(env.ctx, &user, dtos.RoleDto{
+		Name:        "Coordinator",
+		Permissions: permissions,
+	})
+	if err != nil {
+		panic(err)
+	}
+
+	return role
+}
+
+func TestGetPermissions(t *testing.T) {
+	testEnv, testApp := setup(t)
+	defer testEnv.teardown()
+
+	tReq := test.CreateRequestTester(
+		testApp.routes(),
+		http.MethodGet,
+		"/permissions",
+	)
+	tReq.AddCookie(testEnv.fixtures.Tokens.AdminAccessToken)
+
+	rs := tReq.Do(t)
+
+	var rsData []models.Permission
+	err := httptools.ReadJSON(rs.Body, &rsData)
+	require.Nil(t, err)
+
+	assert.Equal(t, http.StatusOK, rs.StatusCode)
+	assert.Equal(t, models.AllPermissions, rsData)
+}
+
+func TestGetRoles(t *testing.T) {
+	testEnv, testApp := setup(t)
+	defer testEnv.teardown()
+
+	role := testEnv.createRole(models.StatsReadPermission)
+
+	tReq := test.CreateRequestTester(
+		testApp.routes(),
+		http.MethodGet,
+		"/roles",
+	)
+	tReq.AddCookie(testEnv.fixtures.Tokens.AdminAccessToken)
+
+	rs := tReq.Do(t)
+
+	var rsData []models.RoleDefinition
+	err := httptools.ReadJSON(rs.Body, &rsData)
+	require.Nil(t, err)
+
+	assert.Equal(t, http.StatusOK, rs.StatusCode)
+	assert.Equal(t, 5, len(rsData))
+
+	for _, builtIn := range rsData[:4] {
+		assert.Equal(t, true, builtIn.BuiltIn)
+	}
+
+	assert.Equal(t, role.ID, rsData[4].ID)
+	assert.Equal(t, false, rsData[4].BuiltIn)
+	assert.Equal(
+		t,
+		[]models.Permission{models.StatsReadPermission},
+		rsData[4].Permissions,
+	)
+}
+
+func TestGetRolesAccess(t *testing.T) {
+	testEnv, testApp := setup(t)
+	defer testEnv.teardown()
+
+	tReqBase := test.CreateRequestTester(
+		testApp.routes(),
+		http.MethodGet,
+		"/roles",
+	)
+
+	mt := test.CreateMatrixTester()
+
+	mt.AddTestCase(tReqBase, test.NewCaseResponse(http.StatusUnauthorized, nil, nil))
+
+	tReq2 := tReqBase.Copy()
+	tReq2.AddCookie(testEnv.fixtures.Tokens.ManagerAccessToken)
+
+	mt.AddTestCase(tReq2, test.NewCaseResponse(http.StatusForbidden, nil, nil))
+
+	mt.Do(t)
+}
+
+func TestCreateRole(t *testing.T) {
+	testEnv, testApp := setup(t)
+	defer testEnv.teardown()
+
+	data := dtos.RoleDto{
+		Name: "Coordinator",
+		Permissions: []models.Permission{
+			models.LocationsReadPermission,
+			models.StatsReadPermission,
+		},
+	}
+
+	tReq := test.CreateRequestTester(
+		testApp.routes(),
+		http.MethodPost,
+		"/roles",
+	)
+	tReq.AddCookie(testEnv.fixtures.Tokens.AdminAccessToken)
+	tReq.SetData(data)
+
+	rs := tReq.Do(t)
+
+	var rsData models.RoleDefinition
+	err := httptools.ReadJSON(rs.Body, &rsData)
+	require.Nil(t, err)
+
+	assert.Equal(t, http.StatusCreated, rs.StatusCode)
+	assert.Equal(t, data.Name, rsData.Name)
+	assert.Equal(t, data.Permissions, rsData.Permissions)
+	assert.Equal(t, false, rsData.BuiltIn)
+}
+
+func TestCreateRoleNotGrantable(t *testing.T) {
+	testEnv, testApp := setup(t)
+	defer testEnv.teardown()
+
+	data := dtos.RoleDto{
+		Name: "Coordinator",
+		Permissions: []models.Permission{
+			models.OrganisationsWritePermission,
+		},
+	}
+
+	tReq := test.CreateRequestTester(
+		testApp.routes(),
+		http.MethodPost,
+		"/roles",
+	)
+	tReq.AddCookie(testEnv.fixtures.Tokens.AdminAccessToken)
+	tReq.SetData(data)
+
+	rs := tReq.Do(t)
+
+	assert.Equal(t, http.StatusBadRequest, rs.StatusCode)
+
+	// not even by users who have the permission themselves
+	tReq = test.CreateRequestTester(
+		testApp.routes(),
+		http.MethodPost,
+		"/roles",
+	)
+	tReq.AddCookie(testEnv.fixtures.Tokens.SuperAdminAccessToken)
+	tReq.SetData(data)
+
+	rs = tReq.Do(t)
+
+	assert.Equal(t, http.StatusBadRequest, rs.StatusCode)
+
+	tReq = test.CreateRequestTester(
+		testApp.routes(),
+		http.MethodPost,
+		"/roles",
+	)
+	tReq.AddCookie(testEnv.fixtures.Tokens.AdminAccessToken)
+	tReq.SetData(dtos.RoleDto{
+		Name: "Coordinator",
+		Permissions: []models.Permission{
+			models.UsersWritePermission,
+		},
+	})
+
+	rs = tReq.Do(t)
+
+	assert.Equal(t, http.StatusBadRequest, rs.StatusCode)
+}
+
+func TestCreateRoleFailValidation(t *testing.T) {
+	testEnv, testApp := setup(t)
+	defer testEnv.teardown()
+
+	tReqBase := test.CreateRequestTester(
+		testApp.routes(),
+		http.MethodPost,
+		"/roles",
+	)
+	tReqBase.AddCookie(testEnv.fixtures.Tokens.AdminAccessToken)
+
+	mt := test.CreateMatrixTester()
+
+	tReq1 := tReqBase.Copy()
+	tReq1.SetData(dtos.RoleDto{
+		Name:        string(models.ManagerRole),
+		Permissions: []models.Permission{},
+	})
+
+	tRes1 := test.NewCaseResponse(http.StatusUnprocessableEntity, nil,
+		errortools.NewErrorDto(http.StatusUnprocessableEntity, map[string]interface{}{
+			"name": "must not be a built-in role",
+		}))
+
+	mt.AddTestCase(tReq1, tRes1)
+
+	tReq2 := tReqBase.Copy()
+	tReq2.SetData(dtos.RoleDto{
+		Name:        "Coordinator",
+		Permissions: []models.Permission{"unknown"},
+	})
+
+	tRes2 := test.NewCaseResponse(http.StatusUnprocessableEntity, nil,
+		errortools.NewErrorDto(http.StatusUnprocessableEntity, map[string]interface{}{
+			"permissions[0]": "must be a valid value",
+		}))
+
+	mt.AddTestCase(tReq2, tRes2)
+
+	mt.Do(t)
+}
+
+func TestUpdateRole(t *testing.T) {
+	testEnv, testApp := setup(t)
+	defer testEnv.teardown()
+
+	role := testEnv.createRole(models.StatsReadPermission)
+
+	data := dtos.RoleDto{
+		Name: "Regional coordinator",
+		Permissions: []models.Permission{
+			models.StatsReadPermission,
+			models.StatsExportPermission,
+		},
+	}
+
+	tReq := test.CreateRequestTester(
+		testApp.routes(),
+		http.MethodPatch,
+		"/roles/%s",
+		role.ID,
+	)
+	tReq.AddCookie(testEnv.fixtures.Tokens.AdminAccessToken)
+	tReq.SetData(data)
+
+	rs := tReq.Do(t)
+
+	var rsData models.RoleDefinition
+	err := httptools.ReadJSON(rs.Body, &rsData)
+	require.Nil(t, err)
+
+	assert.Equal(t, http.StatusOK, rs.StatusCode)
+	assert.Equal(t, data.Name, rsData.Name)
+	assert.Equal(t, data.Permissions, rsData.Permissions)
+}
+
+func TestUpdateRoleBuiltIn(t *testing.T) {
+	testEnv, testApp := setup(t)
+	defer testEnv.teardown()
+
+	roles, err := testApp.services.Roles.GetAll(
+		context.Background(),
+		testEnv.fixtures.AdminUser,
+	)
+	require.Nil(t, err)
+
+	tReq := test.CreateRequestTester(
+		testApp.routes(),
+		http.MethodPatch,
+		"/roles/%s",
+		roles[0].ID,
+	)
+	tReq.AddCookie(testEnv.fixtures.Tokens.AdminAccessToken)
+	tReq.SetData(dtos.RoleDto{
+		Name:        "Coordinator",
+		Permissions: []models.Permission{},
+	})
+
+	rs := tReq.Do(t)
+
+	assert.Equal(t, http.StatusNotFound, rs.StatusCode)
+}
+
+func TestDeleteRole(t *testing.T) {
+	testEnv, testApp := setup(t)
+	defer testEnv.teardown()
+
+	role := testEnv.createRole(models.StatsReadPermission)
+
+	tReq := test.CreateRequestTester(
+		testApp.routes(),
+		http.MethodDelete,
+		"/roles/%s",
+		role.ID,
+	)
+	tReq.AddCookie(testEnv.fixtures.Tokens.AdminAccessToken)
+
+	rs1 := tReq.Do(t)
+	assert.Equal(t, http.StatusOK, rs1.StatusCode)
+
+	rs2 := tReq.Do(t)
+	assert.Equal(t, http.StatusNotFound, rs2.StatusCode)
+}
+
+func TestSetManagerRole(t *testing.T) {
+	testEnv, testApp := setup(t)
+	defer testEnv.teardown()
+
+	role := testEnv.createRole(
+		models.LocationsReadPermission,
+		models.StatsReadPermission,
+	)
+
+	tReq1 := test.CreateRequestTester(
+		testApp.routes(),
+		http.MethodPut,
+		"/users/%s/role",
+		testEnv.fixtures.ManagerUser.ID,
+	)
+	tReq1.AddCookie(testEnv.fixtures.Tokens.AdminAccessToken)
+	tReq1.SetData(dtos.UserRoleDto{
+		RoleID: &role.ID,
+	})
+
+	rs1 := tReq1.Do(t)
+
+	var rsData models.User
+	err := httptools.ReadJSON(rs1.Body, &rsData)
+	require.Nil(t, err)
+
+	assert.Equal(t, http.StatusOK, rs1.StatusCode)
+	assert.Equal(t, role.ID, rsData.CustomRoleID.String)
+
+	tReq2 := test.CreateRequestTester(
+		testApp.routes(),
+		http.MethodGet,
+		"/locations/%s",
+		testEnv.fixtures.DefaultLocation.ID,
+	)
+	tReq2.AddCookie(testEnv.fixtures.Tokens.ManagerAccessToken)
+
+	rs2 := tReq2.Do(t)
+	assert.Equal(t, http.StatusOK, rs2.StatusCode)
+
+	tReq3 := test.CreateRequestTester(
+		testApp.routes(),
+		http.MethodGet,
+		"/schools",
+	)
+	tReq3.AddCookie(testEnv.fixtures.Tokens.ManagerAccessToken)
+
+	rs3 := tReq3.Do(t)
+	assert.Equal(t, http.StatusForbidden, rs3.StatusCode)
+
+	tReq4 := test.CreateRequestTester(
+		testApp.routes(),
+		http.MethodPut,
+		"/users/%s/role",
+		testEnv.fixtures.ManagerUser.ID,
+	)
+	tReq4.AddCookie(testEnv.fixtures.Tokens.AdminAccessToken)
+	tReq4.SetData(dtos.UserRoleDto{
+		RoleID: nil,
+	})
+
+	rs4 := tReq4.Do(t)
+	assert.Equal(t, http.StatusOK, rs4.StatusCode)
+
+	rs5 := tReq3.Do(t)
+	assert.Equal(t, http.StatusOK, rs5.StatusCode)
+}
+
+func TestGetInfoLoggedInUserPermissions(t *testing.T) {
+	testEnv, testApp := setup(t)
+	defer testEnv.teardown()
+
+	tReq := test.CreateRequestTester(
+		testApp.routes(),
+		http.MethodGet,
+		"/current-user",
+	)
+	tReq.AddCookie(testEnv.fixtures.Tokens.DefaultAccessToken)
+
+	rs := tReq.Do(t)
+
+	var rsData models.User
+	err := httptools.ReadJSON(rs.Body, &rsData)
+	require.Nil(t, err)
+
+	assert.Equal(t, http.StatusOK, rs.StatusCode)
+	assert.Contains(t, rsData.Permissions, models.CheckInsWritePermission)
+	assert.NotContains(t, rsData.Permissions, models.LocationsListPermission)
+}
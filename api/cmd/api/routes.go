@@ -11,10 +11,19 @@ import (
 func (app *Application) routes() http.Handler {
 	mux := http.NewServeMux()
 
+	app.apiKeysRoutes(mux)
+	app.auditRoutes(mux)
 	app.authRoutes(mux)
 	app.checkInsRoutes(mux)
+	app.devicesRoutes(mux)
 	app.locationsRoutes(mux)
+	app.lockoutsRoutes(mux)
+	app.oidcRoutes(mux)
+	app.organisationsRoutes(mux)
+	app.rolesRoutes(mux)
 	app.schoolsRoutes(mux)
+	app.sessionsRoutes(mux)
+	app.twoFactorRoutes(mux)
 	app.usersRoutes(mux)
 	app.websocketsRoutes(mux)
 	app.stateRoutes(mux)
@@ -15,19 +15,23 @@ import (
 func (app *Application) schoolsRoutes(mux *http.ServeMux) {
 	mux.HandleFunc(
 		"GET /schools",
-		app.authAccess(managerAndAdminRole, app.getPaginatedSchoolsHandler),
+		app.authAccess(models.SchoolsReadPermission, app.getPaginatedSchoolsHandler),
 	)
 	mux.HandleFunc(
 		"POST /schools",
-		app.authAccess(managerAndAdminRole, app.createSchoolHandler),
+		app.authAccess(models.SchoolsWritePermission, app.createSchoolHandler),
 	)
 	mux.HandleFunc(
 		"PATCH /schools/{id}",
-		app.authAccess(managerAndAdminRole, app.updateSchoolHandler),
+		app.authAccess(models.SchoolsWritePermission, app.updateSchoolHandler),
 	)
 	mux.HandleFunc(
 		"DELETE /schools/{id}",
-		app.authAccess(managerAndAdminRole, app.deleteSchoolHandler),
+		app.authAccess(models.SchoolsWritePermission, app.deleteSchoolHandler),
+	)
+	mux.HandleFunc(
+		"POST /schools/{id}/restore",
+		app.authAccess(models.SchoolsRestorePermission, app.restoreSchoolHandler),
 	)
 }
 
@@ -91,7 +95,12 @@ func (app *Application) createSchoolHandler(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
-	school, err := app.services.Schools.Create(r.Context(), schoolDto)
+	user := context.GetValue[models.User](r.Context(), constants.UserContextKey)
+	school, err := app.services.Schools.Create(
+		r.Context(),
+		user.OrganisationID,
+		schoolDto,
+	)
 	if err != nil {
 		httptools.HandleError(w, r, err)
 		return
@@ -133,7 +142,13 @@ func (app *Application) updateSchoolHandler(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
-	school, err := app.services.Schools.Update(r.Context(), id, schoolDto)
+	user := context.GetValue[models.User](r.Context(), constants.UserContextKey)
+	school, err := app.services.Schools.Update(
+		r.Context(),
+		user.OrganisationID,
+		id,
+		schoolDto,
+	)
 	if err != nil {
 		httptools.HandleError(w, r, err)
 		return
@@ -161,9 +176,47 @@ func (app *Application) deleteSchoolHandler(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
-	school, err := app.services.Schools.Delete(r.Context(), id)
+	user := context.GetValue[models.User](r.Context(), constants.UserContextKey)
+	school, err := app.services.Schools.Delete(
+		r.Context(),
+		user.OrganisationID,
+		id,
+	)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+	}
+
+	err = httptools.WriteJSON(w, http.StatusOK, school, nil)
+	if err != nil {
+		httptools.ServerErrorResponse(w, r, err)
+	}
+}
+
+// @Summary	Restore deleted school
+// @Tags		schools
+// @Param		id	path		int	true	"School ID"
+// @Success	200	{object}	School
+// @Failure	400	{object}	ErrorDto
+// @Failure	401	{object}	ErrorDto
+// @Failure	404	{object}	ErrorDto
+// @Failure	500	{object}	ErrorDto
+// @Router		/schools/{id}/restore [post].
+func (app *Application) restoreSchoolHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := parse.URLParam(r, "id", parse.Int64(true, false))
+	if err != nil {
+		httptools.BadRequestResponse(w, r, err)
+		return
+	}
+
+	user := context.GetValue[models.User](r.Context(), constants.UserContextKey)
+	school, err := app.services.Schools.Restore(
+		r.Context(),
+		user.OrganisationID,
+		id,
+	)
 	if err != nil {
 		httptools.HandleError(w, r, err)
+		return
 	}
 
 	err = httptools.WriteJSON(w, http.StatusOK, school, nil)
@@ -22,7 +22,10 @@ func TestGetPaginatedSchoolsDefaultPage(t *testing.T) {
 	defer testEnv.teardown()
 
 	testEnv.createSchools(20)
-	amount, err := testApp.services.Schools.GetTotalCount(context.Background())
+	amount, err := testApp.services.Schools.GetTotalCount(
+		context.Background(),
+		testEnv.fixtures.AdminUser,
+	)
 	require.Nil(t, err)
 
 	users := []*http.Cookie{
@@ -65,7 +68,10 @@ func TestGetPaginatedSchoolsSpecificPage(t *testing.T) {
 	defer testEnv.teardown()
 
 	schools := testEnv.createSchools(20)
-	amount, err := testApp.services.Schools.GetTotalCount(context.Background())
+	amount, err := testApp.services.Schools.GetTotalCount(
+		context.Background(),
+		testEnv.fixtures.AdminUser,
+	)
 	require.Nil(t, err)
 
 	tReq := test.CreateRequestTester(
@@ -622,3 +628,70 @@ func TestDeleteSchoolAccess(t *testing.T) {
 
 	mt.Do(t)
 }
+
+func TestRestoreSchool(t *testing.T) {
+	testEnv, testApp := setup(t)
+	defer testEnv.teardown()
+
+	school := testEnv.createSchools(1)[0]
+
+	tReqDelete := test.CreateRequestTester(
+		testApp.routes(),
+		http.MethodDelete,
+		"/schools/%d",
+		school.ID,
+	)
+	tReqDelete.AddCookie(testEnv.fixtures.Tokens.AdminAccessToken)
+
+	rs := tReqDelete.Do(t)
+	assert.Equal(t, http.StatusOK, rs.StatusCode)
+
+	rs = tReqDelete.Do(t)
+	assert.Equal(t, http.StatusNotFound, rs.StatusCode)
+
+	tReq := test.CreateRequestTester(
+		testApp.routes(),
+		http.MethodPost,
+		"/schools/%d/restore",
+		school.ID,
+	)
+	tReq.AddCookie(testEnv.fixtures.Tokens.AdminAccessToken)
+
+	rs = tReq.Do(t)
+
+	var rsData models.School
+	err := httptools.ReadJSON(rs.Body, &rsData)
+	require.Nil(t, err)
+
+	assert.Equal(t, http.StatusOK, rs.StatusCode)
+	assert.Equal(t, school.ID, rsData.ID)
+	assert.Equal(t, school.Name, rsData.Name)
+
+	rs = tReq.Do(t)
+	assert.Equal(t, http.StatusNotFound, rs.StatusCode)
+}
+
+func TestRestoreSchoolAccess(t *testing.T) {
+	testEnv, testApp := setup(t)
+	defer testEnv.teardown()
+
+	tReqBase := test.CreateRequestTester(
+		testApp.routes(),
+		http.MethodPost,
+		"/schools/1/restore",
+	)
+
+	mt := test.CreateMatrixTester()
+
+	mt.AddTestCase(tReqBase, test.NewCaseResponse(http.StatusUnauthorized, nil, nil))
+
+	tReq2 := tReqBase.Copy()
+	tReq2.AddCookie(testEnv.fixtures.Tokens.DefaultAccessToken)
+	mt.AddTestCase(tReq2, test.NewCaseResponse(http.StatusForbidden, nil, nil))
+
+	tReq3 := tReqBase.Copy()
+	tReq3.AddCookie(testEnv.fixtures.Tokens.ManagerAccessToken)
+	mt.AddTestCase(tReq3, test.NewCaseResponse(http.StatusForbidden, nil, nil))
+
+	mt.Do(t)
+}
@@ -0,0 +1,227 @@
+package main
+
+import (
+	"net/http"
+
+	httptools "github.com/XDoubleU/essentia/pkg/communication/http"
+	"github.com/XDoubleU/essentia/pkg/context"
+	"github.com/XDoubleU/essentia/pkg/parse"
+
+	"check-in/api/internal/constants"
+	"check-in/api/internal/models"
+)
+
+func (app *Application) sessionsRoutes(mux *http.ServeMux) {
+	mux.HandleFunc(
+		"GET /current-user/sessions",
+		app.authAccess(signedIn, app.getSessionsHandler),
+	)
+	mux.HandleFunc(
+		"DELETE /current-user/sessions",
+		app.authAccess(signedIn, app.deleteSessionsHandler),
+	)
+	mux.HandleFunc(
+		"DELETE /current-user/sessions/{id}",
+		app.authAccess(signedIn, app.deleteSessionHandler),
+	)
+	mux.HandleFunc(
+		"GET /users/{id}/sessions",
+		app.authAccess(models.UsersReadPermission, app.getUserSessionsHandler),
+	)
+	mux.HandleFunc(
+		"DELETE /users/{id}/sessions",
+		app.authAccess(models.UsersWritePermission, app.deleteUserSessionsHandler),
+	)
+	mux.HandleFunc(
+		"DELETE /users/{id}/sessions/{sessionId}",
+		app.authAccess(models.UsersWritePermission, app.deleteUserSessionHandler),
+	)
+}
+
+// @Summary	Get the active sessions of the current user
+// @Tags		users
+// @Success	200	{object}	[]Session
+// @Failure	401	{object}	ErrorDto
+// @Failure	500	{object}	ErrorDto
+// @Router		/current-user/sessions [get].
+func (app *Application) getSessionsHandler(w http.ResponseWriter, r *http.Request) {
+	user := context.GetValue[models.User](r.Context(), constants.UserContextKey)
+
+	sessions, err := app.services.Sessions.GetAll(r.Context(), user)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	err = httptools.WriteJSON(w, http.StatusOK, sessions, nil)
+	if err != nil {
+		httptools.ServerErrorResponse(w, r, err)
+	}
+}
+
+// @Summary	Sign out the current user everywhere
+// @Tags		users
+// @Success	200	{object}	nil
+// @Failure	401	{object}	ErrorDto
+// @Failure	500	{object}	ErrorDto
+// @Router		/current-user/sessions [delete].
+func (app *Application) deleteSessionsHandler(w http.ResponseWriter, r *http.Request) {
+	user := context.GetValue[models.User](r.Context(), constants.UserContextKey)
+
+	err := app.services.Sessions.DeleteAll(r.Context(), user)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	app.expireAuthCookies(w)
+}
+
+// @Summary	Sign out a session of the current user
+// @Tags		users
+// @Param		id	path		string	true	"Session ID"
+// @Success	200	{object}	Session
+// @Failure	400	{object}	ErrorDto
+// @Failure	401	{object}	ErrorDto
+// @Failure	404	{object}	ErrorDto
+// @Failure	500	{object}	ErrorDto
+// @Router		/current-user/sessions/{id} [delete].
+func (app *Application) deleteSessionHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := parse.URLParam(r, "id", parse.UUID)
+	if err != nil {
+		httptools.BadRequestResponse(w, r, err)
+		return
+	}
+
+	user := context.GetValue[models.User](r.Context(), constants.UserContextKey)
+
+	session, err := app.services.Sessions.Delete(r.Context(), user, id)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	if session.Current {
+		app.expireAuthCookies(w)
+	}
+
+	err = httptools.WriteJSON(w, http.StatusOK, session, nil)
+	if err != nil {
+		httptools.ServerErrorResponse(w, r, err)
+	}
+}
+
+// @Summary	Get the active sessions of a user
+// @Tags		users
+// @Param		id	path		string	true	"User ID"
+// @Success	200	{object}	[]Session
+// @Failure	400	{object}	ErrorDto
+// @Failure	401	{object}	ErrorDto
+// @Failure	404	{object}	ErrorDto
+// @Failure	500	{object}	ErrorDto
+// @Router		/users/{id}/sessions [get].
+func (app *Application) getUserSessionsHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := parse.URLParam(r, "id", parse.UUID)
+	if err != nil {
+		httptools.BadRequestResponse(w, r, err)
+		return
+	}
+
+	currentUser := context.GetValue[models.User](r.Context(), constants.UserContextKey)
+
+	sessions, err := app.services.Sessions.GetAllForUser(
+		r.Context(),
+		currentUser.OrganisationID,
+		id,
+	)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	err = httptools.WriteJSON(w, http.StatusOK, sessions, nil)
+	if err != nil {
+		httptools.ServerErrorResponse(w, r, err)
+	}
+}
+
+// @Summary	Sign out a user everywhere
+// @Tags		users
+// @Param		id	path		string	true	"User ID"
+// @Success	200	{object}	nil
+// @Failure	400	{object}	ErrorDto
+// @Failure	401	{object}	ErrorDto
+// @Failure	404	{object}	ErrorDto
+// @Failure	500	{object}	ErrorDto
+// @Router		/users/{id}/sessions [delete].
+func (app *Application) deleteUserSessionsHandler(
+	w http.ResponseWriter,
+	r *http.Request,
+) {
+	id, err := parse.URLParam(r, "id", parse.UUID)
+	if err != nil {
+		httptools.BadRequestResponse(w, r, err)
+		return
+	}
+
+	currentUser := context.GetValue[models.User](r.Context(), constants.UserContextKey)
+
+	err = app.services.Sessions.DeleteAllForUser(
+		r.Context(),
+		currentUser.OrganisationID,
+		id,
+	)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+	}
+}
+
+// @Summary	Sign out a session of a user
+// @Tags		users
+// @Param		id			path		string	true	"User ID"
+// @Param		sessionId	path		string	true	"Session ID"
+// @Success	200			{object}	Session
+// @Failure	400			{object}	ErrorDto
+// @Failure	401			{object}	ErrorDto
+// @Failure	404			{object}	ErrorDto
+// @Failure	500			{object}	ErrorDto
+// @Router		/users/{id}/sessions/{sessionId} [delete].
+func (app *Application) deleteUserSessionHandler(
+	w http.ResponseWriter,
+	r *http.Request,
+) {
+	id, err := parse.URLParam(r, "id", parse.UUID)
+	if err != nil {
+		httptools.BadRequestResponse(w, r, err)
+		return
+	}
+
+	sessionID, err := parse.URLParam(r, "sessionId", parse.UUID)
+	if err != nil {
+		httptools.BadRequestResponse(w, r, err)
+		return
+	}
+
+	currentUser := context.GetValue[models.User](r.Context(), constants.UserContextKey)
+
+	session, err := app.services.Sessions.DeleteForUser(
+		r.Context(),
+		currentUser.OrganisationID,
+		id,
+		sessionID,
+	)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	err = httptools.WriteJSON(w, http.StatusOK, session, nil)
+	if err != nil {
+		httptools.ServerErrorResponse(w, r, err)
+	}
+}
+
+func (app *Application) expireAuthCookies(w http.ResponseWriter) {
+	http.SetCookie(w, app.services.Auth.ExpiredCookie(models.AccessScope))
+	http.SetCookie(w, app.services.Auth.ExpiredCookie(models.RefreshScope))
+}
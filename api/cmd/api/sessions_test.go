@@ -0,0 +1,373 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	httptools "github.com/XDoubleU/essentia/pkg/communication/http"
+	"github.com/XDoubleU/essentia/pkg/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"check-in/api/internal/dtos"
+	"check-in/api/internal/models"
+)
+
+// signInFrom signs in the manager from a browser with the given user agent
+// and returns its access and refresh token.
+func signInFrom(
+	t *testing.T,
+	testApp Application,
+	userAgent string,
+) (*http.Cookie, *http.Cookie) {
+	body, err := json.Marshal(dtos.SignInDto{
+		Username:   "Manager",
+		Password:   "testpassword",
+		RememberMe: true,
+		Code:       "",
+	})
+	require.Nil(t, err)
+
+	req := httptest.NewRequest(http.MethodPost, "/auth/signin", bytes.NewReader(body))
+	req.Header.Set("User-Agent", userAgent)
+
+	rr := httptest.NewRecorder()
+	testApp.routes().ServeHTTP(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	cookies := rr.Result().Cookies()
+
+	return findCookie(cookies, "accessToken"), findCookie(cookies, "refreshToken")
+}
+
+func getSessions(
+	t *testing.T,
+	testApp Application,
+	accessToken *http.Cookie,
+) []models.Session {
+	tReq := test.CreateRequestTester(
+		testApp.routes(),
+		http.MethodGet,
+		"/current-user/sessions",
+	)
+	tReq.AddCookie(accessToken)
+
+	rs := tReq.Do(t)
+	require.Equal(t, http.StatusOK, rs.StatusCode)
+
+	var rsData []models.Session
+	err := httptools.ReadJSON(rs.Body, &rsData)
+	require.Nil(t, err)
+
+	return rsData
+}
+
+func TestGetSessions(t *testing.T) {
+	testEnv, testApp := setup(t)
+	defer testEnv.teardown()
+
+	accessToken, _ := signInFrom(t, testApp, "Firefox")
+	_, _ = signInFrom(t, testApp, "Safari")
+
+	sessions := getSessions(t, testApp, accessToken)
+
+	require.Equal(t, 2, len(sessions))
+
+	userAgents := []string{sessions[0].UserAgent, sessions[1].UserAgent}
+	assert.ElementsMatch(t, []string{"Firefox", "Safari"}, userAgents)
+
+	for _, session := range sessions {
+		assert.Equal(t, session.UserAgent == "Firefox", session.Current)
+		assert.NotEqual(t, "", session.IP)
+		assert.Equal(t, false, session.CreatedAt.IsZero())
+		assert.Equal(t, true, session.Expiry.After(session.LastUsedAt))
+	}
+}
+
+func TestRefreshKeepsSession(t *testing.T) {
+	testEnv, testApp := setup(t)
+	defer testEnv.teardown()
+
+	accessToken, refreshToken := signInFrom(t, testApp, "Firefox")
+
+	tReq := test.CreateRequestTester(
+		testApp.routes(),
+		http.MethodGet,
+		"/auth/refresh",
+	)
+	tReq.AddCookie(refreshToken)
+
+	rs := tReq.Do(t)
+	require.Equal(t, http.StatusOK, rs.StatusCode)
+
+	newAccessToken := findCookie(rs.Cookies(), "accessToken")
+	require.NotNil(t, newAccessToken)
+
+	sessions1 := getSessions(t, testApp, accessToken)
+	sessions2 := getSessions(t, testApp, newAccessToken)
+
+	require.Equal(t, 1, len(sessions1))
+	require.Equal(t, 1, len(sessions2))
+	assert.Equal(t, sessions1[0].ID, sessions2[0].ID)
+	assert.Equal(t, true, sessions2[0].Current)
+}
+
+func TestSessionLastUsedAtThrottled(t *testing.T) {
+	testEnv, testApp := setup(t)
+	defer testEnv.teardown()
+
+	accessToken, _ := signInFrom(t, testApp, "Firefox")
+
+	// used less than a minute ago, so it isn't stored again
+	session := getSessions(t, testApp, accessToken)[0]
+	assert.Equal(t, session.CreatedAt, session.LastUsedAt)
+
+	_, err := postgresDB.Exec(
+		context.Background(),
+		"UPDATE sessions SET last_used_at = $1 WHERE id = $2",
+		session.LastUsedAt.Add(-2*time.Minute),
+		session.ID,
+	)
+	require.Nil(t, err)
+
+	_ = getSessions(t, testApp, accessToken)
+
+	session = getSessions(t, testApp, accessToken)[0]
+	assert.Equal(t, true, session.LastUsedAt.After(session.CreatedAt))
+}
+
+func TestDeleteSession(t *testing.T) {
+	testEnv, testApp := setup(t)
+	defer testEnv.teardown()
+
+	accessToken, _ := signInFrom(t, testApp, "Firefox")
+	otherAccessToken, otherRefreshToken := signInFrom(t, testApp, "Safari")
+
+	other := getSessions(t, testApp, otherAccessToken)[0]
+	require.Equal(t, true, other.Current)
+
+	tReq := test.CreateRequestTester(
+		testApp.routes(),
+		http.MethodDelete,
+		"/current-user/sessions/%s",
+		other.ID,
+	)
+	tReq.AddCookie(accessToken)
+
+	rs1 := tReq.Do(t)
+	assert.Equal(t, http.StatusOK, rs1.StatusCode)
+	assert.Nil(t, findCookie(rs1.Cookies(), "accessToken"))
+
+	rs2 := tReq.Do(t)
+	assert.Equal(t, http.StatusNotFound, rs2.StatusCode)
+
+	tReqMe := test.CreateRequestTester(
+		testApp.routes(),
+		http.MethodGet,
+		"/current-user",
+	)
+	tReqMe.AddCookie(otherAccessToken)
+
+	rs3 := tReqMe.Do(t)
+	assert.Equal(t, http.StatusUnauthorized, rs3.StatusCode)
+
+	tReqRefresh := test.CreateRequestTester(
+		testApp.routes(),
+		http.MethodGet,
+		"/auth/refresh",
+	)
+	tReqRefresh.AddCookie(otherRefreshToken)
+
+	rs4 := tReqRefresh.Do(t)
+	assert.Equal(t, http.StatusUnauthorized, rs4.StatusCode)
+
+	assert.Equal(t, 1, len(getSessions(t, testApp, accessToken)))
+}
+
+func TestDeleteSessionOfOtherUser(t *testing.T) {
+	testEnv, testApp := setup(t)
+	defer testEnv.teardown()
+
+	managerAccessToken, _ := signInFrom(t, testApp, "Firefox")
+	session := getSessions(t, testApp, managerAccessToken)[0]
+
+	tReq := test.CreateRequestTester(
+		testApp.routes(),
+		http.MethodDelete,
+		"/current-user/sessions/%s",
+		session.ID,
+	)
+	tReq.AddCookie(testEnv.fixtures.Tokens.DefaultAccessToken)
+
+	rs := tReq.Do(t)
+
+	assert.Equal(t, http.StatusNotFound, rs.StatusCode)
+}
+
+func TestDeleteSessions(t *testing.T) {
+	testEnv, testApp := setup(t)
+	defer testEnv.teardown()
+
+	accessToken, _ := signInFrom(t, testApp, "Firefox")
+	_, _ = signInFrom(t, testApp, "Safari")
+
+	tReq := test.CreateRequestTester(
+		testApp.routes(),
+		http.MethodDelete,
+		"/current-user/sessions",
+	)
+	tReq.AddCookie(accessToken)
+
+	rs1 := tReq.Do(t)
+	assert.Equal(t, http.StatusOK, rs1.StatusCode)
+
+	rs2 := tReq.Do(t)
+	assert.Equal(t, http.StatusUnauthorized, rs2.StatusCode)
+
+	// tokens created before sessions existed are revoked as well
+	tReqMe := test.CreateRequestTester(
+		testApp.routes(),
+		http.MethodGet,
+		"/current-user",
+	)
+	tReqMe.AddCookie(testEnv.fixtures.Tokens.ManagerAccessToken)
+
+	rs3 := tReqMe.Do(t)
+	assert.Equal(t, http.StatusUnauthorized, rs3.StatusCode)
+}
+
+func TestUserSessions(t *testing.T) {
+	testEnv, testApp := setup(t)
+	defer testEnv.teardown()
+
+	managerAccessToken, _ := signInFrom(t, testApp, "Firefox")
+	session := getSessions(t, testApp, managerAccessToken)[0]
+
+	tReqGet := test.CreateRequestTester(
+		testApp.routes(),
+		http.MethodGet,
+		"/users/%s/sessions",
+		testEnv.fixtures.ManagerUser.ID,
+	)
+	tReqGet.AddCookie(testEnv.fixtures.Tokens.AdminAccessToken)
+
+	rs1 := tReqGet.Do(t)
+
+	var rsData []models.Session
+	err := httptools.ReadJSON(rs1.Body, &rsData)
+	require.Nil(t, err)
+
+	assert.Equal(t, http.StatusOK, rs1.StatusCode)
+	require.Equal(t, 1, len(rsData))
+	assert.Equal(t, session.ID, rsData[0].ID)
+	assert.Equal(t, false, rsData[0].Current)
+
+	tReqDelete := test.CreateRequestTester(
+		testApp.routes(),
+		http.MethodDelete,
+		"/users/%s/sessions/%s",
+		testEnv.fixtures.ManagerUser.ID,
+		session.ID,
+	)
+	tReqDelete.AddCookie(testEnv.fixtures.Tokens.AdminAccessToken)
+
+	rs2 := tReqDelete.Do(t)
+	assert.Equal(t, http.StatusOK, rs2.StatusCode)
+
+	tReqMe := test.CreateRequestTester(
+		testApp.routes(),
+		http.MethodGet,
+		"/current-user",
+	)
+	tReqMe.AddCookie(managerAccessToken)
+
+	rs3 := tReqMe.Do(t)
+	assert.Equal(t, http.StatusUnauthorized, rs3.StatusCode)
+}
+
+func TestDeleteUserSessions(t *testing.T) {
+	testEnv, testApp := setup(t)
+	defer testEnv.teardown()
+
+	managerAccessToken, _ := signInFrom(t, testApp, "Firefox")
+
+	tReq := test.CreateRequestTester(
+		testApp.routes(),
+		http.MethodDelete,
+		"/users/%s/sessions",
+		testEnv.fixtures.ManagerUser.ID,
+	)
+	tReq.AddCookie(testEnv.fixtures.Tokens.AdminAccessToken)
+
+	rs1 := tReq.Do(t)
+	assert.Equal(t, http.StatusOK, rs1.StatusCode)
+
+	tReqMe := test.CreateRequestTester(
+		testApp.routes(),
+		http.MethodGet,
+		"/current-user",
+	)
+	tReqMe.AddCookie(managerAccessToken)
+
+	rs2 := tReqMe.Do(t)
+	assert.Equal(t, http.StatusUnauthorized, rs2.StatusCode)
+
+	tReqAudit := test.CreateRequestTester(
+		testApp.routes(),
+		http.MethodGet,
+		"/audit",
+	)
+	tReqAudit.SetQuery(map[string][]string{
+		"entityType": {"session"},
+		"action":     {string(models.AuditDelete)},
+		"actorId":    {testEnv.fixtures.AdminUser.ID},
+	})
+	tReqAudit.AddCookie(testEnv.fixtures.Tokens.AdminAccessToken)
+
+	rs3 := tReqAudit.Do(t)
+
+	var rsData dtos.PaginatedAuditEventsDto
+	err := httptools.ReadJSON(rs3.Body, &rsData)
+	require.Nil(t, err)
+
+	assert.Equal(t, http.StatusOK, rs3.StatusCode)
+	assert.Equal(t, 1, len(rsData.Data))
+}
+
+func TestUserSessionsAccess(t *testing.T) {
+	testEnv, testApp := setup(t)
+	defer testEnv.teardown()
+
+	tReqBase := test.CreateRequestTester(
+		testApp.routes(),
+		http.MethodGet,
+		"/users/%s/sessions",
+		testEnv.fixtures.ManagerUser.ID,
+	)
+
+	mt := test.CreateMatrixTester()
+
+	mt.AddTestCase(tReqBase, test.NewCaseResponse(http.StatusUnauthorized, nil, nil))
+
+	tReq2 := tReqBase.Copy()
+	tReq2.AddCookie(testEnv.fixtures.Tokens.DefaultAccessToken)
+
+	mt.AddTestCase(tReq2, test.NewCaseResponse(http.StatusForbidden, nil, nil))
+
+	tReq3 := test.CreateRequestTester(
+		testApp.routes(),
+		http.MethodGet,
+		"/users/%s/sessions",
+		testEnv.fixtures.AdminUser.ID,
+	)
+	tReq3.AddCookie(testEnv.fixtures.Tokens.AdminAccessToken)
+
+	mt.AddTestCase(tReq3, test.NewCaseResponse(http.StatusNotFound, nil, nil))
+
+	mt.Do(t)
+}
@@ -15,7 +15,7 @@ type Service[T any] interface {
 		limit int64,
 		offset int64,
 	) ([]*T, error)
-	GetTotalCount(ctx context.Context) (*int64, error)
+	GetTotalCount(ctx context.Context, user *models.User) (*int64, error)
 }
 
 func getAllPaginated[T any](
@@ -30,7 +30,7 @@ func getAllPaginated[T any](
 		return nil, err
 	}
 
-	total, err := service.GetTotalCount(ctx)
+	total, err := service.GetTotalCount(ctx, user)
 	if err != nil {
 		return nil, err
 	}
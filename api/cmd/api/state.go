@@ -4,8 +4,12 @@ import (
 	"net/http"
 
 	httptools "github.com/XDoubleU/essentia/pkg/communication/http"
+	contexttools "github.com/XDoubleU/essentia/pkg/context"
+	"github.com/XDoubleU/essentia/pkg/parse"
 
+	"check-in/api/internal/constants"
 	"check-in/api/internal/dtos"
+	"check-in/api/internal/models"
 )
 
 func (app *Application) stateRoutes(mux *http.ServeMux) {
@@ -15,17 +19,32 @@ func (app *Application) stateRoutes(mux *http.ServeMux) {
 	)
 	mux.HandleFunc(
 		"PATCH /state",
-		app.authAccess(adminRole, app.updateStateHandler),
+		app.authAccess(models.StateWritePermission, app.updateStateHandler),
 	)
 }
 
-// @Summary	Get current state
+// @Summary	Get current state of an organisation
 // @Tags		state
-// @Success	200	{object}	State
-// @Failure	500	{object}	ErrorDto
+// @Param		organisationId	query		string	true	"Organisation ID"
+// @Success	200				{object}	State
+// @Failure	400				{object}	ErrorDto
+// @Failure	404				{object}	ErrorDto
+// @Failure	500				{object}	ErrorDto
 // @Router		/state [get].
 func (app *Application) getStateHandler(w http.ResponseWriter, r *http.Request) {
-	err := httptools.WriteJSON(w, http.StatusOK, app.services.State.Current.Get(), nil)
+	organisationID, err := parse.RequiredQueryParam(r, "organisationId", parse.UUID)
+	if err != nil {
+		httptools.BadRequestResponse(w, r, err)
+		return
+	}
+
+	state, err := app.services.State.Get(organisationID)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	err = httptools.WriteJSON(w, http.StatusOK, state, nil)
 	if err != nil {
 		httptools.ServerErrorResponse(w, r, err)
 	}
@@ -35,6 +54,9 @@ func (app *Application) getStateHandler(w http.ResponseWriter, r *http.Request)
 // @Tags		state
 // @Param		stateDto	body		StateDto	true	"StateDto"
 // @Success	200			{object}	State
+// @Failure	400			{object}	ErrorDto
+// @Failure	401			{object}	ErrorDto
+// @Failure	422			{object}	ErrorDto
 // @Failure	500			{object}	ErrorDto
 // @Router		/state [patch].
 func (app *Application) updateStateHandler(w http.ResponseWriter, r *http.Request) {
@@ -46,9 +68,20 @@ func (app *Application) updateStateHandler(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
-	state, err := app.services.State.UpdateState(r.Context(), stateDto)
+	if v, validationErrors := stateDto.Validate(); !v {
+		httptools.FailedValidationResponse(w, r, validationErrors)
+		return
+	}
+
+	user := contexttools.GetValue[models.User](r.Context(), constants.UserContextKey)
+	state, err := app.services.State.UpdateState(
+		r.Context(),
+		user.OrganisationID,
+		stateDto,
+	)
 	if err != nil {
 		httptools.HandleError(w, r, err)
+		return
 	}
 
 	err = httptools.WriteJSON(w, http.StatusOK, state, nil)
@@ -1,10 +1,13 @@
 package main
 
 import (
+	"context"
 	"net/http"
 	"testing"
+	"time"
 
 	httptools "github.com/XDoubleU/essentia/pkg/communication/http"
+	errortools "github.com/XDoubleU/essentia/pkg/errors"
 	"github.com/XDoubleU/essentia/pkg/test"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -22,6 +25,9 @@ func TestGetState(t *testing.T) {
 		http.MethodGet,
 		"/state",
 	)
+	tReq.SetQuery(map[string][]string{
+		"organisationId": {testEnv.fixtures.Organisation.ID},
+	})
 	rs := tReq.Do(t)
 
 	var rsData models.State
@@ -33,6 +39,32 @@ func TestGetState(t *testing.T) {
 	assert.Equal(t, true, rsData.IsDatabaseActive)
 }
 
+func TestGetStateUnknownOrganisation(t *testing.T) {
+	testEnv, testApp := setup(t)
+	defer testEnv.teardown()
+
+	mt := test.CreateMatrixTester()
+
+	tReq1 := test.CreateRequestTester(
+		testApp.routes(),
+		http.MethodGet,
+		"/state",
+	)
+	mt.AddTestCase(tReq1, test.NewCaseResponse(http.StatusBadRequest, nil, nil))
+
+	tReq2 := test.CreateRequestTester(
+		testApp.routes(),
+		http.MethodGet,
+		"/state",
+	)
+	tReq2.SetQuery(map[string][]string{
+		"organisationId": {"00000000-0000-0000-0000-000000000000"},
+	})
+	mt.AddTestCase(tReq2, test.NewCaseResponse(http.StatusNotFound, nil, nil))
+
+	mt.Do(t)
+}
+
 func TestUpdateState(t *testing.T) {
 	testEnv, testApp := setup(t)
 	defer testEnv.teardown()
@@ -61,6 +93,169 @@ func TestUpdateState(t *testing.T) {
 	assert.Equal(t, true, rsData.IsDatabaseActive)
 }
 
+func TestUpdateStateMaintenanceWindow(t *testing.T) {
+	testEnv, testApp := setup(t)
+	defer testEnv.teardown()
+
+	start := testApp.getTimeNowUTC().Add(-time.Hour)
+	end := testApp.getTimeNowUTC().Add(time.Hour)
+
+	tReq := test.CreateRequestTester(
+		testApp.routes(),
+		http.MethodPatch,
+		"/state",
+	)
+	tReq.AddCookie(testEnv.fixtures.Tokens.AdminAccessToken)
+
+	tReq.SetData(dtos.StateDto{
+		IsMaintenance:    false,
+		MaintenanceStart: &start,
+		MaintenanceEnd:   &end,
+	})
+
+	rs := tReq.Do(t)
+
+	var rsData models.State
+	err := httptools.ReadJSON(rs.Body, &rsData)
+	require.Nil(t, err)
+
+	assert.Equal(t, http.StatusOK, rs.StatusCode)
+	assert.Equal(t, true, rsData.IsMaintenance)
+	assert.Equal(t, false, rsData.MaintenanceStart.Valid)
+	assert.Equal(t, true, rsData.MaintenanceEnd.Valid)
+	assert.Equal(t, end.Unix(), rsData.MaintenanceEnd.Time.Unix())
+}
+
+func TestUpdateStateMaintenanceWindowEnded(t *testing.T) {
+	testEnv, testApp := setup(t)
+	defer testEnv.teardown()
+
+	start := testApp.getTimeNowUTC().Add(-2 * time.Hour)
+	end := testApp.getTimeNowUTC().Add(-time.Hour)
+
+	tReq := test.CreateRequestTester(
+		testApp.routes(),
+		http.MethodPatch,
+		"/state",
+	)
+	tReq.AddCookie(testEnv.fixtures.Tokens.AdminAccessToken)
+
+	tReq.SetData(dtos.StateDto{
+		IsMaintenance:    true,
+		MaintenanceStart: &start,
+		MaintenanceEnd:   &end,
+	})
+
+	rs := tReq.Do(t)
+
+	var rsData models.State
+	err := httptools.ReadJSON(rs.Body, &rsData)
+	require.Nil(t, err)
+
+	assert.Equal(t, http.StatusOK, rs.StatusCode)
+	assert.Equal(t, false, rsData.IsMaintenance)
+	assert.Equal(t, false, rsData.MaintenanceStart.Valid)
+	assert.Equal(t, false, rsData.MaintenanceEnd.Valid)
+}
+
+func TestUpdateStateFailValidation(t *testing.T) {
+	testEnv, testApp := setup(t)
+	defer testEnv.teardown()
+
+	start := testApp.getTimeNowUTC().Add(time.Hour)
+	end := testApp.getTimeNowUTC()
+
+	tReq := test.CreateRequestTester(
+		testApp.routes(),
+		http.MethodPatch,
+		"/state",
+	)
+	tReq.AddCookie(testEnv.fixtures.Tokens.AdminAccessToken)
+
+	tReq.SetData(dtos.StateDto{
+		IsMaintenance:    false,
+		MaintenanceStart: &start,
+		MaintenanceEnd:   &end,
+	})
+
+	rs := tReq.Do(t)
+
+	var rsData errortools.ErrorDto
+	err := httptools.ReadJSON(rs.Body, &rsData)
+	require.Nil(t, err)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, rs.StatusCode)
+	assert.Equal(t, map[string]interface{}{
+		"maintenanceEnd": "must be after maintenanceStart",
+	}, rsData.Message)
+}
+
+func TestMaintenanceModeWrites(t *testing.T) {
+	testEnv, testApp := setup(t)
+	defer testEnv.teardown()
+
+	//nolint:exhaustruct //other fields are optional
+	_, err := testApp.services.State.UpdateState(
+		context.Background(),
+		testEnv.fixtures.Organisation.ID,
+		dtos.StateDto{
+			IsMaintenance: true,
+		})
+	require.Nil(t, err)
+
+	mt := test.CreateMatrixTester()
+
+	tReq1 := test.CreateRequestTester(
+		testApp.routes(),
+		http.MethodPost,
+		"/checkins",
+	)
+	tReq1.AddCookie(testEnv.fixtures.Tokens.DefaultAccessToken)
+	tReq1.SetData(dtos.CreateCheckInDto{
+		SchoolID: 1,
+	})
+	mt.AddTestCase(
+		tReq1,
+		test.NewCaseResponse(http.StatusServiceUnavailable, nil, nil),
+	)
+
+	tReq2 := test.CreateRequestTester(
+		testApp.routes(),
+		http.MethodPost,
+		"/schools",
+	)
+	tReq2.AddCookie(testEnv.fixtures.Tokens.ManagerAccessToken)
+	tReq2.SetData(dtos.SchoolDto{
+		Name: "MaintenanceSchool",
+	})
+	mt.AddTestCase(
+		tReq2,
+		test.NewCaseResponse(http.StatusServiceUnavailable, nil, nil),
+	)
+
+	tReq3 := test.CreateRequestTester(
+		testApp.routes(),
+		http.MethodPost,
+		"/schools",
+	)
+	tReq3.AddCookie(testEnv.fixtures.Tokens.AdminAccessToken)
+	tReq3.SetData(dtos.SchoolDto{
+		Name: "MaintenanceSchool",
+	})
+	mt.AddTestCase(tReq3, test.NewCaseResponse(http.StatusCreated, nil, nil))
+
+	tReq4 := test.CreateRequestTester(
+		testApp.routes(),
+		http.MethodGet,
+		"/locations/%s",
+		testEnv.fixtures.DefaultLocation.ID,
+	)
+	tReq4.AddCookie(testEnv.fixtures.Tokens.DefaultAccessToken)
+	mt.AddTestCase(tReq4, test.NewCaseResponse(http.StatusOK, nil, nil))
+
+	mt.Do(t)
+}
+
 func TestUpdateStateAccess(t *testing.T) {
 	testEnv, testApp := setup(t)
 	defer testEnv.teardown()
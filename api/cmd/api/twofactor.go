@@ -0,0 +1,159 @@
+package main
+
+import (
+	"net/http"
+
+	httptools "github.com/XDoubleU/essentia/pkg/communication/http"
+	"github.com/XDoubleU/essentia/pkg/context"
+	"github.com/XDoubleU/essentia/pkg/parse"
+
+	"check-in/api/internal/constants"
+	"check-in/api/internal/dtos"
+	"check-in/api/internal/models"
+)
+
+func (app *Application) twoFactorRoutes(mux *http.ServeMux) {
+	mux.HandleFunc(
+		"POST /current-user/two-factor",
+		app.authAccess(signedIn, app.enrolTwoFactorHandler),
+	)
+	mux.HandleFunc(
+		"POST /current-user/two-factor/enable",
+		app.authAccess(signedIn, app.enableTwoFactorHandler),
+	)
+	mux.HandleFunc(
+		"POST /current-user/two-factor/disable",
+		app.authAccess(signedIn, app.disableTwoFactorHandler),
+	)
+	mux.HandleFunc(
+		"DELETE /users/{id}/two-factor",
+		app.authAccess(models.UsersWritePermission, app.resetTwoFactorHandler),
+	)
+}
+
+// @Summary	Enrol in two-factor authentication, replaces a pending enrolment
+// @Tags		users
+// @Success	200	{object}	TwoFactorEnrolment
+// @Failure	400	{object}	ErrorDto
+// @Failure	401	{object}	ErrorDto
+// @Failure	500	{object}	ErrorDto
+// @Router		/current-user/two-factor [post].
+func (app *Application) enrolTwoFactorHandler(w http.ResponseWriter, r *http.Request) {
+	user := context.GetValue[models.User](r.Context(), constants.UserContextKey)
+	enrolment, err := app.services.TwoFactor.Enrol(r.Context(), user)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	err = httptools.WriteJSON(w, http.StatusOK, enrolment, nil)
+	if err != nil {
+		httptools.ServerErrorResponse(w, r, err)
+	}
+}
+
+// @Summary	Enable two-factor authentication, the recovery codes are only returned once
+// @Tags		users
+// @Param		twoFactorCodeDto	body		TwoFactorCodeDto	true	"TwoFactorCodeDto"
+// @Success	200					{object}	RecoveryCodes
+// @Failure	400					{object}	ErrorDto
+// @Failure	401					{object}	ErrorDto
+// @Failure	422					{object}	ErrorDto
+// @Failure	500					{object}	ErrorDto
+// @Router		/current-user/two-factor/enable [post].
+func (app *Application) enableTwoFactorHandler(w http.ResponseWriter, r *http.Request) {
+	var twoFactorCodeDto dtos.TwoFactorCodeDto
+
+	err := httptools.ReadJSON(r.Body, &twoFactorCodeDto)
+	if err != nil {
+		httptools.BadRequestResponse(w, r, err)
+		return
+	}
+
+	if v, validationErrors := twoFactorCodeDto.Validate(); !v {
+		httptools.FailedValidationResponse(w, r, validationErrors)
+		return
+	}
+
+	user := context.GetValue[models.User](r.Context(), constants.UserContextKey)
+	recoveryCodes, err := app.services.TwoFactor.Enable(
+		r.Context(),
+		user,
+		twoFactorCodeDto.Code,
+	)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	err = httptools.WriteJSON(w, http.StatusOK, recoveryCodes, nil)
+	if err != nil {
+		httptools.ServerErrorResponse(w, r, err)
+	}
+}
+
+// @Summary	Disable two-factor authentication
+// @Tags		users
+// @Param		twoFactorCodeDto	body		TwoFactorCodeDto	true	"TwoFactorCodeDto"
+// @Success	200					{object}	nil
+// @Failure	400					{object}	ErrorDto
+// @Failure	401					{object}	ErrorDto
+// @Failure	422					{object}	ErrorDto
+// @Failure	500					{object}	ErrorDto
+// @Router		/current-user/two-factor/disable [post].
+func (app *Application) disableTwoFactorHandler(
+	w http.ResponseWriter,
+	r *http.Request,
+) {
+	var twoFactorCodeDto dtos.TwoFactorCodeDto
+
+	err := httptools.ReadJSON(r.Body, &twoFactorCodeDto)
+	if err != nil {
+		httptools.BadRequestResponse(w, r, err)
+		return
+	}
+
+	if v, validationErrors := twoFactorCodeDto.Validate(); !v {
+		httptools.FailedValidationResponse(w, r, validationErrors)
+		return
+	}
+
+	user := context.GetValue[models.User](r.Context(), constants.UserContextKey)
+	err = app.services.TwoFactor.Disable(r.Context(), user, twoFactorCodeDto.Code)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+	}
+}
+
+// @Summary	Reset two-factor authentication of a manager user
+// @Tags		users
+// @Param		id	path		string	true	"User ID"
+// @Success	200	{object}	User
+// @Failure	400	{object}	ErrorDto
+// @Failure	401	{object}	ErrorDto
+// @Failure	404	{object}	ErrorDto
+// @Failure	500	{object}	ErrorDto
+// @Router		/users/{id}/two-factor [delete].
+func (app *Application) resetTwoFactorHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := parse.URLParam(r, "id", parse.UUID)
+	if err != nil {
+		httptools.BadRequestResponse(w, r, err)
+		return
+	}
+
+	currentUser := context.GetValue[models.User](r.Context(), constants.UserContextKey)
+	user, err := app.services.TwoFactor.Reset(
+		r.Context(),
+		currentUser.OrganisationID,
+		id,
+	)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	err = httptools.WriteJSON(w, http.StatusOK, user, nil)
+	if err != nil {
+		httptools.ServerErrorResponse(w, r, err)
+	}
+}
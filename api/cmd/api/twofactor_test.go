@@ -0,0 +1,364 @@
+package main
+
+import (
+	"encoding/base32"
+	"net/http"
+	"strings"
+	"testing"
+
+	httptools "github.com/XDoubleU/essentia/pkg/communication/http"
+	"github.com/XDoubleU/essentia/pkg/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"check-in/api/internal/dtos"
+	"check-in/api/internal/models"
+	"check-in/api/internal/totp"
+)
+
+func (env *TestEnv) enableTwoFactor(user *models.User) ([]byte, []string) {
+	enrolment, err := env.app.services.TwoFactor.Enrol(env.ctx, user)
+	if err != nil {
+		panic(err)
+	}
+
+	secret, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(
+		enrolment.Secret,
+	)
+	if err != nil {
+		panic(err)
+	}
+
+	recoveryCodes, err := env.app.services.TwoFactor.Enable(
+		env.ctx,
+		user,
+		totp.Code(secret, totp.Step(env.app.getTimeNowUTC())),
+	)
+	if err != nil {
+		panic(err)
+	}
+
+	return secret, recoveryCodes.RecoveryCodes
+}
+
+func TestEnrolTwoFactor(t *testing.T) {
+	testEnv, testApp := setup(t)
+	defer testEnv.teardown()
+
+	tReq := test.CreateRequestTester(
+		testApp.routes(),
+		http.MethodPost,
+		"/current-user/two-factor",
+	)
+	tReq.AddCookie(testEnv.fixtures.Tokens.AdminAccessToken)
+
+	rs := tReq.Do(t)
+
+	var rsData models.TwoFactorEnrolment
+	err := httptools.ReadJSON(rs.Body, &rsData)
+	require.Nil(t, err)
+
+	assert.Equal(t, http.StatusOK, rs.StatusCode)
+	assert.Equal(t, true, strings.HasPrefix(rsData.URI, "otpauth://totp/"))
+	assert.Contains(t, rsData.URI, "secret="+rsData.Secret)
+	assert.Contains(t, rsData.URI, "Admin")
+}
+
+func TestEnrolTwoFactorDefaultUser(t *testing.T) {
+	testEnv, testApp := setup(t)
+	defer testEnv.teardown()
+
+	tReq := test.CreateRequestTester(
+		testApp.routes(),
+		http.MethodPost,
+		"/current-user/two-factor",
+	)
+	tReq.AddCookie(testEnv.fixtures.Tokens.DefaultAccessToken)
+
+	rs := tReq.Do(t)
+
+	assert.Equal(t, http.StatusBadRequest, rs.StatusCode)
+}
+
+func TestEnableTwoFactor(t *testing.T) {
+	testEnv, testApp := setup(t)
+	defer testEnv.teardown()
+
+	enrolment, err := testApp.services.TwoFactor.Enrol(
+		testEnv.ctx,
+		testEnv.fixtures.ManagerUser,
+	)
+	require.Nil(t, err)
+
+	secret, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(
+		enrolment.Secret,
+	)
+	require.Nil(t, err)
+
+	tReq := test.CreateRequestTester(
+		testApp.routes(),
+		http.MethodPost,
+		"/current-user/two-factor/enable",
+	)
+	tReq.AddCookie(testEnv.fixtures.Tokens.ManagerAccessToken)
+	tReq.SetData(dtos.TwoFactorCodeDto{
+		Code: totp.Code(secret, totp.Step(testApp.getTimeNowUTC())),
+	})
+
+	rs := tReq.Do(t)
+
+	var rsData models.RecoveryCodes
+	err = httptools.ReadJSON(rs.Body, &rsData)
+	require.Nil(t, err)
+
+	assert.Equal(t, http.StatusOK, rs.StatusCode)
+	assert.Equal(t, 10, len(rsData.RecoveryCodes))
+
+	tReqUser := test.CreateRequestTester(
+		testApp.routes(),
+		http.MethodGet,
+		"/current-user",
+	)
+	tReqUser.AddCookie(testEnv.fixtures.Tokens.ManagerAccessToken)
+
+	rs = tReqUser.Do(t)
+
+	var rsUser models.User
+	err = httptools.ReadJSON(rs.Body, &rsUser)
+	require.Nil(t, err)
+
+	assert.Equal(t, true, rsUser.TwoFactorEnabled)
+}
+
+func TestEnableTwoFactorInvalidCode(t *testing.T) {
+	testEnv, testApp := setup(t)
+	defer testEnv.teardown()
+
+	_, err := testApp.services.TwoFactor.Enrol(
+		testEnv.ctx,
+		testEnv.fixtures.ManagerUser,
+	)
+	require.Nil(t, err)
+
+	tReq := test.CreateRequestTester(
+		testApp.routes(),
+		http.MethodPost,
+		"/current-user/two-factor/enable",
+	)
+	tReq.AddCookie(testEnv.fixtures.Tokens.ManagerAccessToken)
+	tReq.SetData(dtos.TwoFactorCodeDto{
+		Code: "000000",
+	})
+
+	rs := tReq.Do(t)
+
+	assert.Equal(t, http.StatusBadRequest, rs.StatusCode)
+}
+
+func TestSignInTwoFactor(t *testing.T) {
+	testEnv, testApp := setup(t)
+	defer testEnv.teardown()
+
+	secret, _ := testEnv.enableTwoFactor(testEnv.fixtures.AdminUser)
+
+	tReq := test.CreateRequestTester(
+		testApp.routes(),
+		http.MethodPost,
+		"/auth/signin",
+	)
+	tReq.SetData(dtos.SignInDto{
+		Username:   "Admin",
+		Password:   "testpassword",
+		RememberMe: false,
+		Code:       "",
+	})
+
+	rs := tReq.Do(t)
+
+	var rsPending dtos.SignInPendingDto
+	err := httptools.ReadJSON(rs.Body, &rsPending)
+	require.Nil(t, err)
+
+	assert.Equal(t, http.StatusAccepted, rs.StatusCode)
+	assert.Equal(t, true, rsPending.TwoFactorPending)
+	assert.Equal(t, 0, len(rs.Header.Values("set-cookie")))
+
+	// the code of the current step was used to enable two-factor authentication
+	code := totp.Code(secret, totp.Step(testApp.getTimeNowUTC())+1)
+
+	tReqCode := test.CreateRequestTester(
+		testApp.routes(),
+		http.MethodPost,
+		"/auth/signin",
+	)
+	tReqCode.SetData(dtos.SignInDto{
+		Username:   "Admin",
+		Password:   "testpassword",
+		RememberMe: false,
+		Code:       code,
+	})
+
+	rs = tReqCode.Do(t)
+
+	var rsUser models.User
+	err = httptools.ReadJSON(rs.Body, &rsUser)
+	require.Nil(t, err)
+
+	assert.Equal(t, http.StatusOK, rs.StatusCode)
+	assert.Equal(t, true, rsUser.TwoFactorEnabled)
+
+	rs = tReqCode.Do(t)
+	assert.Equal(t, http.StatusUnauthorized, rs.StatusCode)
+}
+
+func TestSignInTwoFactorRecoveryCode(t *testing.T) {
+	testEnv, testApp := setup(t)
+	defer testEnv.teardown()
+
+	_, recoveryCodes := testEnv.enableTwoFactor(testEnv.fixtures.AdminUser)
+
+	tReq := test.CreateRequestTester(
+		testApp.routes(),
+		http.MethodPost,
+		"/auth/signin",
+	)
+	tReq.SetData(dtos.SignInDto{
+		Username:   "Admin",
+		Password:   "testpassword",
+		RememberMe: false,
+		Code:       strings.ToLower(recoveryCodes[0]),
+	})
+
+	rs1 := tReq.Do(t)
+	assert.Equal(t, http.StatusOK, rs1.StatusCode)
+
+	rs2 := tReq.Do(t)
+	assert.Equal(t, http.StatusUnauthorized, rs2.StatusCode)
+}
+
+func TestTwoFactorRequired(t *testing.T) {
+	testEnv, testApp := setup(t)
+	defer testEnv.teardown()
+
+	required := true
+
+	tReqUpdate := test.CreateRequestTester(
+		testApp.routes(),
+		http.MethodPatch,
+		"/users/%s",
+		testEnv.fixtures.ManagerUser.ID,
+	)
+	tReqUpdate.AddCookie(testEnv.fixtures.Tokens.AdminAccessToken)
+	tReqUpdate.SetData(dtos.UpdateUserDto{
+		Username:          nil,
+		Password:          nil,
+		TwoFactorRequired: &required,
+	})
+
+	rs := tReqUpdate.Do(t)
+
+	var rsUser models.User
+	err := httptools.ReadJSON(rs.Body, &rsUser)
+	require.Nil(t, err)
+
+	assert.Equal(t, http.StatusOK, rs.StatusCode)
+	assert.Equal(t, true, rsUser.TwoFactorRequired)
+
+	tReqLocations := test.CreateRequestTester(
+		testApp.routes(),
+		http.MethodGet,
+		"/locations",
+	)
+	tReqLocations.AddCookie(testEnv.fixtures.Tokens.ManagerAccessToken)
+
+	rs = tReqLocations.Do(t)
+	assert.Equal(t, http.StatusForbidden, rs.StatusCode)
+
+	tReqEnrol := test.CreateRequestTester(
+		testApp.routes(),
+		http.MethodPost,
+		"/current-user/two-factor",
+	)
+	tReqEnrol.AddCookie(testEnv.fixtures.Tokens.ManagerAccessToken)
+
+	rs = tReqEnrol.Do(t)
+	assert.Equal(t, http.StatusOK, rs.StatusCode)
+
+	testEnv.enableTwoFactor(testEnv.fixtures.ManagerUser)
+
+	rs = tReqLocations.Do(t)
+	assert.Equal(t, http.StatusOK, rs.StatusCode)
+}
+
+func TestDisableTwoFactor(t *testing.T) {
+	testEnv, testApp := setup(t)
+	defer testEnv.teardown()
+
+	_, recoveryCodes := testEnv.enableTwoFactor(testEnv.fixtures.ManagerUser)
+
+	tReq := test.CreateRequestTester(
+		testApp.routes(),
+		http.MethodPost,
+		"/current-user/two-factor/disable",
+	)
+	tReq.AddCookie(testEnv.fixtures.Tokens.ManagerAccessToken)
+	tReq.SetData(dtos.TwoFactorCodeDto{
+		Code: recoveryCodes[0],
+	})
+
+	rs1 := tReq.Do(t)
+	assert.Equal(t, http.StatusOK, rs1.StatusCode)
+
+	rs2 := tReq.Do(t)
+	assert.Equal(t, http.StatusBadRequest, rs2.StatusCode)
+}
+
+func TestResetTwoFactor(t *testing.T) {
+	testEnv, testApp := setup(t)
+	defer testEnv.teardown()
+
+	testEnv.enableTwoFactor(testEnv.fixtures.ManagerUser)
+
+	tReq := test.CreateRequestTester(
+		testApp.routes(),
+		http.MethodDelete,
+		"/users/%s/two-factor",
+		testEnv.fixtures.ManagerUser.ID,
+	)
+	tReq.AddCookie(testEnv.fixtures.Tokens.AdminAccessToken)
+
+	rs1 := tReq.Do(t)
+
+	var rsData models.User
+	err := httptools.ReadJSON(rs1.Body, &rsData)
+	require.Nil(t, err)
+
+	assert.Equal(t, http.StatusOK, rs1.StatusCode)
+	assert.Equal(t, false, rsData.TwoFactorEnabled)
+
+	rs2 := tReq.Do(t)
+	assert.Equal(t, http.StatusBadRequest, rs2.StatusCode)
+}
+
+func TestResetTwoFactorAccess(t *testing.T) {
+	testEnv, testApp := setup(t)
+	defer testEnv.teardown()
+
+	tReqBase := test.CreateRequestTester(
+		testApp.routes(),
+		http.MethodDelete,
+		"/users/%s/two-factor",
+		testEnv.fixtures.ManagerUser.ID,
+	)
+
+	mt := test.CreateMatrixTester()
+
+	mt.AddTestCase(tReqBase, test.NewCaseResponse(http.StatusUnauthorized, nil, nil))
+
+	tReq2 := tReqBase.Copy()
+	tReq2.AddCookie(testEnv.fixtures.Tokens.ManagerAccessToken)
+
+	mt.AddTestCase(tReq2, test.NewCaseResponse(http.StatusForbidden, nil, nil))
+
+	mt.Do(t)
+}
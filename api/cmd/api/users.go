@@ -15,27 +15,43 @@ import (
 func (app *Application) usersRoutes(mux *http.ServeMux) {
 	mux.HandleFunc(
 		"GET /current-user",
-		app.authAccess(allRoles, app.getInfoLoggedInUserHandler),
+		app.authAccess(signedIn, app.getInfoLoggedInUserHandler),
 	)
 	mux.HandleFunc(
 		"GET /users",
-		app.authAccess(adminRole, app.getPaginatedManagerUsersHandler),
+		app.authAccess(models.UsersListPermission, app.getPaginatedManagerUsersHandler),
 	)
 	mux.HandleFunc(
 		"GET /users/{id}",
-		app.authAccess(managerAndAdminRole, app.getUserHandler),
+		app.authAccess(models.UsersReadPermission, app.getUserHandler),
 	)
 	mux.HandleFunc(
 		"POST /users",
-		app.authAccess(adminRole, app.createManagerUserHandler),
+		app.authAccess(models.UsersWritePermission, app.createManagerUserHandler),
 	)
 	mux.HandleFunc(
 		"PATCH /users/{id}",
-		app.authAccess(adminRole, app.updateManagerUserHandler),
+		app.authAccess(models.UsersWritePermission, app.updateManagerUserHandler),
 	)
 	mux.HandleFunc(
 		"DELETE /users/{id}",
-		app.authAccess(adminRole, app.deleteManagerUserHandler),
+		app.authAccess(models.UsersWritePermission, app.deleteManagerUserHandler),
+	)
+	mux.HandleFunc(
+		"POST /users/{id}/restore",
+		app.authAccess(models.UsersWritePermission, app.restoreManagerUserHandler),
+	)
+	mux.HandleFunc(
+		"GET /users/{id}/locations",
+		app.authAccess(models.UsersListPermission, app.getManagerLocationsHandler),
+	)
+	mux.HandleFunc(
+		"POST /users/{id}/locations/{locationId}",
+		app.authAccess(models.UsersWritePermission, app.assignManagerLocationHandler),
+	)
+	mux.HandleFunc(
+		"DELETE /users/{id}/locations/{locationId}",
+		app.authAccess(models.UsersWritePermission, app.unassignManagerLocationHandler),
 	)
 }
 
@@ -71,7 +87,12 @@ func (app *Application) getUserHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	user, err := app.services.Locations.GetDefaultUserByUserID(r.Context(), id)
+	currentUser := context.GetValue[models.User](r.Context(), constants.UserContextKey)
+	user, err := app.services.Locations.GetDefaultUserByUserID(
+		r.Context(),
+		currentUser.OrganisationID,
+		id,
+	)
 	if err != nil {
 		httptools.HandleError(w, r, err)
 		return
@@ -146,8 +167,10 @@ func (app *Application) createManagerUserHandler(
 		return
 	}
 
+	currentUser := context.GetValue[models.User](r.Context(), constants.UserContextKey)
 	user, err := app.services.Users.Create(
 		r.Context(),
+		currentUser.OrganisationID,
 		createUserDto,
 		models.ManagerRole,
 	)
@@ -195,8 +218,10 @@ func (app *Application) updateManagerUserHandler(
 		return
 	}
 
+	currentUser := context.GetValue[models.User](r.Context(), constants.UserContextKey)
 	user, err := app.services.Users.Update(
 		r.Context(),
+		currentUser.OrganisationID,
 		id,
 		updateUserDto,
 		models.ManagerRole,
@@ -231,7 +256,13 @@ func (app *Application) deleteManagerUserHandler(
 		return
 	}
 
-	user, err := app.services.Users.Delete(r.Context(), id, models.ManagerRole)
+	currentUser := context.GetValue[models.User](r.Context(), constants.UserContextKey)
+	user, err := app.services.Users.Delete(
+		r.Context(),
+		currentUser.OrganisationID,
+		id,
+		models.ManagerRole,
+	)
 	if err != nil {
 		httptools.HandleError(w, r, err)
 		return
@@ -242,3 +273,165 @@ func (app *Application) deleteManagerUserHandler(
 		httptools.ServerErrorResponse(w, r, err)
 	}
 }
+
+// @Summary	Restore deleted user
+// @Tags		users
+// @Param		id	path		string	true	"User ID"
+// @Success	200	{object}	User
+// @Failure	400	{object}	ErrorDto
+// @Failure	401	{object}	ErrorDto
+// @Failure	404	{object}	ErrorDto
+// @Failure	500	{object}	ErrorDto
+// @Router		/users/{id}/restore [post].
+func (app *Application) restoreManagerUserHandler(
+	w http.ResponseWriter,
+	r *http.Request,
+) {
+	id, err := parse.URLParam(r, "id", parse.UUID)
+	if err != nil {
+		httptools.BadRequestResponse(w, r, err)
+		return
+	}
+
+	currentUser := context.GetValue[models.User](r.Context(), constants.UserContextKey)
+	user, err := app.services.Users.Restore(
+		r.Context(),
+		currentUser.OrganisationID,
+		id,
+		models.ManagerRole,
+	)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	err = httptools.WriteJSON(w, http.StatusOK, user, nil)
+	if err != nil {
+		httptools.ServerErrorResponse(w, r, err)
+	}
+}
+
+// @Summary	Get locations assigned to manager
+// @Tags		users
+// @Param		id	path		string	true	"User ID"
+// @Success	200	{object}	[]Location
+// @Failure	400	{object}	ErrorDto
+// @Failure	401	{object}	ErrorDto
+// @Failure	404	{object}	ErrorDto
+// @Failure	500	{object}	ErrorDto
+// @Router		/users/{id}/locations [get].
+func (app *Application) getManagerLocationsHandler(
+	w http.ResponseWriter,
+	r *http.Request,
+) {
+	id, err := parse.URLParam(r, "id", parse.UUID)
+	if err != nil {
+		httptools.BadRequestResponse(w, r, err)
+		return
+	}
+
+	currentUser := context.GetValue[models.User](r.Context(), constants.UserContextKey)
+	locations, err := app.services.Locations.GetAllOfManager(
+		r.Context(),
+		currentUser,
+		id,
+	)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	err = httptools.WriteJSON(w, http.StatusOK, locations, nil)
+	if err != nil {
+		httptools.ServerErrorResponse(w, r, err)
+	}
+}
+
+// @Summary	Assign location to manager
+// @Tags		users
+// @Param		id			path		string	true	"User ID"
+// @Param		locationId	path		string	true	"Location ID"
+// @Success	201			{object}	Location
+// @Failure	400			{object}	ErrorDto
+// @Failure	401			{object}	ErrorDto
+// @Failure	404			{object}	ErrorDto
+// @Failure	409			{object}	ErrorDto
+// @Failure	500			{object}	ErrorDto
+// @Router		/users/{id}/locations/{locationId} [post].
+func (app *Application) assignManagerLocationHandler(
+	w http.ResponseWriter,
+	r *http.Request,
+) {
+	id, err := parse.URLParam(r, "id", parse.UUID)
+	if err != nil {
+		httptools.BadRequestResponse(w, r, err)
+		return
+	}
+
+	locationID, err := parse.URLParam(r, "locationId", parse.UUID)
+	if err != nil {
+		httptools.BadRequestResponse(w, r, err)
+		return
+	}
+
+	currentUser := context.GetValue[models.User](r.Context(), constants.UserContextKey)
+	location, err := app.services.Locations.AssignManager(
+		r.Context(),
+		currentUser,
+		id,
+		locationID,
+	)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	err = httptools.WriteJSON(w, http.StatusCreated, location, nil)
+	if err != nil {
+		httptools.ServerErrorResponse(w, r, err)
+	}
+}
+
+// @Summary	Unassign location from manager
+// @Tags		users
+// @Param		id			path		string	true	"User ID"
+// @Param		locationId	path		string	true	"Location ID"
+// @Success	200			{object}	Location
+// @Failure	400			{object}	ErrorDto
+// @Failure	401			{object}	ErrorDto
+// @Failure	404			{object}	ErrorDto
+// @Failure	500			{object}	ErrorDto
+// @Router		/users/{id}/locations/{locationId} [delete].
+func (app *Application) unassignManagerLocationHandler(
+	w http.ResponseWriter,
+	r *http.Request,
+) {
+	id, err := parse.URLParam(r, "id", parse.UUID)
+	if err != nil {
+		httptools.BadRequestResponse(w, r, err)
+		return
+	}
+
+	locationID, err := parse.URLParam(r, "locationId", parse.UUID)
+	if err != nil {
+		httptools.BadRequestResponse(w, r, err)
+		return
+	}
+
+	currentUser := context.GetValue[models.User](r.Context(), constants.UserContextKey)
+	location, err := app.services.Locations.UnassignManager(
+		r.Context(),
+		currentUser,
+		id,
+		locationID,
+	)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	err = httptools.WriteJSON(w, http.StatusOK, location, nil)
+	if err != nil {
+		httptools.ServerErrorResponse(w, r, err)
+	}
+}
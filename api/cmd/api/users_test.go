@@ -120,6 +120,7 @@ func TestGetUser(t *testing.T) {
 	userID := testEnv.createLocations(1)[0].UserID
 	defaultUser, _ := testApp.services.Locations.GetDefaultUserByUserID(
 		context.Background(),
+		testEnv.fixtures.Organisation.ID,
 		userID,
 	)
 
@@ -247,7 +248,10 @@ func TestGetPaginatedManagerUsersDefaultPage(t *testing.T) {
 
 	testEnv.createManagerUsers(20)
 
-	amount, err := testApp.services.Users.GetTotalCount(context.Background())
+	amount, err := testApp.services.Users.GetTotalCount(
+		context.Background(),
+		testEnv.fixtures.AdminUser,
+	)
 	require.Nil(t, err)
 
 	tReq := test.CreateRequestTester(
@@ -286,7 +290,10 @@ func TestGetPaginatedManagerUsersSpecificPage(t *testing.T) {
 
 	users := testEnv.createManagerUsers(20)
 
-	amount, err := testApp.services.Users.GetTotalCount(context.Background())
+	amount, err := testApp.services.Users.GetTotalCount(
+		context.Background(),
+		testEnv.fixtures.AdminUser,
+	)
 	require.Nil(t, err)
 
 	tReq := test.CreateRequestTester(
@@ -329,7 +336,10 @@ func TestGetPaginatedManagerUsersPageFull(t *testing.T) {
 
 	testEnv.createManagerUsers(20)
 
-	amount, err := testApp.services.Users.GetTotalCount(context.Background())
+	amount, err := testApp.services.Users.GetTotalCount(
+		context.Background(),
+		testEnv.fixtures.AdminUser,
+	)
 	require.Nil(t, err)
 
 	tReq := test.CreateRequestTester(
@@ -496,8 +506,9 @@ func TestUpdateManagerUser(t *testing.T) {
 	username, password := "test", "testpassword"
 
 	data := dtos.UpdateUserDto{
-		Username: &username,
-		Password: &password,
+		Username:          &username,
+		Password:          &password,
+		TwoFactorRequired: nil,
 	}
 
 	tReq := test.CreateRequestTester(
@@ -532,8 +543,9 @@ func TestUpdateManagerUserUserNameExists(t *testing.T) {
 	username, password := testEnv.fixtures.ManagerUser.Username, "testpassword"
 
 	data := dtos.UpdateUserDto{
-		Username: &username,
-		Password: &password,
+		Username:          &username,
+		Password:          &password,
+		TwoFactorRequired: nil,
 	}
 
 	tReq := test.CreateRequestTester(
@@ -567,8 +579,9 @@ func TestUpdateManagerUserNotFound(t *testing.T) {
 	username, password := "test", "testpassword"
 
 	data := dtos.UpdateUserDto{
-		Username: &username,
-		Password: &password,
+		Username:          &username,
+		Password:          &password,
+		TwoFactorRequired: nil,
 	}
 
 	id, _ := uuid.NewUUID()
@@ -604,8 +617,9 @@ func TestUpdateManagerUserNotUUID(t *testing.T) {
 	username, password := "test", "testpassword"
 
 	data := dtos.UpdateUserDto{
-		Username: &username,
-		Password: &password,
+		Username:          &username,
+		Password:          &password,
+		TwoFactorRequired: nil,
 	}
 
 	tReq := test.CreateRequestTester(
@@ -645,8 +659,9 @@ func TestUpdateManagerUserFailValidation(t *testing.T) {
 	tReq.AddCookie(testEnv.fixtures.Tokens.AdminAccessToken)
 
 	tReq.SetData(dtos.UpdateUserDto{
-		Username: &username,
-		Password: &password,
+		Username:          &username,
+		Password:          &password,
+		TwoFactorRequired: nil,
 	})
 
 	mt := test.CreateMatrixTester()
@@ -797,3 +812,237 @@ func TestDeleteManagerUserAccess(t *testing.T) {
 
 	mt.Do(t)
 }
+
+func TestRestoreManagerUser(t *testing.T) {
+	testEnv, testApp := setup(t)
+	defer testEnv.teardown()
+
+	user := testEnv.createManagerUsers(1)[0]
+
+	tReqDelete := test.CreateRequestTester(
+		testApp.routes(),
+		http.MethodDelete,
+		"/users/%s",
+		user.ID,
+	)
+	tReqDelete.AddCookie(testEnv.fixtures.Tokens.AdminAccessToken)
+
+	rs := tReqDelete.Do(t)
+	assert.Equal(t, http.StatusOK, rs.StatusCode)
+
+	tReq := test.CreateRequestTester(
+		testApp.routes(),
+		http.MethodPost,
+		"/users/%s/restore",
+		user.ID,
+	)
+	tReq.AddCookie(testEnv.fixtures.Tokens.AdminAccessToken)
+
+	rs = tReq.Do(t)
+
+	var rsData models.User
+	err := httptools.ReadJSON(rs.Body, &rsData)
+	require.Nil(t, err)
+
+	assert.Equal(t, http.StatusOK, rs.StatusCode)
+	assert.Equal(t, user.ID, rsData.ID)
+	assert.Equal(t, user.Username, rsData.Username)
+	assert.Equal(t, models.ManagerRole, rsData.Role)
+
+	rs = tReq.Do(t)
+	assert.Equal(t, http.StatusNotFound, rs.StatusCode)
+}
+
+func TestRestoreManagerUserAccess(t *testing.T) {
+	testEnv, testApp := setup(t)
+	defer testEnv.teardown()
+
+	user := testEnv.createManagerUsers(1)[0]
+
+	tReqBase := test.CreateRequestTester(
+		testApp.routes(),
+		http.MethodPost,
+		"/users/%s/restore",
+		user.ID,
+	)
+
+	mt := test.CreateMatrixTester()
+
+	mt.AddTestCase(tReqBase, test.NewCaseResponse(http.StatusUnauthorized, nil, nil))
+
+	tReq2 := tReqBase.Copy()
+	tReq2.AddCookie(testEnv.fixtures.Tokens.DefaultAccessToken)
+	mt.AddTestCase(tReq2, test.NewCaseResponse(http.StatusForbidden, nil, nil))
+
+	tReq3 := tReqBase.Copy()
+	tReq3.AddCookie(testEnv.fixtures.Tokens.ManagerAccessToken)
+	mt.AddTestCase(tReq3, test.NewCaseResponse(http.StatusForbidden, nil, nil))
+
+	mt.Do(t)
+}
+
+func TestGetManagerLocations(t *testing.T) {
+	testEnv, testApp := setup(t)
+	defer testEnv.teardown()
+
+	testEnv.createLocations(2)
+
+	tReq := test.CreateRequestTester(
+		testApp.routes(),
+		http.MethodGet,
+		"/users/%s/locations",
+		testEnv.fixtures.ManagerUser.ID,
+	)
+	tReq.AddCookie(testEnv.fixtures.Tokens.AdminAccessToken)
+
+	rs := tReq.Do(t)
+
+	var rsData []models.Location
+	err := httptools.ReadJSON(rs.Body, &rsData)
+	require.Nil(t, err)
+
+	assert.Equal(t, http.StatusOK, rs.StatusCode)
+	assert.Equal(t, 3, len(rsData))
+}
+
+func TestGetManagerLocationsNotManager(t *testing.T) {
+	testEnv, testApp := setup(t)
+	defer testEnv.teardown()
+
+	tReq := test.CreateRequestTester(
+		testApp.routes(),
+		http.MethodGet,
+		"/users/%s/locations",
+		testEnv.fixtures.DefaultUser.ID,
+	)
+	tReq.AddCookie(testEnv.fixtures.Tokens.AdminAccessToken)
+
+	rs := tReq.Do(t)
+
+	assert.Equal(t, http.StatusNotFound, rs.StatusCode)
+}
+
+func TestAssignManagerLocation(t *testing.T) {
+	testEnv, testApp := setup(t)
+	defer testEnv.teardown()
+
+	location, err := testApp.services.Locations.Create(
+		context.Background(),
+		testEnv.fixtures.AdminUser,
+		dtos.CreateLocationDto{
+			Name:     "Unassigned",
+			Capacity: 10,
+			TimeZone: "Europe/Brussels",
+			Username: "UnassignedUser",
+			Password: "testpassword",
+		},
+	)
+	require.Nil(t, err)
+
+	tReq1 := test.CreateRequestTester(
+		testApp.routes(),
+		http.MethodGet,
+		"/locations/%s",
+		location.ID,
+	)
+	tReq1.AddCookie(testEnv.fixtures.Tokens.ManagerAccessToken)
+
+	rs1 := tReq1.Do(t)
+	assert.Equal(t, http.StatusNotFound, rs1.StatusCode)
+
+	tReq2 := test.CreateRequestTester(
+		testApp.routes(),
+		http.MethodPost,
+		"/users/%s/locations/%s",
+		testEnv.fixtures.ManagerUser.ID,
+		location.ID,
+	)
+	tReq2.AddCookie(testEnv.fixtures.Tokens.AdminAccessToken)
+
+	rs2 := tReq2.Do(t)
+
+	var rsData models.Location
+	err = httptools.ReadJSON(rs2.Body, &rsData)
+	require.Nil(t, err)
+
+	assert.Equal(t, http.StatusCreated, rs2.StatusCode)
+	assert.Equal(t, location.ID, rsData.ID)
+
+	rs3 := tReq1.Do(t)
+	assert.Equal(t, http.StatusOK, rs3.StatusCode)
+}
+
+func TestAssignManagerLocationExists(t *testing.T) {
+	testEnv, testApp := setup(t)
+	defer testEnv.teardown()
+
+	tReq := test.CreateRequestTester(
+		testApp.routes(),
+		http.MethodPost,
+		"/users/%s/locations/%s",
+		testEnv.fixtures.ManagerUser.ID,
+		testEnv.fixtures.DefaultLocation.ID,
+	)
+	tReq.AddCookie(testEnv.fixtures.Tokens.AdminAccessToken)
+
+	rs := tReq.Do(t)
+
+	assert.Equal(t, http.StatusConflict, rs.StatusCode)
+}
+
+func TestUnassignManagerLocation(t *testing.T) {
+	testEnv, testApp := setup(t)
+	defer testEnv.teardown()
+
+	tReq1 := test.CreateRequestTester(
+		testApp.routes(),
+		http.MethodDelete,
+		"/users/%s/locations/%s",
+		testEnv.fixtures.ManagerUser.ID,
+		testEnv.fixtures.DefaultLocation.ID,
+	)
+	tReq1.AddCookie(testEnv.fixtures.Tokens.AdminAccessToken)
+
+	rs1 := tReq1.Do(t)
+	assert.Equal(t, http.StatusOK, rs1.StatusCode)
+
+	rs2 := tReq1.Do(t)
+	assert.Equal(t, http.StatusNotFound, rs2.StatusCode)
+
+	tReq2 := test.CreateRequestTester(
+		testApp.routes(),
+		http.MethodGet,
+		"/locations/%s",
+		testEnv.fixtures.DefaultLocation.ID,
+	)
+	tReq2.AddCookie(testEnv.fixtures.Tokens.ManagerAccessToken)
+
+	rs3 := tReq2.Do(t)
+	assert.Equal(t, http.StatusNotFound, rs3.StatusCode)
+}
+
+func TestManagerLocationsAccess(t *testing.T) {
+	testEnv, testApp := setup(t)
+	defer testEnv.teardown()
+
+	mt := test.CreateMatrixTester()
+
+	for _, method := range []string{http.MethodPost, http.MethodDelete} {
+		tReqBase := test.CreateRequestTester(
+			testApp.routes(),
+			method,
+			"/users/%s/locations/%s",
+			testEnv.fixtures.ManagerUser.ID,
+			testEnv.fixtures.DefaultLocation.ID,
+		)
+
+		mt.AddTestCase(tReqBase, test.NewCaseResponse(http.StatusUnauthorized, nil, nil))
+
+		tReq2 := tReqBase.Copy()
+		tReq2.AddCookie(testEnv.fixtures.Tokens.ManagerAccessToken)
+
+		mt.AddTestCase(tReq2, test.NewCaseResponse(http.StatusForbidden, nil, nil))
+	}
+
+	mt.Do(t)
+}
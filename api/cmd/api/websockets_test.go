@@ -21,11 +21,16 @@ func TestAllLocationsWebSocketCheckIn(t *testing.T) {
 
 	//nolint:exhaustruct // other fields are optional
 	tWeb.SetInitialMessage(dtos.SubscribeMessageDto{
-		Subject: "all-locations",
+		Subject:        "all-locations",
+		OrganisationID: testEnv.fixtures.Organisation.ID,
 	})
 
 	tWeb.SetParallelOperation(func(t *testing.T, _ *httptest.Server) {
-		school, err := testApp.services.Schools.GetByID(context.Background(), int64(1))
+		school, err := testApp.services.Schools.GetByID(
+			context.Background(),
+			testEnv.fixtures.Organisation.ID,
+			int64(1),
+		)
 		require.Nil(t, err)
 
 		_, err = testApp.services.CheckInsWriter.Create(
@@ -59,7 +64,8 @@ func TestAllLocationsWebSocketCapUpdate(t *testing.T) {
 	tWeb := test.CreateWebSocketTester(testApp.routes())
 	//nolint:exhaustruct // other fields are optional
 	tWeb.SetInitialMessage(dtos.SubscribeMessageDto{
-		Subject: "all-locations",
+		Subject:        "all-locations",
+		OrganisationID: testEnv.fixtures.Organisation.ID,
 	})
 	tWeb.SetParallelOperation(func(t *testing.T, _ *httptest.Server) {
 		newCap := int64(10)
@@ -92,11 +98,16 @@ func TestSingleLocationWebSocketCheckIn(t *testing.T) {
 
 	tWeb.SetInitialMessage(dtos.SubscribeMessageDto{
 		Subject:        "single-location",
+		OrganisationID: testEnv.fixtures.Organisation.ID,
 		NormalizedName: testEnv.fixtures.DefaultLocation.NormalizedName,
 	})
 
 	tWeb.SetParallelOperation(func(t *testing.T, _ *httptest.Server) {
-		school, err := testApp.services.Schools.GetByID(context.Background(), int64(1))
+		school, err := testApp.services.Schools.GetByID(
+			context.Background(),
+			testEnv.fixtures.Organisation.ID,
+			int64(1),
+		)
 		require.Nil(t, err)
 
 		_, err = testApp.services.CheckInsWriter.Create(
@@ -130,6 +141,7 @@ func TestSingleLocationWebSocketCapUpdate(t *testing.T) {
 
 	tWeb.SetInitialMessage(dtos.SubscribeMessageDto{
 		Subject:        "single-location",
+		OrganisationID: testEnv.fixtures.Organisation.ID,
 		NormalizedName: testEnv.fixtures.DefaultLocation.NormalizedName,
 	})
 
@@ -167,12 +179,14 @@ func TestStateUpdate(t *testing.T) {
 
 	//nolint:exhaustruct //other fields are optional
 	tWeb.SetInitialMessage(dtos.SubscribeMessageDto{
-		Subject: "state",
+		Subject:        "state",
+		OrganisationID: testEnv.fixtures.Organisation.ID,
 	})
 
 	tWeb.SetParallelOperation(func(t *testing.T, _ *httptest.Server) {
 		_, err := testApp.services.State.UpdateState(
 			context.Background(),
+			testEnv.fixtures.Organisation.ID,
 			dtos.StateDto{
 				IsMaintenance: true,
 			},
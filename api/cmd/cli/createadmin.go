@@ -7,8 +7,6 @@ import (
 	"log/slog"
 	"time"
 
-	"github.com/XDoubleU/essentia/pkg/database/postgres"
-
 	"check-in/api/internal/config"
 	"check-in/api/internal/dtos"
 	"check-in/api/internal/models"
@@ -16,21 +14,19 @@ import (
 	"check-in/api/internal/services"
 )
 
-func createAdmin(cfg config.Config, username string, password string) {
+func createAdmin(
+	cfg config.Config,
+	username string,
+	password string,
+	organisationName string,
+	role models.Role,
+) {
 	if username == "" || password == "" {
 		fmt.Println("please provide a username and password")
 		return
 	}
 
-	db, err := postgres.Connect(
-		slog.Default(),
-		cfg.DBDsn,
-		25, //nolint:mnd //no magic number
-		"15m",
-		10,             //nolint:mnd //no magic number
-		10*time.Second, //nolint:mnd //no magic number
-		30*time.Second, //nolint:mnd //no magic number
-	)
+	db, err := connect(cfg)
 	if err != nil {
 		fmt.Println(err.Error())
 		return
@@ -43,18 +39,27 @@ func createAdmin(cfg config.Config, username string, password string) {
 		time.Now,
 	)
 
-	_, err = services.Users.Create(
+	organisation, err := services.Organisations.GetByName(
 		context.Background(),
+		organisationName,
+	)
+	if err != nil {
+		fmt.Println(err.Error())
+		return
+	}
 
+	_, err = services.Users.Create(
+		context.Background(),
+		organisation.ID,
 		dtos.CreateUserDto{
 			Username: username,
 			Password: password,
 		},
-		models.AdminRole,
+		role,
 	)
 	if err != nil {
 		fmt.Println(err.Error())
 		return
 	}
-	fmt.Println("admin added")
+	fmt.Printf("%s added\n", role)
 }
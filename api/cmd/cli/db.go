@@ -0,0 +1,22 @@
+package main
+
+import (
+	"log/slog"
+	"time"
+
+	"github.com/XDoubleU/essentia/pkg/database/postgres"
+
+	"check-in/api/internal/config"
+)
+
+func connect(cfg config.Config) (postgres.DB, error) {
+	return postgres.Connect(
+		slog.Default(),
+		cfg.DBDsn,
+		25, //nolint:mnd //no magic number
+		"15m",
+		10,             //nolint:mnd //no magic number
+		10*time.Second, //nolint:mnd //no magic number
+		30*time.Second, //nolint:mnd //no magic number
+	)
+}
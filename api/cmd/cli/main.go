@@ -8,12 +8,14 @@ import (
 	"os"
 
 	"check-in/api/internal/config"
+	"check-in/api/internal/models"
 )
 
 func main() {
 	cfg := config.New(slog.New(slog.NewTextHandler(os.Stdout, nil)))
 	var username string
 	var password string
+	var organisationName string
 
 	flag.StringVar(
 		&cfg.DBDsn,
@@ -23,13 +25,23 @@ func main() {
 	)
 	flag.StringVar(&username, "u", "", "username of admin user")
 	flag.StringVar(&password, "p", "", "password of admin user")
+	flag.StringVar(
+		&organisationName,
+		"o",
+		"Default",
+		"name of the organisation of the admin user",
+	)
 
 	flag.Parse()
 
 	command := flag.Arg(0)
 	switch command {
 	case "createadmin":
-		createAdmin(cfg, username, password)
+		createAdmin(cfg, username, password, organisationName, models.AdminRole)
+	case "createsuperadmin":
+		createAdmin(cfg, username, password, organisationName, models.SuperAdminRole)
+	case "rebuildrollups":
+		rebuildRollups(cfg)
 	default:
 		fmt.Println("invalid command")
 		return
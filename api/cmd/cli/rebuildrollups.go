@@ -0,0 +1,28 @@
+//nolint:forbidigo //returns output of cli
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"check-in/api/internal/config"
+	"check-in/api/internal/repositories"
+)
+
+func rebuildRollups(cfg config.Config) {
+	db, err := connect(cfg)
+	if err != nil {
+		fmt.Println(err.Error())
+		return
+	}
+
+	repos := repositories.New(db, time.Now)
+
+	err = repos.Rollups.Rebuild(context.Background())
+	if err != nil {
+		fmt.Println(err.Error())
+		return
+	}
+	fmt.Println("rollups rebuilt")
+}
@@ -3,21 +3,40 @@ package config
 
 import (
 	"log/slog"
+	"strings"
 
 	"github.com/XDoubleU/essentia/pkg/config"
 )
 
 type Config struct {
-	Env           string
-	Port          int
-	Throttle      bool
-	WebURL        string
-	SentryDsn     string
-	SampleRate    float64
-	AccessExpiry  string
-	RefreshExpiry string
-	DBDsn         string
-	Release       string
+	Env                 string
+	Port                int
+	Throttle            bool
+	WebURL              string
+	SentryDsn           string
+	SampleRate          float64
+	AccessExpiry        string
+	RefreshExpiry       string
+	DeviceRefreshExpiry string
+	PairingCodeExpiry   string
+	DBDsn               string
+	Release             string
+	OIDC                OIDCConfig
+}
+
+// OIDCConfig configures single sign-on with an OpenID Connect issuer,
+// it's disabled when no issuer is configured. Users are provisioned
+// in Organisation with the role of the first group they're a member of.
+type OIDCConfig struct {
+	Issuer        string
+	ClientID      string
+	ClientSecret  string
+	RedirectURL   string
+	Scopes        []string
+	GroupsClaim   string
+	AdminGroups   []string
+	ManagerGroups []string
+	Organisation  string
 }
 
 func New(logger *slog.Logger) Config {
@@ -33,8 +52,36 @@ func New(logger *slog.Logger) Config {
 	cfg.SampleRate = parser.EnvFloat("SAMPLE_RATE", 1.0)
 	cfg.AccessExpiry = parser.EnvStr("ACCESS_EXPIRY", "1h")
 	cfg.RefreshExpiry = parser.EnvStr("REFRESH_EXPIRY", "7d")
+	cfg.DeviceRefreshExpiry = parser.EnvStr("DEVICE_REFRESH_EXPIRY", "90d")
+	cfg.PairingCodeExpiry = parser.EnvStr("PAIRING_CODE_EXPIRY", "10m")
 	cfg.DBDsn = parser.EnvStr("DB_DSN", "postgres://postgres@localhost/postgres")
 	cfg.Release = parser.EnvStr("RELEASE", config.DevEnv)
 
+	cfg.OIDC.Issuer = parser.EnvStr("OIDC_ISSUER", "")
+	cfg.OIDC.ClientID = parser.EnvStr("OIDC_CLIENT_ID", "")
+	cfg.OIDC.ClientSecret = parser.EnvStr("OIDC_CLIENT_SECRET", "")
+	cfg.OIDC.RedirectURL = parser.EnvStr(
+		"OIDC_REDIRECT_URL",
+		"http://localhost:8000/auth/oidc/callback",
+	)
+	cfg.OIDC.Scopes = parser.EnvStrArray(
+		"OIDC_SCOPES",
+		[]string{"openid", "profile", "groups"},
+	)
+	cfg.OIDC.GroupsClaim = parser.EnvStr("OIDC_GROUPS_CLAIM", "groups")
+	cfg.OIDC.AdminGroups = splitList(parser.EnvStr("OIDC_ADMIN_GROUPS", ""))
+	cfg.OIDC.ManagerGroups = splitList(parser.EnvStr("OIDC_MANAGER_GROUPS", ""))
+	cfg.OIDC.Organisation = parser.EnvStr("OIDC_ORGANISATION", "")
+
 	return cfg
 }
+
+// splitList splits a comma separated list, unlike EnvStrArray
+// it can be empty by default.
+func splitList(value string) []string {
+	if value == "" {
+		return []string{}
+	}
+
+	return strings.Split(value, ",")
+}
@@ -4,5 +4,7 @@ import "github.com/XDoubleU/essentia/pkg/context"
 
 const DateFormat = "2006-01-02"
 const CSVFileNameFormat = "20060102150405"
+const TimeOfDayFormat = "15:04"
 
 const UserContextKey = context.Key("user")
+const TxContextKey = context.Key("tx")
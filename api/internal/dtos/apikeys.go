@@ -0,0 +1,34 @@
+package dtos
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/XDoubleU/essentia/pkg/validate"
+
+	"check-in/api/internal/models"
+)
+
+type CreateAPIKeyDto struct {
+	Name   string              `json:"name"`
+	Scopes []models.Permission `json:"scopes"`
+	// Expiry is optional, without expiry the key is valid until it is revoked.
+	Expiry *time.Time `json:"expiry"`
+} //	@name	CreateAPIKeyDto
+
+func (dto *CreateAPIKeyDto) Validate() (bool, map[string]string) {
+	v := validate.New()
+
+	validate.Check(v, "name", dto.Name, validate.IsNotEmpty)
+
+	for i, scope := range dto.Scopes {
+		validate.Check(
+			v,
+			fmt.Sprintf("scopes[%d]", i),
+			scope,
+			validate.IsInSlice(models.AllPermissions),
+		)
+	}
+
+	return v.Valid(), v.Errors()
+}
@@ -0,0 +1,7 @@
+package dtos
+
+import "check-in/api/internal/models"
+
+type PaginatedAuditEventsDto struct {
+	PaginatedResultDto[models.AuditEvent]
+} //	@name	PaginatedAuditEventsDto
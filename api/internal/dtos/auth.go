@@ -6,8 +6,17 @@ type SignInDto struct {
 	Username   string `json:"username"`
 	Password   string `json:"password"`
 	RememberMe bool   `json:"rememberMe"`
+	// Code is a TOTP code or a recovery code,
+	// it's only required when two-factor authentication is enabled.
+	Code string `json:"code"`
 } //	@name	SignInDto
 
+// SignInPendingDto is returned when the credentials are valid,
+// but the user still has to provide a two-factor code.
+type SignInPendingDto struct {
+	TwoFactorPending bool `json:"twoFactorPending"`
+} //	@name	SignInPendingDto
+
 func (dto *SignInDto) Validate() (bool, map[string]string) {
 	v := validate.New()
 
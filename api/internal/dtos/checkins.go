@@ -1,22 +1,57 @@
 package dtos
 
 import (
+	"fmt"
+	"time"
+
 	"github.com/XDoubleU/essentia/pkg/validate"
 	"github.com/jackc/pgx/v5/pgtype"
+
+	"check-in/api/internal/models"
 )
 
+const MaxCheckInBatchSize = 500
+
 type CreateCheckInDto struct {
 	SchoolID int64 `json:"schoolId"`
 } //	@name	CreateCheckInDto
 
+type CreateCheckInBatchDto struct {
+	CheckIns []BatchCheckInDto `json:"checkIns"`
+} //	@name	CreateCheckInBatchDto
+
+type BatchCheckInDto struct {
+	IdempotencyKey string    `json:"idempotencyKey"`
+	SchoolID       int64     `json:"schoolId"`
+	CreatedAt      time.Time `json:"createdAt"`
+} //	@name	BatchCheckInDto
+
+type CheckInBatchResultDto struct {
+	Created    []*CheckInDto     `json:"created"`
+	Duplicates []string          `json:"duplicates"`
+	Rejected   map[string]string `json:"rejected"`
+} //	@name	CheckInBatchResultDto
+
 type CheckInDto struct {
-	ID         int64              `json:"id"`
-	LocationID string             `json:"locationId"`
-	SchoolName string             `json:"schoolName"`
-	Capacity   int64              `json:"capacity"`
-	CreatedAt  pgtype.Timestamptz `json:"createdAt"  swaggertype:"string"`
+	ID           int64              `json:"id"`
+	LocationID   string             `json:"locationId"`
+	SchoolName   string             `json:"schoolName"`
+	Capacity     int64              `json:"capacity"`
+	CreatedAt    pgtype.Timestamptz `json:"createdAt"    swaggertype:"string"`
+	CheckedOutAt pgtype.Timestamptz `json:"checkedOutAt" swaggertype:"string"`
 } //	@name	CheckInDto
 
+func NewCheckInDto(checkIn models.CheckIn, schoolName string) *CheckInDto {
+	return &CheckInDto{
+		ID:           checkIn.ID,
+		LocationID:   checkIn.LocationID,
+		SchoolName:   schoolName,
+		Capacity:     checkIn.Capacity,
+		CreatedAt:    checkIn.CreatedAt,
+		CheckedOutAt: checkIn.CheckedOutAt,
+	}
+}
+
 func (dto *CreateCheckInDto) Validate() (bool, map[string]string) {
 	v := validate.New()
 
@@ -24,3 +59,35 @@ func (dto *CreateCheckInDto) Validate() (bool, map[string]string) {
 
 	return v.Valid(), v.Errors()
 }
+
+func (dto *CreateCheckInBatchDto) Validate() (bool, map[string]string) {
+	v := validate.New()
+
+	validate.Check(v, "checkIns", len(dto.CheckIns), validate.IsGreaterThan(0))
+	validate.Check(
+		v,
+		"checkIns",
+		len(dto.CheckIns),
+		validate.IsLesserThanOrEqual(MaxCheckInBatchSize),
+	)
+
+	for i, checkIn := range dto.CheckIns {
+		prefix := fmt.Sprintf("checkIns[%d]", i)
+
+		validate.Check(
+			v,
+			prefix+".idempotencyKey",
+			checkIn.IdempotencyKey,
+			validate.IsNotEmpty,
+		)
+		validate.Check(
+			v,
+			prefix+".schoolId",
+			checkIn.SchoolID,
+			validate.IsGreaterThan(int64(0)),
+		)
+		validate.Check(v, prefix+".createdAt", checkIn.CreatedAt, isNotZeroTime)
+	}
+
+	return v.Valid(), v.Errors()
+}
@@ -0,0 +1,58 @@
+package dtos
+
+import (
+	"github.com/XDoubleU/essentia/pkg/validate"
+)
+
+type CreateDeviceDto struct {
+	Name     string `json:"name"`
+	Username string `json:"username"`
+	Password string `json:"password"`
+} //	@name	CreateDeviceDto
+
+type UpdateDeviceDto struct {
+	Name string `json:"name"`
+} //	@name	UpdateDeviceDto
+
+func (dto *CreateDeviceDto) Validate() (bool, map[string]string) {
+	v := validate.New()
+
+	validate.Check(v, "name", dto.Name, validate.IsNotEmpty)
+	validate.Check(v, "username", dto.Username, validate.IsNotEmpty)
+	validate.Check(v, "password", dto.Password, validate.IsNotEmpty)
+
+	return v.Valid(), v.Errors()
+}
+
+func (dto *UpdateDeviceDto) Validate() (bool, map[string]string) {
+	v := validate.New()
+
+	validate.Check(v, "name", dto.Name, validate.IsNotEmpty)
+
+	return v.Valid(), v.Errors()
+}
+
+type CreatePairingCodeDto struct {
+	// Name is the name of the device which is created when pairing.
+	Name string `json:"name"`
+} //	@name	CreatePairingCodeDto
+
+type PairDto struct {
+	Code string `json:"code"`
+} //	@name	PairDto
+
+func (dto *CreatePairingCodeDto) Validate() (bool, map[string]string) {
+	v := validate.New()
+
+	validate.Check(v, "name", dto.Name, validate.IsNotEmpty)
+
+	return v.Valid(), v.Errors()
+}
+
+func (dto *PairDto) Validate() (bool, map[string]string) {
+	v := validate.New()
+
+	validate.Check(v, "code", dto.Code, validate.IsNotEmpty)
+
+	return v.Valid(), v.Errors()
+}
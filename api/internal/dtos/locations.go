@@ -1,37 +1,103 @@
 package dtos
 
 import (
+	"fmt"
+	"time"
+
 	"github.com/XDoubleU/essentia/pkg/validate"
 
 	"check-in/api/internal/models"
 )
 
 type CheckInsGraphDto struct {
-	Dates                 []string         `json:"dates"`
-	CapacitiesPerLocation map[string][]int `json:"capacitiesPerLocation"`
-	ValuesPerSchool       map[string][]int `json:"valuesPerSchool"`
+	Dates                 []string          `json:"dates"`
+	CapacitiesPerLocation map[string][]int  `json:"capacitiesPerLocation"`
+	ValuesPerSchool       map[string][]int  `json:"valuesPerSchool"`
+	ClosedPerLocation     map[string][]bool `json:"closedPerLocation,omitempty"`
 } //	@name	CheckInsGraphDto
 
+// CheckInsHeatmapDto holds per location the amount of check-ins
+// per weekday, starting at sunday, and per hour of the day.
+type CheckInsHeatmapDto struct {
+	ValuesPerLocation map[string][][]int `json:"valuesPerLocation"`
+} //	@name	CheckInsHeatmapDto
+
+// CheckInsComparisonDto compares the check-ins of a period with those
+// of another period. The daily series are aligned on the day within
+// each period, so the i-th value is the i-th day of its period.
+type CheckInsComparisonDto struct {
+	Total                  ComparisonTotalDto            `json:"total"`
+	TotalsPerSchool        map[string]ComparisonTotalDto `json:"totalsPerSchool"`
+	TotalsPerLocation      map[string]ComparisonTotalDto `json:"totalsPerLocation"`
+	Dates                  []string                      `json:"dates"`
+	CompareDates           []string                      `json:"compareDates"`
+	ValuesPerSchool        map[string][]int              `json:"valuesPerSchool"`
+	CompareValuesPerSchool map[string][]int              `json:"compareValuesPerSchool"`
+} //	@name	CheckInsComparisonDto
+
+// ComparisonTotalDto holds the check-ins of both periods,
+// the percentage change is null if there were no check-ins
+// in the period compared to.
+type ComparisonTotalDto struct {
+	Count            int      `json:"count"`
+	CompareCount     int      `json:"compareCount"`
+	Delta            int      `json:"delta"`
+	PercentageChange *float64 `json:"percentageChange"`
+} //	@name	ComparisonTotalDto
+
+func NewComparisonTotalDto(
+	count int,
+	compareCount int,
+) ComparisonTotalDto {
+	delta := count - compareCount
+
+	var percentageChange *float64
+	if compareCount != 0 {
+		//nolint:mnd //no magic number
+		change := float64(delta) / float64(compareCount) * 100
+		percentageChange = &change
+	}
+
+	return ComparisonTotalDto{
+		Count:            count,
+		CompareCount:     compareCount,
+		Delta:            delta,
+		PercentageChange: percentageChange,
+	}
+}
+
 type PaginatedLocationsDto struct {
 	PaginatedResultDto[models.Location]
 } //	@name	PaginatedLocationsDto
 
 type CreateLocationDto struct {
-	Name     string `json:"name"`
-	Capacity int64  `json:"capacity"`
-	Username string `json:"username"`
-	Password string `json:"password"`
-	TimeZone string `json:"timeZone"`
+	Name             string  `json:"name"`
+	Capacity         int64   `json:"capacity"`
+	Username         string  `json:"username"`
+	Password         string  `json:"password"`
+	TimeZone         string  `json:"timeZone"`
+	BusinessDayStart *string `json:"businessDayStart"`
 } //	@name	CreateLocationDto
 
 type UpdateLocationDto struct {
-	Name     *string `json:"name"`
-	Capacity *int64  `json:"capacity"`
-	Username *string `json:"username"`
-	Password *string `json:"password"`
-	TimeZone *string `json:"timeZone"`
+	Name             *string `json:"name"`
+	Capacity         *int64  `json:"capacity"`
+	Username         *string `json:"username"`
+	Password         *string `json:"password"`
+	TimeZone         *string `json:"timeZone"`
+	BusinessDayStart *string `json:"businessDayStart"`
 } //	@name	UpdateLocationDto
 
+type OpeningHoursDto struct {
+	Periods     []models.OpeningPeriod `json:"periods"`
+	ClosingDays []models.ClosingDay    `json:"closingDays"`
+} //	@name	OpeningHoursDto
+
+type CapacityChangeDto struct {
+	Capacity      int64     `json:"capacity"`
+	EffectiveFrom time.Time `json:"effectiveFrom"`
+} //	@name	CapacityChangeDto
+
 func (dto *CreateLocationDto) Validate() (bool, map[string]string) {
 	v := validate.New()
 
@@ -41,6 +107,7 @@ func (dto *CreateLocationDto) Validate() (bool, map[string]string) {
 	validate.Check(v, "password", dto.Password, validate.IsNotEmpty)
 	validate.Check(v, "timeZone", dto.TimeZone, validate.IsNotEmpty)
 	validate.Check(v, "timeZone", dto.TimeZone, validate.IsValidTimeZone)
+	validate.CheckOptional(v, "businessDayStart", dto.BusinessDayStart, isTimeOfDay)
 
 	return v.Valid(), v.Errors()
 }
@@ -59,6 +126,51 @@ func (dto *UpdateLocationDto) Validate() (bool, map[string]string) {
 	validate.CheckOptional(v, "password", dto.Password, validate.IsNotEmpty)
 	validate.CheckOptional(v, "timeZone", dto.TimeZone, validate.IsNotEmpty)
 	validate.CheckOptional(v, "timeZone", dto.TimeZone, validate.IsValidTimeZone)
+	validate.CheckOptional(v, "businessDayStart", dto.BusinessDayStart, isTimeOfDay)
+
+	return v.Valid(), v.Errors()
+}
+
+func (dto *OpeningHoursDto) Validate() (bool, map[string]string) {
+	v := validate.New()
+
+	for i, period := range dto.Periods {
+		prefix := fmt.Sprintf("periods[%d]", i)
+
+		validate.Check(
+			v,
+			prefix+".weekday",
+			period.Weekday,
+			validate.IsGreaterThanOrEqual(int64(time.Sunday)),
+		)
+		validate.Check(
+			v,
+			prefix+".weekday",
+			period.Weekday,
+			validate.IsLesserThanOrEqual(int64(time.Saturday)),
+		)
+		validate.Check(v, prefix+".opensAt", period.OpensAt, isTimeOfDay)
+		validate.Check(v, prefix+".closesAt", period.ClosesAt, isTimeOfDay)
+	}
+
+	dates := []string{}
+	for i, closingDay := range dto.ClosingDays {
+		prefix := fmt.Sprintf("closingDays[%d]", i)
+
+		validate.Check(v, prefix+".date", closingDay.Date, isDate)
+		validate.Check(v, prefix+".date", closingDay.Date, isNotIn(dates))
+
+		dates = append(dates, closingDay.Date)
+	}
+
+	return v.Valid(), v.Errors()
+}
+
+func (dto *CapacityChangeDto) Validate() (bool, map[string]string) {
+	v := validate.New()
+
+	validate.Check(v, "capacity", dto.Capacity, validate.IsGreaterThan(int64(0)))
+	validate.Check(v, "effectiveFrom", dto.EffectiveFrom, isNotZeroTime)
 
 	return v.Valid(), v.Errors()
 }
@@ -0,0 +1,23 @@
+package dtos
+
+import (
+	"github.com/XDoubleU/essentia/pkg/validate"
+
+	"check-in/api/internal/models"
+)
+
+type PaginatedOrganisationsDto struct {
+	PaginatedResultDto[models.Organisation]
+} //	@name	PaginatedOrganisationsDto
+
+type OrganisationDto struct {
+	Name string `json:"name"`
+} //	@name	OrganisationDto
+
+func (dto *OrganisationDto) Validate() (bool, map[string]string) {
+	v := validate.New()
+
+	validate.Check(v, "name", dto.Name, validate.IsNotEmpty)
+
+	return v.Valid(), v.Errors()
+}
@@ -0,0 +1,63 @@
+package dtos
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/XDoubleU/essentia/pkg/validate"
+
+	"check-in/api/internal/models"
+)
+
+type RoleDto struct {
+	Name        string              `json:"name"`
+	Permissions []models.Permission `json:"permissions"`
+} //	@name	RoleDto
+
+type UserRoleDto struct {
+	// RoleID is the ID of a custom role,
+	// null restores the permissions of the manager role.
+	RoleID *string `json:"roleId"`
+} //	@name	UserRoleDto
+
+func (dto *RoleDto) Validate() (bool, map[string]string) {
+	v := validate.New()
+
+	validate.Check(v, "name", dto.Name, validate.IsNotEmpty)
+	validate.Check(
+		v,
+		"name",
+		models.Role(dto.Name),
+		isNotBuiltInRole,
+	)
+
+	for i, permission := range dto.Permissions {
+		validate.Check(
+			v,
+			fmt.Sprintf("permissions[%d]", i),
+			permission,
+			validate.IsInSlice(models.AllPermissions),
+		)
+	}
+
+	return v.Valid(), v.Errors()
+}
+
+func (dto *UserRoleDto) Validate() (bool, map[string]string) {
+	v := validate.New()
+
+	validate.CheckOptional(v, "roleId", dto.RoleID, validate.IsNotEmpty)
+
+	return v.Valid(), v.Errors()
+}
+
+func isNotBuiltInRole(role models.Role) (bool, string) {
+	builtInRoles := []models.Role{
+		models.DefaultRole,
+		models.ManagerRole,
+		models.AdminRole,
+		models.SuperAdminRole,
+	}
+
+	return !slices.Contains(builtInRoles, role), "must not be a built-in role"
+}
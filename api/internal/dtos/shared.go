@@ -1,5 +1,15 @@
 package dtos
 
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/XDoubleU/essentia/pkg/validate"
+
+	"check-in/api/internal/constants"
+)
+
 type PaginatedResultDto[T any] struct {
 	Data       []*T       `json:"data"`
 	Pagination Pagination `json:"pagination"`
@@ -9,3 +19,36 @@ type Pagination struct {
 	Current int64 `json:"current"`
 	Total   int64 `json:"total"`
 } //	@name	Pagination
+
+func isAfter(other time.Time, otherName string) validate.ValidatorFunc[time.Time] {
+	return func(value time.Time) (bool, string) {
+		return value.After(other), fmt.Sprintf("must be after %s", otherName)
+	}
+}
+
+func isNotZeroTime(value time.Time) (bool, string) {
+	return !value.IsZero(), "must be provided"
+}
+
+func isTimeOfDay(value string) (bool, string) {
+	_, err := time.Parse(constants.TimeOfDayFormat, value)
+	return err == nil, "must be a time of day (format: 'HH:mm')"
+}
+
+func isDate(value string) (bool, string) {
+	_, err := time.Parse(constants.DateFormat, value)
+	return err == nil, "must be a date (format: 'yyyy-MM-dd')"
+}
+
+func isNotIn(values []string) validate.ValidatorFunc[string] {
+	return func(value string) (bool, string) {
+		return !slices.Contains(values, value), "must be unique"
+	}
+}
+
+func hasMaxLength(maxLength int) validate.ValidatorFunc[string] {
+	return func(value string) (bool, string) {
+		return len(value) <= maxLength,
+			fmt.Sprintf("must be at most %d characters long", maxLength)
+	}
+}
@@ -1,5 +1,34 @@
 package dtos
 
+import (
+	"time"
+
+	"github.com/XDoubleU/essentia/pkg/validate"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
 type StateDto struct {
-	IsMaintenance bool `json:"isMaintenance"`
+	IsMaintenance    bool       `json:"isMaintenance"`
+	MaintenanceStart *time.Time `json:"maintenanceStart"`
+	MaintenanceEnd   *time.Time `json:"maintenanceEnd"`
 } //	@name	StateDto
+
+type MaintenanceDto struct {
+	Message        string             `json:"message"`
+	MaintenanceEnd pgtype.Timestamptz `json:"maintenanceEnd" swaggertype:"string"`
+} //	@name	MaintenanceDto
+
+func (dto *StateDto) Validate() (bool, map[string]string) {
+	v := validate.New()
+
+	if dto.MaintenanceStart != nil {
+		validate.CheckOptional(
+			v,
+			"maintenanceEnd",
+			dto.MaintenanceEnd,
+			isAfter(*dto.MaintenanceStart, "maintenanceStart"),
+		)
+	}
+
+	return v.Valid(), v.Errors()
+}
@@ -0,0 +1,16 @@
+package dtos
+
+import "github.com/XDoubleU/essentia/pkg/validate"
+
+// TwoFactorCodeDto holds a TOTP code or a recovery code.
+type TwoFactorCodeDto struct {
+	Code string `json:"code"`
+} //	@name	TwoFactorCodeDto
+
+func (dto *TwoFactorCodeDto) Validate() (bool, map[string]string) {
+	v := validate.New()
+
+	validate.Check(v, "code", dto.Code, validate.IsNotEmpty)
+
+	return v.Valid(), v.Errors()
+}
@@ -16,8 +16,9 @@ type CreateUserDto struct {
 } //	@name	CreateUserDto
 
 type UpdateUserDto struct {
-	Username *string `json:"username"`
-	Password *string `json:"password"`
+	Username          *string `json:"username"`
+	Password          *string `json:"password"`
+	TwoFactorRequired *bool   `json:"twoFactorRequired"`
 } //	@name	UpdateUserDto
 
 func (dto *CreateUserDto) Validate() (bool, map[string]string) {
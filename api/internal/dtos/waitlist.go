@@ -0,0 +1,58 @@
+package dtos
+
+import (
+	"github.com/XDoubleU/essentia/pkg/validate"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"check-in/api/internal/models"
+)
+
+const maxShortCodeLength = 16
+
+type CreateWaitlistEntryDto struct {
+	SchoolID  int64   `json:"schoolId"`
+	ShortCode *string `json:"shortCode"`
+} //	@name	CreateWaitlistEntryDto
+
+type WaitlistEntryDto struct {
+	ID         int64              `json:"id"`
+	LocationID string             `json:"locationId"`
+	SchoolName string             `json:"schoolName"`
+	ShortCode  pgtype.Text        `json:"shortCode"  swaggertype:"string"`
+	CreatedAt  pgtype.Timestamptz `json:"createdAt"  swaggertype:"string"`
+	PromotedAt pgtype.Timestamptz `json:"promotedAt" swaggertype:"string"`
+	CheckInID  pgtype.Int8        `json:"checkInId"  swaggertype:"integer"`
+	Position   int64              `json:"position"`
+} //	@name	WaitlistEntryDto
+
+func NewWaitlistEntryDto(
+	entry models.WaitlistEntry,
+	schoolName string,
+	position int64,
+) *WaitlistEntryDto {
+	return &WaitlistEntryDto{
+		ID:         entry.ID,
+		LocationID: entry.LocationID,
+		SchoolName: schoolName,
+		ShortCode:  entry.ShortCode,
+		CreatedAt:  entry.CreatedAt,
+		PromotedAt: entry.PromotedAt,
+		CheckInID:  entry.CheckInID,
+		Position:   position,
+	}
+}
+
+func (dto *CreateWaitlistEntryDto) Validate() (bool, map[string]string) {
+	v := validate.New()
+
+	validate.Check(v, "schoolId", dto.SchoolID, validate.IsGreaterThan(int64(0)))
+	validate.CheckOptional(v, "shortCode", dto.ShortCode, validate.IsNotEmpty)
+	validate.CheckOptional(
+		v,
+		"shortCode",
+		dto.ShortCode,
+		hasMaxLength(maxShortCodeLength),
+	)
+
+	return v.Valid(), v.Errors()
+}
@@ -18,6 +18,9 @@ const (
 type LocationStateDto struct {
 	NormalizedName     string             `json:"normalizedName"`
 	Available          int64              `json:"available"`
+	Occupancy          int64              `json:"occupancy"`
+	CheckInsToday      int64              `json:"checkInsToday"`
+	WaitlistLength     int64              `json:"waitlistLength"`
 	Capacity           int64              `json:"capacity"`
 	AvailableYesterday int64              `json:"availableYesterday"`
 	CapacityYesterday  int64              `json:"capacityYesterday"`
@@ -28,6 +31,9 @@ func NewLocationStateDto(location models.Location) LocationStateDto {
 	return LocationStateDto{
 		NormalizedName:     location.NormalizedName,
 		Available:          location.Available,
+		Occupancy:          location.Occupancy,
+		CheckInsToday:      location.CheckInsToday,
+		WaitlistLength:     location.WaitlistLength,
 		Capacity:           location.Capacity,
 		YesterdayFullAt:    location.YesterdayFullAt,
 		AvailableYesterday: location.AvailableYesterday,
@@ -35,26 +41,51 @@ func NewLocationStateDto(location models.Location) LocationStateDto {
 	}
 }
 
+type WaitlistPromotionEventDto struct {
+	NormalizedName string           `json:"normalizedName"`
+	Promoted       WaitlistEntryDto `json:"promoted"`
+} //	@name	WaitlistPromotionEvent
+
 type SubscribeMessageDto struct {
 	Subject        WebSocketSubject `json:"subject"`
+	OrganisationID string           `json:"organisationId"`
 	NormalizedName string           `json:"normalizedName"`
 } //	@name	SubscribeMessageDto
 
+// AllLocationsTopic is the name of the topic
+// with the states of all locations of an organisation.
+func AllLocationsTopic(organisationID string) string {
+	return organisationID + "/*"
+}
+
+// LocationTopic is the name of the topic with the state of a location,
+// location names are only unique within an organisation.
+func LocationTopic(organisationID string, normalizedName string) string {
+	return organisationID + "/locations/" + normalizedName
+}
+
+// StateTopic is the name of the topic with the state of an organisation.
+func StateTopic(organisationID string) string {
+	return organisationID + "/" + string(State)
+}
+
 func (dto SubscribeMessageDto) Topic() string {
 	if dto.Subject == AllLocations {
-		return "*"
+		return AllLocationsTopic(dto.OrganisationID)
 	}
 
 	if dto.Subject == SingleLocation {
-		return dto.NormalizedName
+		return LocationTopic(dto.OrganisationID, dto.NormalizedName)
 	}
 
-	return string(dto.Subject)
+	return StateTopic(dto.OrganisationID)
 }
 
 func (dto SubscribeMessageDto) Validate() (bool, map[string]string) {
 	v := validate.New()
 
+	validate.Check(v, "organisationId", dto.OrganisationID, validate.IsNotEmpty)
+
 	if dto.Subject == SingleLocation {
 		validate.Check(v, "normalizedName", dto.NormalizedName, validate.IsNotEmpty)
 	}
@@ -0,0 +1,30 @@
+package models
+
+import "github.com/jackc/pgx/v5/pgtype"
+
+// APIKey authenticates integrations with an Authorization header,
+// its scopes are the permissions of requests using the key.
+// Only the hash of the key is stored, the key itself is only
+// returned on creation.
+type APIKey struct {
+	ID             string             `json:"id"`
+	Key            string             `json:"key,omitempty"`
+	Name           string             `json:"name"`
+	Scopes         []Permission       `json:"scopes"`
+	OrganisationID string             `json:"-"`
+	Expiry         pgtype.Timestamptz `json:"expiry"     swaggertype:"string"`
+	LastUsedAt     pgtype.Timestamptz `json:"lastUsedAt" swaggertype:"string"`
+	CreatedAt      pgtype.Timestamptz `json:"createdAt"  swaggertype:"string"`
+} //	@name	APIKey
+
+// User returns the user which acts on behalf of the API key.
+func (apiKey *APIKey) User() *User {
+	//nolint:exhaustruct //other fields are optional
+	return &User{
+		ID:             apiKey.ID,
+		Username:       apiKey.Name,
+		Role:           APIKeyRole,
+		Permissions:    apiKey.Scopes,
+		OrganisationID: apiKey.OrganisationID,
+	}
+}
@@ -0,0 +1,46 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+type AuditAction string //	@name	AuditAction
+
+const (
+	AuditCreate   AuditAction = "create"
+	AuditUpdate   AuditAction = "update"
+	AuditDelete   AuditAction = "delete"
+	AuditRestore  AuditAction = "restore"
+	AuditCheckOut AuditAction = "checkOut"
+	AuditLockout  AuditAction = "lockout"
+)
+
+// AuditEvent records a mutation. Before and After only hold the fields
+// which changed, they're null when the entity was created or deleted.
+// The actor is null for mutations which weren't made by a signed in user.
+type AuditEvent struct {
+	ID             int64              `json:"id"`
+	OrganisationID pgtype.Text        `json:"-"`
+	ActorID        pgtype.Text        `json:"actorId"    swaggertype:"string"`
+	ActorRole      pgtype.Text        `json:"actorRole"  swaggertype:"string"`
+	Action         AuditAction        `json:"action"`
+	EntityType     string             `json:"entityType"`
+	EntityID       string             `json:"entityId"`
+	Before         json.RawMessage    `json:"before"     swaggertype:"object"`
+	After          json.RawMessage    `json:"after"      swaggertype:"object"`
+	CreatedAt      pgtype.Timestamptz `json:"createdAt"  swaggertype:"string"`
+} //	@name	AuditEvent
+
+// AuditEventFilter narrows down the audit events which are fetched.
+// Empty fields aren't filtered on.
+type AuditEventFilter struct {
+	ActorID    string
+	Action     AuditAction
+	EntityType string
+	EntityID   string
+	Start      time.Time
+	End        time.Time
+}
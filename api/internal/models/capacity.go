@@ -0,0 +1,30 @@
+package models
+
+import (
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+type CapacityChange struct {
+	ID            int64              `json:"id"`
+	LocationID    string             `json:"locationId"`
+	Capacity      int64              `json:"capacity"`
+	EffectiveFrom pgtype.Timestamptz `json:"effectiveFrom" swaggertype:"string"`
+} //	@name	CapacityChange
+
+// CapacityAt returns the capacity in force at the provided time.
+// The changes should be sorted by EffectiveFrom. If no change
+// was in force yet, the fallback capacity is returned.
+func CapacityAt(changes []*CapacityChange, at time.Time, fallback int64) int64 {
+	capacity := fallback
+	for _, change := range changes {
+		if change.EffectiveFrom.Time.After(at) {
+			break
+		}
+
+		capacity = change.Capacity
+	}
+
+	return capacity
+}
@@ -1,13 +1,36 @@
 package models
 
 import (
+	"time"
+
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
 type CheckIn struct {
-	ID         int64
+	ID             int64
+	LocationID     string
+	SchoolID       int64
+	Capacity       int64
+	CreatedAt      pgtype.Timestamptz
+	IdempotencyKey pgtype.Text
+	CheckedOutAt   pgtype.Timestamptz
+	DeviceID       pgtype.Text
+}
+
+// CheckInCount is the amount of check-ins of a school
+// at a location on a business date.
+type CheckInCount struct {
 	LocationID string
 	SchoolID   int64
-	Capacity   int64
-	CreatedAt  pgtype.Timestamptz
+	Date       time.Time
+	Count      int64
+}
+
+// CheckInHourCount is the amount of check-ins at a location
+// in an hour of a date, both in the time zone of the location.
+type CheckInHourCount struct {
+	LocationID string
+	Date       time.Time
+	Hour       int
+	Count      int64
 }
@@ -0,0 +1,37 @@
+package models
+
+import (
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Device is a kiosk of a location with its own credentials,
+// paired devices have no credentials and only use their tokens.
+// Tokens of a device are issued for the user of its location,
+// revoking a device only signs out that device.
+type Device struct {
+	ID             string             `json:"id"`
+	LocationID     string             `json:"locationId"`
+	Name           string             `json:"name"`
+	Username       pgtype.Text        `json:"username"  swaggertype:"string"`
+	PasswordHash   []byte             `json:"-"`
+	UserID         string             `json:"-"`
+	OrganisationID string             `json:"-"`
+	CreatedAt      pgtype.Timestamptz `json:"createdAt" swaggertype:"string"`
+	RevokedAt      pgtype.Timestamptz `json:"revokedAt" swaggertype:"string"`
+} //	@name	Device
+
+func (device *Device) CompareHashAndPassword(password string) (bool, error) {
+	return compareHashAndPassword(device.PasswordHash, password)
+}
+
+// PairingCode is exchanged once by a kiosk for the tokens of a new device.
+// Only the hash of the code is stored.
+type PairingCode struct {
+	Code       string    `json:"code"`
+	QRPayload  string    `json:"qrPayload"`
+	LocationID string    `json:"locationId"`
+	DeviceName string    `json:"deviceName"`
+	Expiry     time.Time `json:"expiry"`
+} //	@name	PairingCode
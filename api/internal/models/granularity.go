@@ -0,0 +1,52 @@
+package models
+
+import (
+	"slices"
+	"time"
+)
+
+// Granularity is the size of the buckets in which range statistics
+// are aggregated.
+type Granularity string //	@name	Granularity
+
+const (
+	DayGranularity   Granularity = "day"
+	WeekGranularity  Granularity = "week"
+	MonthGranularity Granularity = "month"
+	YearGranularity  Granularity = "year"
+)
+
+func (granularity Granularity) IsValid() bool {
+	return slices.Contains(
+		[]Granularity{
+			DayGranularity,
+			WeekGranularity,
+			MonthGranularity,
+			YearGranularity,
+		},
+		granularity,
+	)
+}
+
+// Start returns the business date on which the bucket containing the
+// provided business date starts, as midnight UTC. Business dates are local
+// to a location, see Location.BusinessDate, so buckets follow the time zone
+// of the location. Weeks are ISO weeks, which start on monday.
+func (granularity Granularity) Start(date time.Time) time.Time {
+	date = time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+
+	switch granularity {
+	case WeekGranularity:
+		//nolint:mnd //no magic number
+		daysSinceMonday := (int(date.Weekday()) + 6) % 7
+		return date.AddDate(0, 0, -daysSinceMonday)
+	case MonthGranularity:
+		return date.AddDate(0, 0, 1-date.Day())
+	case YearGranularity:
+		return date.AddDate(0, 0, 1-date.YearDay())
+	case DayGranularity:
+		return date
+	default:
+		return date
+	}
+}
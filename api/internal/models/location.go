@@ -6,6 +6,8 @@ import (
 
 	"github.com/dlclark/regexp2"
 	"github.com/jackc/pgx/v5/pgtype"
+
+	"check-in/api/internal/constants"
 )
 
 type Location struct {
@@ -13,12 +15,17 @@ type Location struct {
 	Name               string             `json:"name"`
 	NormalizedName     string             `json:"normalizedName"`
 	Available          int64              `json:"available"`
+	Occupancy          int64              `json:"occupancy"`
+	CheckInsToday      int64              `json:"checkInsToday"`
+	WaitlistLength     int64              `json:"waitlistLength"`
 	Capacity           int64              `json:"capacity"`
 	AvailableYesterday int64              `json:"availableYesterday"`
 	CapacityYesterday  int64              `json:"capacityYesterday"`
 	YesterdayFullAt    pgtype.Timestamptz `json:"yesterdayFullAt"    swaggertype:"string"`
 	TimeZone           string             `json:"timeZone"`
+	BusinessDayStart   string             `json:"businessDayStart"`
 	UserID             string             `json:"userId"`
+	OrganisationID     string             `json:"organisationId"`
 } //	@name	Location
 
 func (location *Location) SetFields(
@@ -51,7 +58,15 @@ func (location *Location) SetCheckInRelatedFields(
 		}
 	}
 
-	location.Available = location.Capacity - int64(len(checkInsToday))
+	location.CheckInsToday = int64(len(checkInsToday))
+	location.Occupancy = 0
+	for _, checkIn := range checkInsToday {
+		if !checkIn.CheckedOutAt.Valid {
+			location.Occupancy++
+		}
+	}
+
+	location.Available = location.Capacity - location.Occupancy
 	location.CapacityYesterday = 0
 	//nolint:exhaustruct //other fields are optional
 	location.YesterdayFullAt = pgtype.Timestamptz{}
@@ -80,6 +95,67 @@ func (location *Location) SetCheckInRelatedFields(
 	}
 }
 
+// DayBounds returns the start and end of the business day
+// which starts on the date of the provided time. The business day
+// starts at BusinessDayStart in the time zone of the location.
+func (location *Location) DayBounds(date time.Time) (time.Time, time.Time) {
+	loc := location.timeLocation()
+	offset := location.businessDayOffset()
+
+	start := time.Date(
+		date.Year(),
+		date.Month(),
+		date.Day(),
+		int(offset/time.Hour),
+		int(offset%time.Hour/time.Minute),
+		0,
+		0,
+		loc,
+	)
+	end := start.AddDate(0, 0, 1).Add(-time.Nanosecond)
+
+	return start.UTC(), end.UTC()
+}
+
+// DayBoundsAt returns the start and end of the business day
+// which contains the provided time.
+func (location *Location) DayBoundsAt(at time.Time) (time.Time, time.Time) {
+	return location.DayBounds(location.BusinessDate(at))
+}
+
+// BusinessDate returns the date on which the business day
+// containing the provided time started, as midnight UTC.
+func (location *Location) BusinessDate(at time.Time) time.Time {
+	local := at.In(location.timeLocation())
+	date := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, time.UTC)
+
+	start, _ := location.DayBounds(date)
+	if at.Before(start) {
+		date = date.AddDate(0, 0, -1)
+	}
+
+	return date
+}
+
+func (location *Location) timeLocation() *time.Location {
+	loc, err := time.LoadLocation(location.TimeZone)
+	if err != nil {
+		return time.UTC
+	}
+
+	return loc
+}
+
+func (location *Location) businessDayOffset() time.Duration {
+	start, err := time.Parse(constants.TimeOfDayFormat, location.BusinessDayStart)
+	if err != nil {
+		return 0
+	}
+
+	return time.Duration(start.Hour())*time.Hour +
+		time.Duration(start.Minute())*time.Minute
+}
+
 func (location *Location) NormalizeName() error {
 	output, err := normalize(location.Name)
 	if err != nil {
@@ -0,0 +1,41 @@
+package models
+
+import "github.com/jackc/pgx/v5/pgtype"
+
+// maxLockoutValueLength is the length of lockouts.value.
+const maxLockoutValueLength = 255
+
+type LockoutKind string //	@name	LockoutKind
+
+const (
+	UsernameLockout LockoutKind = "username"
+	IPLockout       LockoutKind = "ip"
+)
+
+// LockoutKey identifies what failed sign in attempts are counted for.
+type LockoutKey struct {
+	Kind  LockoutKind
+	Value string
+}
+
+// NewLockoutKey truncates values which don't fit in the database,
+// values with the same prefix share their failed attempts.
+func NewLockoutKey(kind LockoutKind, value string) LockoutKey {
+	runes := []rune(value)
+	if len(runes) > maxLockoutValueLength {
+		value = string(runes[:maxLockoutValueLength])
+	}
+
+	return LockoutKey{Kind: kind, Value: value}
+}
+
+// Lockout holds the failed sign in attempts for a username or an IP.
+// LockedUntil is only set once too many attempts failed.
+type Lockout struct {
+	Kind           LockoutKind        `json:"kind"`
+	Value          string             `json:"value"`
+	OrganisationID pgtype.Text        `json:"-"`
+	FailedAttempts int64              `json:"failedAttempts"`
+	LastFailedAt   pgtype.Timestamptz `json:"lastFailedAt"   swaggertype:"string"`
+	LockedUntil    pgtype.Timestamptz `json:"lockedUntil"    swaggertype:"string"`
+} //	@name	Lockout
@@ -0,0 +1,8 @@
+package models
+
+// ManagerLocation assigns a location to a manager,
+// managers only manage the locations assigned to them.
+type ManagerLocation struct {
+	UserID     string `json:"userId"`
+	LocationID string `json:"locationId"`
+} //	@name	ManagerLocation
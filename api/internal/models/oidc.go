@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+// OIDCLogin is a sign in which was redirected to the OpenID Connect
+// issuer and hasn't returned to the callback yet.
+type OIDCLogin struct {
+	State        string
+	CodeVerifier string
+	Nonce        string
+	Expiry       time.Time
+}
@@ -0,0 +1,104 @@
+package models
+
+import (
+	"slices"
+	"time"
+
+	"check-in/api/internal/constants"
+)
+
+type OpeningPeriod struct {
+	Weekday  int64  `json:"weekday"`
+	OpensAt  string `json:"opensAt"`
+	ClosesAt string `json:"closesAt"`
+} //	@name	OpeningPeriod
+
+type ClosingDay struct {
+	Date   string `json:"date"`
+	Reason string `json:"reason"`
+} //	@name	ClosingDay
+
+// OpeningHours holds the weekly schedule and the closing days of a location.
+// Periods of which ClosesAt isn't after OpensAt end on the next day.
+// A location without periods is open every day, except on closing days.
+type OpeningHours struct {
+	Periods     []*OpeningPeriod `json:"periods"`
+	ClosingDays []*ClosingDay    `json:"closingDays"`
+} //	@name	OpeningHours
+
+// IsOpenAt checks if the location is open at the provided wall clock time.
+func (openingHours *OpeningHours) IsOpenAt(at time.Time) bool {
+	minutes := at.Hour()*60 + at.Minute() //nolint:mnd //no magic number
+
+	if !openingHours.isClosingDay(at) {
+		if len(openingHours.Periods) == 0 {
+			return true
+		}
+
+		for _, period := range openingHours.periodsOn(at.Weekday()) {
+			opensAt, closesAt := period.minutes()
+			if minutes >= opensAt && (closesAt <= opensAt || minutes < closesAt) {
+				return true
+			}
+		}
+	}
+
+	previousDay := at.AddDate(0, 0, -1)
+	if openingHours.isClosingDay(previousDay) {
+		return false
+	}
+
+	for _, period := range openingHours.periodsOn(previousDay.Weekday()) {
+		opensAt, closesAt := period.minutes()
+		if closesAt <= opensAt && minutes < closesAt {
+			return true
+		}
+	}
+
+	return false
+}
+
+// IsClosedOn checks if the location doesn't open on the provided date.
+func (openingHours *OpeningHours) IsClosedOn(date time.Time) bool {
+	if openingHours.isClosingDay(date) {
+		return true
+	}
+
+	return len(openingHours.Periods) > 0 &&
+		len(openingHours.periodsOn(date.Weekday())) == 0
+}
+
+func (openingHours *OpeningHours) isClosingDay(date time.Time) bool {
+	dateString := date.Format(constants.DateFormat)
+
+	return slices.ContainsFunc(
+		openingHours.ClosingDays,
+		func(closingDay *ClosingDay) bool {
+			return closingDay.Date == dateString
+		},
+	)
+}
+
+func (openingHours *OpeningHours) periodsOn(weekday time.Weekday) []*OpeningPeriod {
+	periods := []*OpeningPeriod{}
+	for _, period := range openingHours.Periods {
+		if time.Weekday(period.Weekday) == weekday {
+			periods = append(periods, period)
+		}
+	}
+
+	return periods
+}
+
+func (period *OpeningPeriod) minutes() (int, int) {
+	return minutesOfDay(period.OpensAt), minutesOfDay(period.ClosesAt)
+}
+
+func minutesOfDay(timeOfDay string) int {
+	value, err := time.Parse(constants.TimeOfDayFormat, timeOfDay)
+	if err != nil {
+		return 0
+	}
+
+	return value.Hour()*60 + value.Minute() //nolint:mnd //no magic number
+}
@@ -0,0 +1,9 @@
+package models
+
+import "github.com/jackc/pgx/v5/pgtype"
+
+type Organisation struct {
+	ID        string             `json:"id"`
+	Name      string             `json:"name"`
+	CreatedAt pgtype.Timestamptz `json:"createdAt" swaggertype:"string"`
+} //	@name	Organisation
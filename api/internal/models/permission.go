@@ -0,0 +1,106 @@
+package models
+
+import (
+	"slices"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+type Permission string //	@name	Permission
+
+const (
+	AuditReadPermission          Permission = "audit:read"
+	CheckInsWritePermission      Permission = "checkins:write"
+	CheckInsDeletePermission     Permission = "checkins:delete"
+	StatsReadPermission          Permission = "stats:read"
+	StatsExportPermission        Permission = "stats:export"
+	LocationsReadPermission      Permission = "locations:read"
+	LocationsListPermission      Permission = "locations:list"
+	LocationsWritePermission     Permission = "locations:write"
+	LocationsUpdatePermission    Permission = "locations:update"
+	LocationsDeletePermission    Permission = "locations:delete"
+	LocationsRestorePermission   Permission = "locations:restore"
+	SchoolsReadPermission        Permission = "schools:read"
+	SchoolsWritePermission       Permission = "schools:write"
+	SchoolsRestorePermission     Permission = "schools:restore"
+	StateWritePermission         Permission = "state:write"
+	UsersReadPermission          Permission = "users:read"
+	UsersListPermission          Permission = "users:list"
+	UsersWritePermission         Permission = "users:write"
+	RolesReadPermission          Permission = "roles:read"
+	RolesWritePermission         Permission = "roles:write"
+	OrganisationsReadPermission  Permission = "organisations:read"
+	OrganisationsWritePermission Permission = "organisations:write"
+	DevicesReadPermission        Permission = "devices:read"
+	DevicesWritePermission       Permission = "devices:write"
+	APIKeysReadPermission        Permission = "apikeys:read"
+	APIKeysWritePermission       Permission = "apikeys:write"
+	LockoutsReadPermission       Permission = "lockouts:read"
+	LockoutsWritePermission      Permission = "lockouts:write"
+)
+
+//nolint:gochecknoglobals //list of all known permissions
+var AllPermissions = []Permission{
+	AuditReadPermission,
+	CheckInsWritePermission,
+	CheckInsDeletePermission,
+	StatsReadPermission,
+	StatsExportPermission,
+	LocationsReadPermission,
+	LocationsListPermission,
+	LocationsWritePermission,
+	LocationsUpdatePermission,
+	LocationsDeletePermission,
+	LocationsRestorePermission,
+	SchoolsReadPermission,
+	SchoolsWritePermission,
+	SchoolsRestorePermission,
+	StateWritePermission,
+	UsersReadPermission,
+	UsersListPermission,
+	UsersWritePermission,
+	RolesReadPermission,
+	RolesWritePermission,
+	OrganisationsReadPermission,
+	OrganisationsWritePermission,
+	DevicesReadPermission,
+	DevicesWritePermission,
+	APIKeysReadPermission,
+	APIKeysWritePermission,
+	LockoutsReadPermission,
+	LockoutsWritePermission,
+}
+
+// nonGrantablePermissions can't be part of a custom role. They either reach
+// beyond the organisation or are reserved for its admins,
+// a manager with a custom role mustn't be able to manage users or roles.
+//
+//nolint:gochecknoglobals //list of permissions which can't be granted
+var nonGrantablePermissions = []Permission{
+	UsersWritePermission,
+	RolesWritePermission,
+	OrganisationsReadPermission,
+	OrganisationsWritePermission,
+	APIKeysWritePermission,
+	LockoutsWritePermission,
+}
+
+// IsGrantable checks if the permission can be part of a custom role.
+func (permission Permission) IsGrantable() bool {
+	return !slices.Contains(nonGrantablePermissions, permission)
+}
+
+// RoleDefinition bundles permissions under the name of a role.
+// Built-in roles are shared by all organisations and can't be changed,
+// custom roles belong to an organisation and are assigned to managers.
+type RoleDefinition struct {
+	ID             string       `json:"id"`
+	Name           string       `json:"name"`
+	OrganisationID pgtype.Text  `json:"-"`
+	BuiltIn        bool         `json:"builtIn"`
+	Permissions    []Permission `json:"permissions"`
+} //	@name	RoleDefinition
+
+func (user *User) HasPermission(permission Permission) bool {
+	return slices.Contains(user.Permissions, permission)
+}
@@ -0,0 +1,21 @@
+package models
+
+import (
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Session is a sign in of a user, it lasts as long as its tokens.
+// Current is true for the session of the request.
+type Session struct {
+	ID         string      `json:"id"`
+	UserID     string      `json:"-"`
+	DeviceID   pgtype.Text `json:"-"`
+	UserAgent  string      `json:"userAgent"`
+	IP         string      `json:"ip"`
+	CreatedAt  time.Time   `json:"createdAt"`
+	LastUsedAt time.Time   `json:"lastUsedAt"`
+	Expiry     time.Time   `json:"expiry"`
+	Current    bool        `json:"current"`
+} //	@name	Session
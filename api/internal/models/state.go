@@ -1,12 +1,18 @@
 package models
 
+import "github.com/jackc/pgx/v5/pgtype"
+
 type State struct {
-	IsMaintenance    bool `json:"isMaintenance"`
-	IsDatabaseActive bool `json:"isDatabaseActive"`
+	IsMaintenance    bool               `json:"isMaintenance"`
+	IsDatabaseActive bool               `json:"isDatabaseActive"`
+	MaintenanceStart pgtype.Timestamptz `json:"maintenanceStart" swaggertype:"string"`
+	MaintenanceEnd   pgtype.Timestamptz `json:"maintenanceEnd"   swaggertype:"string"`
 } //	@name	State
 
 type StateKey string
 
 const (
-	IsMaintenanceKey StateKey = "IsMaintenance"
+	IsMaintenanceKey    StateKey = "IsMaintenance"
+	MaintenanceStartKey StateKey = "MaintenanceStart"
+	MaintenanceEndKey   StateKey = "MaintenanceEnd"
 )
@@ -1,6 +1,10 @@
 package models
 
-import "time"
+import (
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
 
 type Scope int
 
@@ -16,4 +20,6 @@ type Token struct {
 	Expiry    time.Time
 	Scope     Scope
 	Used      bool
+	DeviceID  pgtype.Text
+	SessionID pgtype.Text
 }
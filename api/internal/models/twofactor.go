@@ -0,0 +1,25 @@
+package models
+
+import "github.com/jackc/pgx/v5/pgtype"
+
+// TwoFactor is the TOTP configuration of a user, it's pending
+// until a code generated with its secret was verified.
+type TwoFactor struct {
+	UserID   string
+	Secret   []byte
+	Enabled  bool
+	LastStep pgtype.Int8
+}
+
+// TwoFactorEnrolment is added to an authenticator app,
+// either by scanning the URI as a QR code or by typing the secret.
+type TwoFactorEnrolment struct {
+	Secret string `json:"secret"`
+	URI    string `json:"uri"`
+} //	@name	TwoFactorEnrolment
+
+// RecoveryCodes can each be used once instead of a TOTP code.
+// Only their hashes are stored, the codes are only returned once.
+type RecoveryCodes struct {
+	RecoveryCodes []string `json:"recoveryCodes"`
+} //	@name	RecoveryCodes
@@ -3,6 +3,7 @@ package models
 import (
 	"errors"
 
+	"github.com/jackc/pgx/v5/pgtype"
 	"golang.org/x/crypto/bcrypt"
 )
 
@@ -12,16 +13,40 @@ const (
 	DefaultRole Role = "default"
 	ManagerRole Role = "manager"
 	AdminRole   Role = "admin"
+	// SuperAdminRole manages organisations,
+	// within its own organisation it acts as an admin.
+	SuperAdminRole Role = "superadmin"
+	// APIKeyRole is the role of requests authenticated with an API key,
+	// like an admin it sees all data of its organisation.
+	APIKeyRole Role = "apikey"
 )
 
 type User struct {
-	ID           string    `json:"id"`
-	Username     string    `json:"username"`
-	PasswordHash []byte    `json:"-"`
-	Role         Role      `json:"role"`
-	Location     *Location `json:"location"`
+	ID                string       `json:"id"`
+	Username          string       `json:"username"`
+	PasswordHash      []byte       `json:"-"`
+	Role              Role         `json:"role"`
+	CustomRoleID      pgtype.Text  `json:"customRoleId"          swaggertype:"string"`
+	Permissions       []Permission `json:"permissions,omitempty"`
+	OrganisationID    string       `json:"organisationId"`
+	DeviceID          pgtype.Text  `json:"deviceId"              swaggertype:"string"`
+	SessionID         pgtype.Text  `json:"-"`
+	TwoFactorEnabled  bool         `json:"twoFactorEnabled"`
+	TwoFactorRequired bool         `json:"twoFactorRequired"`
+	Location          *Location    `json:"location"`
 } //	@name	User
 
+// NeedsTwoFactor is true when an admin requires two-factor authentication
+// for the user, but the user didn't enable it yet.
+func (user *User) NeedsTwoFactor() bool {
+	return user.TwoFactorRequired && !user.TwoFactorEnabled
+}
+
+// IsAdmin is true for admins and super admins.
+func (role Role) IsAdmin() bool {
+	return role == AdminRole || role == SuperAdminRole
+}
+
 func HashPassword(password string) ([]byte, error) {
 	return bcrypt.GenerateFromPassword(
 		[]byte(password),
@@ -30,7 +55,11 @@ func HashPassword(password string) ([]byte, error) {
 }
 
 func (user *User) CompareHashAndPassword(password string) (bool, error) {
-	err := bcrypt.CompareHashAndPassword(user.PasswordHash, []byte(password))
+	return compareHashAndPassword(user.PasswordHash, password)
+}
+
+func compareHashAndPassword(hash []byte, password string) (bool, error) {
+	err := bcrypt.CompareHashAndPassword(hash, []byte(password))
 	if err != nil {
 		switch {
 		case errors.Is(err, bcrypt.ErrMismatchedHashAndPassword):
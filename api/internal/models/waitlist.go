@@ -0,0 +1,13 @@
+package models
+
+import "github.com/jackc/pgx/v5/pgtype"
+
+type WaitlistEntry struct {
+	ID         int64
+	LocationID string
+	SchoolID   int64
+	ShortCode  pgtype.Text
+	CreatedAt  pgtype.Timestamptz
+	PromotedAt pgtype.Timestamptz
+	CheckInID  pgtype.Int8
+}
@@ -0,0 +1,269 @@
+package oidc
+
+import (
+	"context"
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+)
+
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+}
+
+type jwtHeader struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+}
+
+type audience []string
+
+// UnmarshalJSON accepts a single audience as well as a list.
+func (aud *audience) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*aud = audience{single}
+		return nil
+	}
+
+	var multiple []string
+	if err := json.Unmarshal(data, &multiple); err != nil {
+		return err
+	}
+
+	*aud = multiple
+	return nil
+}
+
+type idTokenClaims struct {
+	Issuer            string   `json:"iss"`
+	Subject           string   `json:"sub"`
+	Audience          audience `json:"aud"`
+	Expiry            int64    `json:"exp"`
+	NotBefore         int64    `json:"nbf"`
+	IssuedAt          int64    `json:"iat"`
+	Nonce             string   `json:"nonce"`
+	PreferredUsername string   `json:"preferred_username"`
+}
+
+// verify checks the signature and the claims of an ID token.
+func (provider *Provider) verify(
+	ctx context.Context,
+	rawIDToken string,
+	nonce string,
+	now time.Time,
+) (*Claims, error) {
+	parts := strings.Split(rawIDToken, ".")
+	if len(parts) != 3 { //nolint:mnd //header, payload and signature
+		return nil, fmt.Errorf("%w: malformed token", ErrInvalidIDToken)
+	}
+
+	var header jwtHeader
+
+	err := decodeSegment(parts[0], &header)
+	if err != nil {
+		return nil, err
+	}
+
+	if header.Alg != "RS256" {
+		return nil, fmt.Errorf(
+			"%w: unsupported algorithm '%s'",
+			ErrInvalidIDToken,
+			header.Alg,
+		)
+	}
+
+	key, err := provider.key(ctx, header.Kid, now)
+	if err != nil {
+		return nil, err
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidIDToken, err)
+	}
+
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+
+	err = rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], signature)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidIDToken, err)
+	}
+
+	var claims idTokenClaims
+
+	err = decodeSegment(parts[1], &claims)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case claims.Issuer != provider.config.Issuer:
+		return nil, fmt.Errorf("%w: unexpected issuer", ErrInvalidIDToken)
+	case !slices.Contains(claims.Audience, provider.config.ClientID):
+		return nil, fmt.Errorf("%w: unexpected audience", ErrInvalidIDToken)
+	case now.After(time.Unix(claims.Expiry, 0).Add(clockSkew)):
+		return nil, fmt.Errorf("%w: token expired", ErrInvalidIDToken)
+	case claims.NotBefore != 0 &&
+		now.Add(clockSkew).Before(time.Unix(claims.NotBefore, 0)):
+		return nil, fmt.Errorf("%w: token not valid yet", ErrInvalidIDToken)
+	case claims.IssuedAt == 0:
+		return nil, fmt.Errorf("%w: missing issued at", ErrInvalidIDToken)
+	case now.Add(clockSkew).Before(time.Unix(claims.IssuedAt, 0)):
+		return nil, fmt.Errorf("%w: token issued in the future", ErrInvalidIDToken)
+	case claims.Nonce != nonce:
+		return nil, fmt.Errorf("%w: unexpected nonce", ErrInvalidIDToken)
+	case claims.Subject == "":
+		return nil, fmt.Errorf("%w: missing subject", ErrInvalidIDToken)
+	}
+
+	groups, err := provider.groups(parts[1])
+	if err != nil {
+		return nil, err
+	}
+
+	return &Claims{
+		Subject:           claims.Subject,
+		PreferredUsername: claims.PreferredUsername,
+		Groups:            groups,
+	}, nil
+}
+
+// groups reads the configured groups claim, which is missing
+// when the user isn't a member of any group.
+func (provider *Provider) groups(payload string) ([]string, error) {
+	var claims map[string]json.RawMessage
+
+	err := decodeSegment(payload, &claims)
+	if err != nil {
+		return nil, err
+	}
+
+	rawGroups, ok := claims[provider.config.GroupsClaim]
+	if !ok {
+		return []string{}, nil
+	}
+
+	var groups []string
+
+	err = json.Unmarshal(rawGroups, &groups)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid groups claim", ErrInvalidIDToken)
+	}
+
+	return groups, nil
+}
+
+// key returns the public key with the given ID, the keys are fetched
+// again when the key is unknown as the issuer might have rotated its keys.
+// The keys are fetched at most once per keysRefetchInterval and
+// without holding the lock, so other sign ins don't wait on the issuer.
+func (provider *Provider) key(
+	ctx context.Context,
+	kid string,
+	now time.Time,
+) (*rsa.PublicKey, error) {
+	metadata, err := provider.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	provider.mu.Lock()
+	key, ok := provider.keys[kid]
+	refetch := !ok && now.Sub(provider.keysFetchedAt) >= keysRefetchInterval
+	if refetch {
+		provider.keysFetchedAt = now
+	}
+	provider.mu.Unlock()
+
+	if refetch {
+		var keys map[string]*jwk
+
+		keys, err = provider.fetchKeys(ctx, metadata.JWKSURI)
+		if err != nil {
+			return nil, err
+		}
+
+		provider.mu.Lock()
+		provider.keys = keys
+		provider.mu.Unlock()
+
+		key, ok = keys[kid]
+	}
+
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown key '%s'", ErrInvalidIDToken, kid)
+	}
+
+	return key.publicKey()
+}
+
+func (provider *Provider) fetchKeys(
+	ctx context.Context,
+	jwksURI string,
+) (map[string]*jwk, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, jwksURI, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var jwks struct {
+		Keys []*jwk `json:"keys"`
+	}
+
+	err = provider.do(req, &jwks)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make(map[string]*jwk)
+	for _, key := range jwks.Keys {
+		if key.Kty == "RSA" {
+			keys[key.Kid] = key
+		}
+	}
+
+	return keys, nil
+}
+
+func (key *jwk) publicKey() (*rsa.PublicKey, error) {
+	n, err := base64.RawURLEncoding.DecodeString(key.N)
+	if err != nil {
+		return nil, err
+	}
+
+	e, err := base64.RawURLEncoding.DecodeString(key.E)
+	if err != nil {
+		return nil, err
+	}
+
+	return &rsa.PublicKey{
+		N: new(big.Int).SetBytes(n),
+		E: int(new(big.Int).SetBytes(e).Int64()),
+	}, nil
+}
+
+func decodeSegment(segment string, result any) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidIDToken, err)
+	}
+
+	err = json.Unmarshal(data, result)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidIDToken, err)
+	}
+
+	return nil
+}
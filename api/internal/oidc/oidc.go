@@ -0,0 +1,233 @@
+// Package oidc implements the authorization code flow with PKCE
+// of OpenID Connect. ID tokens have to be signed with RS256.
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	requestTimeout = 10 * time.Second
+	// clockSkew is allowed between the issuer and the API.
+	clockSkew = time.Minute
+	// keysRefetchInterval limits how often the keys are fetched again
+	// for an unknown key ID, so made up key IDs can't flood the issuer.
+	keysRefetchInterval = time.Minute
+)
+
+var ErrInvalidIDToken = errors.New("invalid ID token")
+
+type Config struct {
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
+	GroupsClaim  string
+}
+
+// Claims are the claims of a verified ID token.
+type Claims struct {
+	Subject           string
+	PreferredUsername string
+	Groups            []string
+}
+
+// Provider discovers the endpoints and keys of the issuer on first use.
+type Provider struct {
+	config Config
+	client *http.Client
+
+	mu            *sync.Mutex
+	metadata      *metadata
+	keys          map[string]*jwk
+	keysFetchedAt time.Time
+}
+
+type metadata struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+func NewProvider(config Config) *Provider {
+	return &Provider{
+		config:        config,
+		client:        &http.Client{Timeout: requestTimeout},
+		mu:            &sync.Mutex{},
+		metadata:      nil,
+		keys:          nil,
+		keysFetchedAt: time.Time{},
+	}
+}
+
+// RandomString returns a random URL safe string,
+// used for the state, the nonce and the code verifier.
+func RandomString() (string, error) {
+	randomBytes := make([]byte, 32) //nolint:mnd //no magic number
+
+	_, err := rand.Read(randomBytes)
+	if err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(randomBytes), nil
+}
+
+// AuthCodeURL returns the URL of the issuer to which users are redirected.
+func (provider *Provider) AuthCodeURL(
+	ctx context.Context,
+	state string,
+	nonce string,
+	codeVerifier string,
+) (string, error) {
+	metadata, err := provider.discover(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	authURL, err := url.Parse(metadata.AuthorizationEndpoint)
+	if err != nil {
+		return "", err
+	}
+
+	challenge := sha256.Sum256([]byte(codeVerifier))
+
+	query := authURL.Query()
+	query.Set("response_type", "code")
+	query.Set("client_id", provider.config.ClientID)
+	query.Set("redirect_uri", provider.config.RedirectURL)
+	query.Set("scope", strings.Join(provider.config.Scopes, " "))
+	query.Set("state", state)
+	query.Set("nonce", nonce)
+	query.Set("code_challenge", base64.RawURLEncoding.EncodeToString(challenge[:]))
+	query.Set("code_challenge_method", "S256")
+	authURL.RawQuery = query.Encode()
+
+	return authURL.String(), nil
+}
+
+// Exchange exchanges an authorization code for an ID token
+// and verifies the ID token.
+func (provider *Provider) Exchange(
+	ctx context.Context,
+	code string,
+	codeVerifier string,
+	nonce string,
+	now time.Time,
+) (*Claims, error) {
+	metadata, err := provider.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", provider.config.RedirectURL)
+	form.Set("code_verifier", codeVerifier)
+
+	req, err := http.NewRequestWithContext(
+		ctx,
+		http.MethodPost,
+		metadata.TokenEndpoint,
+		strings.NewReader(form.Encode()),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.SetBasicAuth(
+		url.QueryEscape(provider.config.ClientID),
+		url.QueryEscape(provider.config.ClientSecret),
+	)
+
+	var tokenResponse struct {
+		IDToken string `json:"id_token"`
+	}
+
+	err = provider.do(req, &tokenResponse)
+	if err != nil {
+		return nil, err
+	}
+
+	return provider.verify(ctx, tokenResponse.IDToken, nonce, now)
+}
+
+func (provider *Provider) discover(ctx context.Context) (*metadata, error) {
+	provider.mu.Lock()
+	cached := provider.metadata
+	provider.mu.Unlock()
+
+	// fetched without holding the lock so a slow issuer doesn't block
+	// every other request, concurrent first uses might both fetch
+	if cached != nil {
+		return cached, nil
+	}
+
+	req, err := http.NewRequestWithContext(
+		ctx,
+		http.MethodGet,
+		strings.TrimSuffix(provider.config.Issuer, "/")+
+			"/.well-known/openid-configuration",
+		nil,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	var result metadata
+
+	err = provider.do(req, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	if result.Issuer != provider.config.Issuer {
+		return nil, fmt.Errorf(
+			"issuer '%s' doesn't match the configured issuer '%s'",
+			result.Issuer,
+			provider.config.Issuer,
+		)
+	}
+
+	provider.mu.Lock()
+	provider.metadata = &result
+	provider.mu.Unlock()
+
+	return &result, nil
+}
+
+func (provider *Provider) do(req *http.Request, result any) error {
+	rs, err := provider.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer rs.Body.Close()
+
+	if rs.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(rs.Body, 1024)) //nolint:mnd //enough
+		return fmt.Errorf(
+			"request to '%s' failed with status %d: %s",
+			req.URL,
+			rs.StatusCode,
+			body,
+		)
+	}
+
+	return json.NewDecoder(rs.Body).Decode(result)
+}
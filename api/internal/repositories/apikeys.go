@@ -0,0 +1,189 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/XDoubleU/essentia/pkg/database"
+	"github.com/XDoubleU/essentia/pkg/database/postgres"
+
+	"check-in/api/internal/models"
+	"check-in/api/internal/shared"
+)
+
+type APIKeyRepository struct {
+	db            postgres.DB
+	getTimeNowUTC shared.UTCNowTimeProvider
+}
+
+func (repo APIKeyRepository) GetAll(
+	ctx context.Context,
+	organisationID string,
+) ([]*models.APIKey, error) {
+	query := `
+		SELECT id, name, scopes, (expiry AT TIME ZONE 'utc'),
+		 (last_used_at AT TIME ZONE 'utc'), (created_at AT TIME ZONE 'utc')
+		FROM api_keys
+		WHERE organisation_id = $1
+		ORDER BY created_at ASC
+	`
+
+	rows, err := repo.db.Query(ctx, query, organisationID)
+	if err != nil {
+		return nil, postgres.PgxErrorToHTTPError(err)
+	}
+
+	apiKeys := []*models.APIKey{}
+	for rows.Next() {
+		//nolint:exhaustruct //other fields are optional
+		apiKey := models.APIKey{
+			OrganisationID: organisationID,
+		}
+
+		var scopes []string
+
+		err = rows.Scan(
+			&apiKey.ID,
+			&apiKey.Name,
+			&scopes,
+			&apiKey.Expiry,
+			&apiKey.LastUsedAt,
+			&apiKey.CreatedAt,
+		)
+		if err != nil {
+			return nil, postgres.PgxErrorToHTTPError(err)
+		}
+
+		apiKey.Scopes = toPermissions(scopes)
+		apiKeys = append(apiKeys, &apiKey)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, postgres.PgxErrorToHTTPError(err)
+	}
+
+	return apiKeys, nil
+}
+
+func (repo APIKeyRepository) GetByID(
+	ctx context.Context,
+	organisationID string,
+	id string,
+) (*models.APIKey, error) {
+	query := `
+		SELECT name, scopes, (expiry AT TIME ZONE 'utc'),
+		 (last_used_at AT TIME ZONE 'utc'), (created_at AT TIME ZONE 'utc')
+		FROM api_keys
+		WHERE id = $1 AND organisation_id = $2
+	`
+
+	//nolint:exhaustruct //other fields are optional
+	apiKey := models.APIKey{
+		ID:             id,
+		OrganisationID: organisationID,
+	}
+
+	var scopes []string
+
+	err := repo.db.QueryRow(ctx, query, id, organisationID).Scan(
+		&apiKey.Name,
+		&scopes,
+		&apiKey.Expiry,
+		&apiKey.LastUsedAt,
+		&apiKey.CreatedAt,
+	)
+	if err != nil {
+		return nil, postgres.PgxErrorToHTTPError(err)
+	}
+
+	apiKey.Scopes = toPermissions(scopes)
+
+	return &apiKey, nil
+}
+
+// GetByHash returns an API key which hasn't expired yet
+// and sets the time it was last used.
+func (repo APIKeyRepository) GetByHash(
+	ctx context.Context,
+	hash [32]byte,
+) (*models.APIKey, error) {
+	query := `
+		UPDATE api_keys
+		SET last_used_at = $2
+		WHERE hash = $1 AND (expiry IS NULL OR expiry > $2)
+		RETURNING id, name, scopes, organisation_id,
+		 (expiry AT TIME ZONE 'utc'), (last_used_at AT TIME ZONE 'utc'),
+		 (created_at AT TIME ZONE 'utc')
+	`
+
+	//nolint:exhaustruct //other fields are optional
+	apiKey := models.APIKey{}
+
+	var scopes []string
+
+	err := repo.db.QueryRow(ctx, query, hash[:], repo.getTimeNowUTC()).Scan(
+		&apiKey.ID,
+		&apiKey.Name,
+		&scopes,
+		&apiKey.OrganisationID,
+		&apiKey.Expiry,
+		&apiKey.LastUsedAt,
+		&apiKey.CreatedAt,
+	)
+	if err != nil {
+		return nil, postgres.PgxErrorToHTTPError(err)
+	}
+
+	apiKey.Scopes = toPermissions(scopes)
+
+	return &apiKey, nil
+}
+
+func (repo APIKeyRepository) Create(
+	ctx context.Context,
+	apiKey *models.APIKey,
+	hash [32]byte,
+) error {
+	query := `
+		INSERT INTO api_keys (hash, organisation_id, name, scopes, expiry)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, (created_at AT TIME ZONE 'utc')
+	`
+
+	err := repo.db.QueryRow(
+		ctx,
+		query,
+		hash[:],
+		apiKey.OrganisationID,
+		apiKey.Name,
+		fromPermissions(apiKey.Scopes),
+		apiKey.Expiry,
+	).Scan(&apiKey.ID, &apiKey.CreatedAt)
+	if err != nil {
+		return postgres.PgxErrorToHTTPError(err)
+	}
+
+	return nil
+}
+
+func (repo APIKeyRepository) Delete(
+	ctx context.Context,
+	organisationID string,
+	id string,
+) error {
+	query := `
+		DELETE FROM api_keys
+		WHERE id = $1 AND organisation_id = $2
+	`
+
+	result, err := repo.db.Exec(ctx, query, id, organisationID)
+	if err != nil {
+		return postgres.PgxErrorToHTTPError(err)
+	}
+
+	rowsAffected := result.RowsAffected()
+	if rowsAffected == 0 {
+		return database.ErrResourceNotFound
+	}
+
+	return nil
+}
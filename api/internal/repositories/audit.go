@@ -0,0 +1,157 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/XDoubleU/essentia/pkg/database/postgres"
+
+	"check-in/api/internal/models"
+	"check-in/api/internal/shared"
+)
+
+type AuditRepository struct {
+	db            postgres.DB
+	getTimeNowUTC shared.UTCNowTimeProvider
+}
+
+const auditEventFilterClause = `
+	WHERE organisation_id = $1
+	AND ($2::text = '' OR actor_id::text = $2)
+	AND ($3::text = '' OR action = $3)
+	AND ($4::text = '' OR entity_type = $4)
+	AND ($5::text = '' OR entity_id = $5)
+	AND ($6::timestamptz IS NULL OR created_at >= $6)
+	AND ($7::timestamptz IS NULL OR created_at <= $7)
+`
+
+func (repo AuditRepository) GetTotalCount(
+	ctx context.Context,
+	organisationID string,
+	filter models.AuditEventFilter,
+) (*int64, error) {
+	query := `
+		SELECT COUNT(*)
+		FROM audit_events
+	` + auditEventFilterClause
+
+	var total *int64
+
+	err := repo.db.QueryRow(
+		ctx,
+		query,
+		auditEventFilterArgs(organisationID, filter)...,
+	).Scan(&total)
+	if err != nil {
+		return nil, postgres.PgxErrorToHTTPError(err)
+	}
+
+	return total, nil
+}
+
+func (repo AuditRepository) GetAllPaginated(
+	ctx context.Context,
+	organisationID string,
+	filter models.AuditEventFilter,
+	limit int64,
+	offset int64,
+) ([]*models.AuditEvent, error) {
+	query := `
+		SELECT id, actor_id::text, actor_role, action, entity_type, entity_id,
+		 before, after, (created_at AT TIME ZONE 'utc')
+		FROM audit_events
+	` + auditEventFilterClause + `
+		ORDER BY created_at DESC, id DESC
+		LIMIT $8 OFFSET $9
+	`
+
+	args := append(auditEventFilterArgs(organisationID, filter), limit, offset)
+
+	rows, err := repo.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, postgres.PgxErrorToHTTPError(err)
+	}
+
+	events := []*models.AuditEvent{}
+	for rows.Next() {
+		var event models.AuditEvent
+
+		err = rows.Scan(
+			&event.ID,
+			&event.ActorID,
+			&event.ActorRole,
+			&event.Action,
+			&event.EntityType,
+			&event.EntityID,
+			&event.Before,
+			&event.After,
+			&event.CreatedAt,
+		)
+		if err != nil {
+			return nil, postgres.PgxErrorToHTTPError(err)
+		}
+
+		events = append(events, &event)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, postgres.PgxErrorToHTTPError(err)
+	}
+
+	return events, nil
+}
+
+func (repo AuditRepository) Create(
+	ctx context.Context,
+	event *models.AuditEvent,
+) error {
+	query := `
+		INSERT INTO audit_events
+		 (organisation_id, actor_id, actor_role, action, entity_type, entity_id,
+		 before, after, created_at)
+		VALUES ($1::uuid, $2::uuid, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING id, (created_at AT TIME ZONE 'utc')
+	`
+
+	err := repo.db.QueryRow(
+		ctx,
+		query,
+		event.OrganisationID,
+		event.ActorID,
+		event.ActorRole,
+		event.Action,
+		event.EntityType,
+		event.EntityID,
+		event.Before,
+		event.After,
+		repo.getTimeNowUTC(),
+	).Scan(&event.ID, &event.CreatedAt)
+	if err != nil {
+		return postgres.PgxErrorToHTTPError(err)
+	}
+
+	return nil
+}
+
+func auditEventFilterArgs(
+	organisationID string,
+	filter models.AuditEventFilter,
+) []any {
+	var start, end *time.Time
+	if !filter.Start.IsZero() {
+		start = &filter.Start
+	}
+	if !filter.End.IsZero() {
+		end = &filter.End
+	}
+
+	return []any{
+		organisationID,
+		filter.ActorID,
+		string(filter.Action),
+		filter.EntityType,
+		filter.EntityID,
+		start,
+		end,
+	}
+}
@@ -17,8 +17,8 @@ type AuthRepository struct {
 
 func (repo AuthRepository) CreateToken(ctx context.Context, token *models.Token) error {
 	query := `
-		INSERT INTO tokens (hash, user_id, expiry, scope)
-		VALUES ($1, $2, $3, $4)
+		INSERT INTO tokens (hash, user_id, expiry, scope, device_id, session_id)
+		VALUES ($1, $2, $3, $4, $5, $6)
 	`
 
 	_, err := repo.db.Exec(
@@ -28,6 +28,8 @@ func (repo AuthRepository) CreateToken(ctx context.Context, token *models.Token)
 		token.UserID,
 		token.Expiry,
 		token.Scope,
+		token.DeviceID,
+		token.SessionID,
 	)
 
 	return err
@@ -57,46 +59,81 @@ func (repo AuthRepository) GetToken(
 	ctx context.Context,
 	scope models.Scope,
 	tokenHash [32]byte,
-) (*models.Token, *string, *models.Role, error) {
+) (*models.Token, *models.User, error) {
+	// a custom role replaces the permissions of the built-in role
 	query := `
-		SELECT tokens.used, users.id, users.role
+		SELECT tokens.used, tokens.device_id::text, tokens.session_id::text,
+		 users.id, users.role,
+		 users.custom_role_id::text, users.organisation_id,
+		 COALESCE(roles.permissions, '{}')
 		FROM users
 		INNER JOIN tokens
 		ON tokens.user_id = users.id
+		LEFT JOIN roles
+		ON (users.custom_role_id IS NOT NULL AND roles.id = users.custom_role_id)
+		OR (users.custom_role_id IS NULL AND roles.organisation_id IS NULL
+		 AND roles.name = users.role)
 		WHERE tokens.hash = $1
 		AND tokens.scope = $2
+		AND users.archived_at IS NULL
 		AND tokens.expiry > $3
 	`
 
 	args := []any{tokenHash[:], scope, repo.getTimeNowUTC()}
 
 	var token models.Token
-	var userID string
-	var userRole models.Role
-
-	err := repo.db.QueryRow(ctx, query, args...).
-		Scan(&token.Used, &userID, &userRole)
-
+	//nolint:exhaustruct //other fields are optional
+	user := models.User{}
+
+	var permissions []string
+
+	err := repo.db.QueryRow(ctx, query, args...).Scan(
+		&token.Used,
+		&token.DeviceID,
+		&token.SessionID,
+		&user.ID,
+		&user.Role,
+		&user.CustomRoleID,
+		&user.OrganisationID,
+		&permissions,
+	)
 	if err != nil {
-		return nil, nil, nil, postgres.PgxErrorToHTTPError(err)
+		return nil, nil, postgres.PgxErrorToHTTPError(err)
 	}
 
-	return &token, &userID, &userRole, nil
+	user.Permissions = toPermissions(permissions)
+
+	return &token, &user, nil
 }
 
+// DeleteAllTokensForUser doesn't delete the tokens of devices,
+// those are deleted per device with DeleteAllTokensForDevice.
 func (repo AuthRepository) DeleteAllTokensForUser(
 	ctx context.Context,
 	userID string,
 ) error {
 	query := `
 		DELETE FROM tokens
-		WHERE user_id = $1
+		WHERE user_id = $1 AND device_id IS NULL
 	`
 
 	_, err := repo.db.Exec(ctx, query, userID)
 	return err
 }
 
+func (repo AuthRepository) DeleteAllTokensForDevice(
+	ctx context.Context,
+	deviceID string,
+) error {
+	query := `
+		DELETE FROM tokens
+		WHERE device_id = $1
+	`
+
+	_, err := repo.db.Exec(ctx, query, deviceID)
+	return err
+}
+
 func (repo AuthRepository) SetTokenAsUsed(
 	ctx context.Context,
 	tokenValue string,
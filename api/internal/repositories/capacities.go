@@ -0,0 +1,280 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/XDoubleU/essentia/pkg/database"
+	"github.com/XDoubleU/essentia/pkg/database/postgres"
+	"github.com/jackc/pgx/v5"
+
+	"check-in/api/internal/models"
+)
+
+type CapacityRepository struct {
+	db postgres.DB
+}
+
+func (repo CapacityRepository) GetAll(
+	ctx context.Context,
+	locationID string,
+) ([]*models.CapacityChange, error) {
+	query := `
+		SELECT id, location_id, capacity, (effective_from AT TIME ZONE 'utc')
+		FROM capacity_changes
+		WHERE location_id = $1
+		ORDER BY effective_from ASC
+	`
+
+	rows, err := repo.db.Query(ctx, query, locationID)
+	if err != nil {
+		return nil, postgres.PgxErrorToHTTPError(err)
+	}
+
+	changes := []*models.CapacityChange{}
+	for rows.Next() {
+		var change models.CapacityChange
+
+		err = rows.Scan(
+			&change.ID,
+			&change.LocationID,
+			&change.Capacity,
+			&change.EffectiveFrom,
+		)
+		if err != nil {
+			return nil, postgres.PgxErrorToHTTPError(err)
+		}
+
+		changes = append(changes, &change)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, postgres.PgxErrorToHTTPError(err)
+	}
+
+	return changes, nil
+}
+
+// GetInForce returns the capacity in force at the provided time for each
+// location. Locations without a change in force aren't included.
+func (repo CapacityRepository) GetInForce(
+	ctx context.Context,
+	locationIDs []string,
+	at time.Time,
+) (map[string]int64, error) {
+	query := `
+		SELECT DISTINCT ON (location_id) location_id, capacity
+		FROM capacity_changes
+		WHERE location_id = ANY($1) AND effective_from <= $2
+		ORDER BY location_id, effective_from DESC
+	`
+
+	rows, err := repo.db.Query(ctx, query, locationIDs, at)
+	if err != nil {
+		return nil, postgres.PgxErrorToHTTPError(err)
+	}
+
+	capacities := make(map[string]int64)
+	for rows.Next() {
+		var locationID string
+		var capacity int64
+
+		err = rows.Scan(&locationID, &capacity)
+		if err != nil {
+			return nil, postgres.PgxErrorToHTTPError(err)
+		}
+
+		capacities[locationID] = capacity
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, postgres.PgxErrorToHTTPError(err)
+	}
+
+	return capacities, nil
+}
+
+// GetDue returns the changes which came in force at the provided time
+// but weren't applied yet, grouped by the organisation of their location.
+// Changes of archived locations aren't included.
+func (repo CapacityRepository) GetDue(
+	ctx context.Context,
+	at time.Time,
+) (map[string][]*models.CapacityChange, error) {
+	query := `
+		SELECT capacity_changes.id, location_id, capacity,
+		 (effective_from AT TIME ZONE 'utc'), locations.organisation_id
+		FROM capacity_changes
+		INNER JOIN locations ON locations.id = capacity_changes.location_id
+		WHERE NOT applied AND effective_from <= $1
+		AND locations.archived_at IS NULL
+		ORDER BY effective_from ASC
+	`
+
+	rows, err := repo.db.Query(ctx, query, at)
+	if err != nil {
+		return nil, postgres.PgxErrorToHTTPError(err)
+	}
+
+	changes := make(map[string][]*models.CapacityChange)
+	for rows.Next() {
+		var change models.CapacityChange
+		var organisationID string
+
+		err = rows.Scan(
+			&change.ID,
+			&change.LocationID,
+			&change.Capacity,
+			&change.EffectiveFrom,
+			&organisationID,
+		)
+		if err != nil {
+			return nil, postgres.PgxErrorToHTTPError(err)
+		}
+
+		changes[organisationID] = append(changes[organisationID], &change)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, postgres.PgxErrorToHTTPError(err)
+	}
+
+	return changes, nil
+}
+
+// MarkApplied marks a change as applied,
+// so it isn't returned by GetDue anymore.
+func (repo CapacityRepository) MarkApplied(ctx context.Context, id int64) error {
+	query := `
+		UPDATE capacity_changes
+		SET applied = true
+		WHERE id = $1
+	`
+
+	result, err := repo.db.Exec(ctx, query, id)
+	if err != nil {
+		return postgres.PgxErrorToHTTPError(err)
+	}
+
+	rowsAffected := result.RowsAffected()
+	if rowsAffected == 0 {
+		return database.ErrResourceNotFound
+	}
+
+	return nil
+}
+
+func (repo CapacityRepository) GetByID(
+	ctx context.Context,
+	locationID string,
+	id int64,
+) (*models.CapacityChange, error) {
+	query := `
+		SELECT capacity, (effective_from AT TIME ZONE 'utc')
+		FROM capacity_changes
+		WHERE id = $1 AND location_id = $2
+	`
+
+	//nolint:exhaustruct //other fields are optional
+	change := models.CapacityChange{
+		ID:         id,
+		LocationID: locationID,
+	}
+
+	err := repo.db.QueryRow(ctx, query, id, locationID).Scan(
+		&change.Capacity,
+		&change.EffectiveFrom,
+	)
+	if err != nil {
+		return nil, postgres.PgxErrorToHTTPError(err)
+	}
+
+	return &change, nil
+}
+
+// Create adds a capacity change. A change at the same time
+// for the same location is overwritten.
+func (repo CapacityRepository) Create(
+	ctx context.Context,
+	locationID string,
+	capacity int64,
+	effectiveFrom time.Time,
+) (*models.CapacityChange, error) {
+	query := `
+		INSERT INTO capacity_changes (location_id, capacity, effective_from)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (location_id, effective_from)
+		DO UPDATE SET capacity = EXCLUDED.capacity, applied = false
+		RETURNING id, (effective_from AT TIME ZONE 'utc')
+	`
+
+	//nolint:exhaustruct //other fields are optional
+	change := models.CapacityChange{
+		LocationID: locationID,
+		Capacity:   capacity,
+	}
+
+	err := repo.db.QueryRow(
+		ctx,
+		query,
+		locationID,
+		capacity,
+		effectiveFrom,
+	).Scan(&change.ID, &change.EffectiveFrom)
+	if err != nil {
+		return nil, postgres.PgxErrorToHTTPError(err)
+	}
+
+	return &change, nil
+}
+
+func (repo CapacityRepository) Delete(ctx context.Context, id int64) error {
+	query := `
+		DELETE FROM capacity_changes
+		WHERE id = $1
+	`
+
+	result, err := repo.db.Exec(ctx, query, id)
+	if err != nil {
+		return postgres.PgxErrorToHTTPError(err)
+	}
+
+	rowsAffected := result.RowsAffected()
+	if rowsAffected == 0 {
+		return database.ErrResourceNotFound
+	}
+
+	return nil
+}
+
+// capacityAt returns the capacity in force for a location at the provided
+// time within a transaction, falling back to the capacity of the location.
+func capacityAt(
+	ctx context.Context,
+	tx pgx.Tx,
+	locationID string,
+	fallback int64,
+	at time.Time,
+) (int64, error) {
+	query := `
+		SELECT capacity
+		FROM capacity_changes
+		WHERE location_id = $1 AND effective_from <= $2
+		ORDER BY effective_from DESC
+		LIMIT 1
+	`
+
+	var capacity int64
+
+	err := tx.QueryRow(ctx, query, locationID, at).Scan(&capacity)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return fallback, nil
+	}
+
+	if err != nil {
+		return 0, postgres.PgxErrorToHTTPError(err)
+	}
+
+	return capacity, nil
+}
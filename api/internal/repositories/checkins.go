@@ -6,12 +6,15 @@ import (
 
 	"github.com/XDoubleU/essentia/pkg/database"
 	"github.com/XDoubleU/essentia/pkg/database/postgres"
+	timetools "github.com/XDoubleU/essentia/pkg/time"
 
 	"check-in/api/internal/models"
+	"check-in/api/internal/shared"
 )
 
 type CheckInRepository struct {
-	db postgres.DB
+	db            postgres.DB
+	getTimeNowUTC shared.UTCNowTimeProvider
 }
 
 func (repo CheckInRepository) GetAllInRange(
@@ -22,7 +25,8 @@ func (repo CheckInRepository) GetAllInRange(
 ) ([]*models.CheckIn, error) {
 	query := `
 		SELECT check_ins.id, check_ins.location_id, check_ins.school_id,
-		 check_ins.capacity, (check_ins.created_at AT TIME ZONE 'utc')
+		 check_ins.capacity, (check_ins.created_at AT TIME ZONE 'utc'),
+		 (check_ins.checked_out_at AT TIME ZONE 'utc')
 		FROM check_ins
 		WHERE check_ins.location_id = $1 
 		AND check_ins.created_at >= $2
@@ -52,6 +56,7 @@ func (repo CheckInRepository) GetAllInRange(
 			&checkIn.SchoolID,
 			&checkIn.Capacity,
 			&checkIn.CreatedAt,
+			&checkIn.CheckedOutAt,
 		)
 
 		if err != nil {
@@ -68,13 +73,179 @@ func (repo CheckInRepository) GetAllInRange(
 	return checkIns, nil
 }
 
+// CountPerDayInRange counts the check-ins per location, school and business
+// date, for the business dates from startDate up to and including endDate.
+// Past business days are read from the rollups, only the current business
+// day of each location is counted from its check-ins.
+func (repo CheckInRepository) CountPerDayInRange(
+	ctx context.Context,
+	locationIDs []string,
+	startDate time.Time,
+	endDate time.Time,
+) ([]*models.CheckInCount, error) {
+	query := `
+		WITH today AS (
+			SELECT id, ((($4::timestamptz) AT TIME ZONE time_zone) -
+			 business_day_start::interval)::date AS today_date
+			FROM locations
+			WHERE id = ANY($1)
+		)
+		SELECT check_in_daily_rollups.location_id,
+		 check_in_daily_rollups.school_id, check_in_daily_rollups.business_date,
+		 check_in_daily_rollups.count
+		FROM check_in_daily_rollups
+		INNER JOIN today
+		ON today.id = check_in_daily_rollups.location_id
+		WHERE check_in_daily_rollups.business_date BETWEEN $2::date AND $3::date
+		AND check_in_daily_rollups.business_date < today.today_date
+		AND check_in_daily_rollups.count > 0
+		UNION ALL
+		SELECT check_ins.location_id, check_ins.school_id,
+		 ` + businessDateSQL + ` AS business_date,
+		 COUNT(*)
+		FROM check_ins
+		INNER JOIN locations
+		ON locations.id = check_ins.location_id
+		INNER JOIN today
+		ON today.id = check_ins.location_id
+		WHERE check_ins.created_at >= $5
+		AND ` + businessDateSQL + ` BETWEEN $2::date AND $3::date
+		AND ` + businessDateSQL + ` >= today.today_date
+		GROUP BY check_ins.location_id, check_ins.school_id, business_date
+		ORDER BY business_date
+	`
+
+	now := repo.getTimeNowUTC()
+
+	// the current business day started less than 25 hours ago,
+	// business days are an hour longer when summer time ends
+	rows, err := repo.db.Query(
+		ctx,
+		query,
+		locationIDs,
+		timetools.StartOfDay(startDate),
+		timetools.StartOfDay(endDate),
+		now,
+		now.Add(-25*time.Hour), //nolint:mnd //no magic number
+	)
+	if err != nil {
+		return nil, postgres.PgxErrorToHTTPError(err)
+	}
+
+	checkInCounts := []*models.CheckInCount{}
+
+	for rows.Next() {
+		var checkInCount models.CheckInCount
+
+		err = rows.Scan(
+			&checkInCount.LocationID,
+			&checkInCount.SchoolID,
+			&checkInCount.Date,
+			&checkInCount.Count,
+		)
+		if err != nil {
+			return nil, postgres.PgxErrorToHTTPError(err)
+		}
+
+		checkInCounts = append(checkInCounts, &checkInCount)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, postgres.PgxErrorToHTTPError(err)
+	}
+
+	return checkInCounts, nil
+}
+
+// CountPerHourInRange counts the check-ins per location, local date and hour,
+// for the local dates from startDate up to and including endDate.
+// Past days are read from the rollups, only the current day
+// of each location is counted from its check-ins.
+func (repo CheckInRepository) CountPerHourInRange(
+	ctx context.Context,
+	locationIDs []string,
+	startDate time.Time,
+	endDate time.Time,
+) ([]*models.CheckInHourCount, error) {
+	query := `
+		WITH today AS (
+			SELECT id, ($4::timestamptz AT TIME ZONE time_zone)::date AS today_date
+			FROM locations
+			WHERE id = ANY($1)
+		)
+		SELECT check_in_hourly_rollups.location_id,
+		 check_in_hourly_rollups.date, check_in_hourly_rollups.hour,
+		 check_in_hourly_rollups.count
+		FROM check_in_hourly_rollups
+		INNER JOIN today
+		ON today.id = check_in_hourly_rollups.location_id
+		WHERE check_in_hourly_rollups.date BETWEEN $2::date AND $3::date
+		AND check_in_hourly_rollups.date < today.today_date
+		AND check_in_hourly_rollups.count > 0
+		UNION ALL
+		SELECT check_ins.location_id, ` + localDateSQL + ` AS date,
+		 ` + localHourSQL + ` AS hour, COUNT(*)
+		FROM check_ins
+		INNER JOIN locations
+		ON locations.id = check_ins.location_id
+		INNER JOIN today
+		ON today.id = check_ins.location_id
+		WHERE check_ins.created_at >= $5
+		AND ` + localDateSQL + ` BETWEEN $2::date AND $3::date
+		AND ` + localDateSQL + ` >= today.today_date
+		GROUP BY check_ins.location_id, date, hour
+	`
+
+	now := repo.getTimeNowUTC()
+
+	// the current day started less than 25 hours ago,
+	// days are an hour longer when summer time ends
+	rows, err := repo.db.Query(
+		ctx,
+		query,
+		locationIDs,
+		timetools.StartOfDay(startDate),
+		timetools.StartOfDay(endDate),
+		now,
+		now.Add(-25*time.Hour), //nolint:mnd //no magic number
+	)
+	if err != nil {
+		return nil, postgres.PgxErrorToHTTPError(err)
+	}
+
+	checkInCounts := []*models.CheckInHourCount{}
+
+	for rows.Next() {
+		var checkInCount models.CheckInHourCount
+
+		err = rows.Scan(
+			&checkInCount.LocationID,
+			&checkInCount.Date,
+			&checkInCount.Hour,
+			&checkInCount.Count,
+		)
+		if err != nil {
+			return nil, postgres.PgxErrorToHTTPError(err)
+		}
+
+		checkInCounts = append(checkInCounts, &checkInCount)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, postgres.PgxErrorToHTTPError(err)
+	}
+
+	return checkInCounts, nil
+}
+
 func (repo CheckInRepository) GetByID(
 	ctx context.Context,
 	location *models.Location,
 	id int64,
 ) (*models.CheckIn, error) {
 	query := `
-		SELECT school_id, capacity, (created_at AT TIME ZONE 'utc')
+		SELECT school_id, capacity, (created_at AT TIME ZONE 'utc'),
+		 (checked_out_at AT TIME ZONE 'utc')
 		FROM check_ins
 		WHERE id = $1 AND location_id = $2
 	`
@@ -94,6 +265,7 @@ func (repo CheckInRepository) GetByID(
 		&checkIn.SchoolID,
 		&checkIn.Capacity,
 		&checkIn.CreatedAt,
+		&checkIn.CheckedOutAt,
 	)
 
 	if err != nil {
@@ -109,7 +281,18 @@ func (repo CheckInRepository) Delete(ctx context.Context, id int64) error {
 		WHERE id = $1
 	`
 
-	result, err := repo.db.Exec(ctx, query, id)
+	tx, err := repo.db.Begin(ctx)
+	if err != nil {
+		return postgres.PgxErrorToHTTPError(err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	err = removeFromRollup(ctx, tx, id)
+	if err != nil {
+		return err
+	}
+
+	result, err := tx.Exec(ctx, query, id)
 	if err != nil {
 		return postgres.PgxErrorToHTTPError(err)
 	}
@@ -119,5 +302,10 @@ func (repo CheckInRepository) Delete(ctx context.Context, id int64) error {
 		return database.ErrResourceNotFound
 	}
 
+	err = tx.Commit(ctx)
+	if err != nil {
+		return postgres.PgxErrorToHTTPError(err)
+	}
+
 	return nil
 }
@@ -2,8 +2,12 @@ package repositories
 
 import (
 	"context"
+	"errors"
+	"time"
 
 	"github.com/XDoubleU/essentia/pkg/database/postgres"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
 
 	"check-in/api/internal/models"
 	"check-in/api/internal/shared"
@@ -14,36 +18,358 @@ type CheckInWriteRepository struct {
 	getTimeNowUTC shared.UTCNowTimeProvider
 }
 
+var ErrNoAvailableSpots = errors.New("location has no available spots")
+
+// Create inserts a check-in while holding a lock on the location row,
+// so concurrent check-ins can't exceed the capacity of the location
+// within the day returned by dayBounds.
 func (repo CheckInWriteRepository) Create(
 	ctx context.Context,
 	location *models.Location,
 	school *models.School,
+	deviceID pgtype.Text,
+	dayBounds shared.DayBoundsProvider,
 ) (*models.CheckIn, error) {
 	query := `
-		INSERT INTO check_ins (location_id, school_id, capacity, created_at)
-		VALUES ($1, $2, $3, $4)
+		INSERT INTO check_ins
+		(location_id, school_id, capacity, created_at, device_id)
+		VALUES ($1, $2, $3, $4, $5)
 		RETURNING id, (created_at AT TIME ZONE 'utc')
 	`
 
+	tx, err := repo.db.Begin(ctx)
+	if err != nil {
+		return nil, postgres.PgxErrorToHTTPError(err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	baseCapacity, err := lockLocation(ctx, tx, location.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := repo.getTimeNowUTC()
+
+	capacity, err := capacityAt(ctx, tx, location.ID, baseCapacity, now)
+	if err != nil {
+		return nil, err
+	}
+
+	available, err := hasAvailableSpots(ctx, tx, location.ID, capacity, now, dayBounds)
+	if err != nil {
+		return nil, err
+	}
+
+	if !available {
+		return nil, ErrNoAvailableSpots
+	}
+
 	//nolint:exhaustruct //other fields are optional
 	checkIn := models.CheckIn{
 		LocationID: location.ID,
 		SchoolID:   school.ID,
-		Capacity:   location.Capacity,
+		Capacity:   capacity,
+		DeviceID:   deviceID,
 	}
 
-	err := repo.db.QueryRow(
+	err = tx.QueryRow(
 		ctx,
 		query,
 		location.ID,
 		school.ID,
-		location.Capacity,
-		repo.getTimeNowUTC(),
+		capacity,
+		now,
+		deviceID,
 	).Scan(&checkIn.ID, &checkIn.CreatedAt)
 
 	if err != nil {
 		return nil, postgres.PgxErrorToHTTPError(err)
 	}
 
+	err = addToRollup(ctx, tx, checkIn.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return nil, postgres.PgxErrorToHTTPError(err)
+	}
+
+	return &checkIn, nil
+}
+
+func lockLocation(ctx context.Context, tx pgx.Tx, locationID string) (int64, error) {
+	query := `
+		SELECT capacity
+		FROM locations
+		WHERE id = $1
+		FOR UPDATE
+	`
+
+	var capacity int64
+
+	err := tx.QueryRow(ctx, query, locationID).Scan(&capacity)
+	if err != nil {
+		return 0, postgres.PgxErrorToHTTPError(err)
+	}
+
+	return capacity, nil
+}
+
+func hasAvailableSpots(
+	ctx context.Context,
+	tx pgx.Tx,
+	locationID string,
+	capacity int64,
+	at time.Time,
+	dayBounds shared.DayBoundsProvider,
+) (bool, error) {
+	query := `
+		SELECT COUNT(*)
+		FROM check_ins
+		WHERE location_id = $1
+		AND created_at >= $2
+		AND created_at <= $3
+		AND created_at <= $4
+		AND (checked_out_at IS NULL OR checked_out_at > $4)
+	`
+
+	startOfDay, endOfDay := dayBounds(at)
+
+	var count int64
+
+	err := tx.QueryRow(
+		ctx,
+		query,
+		locationID,
+		startOfDay,
+		endOfDay,
+		at,
+	).Scan(&count)
+	if err != nil {
+		return false, postgres.PgxErrorToHTTPError(err)
+	}
+
+	return count < capacity, nil
+}
+
+func (repo CheckInWriteRepository) GetExistingIdempotencyKeys(
+	ctx context.Context,
+	locationID string,
+	keys []string,
+) (map[string]bool, error) {
+	query := `
+		SELECT idempotency_key
+		FROM check_ins
+		WHERE location_id = $1 AND idempotency_key = ANY($2)
+	`
+
+	rows, err := repo.db.Query(ctx, query, locationID, keys)
+	if err != nil {
+		return nil, postgres.PgxErrorToHTTPError(err)
+	}
+
+	existingKeys := make(map[string]bool)
+
+	for rows.Next() {
+		var key string
+
+		err = rows.Scan(&key)
+		if err != nil {
+			return nil, postgres.PgxErrorToHTTPError(err)
+		}
+
+		existingKeys[key] = true
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, postgres.PgxErrorToHTTPError(err)
+	}
+
+	return existingKeys, nil
+}
+
+// CreateBatch inserts all check-ins of a location in a single transaction.
+// Check-ins whose idempotency key already exists for the location are
+// skipped, check-ins that would exceed the capacity of their day are
+// returned as full.
+func (repo CheckInWriteRepository) CreateBatch(
+	ctx context.Context,
+	location *models.Location,
+	checkIns []*models.CheckIn,
+	dayBounds shared.DayBoundsProvider,
+) ([]*models.CheckIn, []*models.CheckIn, error) {
+	query := `
+		INSERT INTO check_ins
+		(location_id, school_id, capacity, created_at, idempotency_key, device_id)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (location_id, idempotency_key) DO NOTHING
+		RETURNING id, (created_at AT TIME ZONE 'utc')
+	`
+
+	tx, err := repo.db.Begin(ctx)
+	if err != nil {
+		return nil, nil, postgres.PgxErrorToHTTPError(err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	baseCapacity, err := lockLocation(ctx, tx, location.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	created := []*models.CheckIn{}
+	full := []*models.CheckIn{}
+
+	for _, checkIn := range checkIns {
+		var capacity int64
+		capacity, err = capacityAt(
+			ctx,
+			tx,
+			location.ID,
+			baseCapacity,
+			checkIn.CreatedAt.Time,
+		)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		var available bool
+		available, err = hasAvailableSpots(
+			ctx,
+			tx,
+			location.ID,
+			capacity,
+			checkIn.CreatedAt.Time,
+			dayBounds,
+		)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if !available {
+			full = append(full, checkIn)
+			continue
+		}
+
+		checkIn.Capacity = capacity
+
+		err = tx.QueryRow(
+			ctx,
+			query,
+			checkIn.LocationID,
+			checkIn.SchoolID,
+			checkIn.Capacity,
+			checkIn.CreatedAt.Time,
+			checkIn.IdempotencyKey,
+			checkIn.DeviceID,
+		).Scan(&checkIn.ID, &checkIn.CreatedAt)
+
+		if errors.Is(err, pgx.ErrNoRows) {
+			continue
+		}
+
+		if err != nil {
+			return nil, nil, postgres.PgxErrorToHTTPError(err)
+		}
+
+		err = addToRollup(ctx, tx, checkIn.ID)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		created = append(created, checkIn)
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return nil, nil, postgres.PgxErrorToHTTPError(err)
+	}
+
+	return created, full, nil
+}
+
+// CheckOut marks a check-in as checked out at the given time.
+// Check-ins which are already checked out aren't updated
+// and result in database.ErrResourceNotFound.
+func (repo CheckInWriteRepository) CheckOut(
+	ctx context.Context,
+	checkIn *models.CheckIn,
+	at time.Time,
+) error {
+	query := `
+		UPDATE check_ins
+		SET checked_out_at = $3
+		WHERE id = $1 AND location_id = $2 AND checked_out_at IS NULL
+		RETURNING (checked_out_at AT TIME ZONE 'utc')
+	`
+
+	err := repo.db.QueryRow(
+		ctx,
+		query,
+		checkIn.ID,
+		checkIn.LocationID,
+		at,
+	).Scan(&checkIn.CheckedOutAt)
+	if err != nil {
+		return postgres.PgxErrorToHTTPError(err)
+	}
+
+	return nil
+}
+
+// CheckOutOldest checks out the oldest check-in of a location which is still
+// checked in within the day returned by dayBounds. This is used by kiosks
+// which don't know who is leaving.
+func (repo CheckInWriteRepository) CheckOutOldest(
+	ctx context.Context,
+	locationID string,
+	dayBounds shared.DayBoundsProvider,
+) (*models.CheckIn, error) {
+	query := `
+		UPDATE check_ins
+		SET checked_out_at = $4
+		WHERE id = (
+			SELECT id
+			FROM check_ins
+			WHERE location_id = $1
+			AND created_at >= $2
+			AND created_at <= $3
+			AND checked_out_at IS NULL
+			ORDER BY created_at, id
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING id, school_id, capacity, (created_at AT TIME ZONE 'utc'),
+		 (checked_out_at AT TIME ZONE 'utc')
+	`
+
+	now := repo.getTimeNowUTC()
+	startOfDay, endOfDay := dayBounds(now)
+
+	//nolint:exhaustruct //other fields are optional
+	checkIn := models.CheckIn{
+		LocationID: locationID,
+	}
+
+	err := repo.db.QueryRow(
+		ctx,
+		query,
+		locationID,
+		startOfDay,
+		endOfDay,
+		now,
+	).Scan(
+		&checkIn.ID,
+		&checkIn.SchoolID,
+		&checkIn.Capacity,
+		&checkIn.CreatedAt,
+		&checkIn.CheckedOutAt,
+	)
+	if err != nil {
+		return nil, postgres.PgxErrorToHTTPError(err)
+	}
+
 	return &checkIn, nil
 }
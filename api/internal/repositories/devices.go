@@ -0,0 +1,215 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/XDoubleU/essentia/pkg/database"
+	"github.com/XDoubleU/essentia/pkg/database/postgres"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"check-in/api/internal/models"
+)
+
+type DeviceRepository struct {
+	db postgres.DB
+}
+
+func (repo DeviceRepository) GetAll(
+	ctx context.Context,
+	locationID string,
+) ([]*models.Device, error) {
+	query := `
+		SELECT id, name, username, (created_at AT TIME ZONE 'utc'),
+		 (revoked_at AT TIME ZONE 'utc')
+		FROM devices
+		WHERE location_id = $1
+		ORDER BY created_at ASC
+	`
+
+	rows, err := repo.db.Query(ctx, query, locationID)
+	if err != nil {
+		return nil, postgres.PgxErrorToHTTPError(err)
+	}
+
+	devices := []*models.Device{}
+	for rows.Next() {
+		//nolint:exhaustruct //other fields are optional
+		device := models.Device{
+			LocationID: locationID,
+		}
+
+		err = rows.Scan(
+			&device.ID,
+			&device.Name,
+			&device.Username,
+			&device.CreatedAt,
+			&device.RevokedAt,
+		)
+		if err != nil {
+			return nil, postgres.PgxErrorToHTTPError(err)
+		}
+
+		devices = append(devices, &device)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, postgres.PgxErrorToHTTPError(err)
+	}
+
+	return devices, nil
+}
+
+func (repo DeviceRepository) GetByID(
+	ctx context.Context,
+	locationID string,
+	id string,
+) (*models.Device, error) {
+	query := `
+		SELECT name, username, (created_at AT TIME ZONE 'utc'),
+		 (revoked_at AT TIME ZONE 'utc')
+		FROM devices
+		WHERE id = $1 AND location_id = $2
+	`
+
+	//nolint:exhaustruct //other fields are optional
+	device := models.Device{
+		ID:         id,
+		LocationID: locationID,
+	}
+
+	err := repo.db.QueryRow(ctx, query, id, locationID).Scan(
+		&device.Name,
+		&device.Username,
+		&device.CreatedAt,
+		&device.RevokedAt,
+	)
+	if err != nil {
+		return nil, postgres.PgxErrorToHTTPError(err)
+	}
+
+	return &device, nil
+}
+
+// GetByUsername only returns devices which aren't revoked
+// and whose location isn't archived.
+func (repo DeviceRepository) GetByUsername(
+	ctx context.Context,
+	username string,
+) (*models.Device, error) {
+	query := `
+		SELECT devices.id, devices.location_id, devices.name,
+		 devices.password_hash, locations.user_id, locations.organisation_id
+		FROM devices
+		INNER JOIN locations
+		ON locations.id = devices.location_id
+		WHERE devices.username = $1
+		AND devices.revoked_at IS NULL
+		AND locations.archived_at IS NULL
+	`
+
+	//nolint:exhaustruct //other fields are optional
+	device := models.Device{
+		Username: pgtype.Text{String: username, Valid: true},
+	}
+
+	err := repo.db.QueryRow(ctx, query, username).Scan(
+		&device.ID,
+		&device.LocationID,
+		&device.Name,
+		&device.PasswordHash,
+		&device.UserID,
+		&device.OrganisationID,
+	)
+	if err != nil {
+		return nil, postgres.PgxErrorToHTTPError(err)
+	}
+
+	return &device, nil
+}
+
+func (repo DeviceRepository) Create(
+	ctx context.Context,
+	locationID string,
+	name string,
+	username pgtype.Text,
+	passwordHash []byte,
+) (*models.Device, error) {
+	query := `
+		INSERT INTO devices (location_id, name, username, password_hash)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, (created_at AT TIME ZONE 'utc')
+	`
+
+	//nolint:exhaustruct //other fields are optional
+	device := models.Device{
+		LocationID: locationID,
+		Name:       name,
+		Username:   username,
+	}
+
+	err := repo.db.QueryRow(
+		ctx,
+		query,
+		locationID,
+		name,
+		username,
+		passwordHash,
+	).Scan(&device.ID, &device.CreatedAt)
+	if err != nil {
+		return nil, postgres.PgxErrorToHTTPError(err)
+	}
+
+	return &device, nil
+}
+
+func (repo DeviceRepository) Rename(
+	ctx context.Context,
+	device models.Device,
+	name string,
+) (*models.Device, error) {
+	query := `
+		UPDATE devices
+		SET name = $3
+		WHERE id = $1 AND location_id = $2
+	`
+
+	result, err := repo.db.Exec(ctx, query, device.ID, device.LocationID, name)
+	if err != nil {
+		return nil, postgres.PgxErrorToHTTPError(err)
+	}
+
+	rowsAffected := result.RowsAffected()
+	if rowsAffected == 0 {
+		return nil, database.ErrResourceNotFound
+	}
+
+	device.Name = name
+
+	return &device, nil
+}
+
+// Revoke prevents a device from signing in again,
+// the device is kept so its check-ins still refer to it.
+func (repo DeviceRepository) Revoke(
+	ctx context.Context,
+	device *models.Device,
+) error {
+	query := `
+		UPDATE devices
+		SET revoked_at = now()
+		WHERE id = $1 AND location_id = $2 AND revoked_at IS NULL
+		RETURNING (revoked_at AT TIME ZONE 'utc')
+	`
+
+	err := repo.db.QueryRow(
+		ctx,
+		query,
+		device.ID,
+		device.LocationID,
+	).Scan(&device.RevokedAt)
+	if err != nil {
+		return postgres.PgxErrorToHTTPError(err)
+	}
+
+	return nil
+}
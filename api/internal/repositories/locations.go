@@ -15,15 +15,30 @@ type LocationRepository struct {
 	db postgres.DB
 }
 
-func (repo LocationRepository) GetTotalCount(ctx context.Context) (*int64, error) {
+// managerFilterClause limits the locations to the ones assigned
+// to the manager in $2, an empty manager ID doesn't filter.
+const managerFilterClause = `
+	AND ($2::text = '' OR id IN (
+		SELECT location_id
+		FROM manager_locations
+		WHERE user_id::text = $2
+	))
+`
+
+func (repo LocationRepository) GetTotalCount(
+	ctx context.Context,
+	organisationID string,
+	managerID string,
+) (*int64, error) {
 	query := `
 		SELECT COUNT(*)
 		FROM locations
-	`
+		WHERE organisation_id = $1 AND archived_at IS NULL
+	` + managerFilterClause
 
 	var total *int64
 
-	err := repo.db.QueryRow(ctx, query).Scan(&total)
+	err := repo.db.QueryRow(ctx, query, organisationID, managerID).Scan(&total)
 	if err != nil {
 		return nil, postgres.PgxErrorToHTTPError(err)
 	}
@@ -31,14 +46,21 @@ func (repo LocationRepository) GetTotalCount(ctx context.Context) (*int64, error
 	return total, nil
 }
 
-func (repo LocationRepository) GetAll(ctx context.Context) ([]*models.Location, error) {
+func (repo LocationRepository) GetAll(
+	ctx context.Context,
+	organisationID string,
+	managerID string,
+) ([]*models.Location, error) {
 	query := `
-		SELECT id, name, capacity, time_zone, user_id
+		SELECT id, name, capacity, time_zone,
+		 to_char(business_day_start, 'HH24:MI'), user_id, organisation_id
 		FROM locations
+		WHERE organisation_id = $1 AND archived_at IS NULL
+	` + managerFilterClause + `
 		ORDER BY name ASC
 	`
 
-	rows, err := repo.db.Query(ctx, query)
+	rows, err := repo.db.Query(ctx, query, organisationID, managerID)
 	if err != nil {
 		return nil, postgres.PgxErrorToHTTPError(err)
 	}
@@ -52,7 +74,9 @@ func (repo LocationRepository) GetAll(ctx context.Context) ([]*models.Location,
 			&location.Name,
 			&location.Capacity,
 			&location.TimeZone,
+			&location.BusinessDayStart,
 			&location.UserID,
+			&location.OrganisationID,
 		)
 		if err != nil {
 			return nil, postgres.PgxErrorToHTTPError(err)
@@ -70,17 +94,29 @@ func (repo LocationRepository) GetAll(ctx context.Context) ([]*models.Location,
 
 func (repo LocationRepository) GetAllPaginated(
 	ctx context.Context,
+	organisationID string,
+	managerID string,
 	limit int64,
 	offset int64,
 ) ([]*models.Location, error) {
 	query := `
-		SELECT id, name, capacity, time_zone, user_id
+		SELECT id, name, capacity, time_zone,
+		 to_char(business_day_start, 'HH24:MI'), user_id, organisation_id
 		FROM locations
+		WHERE organisation_id = $1 AND archived_at IS NULL
+	` + managerFilterClause + `
 		ORDER BY name ASC
-		LIMIT $1 OFFSET $2
+		LIMIT $3 OFFSET $4
 	`
 
-	rows, err := repo.db.Query(ctx, query, limit, offset)
+	rows, err := repo.db.Query(
+		ctx,
+		query,
+		organisationID,
+		managerID,
+		limit,
+		offset,
+	)
 	if err != nil {
 		return nil, postgres.PgxErrorToHTTPError(err)
 	}
@@ -94,7 +130,9 @@ func (repo LocationRepository) GetAllPaginated(
 			&location.Name,
 			&location.Capacity,
 			&location.TimeZone,
+			&location.BusinessDayStart,
 			&location.UserID,
+			&location.OrganisationID,
 		)
 		if err != nil {
 			return nil, postgres.PgxErrorToHTTPError(err)
@@ -110,13 +148,16 @@ func (repo LocationRepository) GetAllPaginated(
 	return locations, nil
 }
 
+// GetByIDs includes archived locations,
+// so their statistics remain available.
 func (repo LocationRepository) GetByIDs(
 	ctx context.Context,
+	organisationID string,
 	ids []string,
 ) ([]*models.Location, error) {
 	locations := []*models.Location{}
 	for _, id := range ids {
-		location, err := repo.GetByID(ctx, id)
+		location, err := repo.getByID(ctx, organisationID, id, true)
 		if err != nil {
 			return nil, err
 		}
@@ -124,26 +165,29 @@ func (repo LocationRepository) GetByIDs(
 		locations = append(locations, location)
 	}
 
-	//nolint:exhaustruct //other fields are optional
-	pgArray := pgtype.Array[pgtype.Text]{}
-	for _, id := range ids {
-		pgArray.Elements = append(pgArray.Elements, pgtype.Text{
-			String: id,
-			Valid:  true,
-		})
-	}
-
 	return locations, nil
 }
 
 func (repo LocationRepository) GetByID(
 	ctx context.Context,
+	organisationID string,
 	id string,
+) (*models.Location, error) {
+	return repo.getByID(ctx, organisationID, id, false)
+}
+
+func (repo LocationRepository) getByID(
+	ctx context.Context,
+	organisationID string,
+	id string,
+	includeArchived bool,
 ) (*models.Location, error) {
 	query := `
-		SELECT id, name, capacity, time_zone, user_id
+		SELECT id, name, capacity, time_zone,
+		 to_char(business_day_start, 'HH24:MI'), user_id, organisation_id
 		FROM locations
-		WHERE locations.id = $1
+		WHERE locations.id = $1 AND organisation_id = $2
+		AND ($3 OR archived_at IS NULL)
 	`
 
 	//nolint:exhaustruct //other fields are optional
@@ -151,12 +195,16 @@ func (repo LocationRepository) GetByID(
 	err := repo.db.QueryRow(
 		ctx,
 		query,
-		id).Scan(
+		id,
+		organisationID,
+		includeArchived).Scan(
 		&location.ID,
 		&location.Name,
 		&location.Capacity,
 		&location.TimeZone,
+		&location.BusinessDayStart,
 		&location.UserID,
+		&location.OrganisationID,
 	)
 	if err != nil {
 		return nil, postgres.PgxErrorToHTTPError(err)
@@ -167,12 +215,14 @@ func (repo LocationRepository) GetByID(
 
 func (repo LocationRepository) GetByUserID(
 	ctx context.Context,
+	organisationID string,
 	id string,
 ) (*models.Location, error) {
 	query := `
-		SELECT id, name, capacity, time_zone, user_id
+		SELECT id, name, capacity, time_zone,
+		 to_char(business_day_start, 'HH24:MI'), user_id, organisation_id
 		FROM locations
-		WHERE user_id = $1
+		WHERE user_id = $1 AND organisation_id = $2 AND archived_at IS NULL
 	`
 
 	//nolint:exhaustruct //other fields are optional
@@ -180,12 +230,15 @@ func (repo LocationRepository) GetByUserID(
 	err := repo.db.QueryRow(
 		ctx,
 		query,
-		id).Scan(
+		id,
+		organisationID).Scan(
 		&location.ID,
 		&location.Name,
 		&location.Capacity,
 		&location.TimeZone,
+		&location.BusinessDayStart,
 		&location.UserID,
+		&location.OrganisationID,
 	)
 	if err != nil {
 		return nil, postgres.PgxErrorToHTTPError(err)
@@ -196,24 +249,29 @@ func (repo LocationRepository) GetByUserID(
 
 func (repo LocationRepository) Create(
 	ctx context.Context,
+	organisationID string,
 	name string,
 	capacity int64,
 	timeZone string,
+	businessDayStart string,
 	userID string,
 ) (*models.Location, error) {
 	query := `
-		INSERT INTO locations (name, capacity, time_zone, user_id)
-		VALUES ($1, $2, $3, $4)
+		INSERT INTO locations
+		(name, capacity, time_zone, business_day_start, user_id, organisation_id)
+		VALUES ($1, $2, $3, $4::time, $5, $6)
 		RETURNING id
 	`
 
 	//nolint:exhaustruct //other fields are optional
 	location := models.Location{
-		Name:      name,
-		Capacity:  capacity,
-		Available: capacity,
-		TimeZone:  timeZone,
-		UserID:    userID,
+		Name:             name,
+		Capacity:         capacity,
+		Available:        capacity,
+		TimeZone:         timeZone,
+		BusinessDayStart: businessDayStart,
+		UserID:           userID,
+		OrganisationID:   organisationID,
 	}
 
 	err := repo.db.QueryRow(
@@ -222,7 +280,9 @@ func (repo LocationRepository) Create(
 		name,
 		capacity,
 		timeZone,
+		businessDayStart,
 		userID,
+		organisationID,
 	).Scan(&location.ID)
 
 	if err != nil {
@@ -239,8 +299,8 @@ func (repo LocationRepository) Update(
 ) (*models.Location, error) {
 	query := `
 		UPDATE locations
-		SET name = $2, capacity = $3, time_zone = $4
-		WHERE id = $1
+		SET name = $2, capacity = $3, time_zone = $4, business_day_start = $5::time
+		WHERE id = $1 AND organisation_id = $6 AND archived_at IS NULL
 	`
 
 	if updateLocationDto.Name != nil {
@@ -258,17 +318,36 @@ func (repo LocationRepository) Update(
 		location.Capacity = *updateLocationDto.Capacity
 	}
 
+	// the business dates of the check-ins move with the time zone
+	// and the start of the day
+	businessDatesChanged := (updateLocationDto.TimeZone != nil &&
+		*updateLocationDto.TimeZone != location.TimeZone) ||
+		(updateLocationDto.BusinessDayStart != nil &&
+			*updateLocationDto.BusinessDayStart != location.BusinessDayStart)
+
 	if updateLocationDto.TimeZone != nil {
 		location.TimeZone = *updateLocationDto.TimeZone
 	}
 
-	resultLocation, err := repo.db.Exec(
+	if updateLocationDto.BusinessDayStart != nil {
+		location.BusinessDayStart = *updateLocationDto.BusinessDayStart
+	}
+
+	tx, err := repo.db.Begin(ctx)
+	if err != nil {
+		return nil, postgres.PgxErrorToHTTPError(err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	resultLocation, err := tx.Exec(
 		ctx,
 		query,
 		location.ID,
 		location.Name,
 		location.Capacity,
 		location.TimeZone,
+		location.BusinessDayStart,
+		location.OrganisationID,
 	)
 
 	if err != nil {
@@ -280,19 +359,37 @@ func (repo LocationRepository) Update(
 		return nil, database.ErrResourceNotFound
 	}
 
+	if businessDatesChanged {
+		err = rebuildRollups(
+			ctx,
+			tx,
+			pgtype.Text{String: location.ID, Valid: true},
+		)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return nil, postgres.PgxErrorToHTTPError(err)
+	}
+
 	return &location, nil
 }
 
-func (repo LocationRepository) Delete(
+// Archive hides a location, its check-ins are kept.
+func (repo LocationRepository) Archive(
 	ctx context.Context,
 	location *models.Location,
 ) error {
 	query := `
-		DELETE FROM locations
-		WHERE id = $1
+		UPDATE locations
+		SET archived_at = now()
+		WHERE id = $1 AND organisation_id = $2 AND archived_at IS NULL
 	`
 
-	result, err := repo.db.Exec(ctx, query, location.ID)
+	result, err := repo.db.Exec(ctx, query, location.ID, location.OrganisationID)
 	if err != nil {
 		return postgres.PgxErrorToHTTPError(err)
 	}
@@ -304,3 +401,37 @@ func (repo LocationRepository) Delete(
 
 	return nil
 }
+
+// Restore makes an archived location visible again.
+func (repo LocationRepository) Restore(
+	ctx context.Context,
+	organisationID string,
+	id string,
+) (*models.Location, error) {
+	query := `
+		UPDATE locations
+		SET archived_at = NULL
+		WHERE id = $1 AND organisation_id = $2 AND archived_at IS NOT NULL
+		RETURNING name, capacity, time_zone,
+		 to_char(business_day_start, 'HH24:MI'), user_id
+	`
+
+	//nolint:exhaustruct //other fields are optional
+	location := models.Location{
+		ID:             id,
+		OrganisationID: organisationID,
+	}
+
+	err := repo.db.QueryRow(ctx, query, id, organisationID).Scan(
+		&location.Name,
+		&location.Capacity,
+		&location.TimeZone,
+		&location.BusinessDayStart,
+		&location.UserID,
+	)
+	if err != nil {
+		return nil, postgres.PgxErrorToHTTPError(err)
+	}
+
+	return &location, nil
+}
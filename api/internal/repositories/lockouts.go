@@ -0,0 +1,244 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/XDoubleU/essentia/pkg/database"
+	"github.com/XDoubleU/essentia/pkg/database/postgres"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"check-in/api/internal/models"
+	"check-in/api/internal/shared"
+)
+
+type LockoutRepository struct {
+	db            postgres.DB
+	getTimeNowUTC shared.UTCNowTimeProvider
+}
+
+// GetAll returns the active lockouts of an organisation and, when
+// includeUnattributed is set, those which can't be attributed
+// to any organisation.
+func (repo LockoutRepository) GetAll(
+	ctx context.Context,
+	organisationID string,
+	includeUnattributed bool,
+) ([]*models.Lockout, error) {
+	query := `
+		SELECT kind, value, organisation_id, failed_attempts,
+		 (last_failed_at AT TIME ZONE 'utc'), (locked_until AT TIME ZONE 'utc')
+		FROM lockouts
+		WHERE locked_until > $2
+		AND (organisation_id = $1 OR ($3 AND organisation_id IS NULL))
+		ORDER BY locked_until DESC
+	`
+
+	rows, err := repo.db.Query(
+		ctx,
+		query,
+		organisationID,
+		repo.getTimeNowUTC(),
+		includeUnattributed,
+	)
+	if err != nil {
+		return nil, postgres.PgxErrorToHTTPError(err)
+	}
+
+	lockouts := []*models.Lockout{}
+	for rows.Next() {
+		//nolint:exhaustruct //other fields are optional
+		lockout := models.Lockout{}
+
+		err = rows.Scan(
+			&lockout.Kind,
+			&lockout.Value,
+			&lockout.OrganisationID,
+			&lockout.FailedAttempts,
+			&lockout.LastFailedAt,
+			&lockout.LockedUntil,
+		)
+		if err != nil {
+			return nil, postgres.PgxErrorToHTTPError(err)
+		}
+
+		lockouts = append(lockouts, &lockout)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, postgres.PgxErrorToHTTPError(err)
+	}
+
+	return lockouts, nil
+}
+
+// GetLockedUntil returns until when a key is locked out,
+// the returned value isn't valid when the key isn't locked out.
+func (repo LockoutRepository) GetLockedUntil(
+	ctx context.Context,
+	key models.LockoutKey,
+) (pgtype.Timestamptz, error) {
+	query := `
+		SELECT MAX(locked_until AT TIME ZONE 'utc')
+		FROM lockouts
+		WHERE kind = $1 AND value = $2 AND locked_until > $3
+	`
+
+	var lockedUntil pgtype.Timestamptz
+
+	err := repo.db.QueryRow(
+		ctx,
+		query,
+		key.Kind,
+		key.Value,
+		repo.getTimeNowUTC(),
+	).Scan(&lockedUntil)
+	if err != nil {
+		return lockedUntil, postgres.PgxErrorToHTTPError(err)
+	}
+
+	return lockedUntil, nil
+}
+
+// RegisterFailure counts a failed attempt for a key, the count starts over
+// when the previous failed attempt happened before resetBefore.
+func (repo LockoutRepository) RegisterFailure(
+	ctx context.Context,
+	key models.LockoutKey,
+	organisationID pgtype.Text,
+	resetBefore time.Time,
+) (*models.Lockout, error) {
+	query := `
+		INSERT INTO lockouts
+		 (kind, value, organisation_id, failed_attempts, last_failed_at)
+		VALUES ($1, $2, $3, 1, $4)
+		ON CONFLICT (kind, value) DO UPDATE
+		SET failed_attempts = CASE
+			WHEN lockouts.last_failed_at < $5 THEN 1
+			ELSE lockouts.failed_attempts + 1
+		 END,
+		 last_failed_at = EXCLUDED.last_failed_at,
+		 organisation_id = COALESCE(
+			EXCLUDED.organisation_id, lockouts.organisation_id
+		 )
+		RETURNING organisation_id, failed_attempts,
+		 (last_failed_at AT TIME ZONE 'utc'), (locked_until AT TIME ZONE 'utc')
+	`
+
+	//nolint:exhaustruct //other fields are optional
+	lockout := models.Lockout{
+		Kind:  key.Kind,
+		Value: key.Value,
+	}
+
+	err := repo.db.QueryRow(
+		ctx,
+		query,
+		key.Kind,
+		key.Value,
+		organisationID,
+		repo.getTimeNowUTC(),
+		resetBefore,
+	).Scan(
+		&lockout.OrganisationID,
+		&lockout.FailedAttempts,
+		&lockout.LastFailedAt,
+		&lockout.LockedUntil,
+	)
+	if err != nil {
+		return nil, postgres.PgxErrorToHTTPError(err)
+	}
+
+	return &lockout, nil
+}
+
+func (repo LockoutRepository) Lock(
+	ctx context.Context,
+	lockout *models.Lockout,
+) error {
+	query := `
+		UPDATE lockouts
+		SET locked_until = $3
+		WHERE kind = $1 AND value = $2
+	`
+
+	result, err := repo.db.Exec(
+		ctx,
+		query,
+		lockout.Kind,
+		lockout.Value,
+		lockout.LockedUntil,
+	)
+	if err != nil {
+		return postgres.PgxErrorToHTTPError(err)
+	}
+
+	rowsAffected := result.RowsAffected()
+	if rowsAffected == 0 {
+		return database.ErrResourceNotFound
+	}
+
+	return nil
+}
+
+// Reset forgets all failed attempts of a key.
+func (repo LockoutRepository) Reset(
+	ctx context.Context,
+	key models.LockoutKey,
+) error {
+	query := `
+		DELETE FROM lockouts
+		WHERE kind = $1 AND value = $2
+	`
+
+	_, err := repo.db.Exec(ctx, query, key.Kind, key.Value)
+	if err != nil {
+		return postgres.PgxErrorToHTTPError(err)
+	}
+
+	return nil
+}
+
+// Delete clears an active lockout of an organisation or, when
+// includeUnattributed is set, one which can't be attributed
+// to any organisation.
+func (repo LockoutRepository) Delete(
+	ctx context.Context,
+	organisationID string,
+	includeUnattributed bool,
+	key models.LockoutKey,
+) (*models.Lockout, error) {
+	query := `
+		DELETE FROM lockouts
+		WHERE kind = $1 AND value = $2 AND locked_until > $4
+		AND (organisation_id = $3 OR ($5 AND organisation_id IS NULL))
+		RETURNING organisation_id, failed_attempts,
+		 (last_failed_at AT TIME ZONE 'utc'), (locked_until AT TIME ZONE 'utc')
+	`
+
+	//nolint:exhaustruct //other fields are optional
+	lockout := models.Lockout{
+		Kind:  key.Kind,
+		Value: key.Value,
+	}
+
+	err := repo.db.QueryRow(
+		ctx,
+		query,
+		key.Kind,
+		key.Value,
+		organisationID,
+		repo.getTimeNowUTC(),
+		includeUnattributed,
+	).Scan(
+		&lockout.OrganisationID,
+		&lockout.FailedAttempts,
+		&lockout.LastFailedAt,
+		&lockout.LockedUntil,
+	)
+	if err != nil {
+		return nil, postgres.PgxErrorToHTTPError(err)
+	}
+
+	return &lockout, nil
+}
@@ -7,31 +7,81 @@ import (
 )
 
 type Repositories struct {
-	Auth           AuthRepository
-	CheckIns       CheckInRepository
-	CheckInsWriter CheckInWriteRepository
-	Locations      LocationRepository
-	Schools        SchoolRepository
-	Users          UserRepository
-	State          StateRepository
+	APIKeys          APIKeyRepository
+	Audit            AuditRepository
+	Auth             AuthRepository
+	Capacities       CapacityRepository
+	CheckIns         CheckInRepository
+	CheckInsWriter   CheckInWriteRepository
+	Devices          DeviceRepository
+	Locations        LocationRepository
+	Lockouts         LockoutRepository
+	ManagerLocations ManagerLocationRepository
+	OIDCLogins       OIDCLoginRepository
+	OpeningHours     OpeningHoursRepository
+	Organisations    OrganisationRepository
+	PairingCodes     PairingCodeRepository
+	Roles            RoleRepository
+	Rollups          RollupRepository
+	Schools          SchoolRepository
+	Sessions         SessionRepository
+	Users            UserRepository
+	State            StateRepository
+	TwoFactor        TwoFactorRepository
+	Transactions     TransactionRepository
+	Waitlist         WaitlistRepository
 }
 
 func New(db postgres.DB, utcNowTimeProvider shared.UTCNowTimeProvider) Repositories {
+	transactions := TransactionRepository{db: db}
+	db = txDB{db: db}
+
+	apiKeys := APIKeyRepository{db: db, getTimeNowUTC: utcNowTimeProvider}
 	checkInsWriter := CheckInWriteRepository{db: db, getTimeNowUTC: utcNowTimeProvider}
-	checkIns := CheckInRepository{db: db}
+	checkIns := CheckInRepository{db: db, getTimeNowUTC: utcNowTimeProvider}
+	devices := DeviceRepository{db: db}
 	schools := SchoolRepository{db: db}
 	locations := LocationRepository{db: db}
+	lockouts := LockoutRepository{db: db, getTimeNowUTC: utcNowTimeProvider}
+	managerLocations := ManagerLocationRepository{db: db}
+	capacities := CapacityRepository{db: db}
+	oidcLogins := OIDCLoginRepository{db: db, getTimeNowUTC: utcNowTimeProvider}
+	openingHours := OpeningHoursRepository{db: db}
+	organisations := OrganisationRepository{db: db}
+	pairingCodes := PairingCodeRepository{db: db, getTimeNowUTC: utcNowTimeProvider}
+	roles := RoleRepository{db: db}
+	rollups := RollupRepository{db: db}
+	sessions := SessionRepository{db: db, getTimeNowUTC: utcNowTimeProvider}
+	audit := AuditRepository{db: db, getTimeNowUTC: utcNowTimeProvider}
 	auth := AuthRepository{db: db, getTimeNowUTC: utcNowTimeProvider}
 	users := UserRepository{db: db}
 	state := StateRepository{db: db}
+	twoFactor := TwoFactorRepository{db: db, getTimeNowUTC: utcNowTimeProvider}
+	waitlist := WaitlistRepository{db: db, getTimeNowUTC: utcNowTimeProvider}
 
 	return Repositories{
-		Auth:           auth,
-		CheckIns:       checkIns,
-		CheckInsWriter: checkInsWriter,
-		Locations:      locations,
-		Schools:        schools,
-		Users:          users,
-		State:          state,
+		APIKeys:          apiKeys,
+		Audit:            audit,
+		Auth:             auth,
+		Capacities:       capacities,
+		CheckIns:         checkIns,
+		CheckInsWriter:   checkInsWriter,
+		Devices:          devices,
+		Locations:        locations,
+		Lockouts:         lockouts,
+		ManagerLocations: managerLocations,
+		OIDCLogins:       oidcLogins,
+		OpeningHours:     openingHours,
+		Organisations:    organisations,
+		PairingCodes:     pairingCodes,
+		Roles:            roles,
+		Rollups:          rollups,
+		Schools:          schools,
+		Sessions:         sessions,
+		Users:            users,
+		State:            state,
+		TwoFactor:        twoFactor,
+		Transactions:     transactions,
+		Waitlist:         waitlist,
 	}
 }
@@ -0,0 +1,76 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/XDoubleU/essentia/pkg/database"
+	"github.com/XDoubleU/essentia/pkg/database/postgres"
+)
+
+type ManagerLocationRepository struct {
+	db postgres.DB
+}
+
+func (repo ManagerLocationRepository) Exists(
+	ctx context.Context,
+	userID string,
+	locationID string,
+) (bool, error) {
+	query := `
+		SELECT EXISTS (
+			SELECT 1
+			FROM manager_locations
+			WHERE user_id = $1 AND location_id = $2
+		)
+	`
+
+	var exists bool
+
+	err := repo.db.QueryRow(ctx, query, userID, locationID).Scan(&exists)
+	if err != nil {
+		return false, postgres.PgxErrorToHTTPError(err)
+	}
+
+	return exists, nil
+}
+
+func (repo ManagerLocationRepository) Create(
+	ctx context.Context,
+	userID string,
+	locationID string,
+) error {
+	query := `
+		INSERT INTO manager_locations (user_id, location_id)
+		VALUES ($1, $2)
+	`
+
+	_, err := repo.db.Exec(ctx, query, userID, locationID)
+	if err != nil {
+		return postgres.PgxErrorToHTTPError(err)
+	}
+
+	return nil
+}
+
+func (repo ManagerLocationRepository) Delete(
+	ctx context.Context,
+	userID string,
+	locationID string,
+) error {
+	query := `
+		DELETE FROM manager_locations
+		WHERE user_id = $1 AND location_id = $2
+	`
+
+	result, err := repo.db.Exec(ctx, query, userID, locationID)
+	if err != nil {
+		return postgres.PgxErrorToHTTPError(err)
+	}
+
+	rowsAffected := result.RowsAffected()
+	if rowsAffected == 0 {
+		return database.ErrResourceNotFound
+	}
+
+	return nil
+}
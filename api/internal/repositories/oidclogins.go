@@ -0,0 +1,81 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/XDoubleU/essentia/pkg/database/postgres"
+
+	"check-in/api/internal/models"
+	"check-in/api/internal/shared"
+)
+
+type OIDCLoginRepository struct {
+	db            postgres.DB
+	getTimeNowUTC shared.UTCNowTimeProvider
+}
+
+func (repo OIDCLoginRepository) Create(
+	ctx context.Context,
+	login *models.OIDCLogin,
+	stateHash [32]byte,
+) error {
+	query := `
+		INSERT INTO oidc_logins (state_hash, code_verifier, nonce, expiry)
+		VALUES ($1, $2, $3, $4)
+	`
+
+	_, err := repo.db.Exec(
+		ctx,
+		query,
+		stateHash[:],
+		login.CodeVerifier,
+		login.Nonce,
+		login.Expiry,
+	)
+	if err != nil {
+		return postgres.PgxErrorToHTTPError(err)
+	}
+
+	return nil
+}
+
+// Consume deletes a sign in which hasn't expired yet and returns it,
+// so the state can only be used once.
+func (repo OIDCLoginRepository) Consume(
+	ctx context.Context,
+	stateHash [32]byte,
+) (*models.OIDCLogin, error) {
+	query := `
+		DELETE FROM oidc_logins
+		WHERE state_hash = $1 AND expiry > $2
+		RETURNING code_verifier, nonce, (expiry AT TIME ZONE 'utc')
+	`
+
+	//nolint:exhaustruct //other fields are optional
+	login := models.OIDCLogin{}
+
+	err := repo.db.QueryRow(ctx, query, stateHash[:], repo.getTimeNowUTC()).Scan(
+		&login.CodeVerifier,
+		&login.Nonce,
+		&login.Expiry,
+	)
+	if err != nil {
+		return nil, postgres.PgxErrorToHTTPError(err)
+	}
+
+	return &login, nil
+}
+
+func (repo OIDCLoginRepository) DeleteExpired(ctx context.Context) error {
+	query := `
+		DELETE FROM oidc_logins
+		WHERE expiry <= $1
+	`
+
+	_, err := repo.db.Exec(ctx, query, repo.getTimeNowUTC())
+	if err != nil {
+		return postgres.PgxErrorToHTTPError(err)
+	}
+
+	return nil
+}
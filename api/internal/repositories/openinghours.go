@@ -0,0 +1,173 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/XDoubleU/essentia/pkg/database/postgres"
+
+	"check-in/api/internal/models"
+)
+
+type OpeningHoursRepository struct {
+	db postgres.DB
+}
+
+func (repo OpeningHoursRepository) GetByLocationID(
+	ctx context.Context,
+	locationID string,
+) (*models.OpeningHours, error) {
+	periods, err := repo.getPeriods(ctx, locationID)
+	if err != nil {
+		return nil, err
+	}
+
+	closingDays, err := repo.getClosingDays(ctx, locationID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.OpeningHours{
+		Periods:     periods,
+		ClosingDays: closingDays,
+	}, nil
+}
+
+func (repo OpeningHoursRepository) getPeriods(
+	ctx context.Context,
+	locationID string,
+) ([]*models.OpeningPeriod, error) {
+	query := `
+		SELECT weekday, to_char(opens_at, 'HH24:MI'), to_char(closes_at, 'HH24:MI')
+		FROM opening_hours
+		WHERE location_id = $1
+		ORDER BY weekday ASC, opens_at ASC
+	`
+
+	rows, err := repo.db.Query(ctx, query, locationID)
+	if err != nil {
+		return nil, postgres.PgxErrorToHTTPError(err)
+	}
+
+	periods := []*models.OpeningPeriod{}
+	for rows.Next() {
+		var period models.OpeningPeriod
+
+		err = rows.Scan(
+			&period.Weekday,
+			&period.OpensAt,
+			&period.ClosesAt,
+		)
+		if err != nil {
+			return nil, postgres.PgxErrorToHTTPError(err)
+		}
+
+		periods = append(periods, &period)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, postgres.PgxErrorToHTTPError(err)
+	}
+
+	return periods, nil
+}
+
+func (repo OpeningHoursRepository) getClosingDays(
+	ctx context.Context,
+	locationID string,
+) ([]*models.ClosingDay, error) {
+	query := `
+		SELECT to_char(date, 'YYYY-MM-DD'), reason
+		FROM closing_days
+		WHERE location_id = $1
+		ORDER BY date ASC
+	`
+
+	rows, err := repo.db.Query(ctx, query, locationID)
+	if err != nil {
+		return nil, postgres.PgxErrorToHTTPError(err)
+	}
+
+	closingDays := []*models.ClosingDay{}
+	for rows.Next() {
+		var closingDay models.ClosingDay
+
+		err = rows.Scan(
+			&closingDay.Date,
+			&closingDay.Reason,
+		)
+		if err != nil {
+			return nil, postgres.PgxErrorToHTTPError(err)
+		}
+
+		closingDays = append(closingDays, &closingDay)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, postgres.PgxErrorToHTTPError(err)
+	}
+
+	return closingDays, nil
+}
+
+// Replace overwrites the opening hours of a location in a single transaction.
+func (repo OpeningHoursRepository) Replace(
+	ctx context.Context,
+	locationID string,
+	openingHours models.OpeningHours,
+) error {
+	tx, err := repo.db.Begin(ctx)
+	if err != nil {
+		return postgres.PgxErrorToHTTPError(err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	_, err = tx.Exec(ctx, "DELETE FROM opening_hours WHERE location_id = $1", locationID)
+	if err != nil {
+		return postgres.PgxErrorToHTTPError(err)
+	}
+
+	_, err = tx.Exec(ctx, "DELETE FROM closing_days WHERE location_id = $1", locationID)
+	if err != nil {
+		return postgres.PgxErrorToHTTPError(err)
+	}
+
+	for _, period := range openingHours.Periods {
+		_, err = tx.Exec(
+			ctx,
+			`
+			INSERT INTO opening_hours (location_id, weekday, opens_at, closes_at)
+			VALUES ($1, $2, $3::time, $4::time)
+			`,
+			locationID,
+			period.Weekday,
+			period.OpensAt,
+			period.ClosesAt,
+		)
+		if err != nil {
+			return postgres.PgxErrorToHTTPError(err)
+		}
+	}
+
+	for _, closingDay := range openingHours.ClosingDays {
+		_, err = tx.Exec(
+			ctx,
+			`
+			INSERT INTO closing_days (location_id, date, reason)
+			VALUES ($1, $2::date, $3)
+			`,
+			locationID,
+			closingDay.Date,
+			closingDay.Reason,
+		)
+		if err != nil {
+			return postgres.PgxErrorToHTTPError(err)
+		}
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return postgres.PgxErrorToHTTPError(err)
+	}
+
+	return nil
+}
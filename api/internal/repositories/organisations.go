@@ -0,0 +1,212 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/XDoubleU/essentia/pkg/database"
+	"github.com/XDoubleU/essentia/pkg/database/postgres"
+
+	"check-in/api/internal/dtos"
+	"check-in/api/internal/models"
+)
+
+type OrganisationRepository struct {
+	db postgres.DB
+}
+
+func (repo OrganisationRepository) GetTotalCount(ctx context.Context) (*int64, error) {
+	query := `
+		SELECT COUNT(*)
+		FROM organisations
+	`
+
+	var total *int64
+
+	err := repo.db.QueryRow(ctx, query).Scan(&total)
+	if err != nil {
+		return nil, postgres.PgxErrorToHTTPError(err)
+	}
+
+	return total, nil
+}
+
+func (repo OrganisationRepository) GetAll(
+	ctx context.Context,
+) ([]*models.Organisation, error) {
+	query := `
+		SELECT id, name, created_at
+		FROM organisations
+		ORDER BY name ASC
+	`
+
+	rows, err := repo.db.Query(ctx, query)
+	if err != nil {
+		return nil, postgres.PgxErrorToHTTPError(err)
+	}
+
+	organisations := []*models.Organisation{}
+	for rows.Next() {
+		var organisation models.Organisation
+
+		err = rows.Scan(
+			&organisation.ID,
+			&organisation.Name,
+			&organisation.CreatedAt,
+		)
+		if err != nil {
+			return nil, postgres.PgxErrorToHTTPError(err)
+		}
+
+		organisations = append(organisations, &organisation)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, postgres.PgxErrorToHTTPError(err)
+	}
+
+	return organisations, nil
+}
+
+func (repo OrganisationRepository) GetAllPaginated(
+	ctx context.Context,
+	limit int64,
+	offset int64,
+) ([]*models.Organisation, error) {
+	query := `
+		SELECT id, name, created_at
+		FROM organisations
+		ORDER BY name ASC
+		LIMIT $1 OFFSET $2
+	`
+
+	rows, err := repo.db.Query(ctx, query, limit, offset)
+	if err != nil {
+		return nil, postgres.PgxErrorToHTTPError(err)
+	}
+
+	organisations := []*models.Organisation{}
+	for rows.Next() {
+		var organisation models.Organisation
+
+		err = rows.Scan(
+			&organisation.ID,
+			&organisation.Name,
+			&organisation.CreatedAt,
+		)
+		if err != nil {
+			return nil, postgres.PgxErrorToHTTPError(err)
+		}
+
+		organisations = append(organisations, &organisation)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, postgres.PgxErrorToHTTPError(err)
+	}
+
+	return organisations, nil
+}
+
+func (repo OrganisationRepository) GetByID(
+	ctx context.Context,
+	id string,
+) (*models.Organisation, error) {
+	query := `
+		SELECT name, created_at
+		FROM organisations
+		WHERE id = $1
+	`
+
+	//nolint:exhaustruct //other fields are optional
+	organisation := models.Organisation{
+		ID: id,
+	}
+
+	err := repo.db.QueryRow(ctx, query, id).Scan(
+		&organisation.Name,
+		&organisation.CreatedAt,
+	)
+	if err != nil {
+		return nil, postgres.PgxErrorToHTTPError(err)
+	}
+
+	return &organisation, nil
+}
+
+func (repo OrganisationRepository) GetByName(
+	ctx context.Context,
+	name string,
+) (*models.Organisation, error) {
+	query := `
+		SELECT id, created_at
+		FROM organisations
+		WHERE name = $1
+	`
+
+	//nolint:exhaustruct //other fields are optional
+	organisation := models.Organisation{
+		Name: name,
+	}
+
+	err := repo.db.QueryRow(ctx, query, name).Scan(
+		&organisation.ID,
+		&organisation.CreatedAt,
+	)
+	if err != nil {
+		return nil, postgres.PgxErrorToHTTPError(err)
+	}
+
+	return &organisation, nil
+}
+
+func (repo OrganisationRepository) Create(
+	ctx context.Context,
+	name string,
+) (*models.Organisation, error) {
+	query := `
+		INSERT INTO organisations (name)
+		VALUES ($1)
+		RETURNING id, created_at
+	`
+
+	//nolint:exhaustruct //other fields are optional
+	organisation := models.Organisation{
+		Name: name,
+	}
+
+	err := repo.db.QueryRow(ctx, query, name).Scan(
+		&organisation.ID,
+		&organisation.CreatedAt,
+	)
+	if err != nil {
+		return nil, postgres.PgxErrorToHTTPError(err)
+	}
+
+	return &organisation, nil
+}
+
+func (repo OrganisationRepository) Update(
+	ctx context.Context,
+	organisation models.Organisation,
+	organisationDto dtos.OrganisationDto,
+) (*models.Organisation, error) {
+	organisation.Name = organisationDto.Name
+
+	query := `
+		UPDATE organisations
+		SET name = $2
+		WHERE id = $1
+	`
+
+	result, err := repo.db.Exec(ctx, query, organisation.ID, organisation.Name)
+	if err != nil {
+		return nil, postgres.PgxErrorToHTTPError(err)
+	}
+
+	rowsAffected := result.RowsAffected()
+	if rowsAffected == 0 {
+		return nil, database.ErrResourceNotFound
+	}
+
+	return &organisation, nil
+}
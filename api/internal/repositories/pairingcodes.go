@@ -0,0 +1,86 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/XDoubleU/essentia/pkg/database/postgres"
+
+	"check-in/api/internal/models"
+	"check-in/api/internal/shared"
+)
+
+type PairingCodeRepository struct {
+	db            postgres.DB
+	getTimeNowUTC shared.UTCNowTimeProvider
+}
+
+func (repo PairingCodeRepository) Create(
+	ctx context.Context,
+	pairingCode *models.PairingCode,
+	hash [32]byte,
+) error {
+	query := `
+		INSERT INTO pairing_codes (hash, location_id, device_name, expiry)
+		VALUES ($1, $2, $3, $4)
+	`
+
+	_, err := repo.db.Exec(
+		ctx,
+		query,
+		hash[:],
+		pairingCode.LocationID,
+		pairingCode.DeviceName,
+		pairingCode.Expiry,
+	)
+	if err != nil {
+		return postgres.PgxErrorToHTTPError(err)
+	}
+
+	return nil
+}
+
+// Consume deletes a pairing code which hasn't expired yet and returns
+// a device for its location, which still has to be created.
+func (repo PairingCodeRepository) Consume(
+	ctx context.Context,
+	hash [32]byte,
+) (*models.Device, error) {
+	query := `
+		WITH pairing_code AS (
+			DELETE FROM pairing_codes
+			WHERE hash = $1 AND expiry > $2
+			RETURNING location_id, device_name
+		)
+		SELECT pairing_code.location_id, pairing_code.device_name,
+		 locations.user_id, locations.organisation_id
+		FROM pairing_code
+		INNER JOIN locations
+		ON locations.id = pairing_code.location_id
+		WHERE locations.archived_at IS NULL
+	`
+
+	//nolint:exhaustruct //other fields are optional
+	device := models.Device{}
+
+	err := repo.db.QueryRow(ctx, query, hash[:], repo.getTimeNowUTC()).Scan(
+		&device.LocationID,
+		&device.Name,
+		&device.UserID,
+		&device.OrganisationID,
+	)
+	if err != nil {
+		return nil, postgres.PgxErrorToHTTPError(err)
+	}
+
+	return &device, nil
+}
+
+func (repo PairingCodeRepository) DeleteExpired(ctx context.Context) error {
+	query := `
+		DELETE FROM pairing_codes
+		WHERE expiry < $1
+	`
+
+	_, err := repo.db.Exec(ctx, query, repo.getTimeNowUTC())
+	return err
+}
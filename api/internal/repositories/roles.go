@@ -0,0 +1,201 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/XDoubleU/essentia/pkg/database"
+	"github.com/XDoubleU/essentia/pkg/database/postgres"
+
+	"check-in/api/internal/dtos"
+	"check-in/api/internal/models"
+)
+
+type RoleRepository struct {
+	db postgres.DB
+}
+
+// GetAll returns the built-in roles followed by
+// the custom roles of the organisation.
+func (repo RoleRepository) GetAll(
+	ctx context.Context,
+	organisationID string,
+) ([]*models.RoleDefinition, error) {
+	query := `
+		SELECT id, name, organisation_id::text, permissions
+		FROM roles
+		WHERE organisation_id IS NULL OR organisation_id = $1
+		ORDER BY organisation_id NULLS FIRST, name ASC
+	`
+
+	rows, err := repo.db.Query(ctx, query, organisationID)
+	if err != nil {
+		return nil, postgres.PgxErrorToHTTPError(err)
+	}
+
+	roles := []*models.RoleDefinition{}
+	for rows.Next() {
+		var role models.RoleDefinition
+		var permissions []string
+
+		err = rows.Scan(
+			&role.ID,
+			&role.Name,
+			&role.OrganisationID,
+			&permissions,
+		)
+		if err != nil {
+			return nil, postgres.PgxErrorToHTTPError(err)
+		}
+
+		role.BuiltIn = !role.OrganisationID.Valid
+		role.Permissions = toPermissions(permissions)
+
+		roles = append(roles, &role)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, postgres.PgxErrorToHTTPError(err)
+	}
+
+	return roles, nil
+}
+
+// GetByID only returns custom roles, built-in roles can't be changed.
+func (repo RoleRepository) GetByID(
+	ctx context.Context,
+	organisationID string,
+	id string,
+) (*models.RoleDefinition, error) {
+	query := `
+		SELECT name, organisation_id::text, permissions
+		FROM roles
+		WHERE id = $1 AND organisation_id = $2
+	`
+
+	//nolint:exhaustruct //other fields are optional
+	role := models.RoleDefinition{
+		ID: id,
+	}
+
+	var permissions []string
+
+	err := repo.db.QueryRow(ctx, query, id, organisationID).Scan(
+		&role.Name,
+		&role.OrganisationID,
+		&permissions,
+	)
+	if err != nil {
+		return nil, postgres.PgxErrorToHTTPError(err)
+	}
+
+	role.Permissions = toPermissions(permissions)
+
+	return &role, nil
+}
+
+func (repo RoleRepository) Create(
+	ctx context.Context,
+	organisationID string,
+	name string,
+	permissions []models.Permission,
+) (*models.RoleDefinition, error) {
+	query := `
+		INSERT INTO roles (name, organisation_id, permissions)
+		VALUES ($1, $2, $3)
+		RETURNING id, organisation_id::text
+	`
+
+	//nolint:exhaustruct //other fields are optional
+	role := models.RoleDefinition{
+		Name:        name,
+		Permissions: permissions,
+	}
+
+	err := repo.db.QueryRow(
+		ctx,
+		query,
+		name,
+		organisationID,
+		fromPermissions(permissions),
+	).Scan(&role.ID, &role.OrganisationID)
+	if err != nil {
+		return nil, postgres.PgxErrorToHTTPError(err)
+	}
+
+	return &role, nil
+}
+
+func (repo RoleRepository) Update(
+	ctx context.Context,
+	role models.RoleDefinition,
+	roleDto dtos.RoleDto,
+) (*models.RoleDefinition, error) {
+	role.Name = roleDto.Name
+	role.Permissions = roleDto.Permissions
+
+	query := `
+		UPDATE roles
+		SET name = $3, permissions = $4
+		WHERE id = $1 AND organisation_id = $2
+	`
+
+	result, err := repo.db.Exec(
+		ctx,
+		query,
+		role.ID,
+		role.OrganisationID,
+		role.Name,
+		fromPermissions(role.Permissions),
+	)
+	if err != nil {
+		return nil, postgres.PgxErrorToHTTPError(err)
+	}
+
+	rowsAffected := result.RowsAffected()
+	if rowsAffected == 0 {
+		return nil, database.ErrResourceNotFound
+	}
+
+	return &role, nil
+}
+
+func (repo RoleRepository) Delete(
+	ctx context.Context,
+	organisationID string,
+	id string,
+) error {
+	query := `
+		DELETE FROM roles
+		WHERE id = $1 AND organisation_id = $2
+	`
+
+	result, err := repo.db.Exec(ctx, query, id, organisationID)
+	if err != nil {
+		return postgres.PgxErrorToHTTPError(err)
+	}
+
+	rowsAffected := result.RowsAffected()
+	if rowsAffected == 0 {
+		return database.ErrResourceNotFound
+	}
+
+	return nil
+}
+
+func toPermissions(values []string) []models.Permission {
+	permissions := make([]models.Permission, 0, len(values))
+	for _, value := range values {
+		permissions = append(permissions, models.Permission(value))
+	}
+
+	return permissions
+}
+
+func fromPermissions(permissions []models.Permission) []string {
+	values := make([]string, 0, len(permissions))
+	for _, permission := range permissions {
+		values = append(values, string(permission))
+	}
+
+	return values
+}
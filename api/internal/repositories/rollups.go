@@ -0,0 +1,180 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/XDoubleU/essentia/pkg/database/postgres"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// businessDateSQL is the business date of a check-in, the date in the
+// time zone of its location on which the business day of the location
+// started.
+const businessDateSQL = `((check_ins.created_at AT TIME ZONE locations.time_zone) -
+ locations.business_day_start::interval)::date`
+
+// localDateSQL and localHourSQL are the calendar date and the hour
+// of a check-in in the time zone of its location.
+const (
+	localDateSQL = `(check_ins.created_at AT TIME ZONE locations.time_zone)::date`
+	localHourSQL = `EXTRACT(HOUR FROM check_ins.created_at AT TIME ZONE
+ locations.time_zone)::int2`
+)
+
+// RollupRepository maintains check_in_daily_rollups, which holds
+// the amount of check-ins per location, school and business date,
+// and check_in_hourly_rollups, which holds the amount of check-ins
+// per location, local date and hour.
+// Rollups are updated together with the check-ins they count.
+type RollupRepository struct {
+	db postgres.DB
+}
+
+// Rebuild recalculates the rollups of all locations from their check-ins.
+func (repo RollupRepository) Rebuild(ctx context.Context) error {
+	tx, err := repo.db.Begin(ctx)
+	if err != nil {
+		return postgres.PgxErrorToHTTPError(err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	err = rebuildRollups(ctx, tx, pgtype.Text{String: "", Valid: false})
+	if err != nil {
+		return err
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return postgres.PgxErrorToHTTPError(err)
+	}
+
+	return nil
+}
+
+// rebuildRollups recalculates the rollups of a location,
+// or of all locations if no location is provided.
+func rebuildRollups(ctx context.Context, tx pgx.Tx, locationID pgtype.Text) error {
+	deleteQuery := `
+		DELETE FROM check_in_daily_rollups
+		WHERE $1::uuid IS NULL OR location_id = $1
+	`
+
+	insertQuery := `
+		INSERT INTO check_in_daily_rollups
+		(location_id, school_id, business_date, count, capacity)
+		SELECT check_ins.location_id, check_ins.school_id,
+		 ` + businessDateSQL + ` AS business_date,
+		 COUNT(*), MAX(check_ins.capacity)
+		FROM check_ins
+		INNER JOIN locations
+		ON locations.id = check_ins.location_id
+		WHERE $1::uuid IS NULL OR check_ins.location_id = $1
+		GROUP BY check_ins.location_id, check_ins.school_id, business_date
+	`
+
+	deleteHourlyQuery := `
+		DELETE FROM check_in_hourly_rollups
+		WHERE $1::uuid IS NULL OR location_id = $1
+	`
+
+	insertHourlyQuery := `
+		INSERT INTO check_in_hourly_rollups (location_id, date, hour, count)
+		SELECT check_ins.location_id, ` + localDateSQL + ` AS date,
+		 ` + localHourSQL + ` AS hour, COUNT(*)
+		FROM check_ins
+		INNER JOIN locations
+		ON locations.id = check_ins.location_id
+		WHERE $1::uuid IS NULL OR check_ins.location_id = $1
+		GROUP BY check_ins.location_id, date, hour
+	`
+
+	for _, rollupQuery := range []string{
+		deleteQuery,
+		insertQuery,
+		deleteHourlyQuery,
+		insertHourlyQuery,
+	} {
+		_, err := tx.Exec(ctx, rollupQuery, locationID)
+		if err != nil {
+			return postgres.PgxErrorToHTTPError(err)
+		}
+	}
+
+	return nil
+}
+
+// addToRollup counts a newly created check-in in the rollups
+// of its day and of its hour.
+func addToRollup(ctx context.Context, tx pgx.Tx, checkInID int64) error {
+	query := `
+		INSERT INTO check_in_daily_rollups
+		(location_id, school_id, business_date, count, capacity)
+		SELECT check_ins.location_id, check_ins.school_id,
+		 ` + businessDateSQL + `, 1, check_ins.capacity
+		FROM check_ins
+		INNER JOIN locations
+		ON locations.id = check_ins.location_id
+		WHERE check_ins.id = $1
+		ON CONFLICT (location_id, school_id, business_date)
+		DO UPDATE SET count = check_in_daily_rollups.count + 1,
+		 capacity = GREATEST(check_in_daily_rollups.capacity, EXCLUDED.capacity)
+	`
+
+	hourlyQuery := `
+		INSERT INTO check_in_hourly_rollups (location_id, date, hour, count)
+		SELECT check_ins.location_id, ` + localDateSQL + `,
+		 ` + localHourSQL + `, 1
+		FROM check_ins
+		INNER JOIN locations
+		ON locations.id = check_ins.location_id
+		WHERE check_ins.id = $1
+		ON CONFLICT (location_id, date, hour)
+		DO UPDATE SET count = check_in_hourly_rollups.count + 1
+	`
+
+	for _, rollupQuery := range []string{query, hourlyQuery} {
+		_, err := tx.Exec(ctx, rollupQuery, checkInID)
+		if err != nil {
+			return postgres.PgxErrorToHTTPError(err)
+		}
+	}
+
+	return nil
+}
+
+// removeFromRollup stops counting a check-in which is about to be deleted.
+func removeFromRollup(ctx context.Context, tx pgx.Tx, checkInID int64) error {
+	query := `
+		UPDATE check_in_daily_rollups
+		SET count = check_in_daily_rollups.count - 1
+		FROM check_ins
+		INNER JOIN locations
+		ON locations.id = check_ins.location_id
+		WHERE check_ins.id = $1
+		AND check_in_daily_rollups.location_id = check_ins.location_id
+		AND check_in_daily_rollups.school_id = check_ins.school_id
+		AND check_in_daily_rollups.business_date = ` + businessDateSQL + `
+	`
+
+	hourlyQuery := `
+		UPDATE check_in_hourly_rollups
+		SET count = check_in_hourly_rollups.count - 1
+		FROM check_ins
+		INNER JOIN locations
+		ON locations.id = check_ins.location_id
+		WHERE check_ins.id = $1
+		AND check_in_hourly_rollups.location_id = check_ins.location_id
+		AND check_in_hourly_rollups.date = ` + localDateSQL + `
+		AND check_in_hourly_rollups.hour = ` + localHourSQL + `
+	`
+
+	for _, rollupQuery := range []string{query, hourlyQuery} {
+		_, err := tx.Exec(ctx, rollupQuery, checkInID)
+		if err != nil {
+			return postgres.PgxErrorToHTTPError(err)
+		}
+	}
+
+	return nil
+}
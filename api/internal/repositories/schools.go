@@ -14,15 +14,19 @@ type SchoolRepository struct {
 	db postgres.DB
 }
 
-func (repo SchoolRepository) GetTotalCount(ctx context.Context) (*int64, error) {
+func (repo SchoolRepository) GetTotalCount(
+	ctx context.Context,
+	organisationID string,
+) (*int64, error) {
 	query := `
 		SELECT COUNT(*)
 		FROM schools
+		WHERE (organisation_id = $1 OR read_only = true) AND archived_at IS NULL
 	`
 
 	var total *int64
 
-	err := repo.db.QueryRow(ctx, query).Scan(&total)
+	err := repo.db.QueryRow(ctx, query, organisationID).Scan(&total)
 	if err != nil {
 		return nil, postgres.PgxErrorToHTTPError(err)
 	}
@@ -30,14 +34,60 @@ func (repo SchoolRepository) GetTotalCount(ctx context.Context) (*int64, error)
 	return total, nil
 }
 
-func (repo SchoolRepository) GetAll(ctx context.Context) ([]*models.School, error) {
+func (repo SchoolRepository) GetAll(
+	ctx context.Context,
+	organisationID string,
+) ([]*models.School, error) {
 	query := `
 		SELECT id, name
 		FROM schools
+		WHERE (organisation_id = $1 OR read_only = true) AND archived_at IS NULL
 		ORDER BY name ASC
 	`
 
-	rows, err := repo.db.Query(ctx, query)
+	rows, err := repo.db.Query(ctx, query, organisationID)
+	if err != nil {
+		return nil, postgres.PgxErrorToHTTPError(err)
+	}
+
+	schools := []*models.School{}
+
+	for rows.Next() {
+		var school models.School
+
+		err = rows.Scan(
+			&school.ID,
+			&school.Name,
+		)
+
+		if err != nil {
+			return nil, postgres.PgxErrorToHTTPError(err)
+		}
+
+		schools = append(schools, &school)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, postgres.PgxErrorToHTTPError(err)
+	}
+
+	return schools, nil
+}
+
+// GetAllIncludingArchived is used to resolve the names
+// of schools referred to by historic check-ins.
+func (repo SchoolRepository) GetAllIncludingArchived(
+	ctx context.Context,
+	organisationID string,
+) ([]*models.School, error) {
+	query := `
+		SELECT id, name
+		FROM schools
+		WHERE organisation_id = $1 OR read_only = true
+		ORDER BY name ASC
+	`
+
+	rows, err := repo.db.Query(ctx, query, organisationID)
 	if err != nil {
 		return nil, postgres.PgxErrorToHTTPError(err)
 	}
@@ -68,11 +118,13 @@ func (repo SchoolRepository) GetAll(ctx context.Context) ([]*models.School, erro
 
 func (repo SchoolRepository) GetAllSortedByLocation(
 	ctx context.Context,
+	organisationID string,
 	locationID string,
 ) ([]*models.School, error) {
 	query := `
 		SELECT id, name
 		FROM schools
+		WHERE (organisation_id = $2 OR read_only = true) AND archived_at IS NULL
 		ORDER BY
 			CASE
 				WHEN read_only = true THEN -1
@@ -86,7 +138,7 @@ func (repo SchoolRepository) GetAllSortedByLocation(
 		DESC, name ASC
 	`
 
-	rows, err := repo.db.Query(ctx, query, locationID)
+	rows, err := repo.db.Query(ctx, query, locationID, organisationID)
 	if err != nil {
 		return nil, postgres.PgxErrorToHTTPError(err)
 	}
@@ -117,17 +169,19 @@ func (repo SchoolRepository) GetAllSortedByLocation(
 
 func (repo SchoolRepository) GetAllPaginated(
 	ctx context.Context,
+	organisationID string,
 	limit int64,
 	offset int64,
 ) ([]*models.School, error) {
 	query := `
 		SELECT id, name, read_only
 		FROM schools
+		WHERE (organisation_id = $1 OR read_only = true) AND archived_at IS NULL
 		ORDER BY name ASC
-		LIMIT $1 OFFSET $2
+		LIMIT $2 OFFSET $3
 	`
 
-	rows, err := repo.db.Query(ctx, query, limit, offset)
+	rows, err := repo.db.Query(ctx, query, organisationID, limit, offset)
 	if err != nil {
 		return nil, postgres.PgxErrorToHTTPError(err)
 	}
@@ -159,12 +213,14 @@ func (repo SchoolRepository) GetAllPaginated(
 
 func (repo SchoolRepository) GetByID(
 	ctx context.Context,
+	organisationID string,
 	id int64,
 ) (*models.School, error) {
 	query := `
 		SELECT name, read_only
 		FROM schools
-		WHERE id = $1
+		WHERE id = $1 AND (organisation_id = $2 OR read_only = true)
+		AND archived_at IS NULL
 	`
 
 	//nolint:exhaustruct //other fields are optional
@@ -175,7 +231,8 @@ func (repo SchoolRepository) GetByID(
 	err := repo.db.QueryRow(
 		ctx,
 		query,
-		id).Scan(&school.Name, &school.ReadOnly)
+		id,
+		organisationID).Scan(&school.Name, &school.ReadOnly)
 
 	if err != nil {
 		return nil, postgres.PgxErrorToHTTPError(err)
@@ -186,12 +243,14 @@ func (repo SchoolRepository) GetByID(
 
 func (repo SchoolRepository) GetByName(
 	ctx context.Context,
+	organisationID string,
 	name string,
 ) (*models.School, error) {
 	query := `
 		SELECT id, read_only
 		FROM schools
-		WHERE name = $1
+		WHERE name = $1 AND (organisation_id = $2 OR read_only = true)
+		AND archived_at IS NULL
 	`
 
 	//nolint:exhaustruct //other fields are optional
@@ -202,7 +261,8 @@ func (repo SchoolRepository) GetByName(
 	err := repo.db.QueryRow(
 		ctx,
 		query,
-		name).Scan(&school.ID, &school.ReadOnly)
+		name,
+		organisationID).Scan(&school.ID, &school.ReadOnly)
 
 	if err != nil {
 		return nil, postgres.PgxErrorToHTTPError(err)
@@ -213,12 +273,14 @@ func (repo SchoolRepository) GetByName(
 
 func (repo SchoolRepository) GetByIDWithoutReadOnly(
 	ctx context.Context,
+	organisationID string,
 	id int64,
 ) (*models.School, error) {
 	query := `
 		SELECT name
 		FROM schools
-		WHERE id = $1 AND read_only = false
+		WHERE id = $1 AND organisation_id = $2 AND read_only = false
+		AND archived_at IS NULL
 	`
 
 	//nolint:exhaustruct //other fields are optional
@@ -230,7 +292,8 @@ func (repo SchoolRepository) GetByIDWithoutReadOnly(
 	err := repo.db.QueryRow(
 		ctx,
 		query,
-		id).Scan(&school.Name)
+		id,
+		organisationID).Scan(&school.Name)
 
 	if err != nil {
 		return nil, postgres.PgxErrorToHTTPError(err)
@@ -241,11 +304,12 @@ func (repo SchoolRepository) GetByIDWithoutReadOnly(
 
 func (repo SchoolRepository) Create(
 	ctx context.Context,
+	organisationID string,
 	name string,
 ) (*models.School, error) {
 	query := `
-		INSERT INTO schools (name)
-		VALUES ($1)
+		INSERT INTO schools (name, organisation_id)
+		VALUES ($1, $2)
 		RETURNING id
 	`
 
@@ -254,7 +318,7 @@ func (repo SchoolRepository) Create(
 		Name: name,
 	}
 
-	err := repo.db.QueryRow(ctx, query, name).Scan(&school.ID)
+	err := repo.db.QueryRow(ctx, query, name, organisationID).Scan(&school.ID)
 
 	if err != nil {
 		return nil, postgres.PgxErrorToHTTPError(err)
@@ -265,6 +329,7 @@ func (repo SchoolRepository) Create(
 
 func (repo SchoolRepository) Update(
 	ctx context.Context,
+	organisationID string,
 	school models.School,
 	schoolDto dtos.SchoolDto,
 ) (*models.School, error) {
@@ -272,11 +337,12 @@ func (repo SchoolRepository) Update(
 
 	query := `
 		UPDATE schools
-		SET name = $2
-		WHERE id = $1 AND read_only = false
+		SET name = $3
+		WHERE id = $1 AND organisation_id = $2 AND read_only = false
+		AND archived_at IS NULL
 	`
 
-	result, err := repo.db.Exec(ctx, query, school.ID, school.Name)
+	result, err := repo.db.Exec(ctx, query, school.ID, organisationID, school.Name)
 	if err != nil {
 		return nil, postgres.PgxErrorToHTTPError(err)
 	}
@@ -289,13 +355,20 @@ func (repo SchoolRepository) Update(
 	return &school, nil
 }
 
-func (repo SchoolRepository) Delete(ctx context.Context, id int64) error {
+// Archive hides a school, check-ins keep referring to it.
+func (repo SchoolRepository) Archive(
+	ctx context.Context,
+	organisationID string,
+	id int64,
+) error {
 	query := `
-		DELETE FROM schools
-		WHERE id = $1 AND read_only = false
+		UPDATE schools
+		SET archived_at = now()
+		WHERE id = $1 AND organisation_id = $2 AND read_only = false
+		AND archived_at IS NULL
 	`
 
-	result, err := repo.db.Exec(ctx, query, id)
+	result, err := repo.db.Exec(ctx, query, id, organisationID)
 	if err != nil {
 		return postgres.PgxErrorToHTTPError(err)
 	}
@@ -307,3 +380,32 @@ func (repo SchoolRepository) Delete(ctx context.Context, id int64) error {
 
 	return nil
 }
+
+// Restore makes an archived school visible again.
+func (repo SchoolRepository) Restore(
+	ctx context.Context,
+	organisationID string,
+	id int64,
+) (*models.School, error) {
+	query := `
+		UPDATE schools
+		SET archived_at = NULL
+		WHERE id = $1 AND organisation_id = $2 AND archived_at IS NOT NULL
+		RETURNING name, read_only
+	`
+
+	//nolint:exhaustruct //other fields are optional
+	school := models.School{
+		ID: id,
+	}
+
+	err := repo.db.QueryRow(ctx, query, id, organisationID).Scan(
+		&school.Name,
+		&school.ReadOnly,
+	)
+	if err != nil {
+		return nil, postgres.PgxErrorToHTTPError(err)
+	}
+
+	return &school, nil
+}
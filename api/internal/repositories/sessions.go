@@ -0,0 +1,210 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/XDoubleU/essentia/pkg/database"
+	"github.com/XDoubleU/essentia/pkg/database/postgres"
+
+	"check-in/api/internal/models"
+	"check-in/api/internal/shared"
+)
+
+// sessionTouchInterval limits how often the last use of a session is stored,
+// so not every request of a user writes to the database.
+const sessionTouchInterval = time.Minute
+
+type SessionRepository struct {
+	db            postgres.DB
+	getTimeNowUTC shared.UTCNowTimeProvider
+}
+
+// GetAll returns the sessions of a user which still have valid tokens,
+// sessions of devices are managed per device.
+func (repo SessionRepository) GetAll(
+	ctx context.Context,
+	userID string,
+) ([]*models.Session, error) {
+	query := `
+		SELECT sessions.id, sessions.user_agent, sessions.ip,
+		 (sessions.created_at AT TIME ZONE 'utc'),
+		 (sessions.last_used_at AT TIME ZONE 'utc'),
+		 (MAX(tokens.expiry) AT TIME ZONE 'utc')
+		FROM sessions
+		INNER JOIN tokens
+		ON tokens.session_id = sessions.id
+		WHERE sessions.user_id = $1 AND sessions.device_id IS NULL
+		AND tokens.expiry > $2
+		GROUP BY sessions.id
+		ORDER BY sessions.last_used_at DESC
+	`
+
+	rows, err := repo.db.Query(ctx, query, userID, repo.getTimeNowUTC())
+	if err != nil {
+		return nil, postgres.PgxErrorToHTTPError(err)
+	}
+
+	sessions := []*models.Session{}
+	for rows.Next() {
+		//nolint:exhaustruct //other fields are optional
+		session := models.Session{
+			UserID: userID,
+		}
+
+		err = rows.Scan(
+			&session.ID,
+			&session.UserAgent,
+			&session.IP,
+			&session.CreatedAt,
+			&session.LastUsedAt,
+			&session.Expiry,
+		)
+		if err != nil {
+			return nil, postgres.PgxErrorToHTTPError(err)
+		}
+
+		sessions = append(sessions, &session)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, postgres.PgxErrorToHTTPError(err)
+	}
+
+	return sessions, nil
+}
+
+func (repo SessionRepository) Create(
+	ctx context.Context,
+	session *models.Session,
+) error {
+	query := `
+		INSERT INTO sessions (user_id, device_id, user_agent, ip, created_at,
+		 last_used_at)
+		VALUES ($1, $2, $3, $4, $5, $5)
+		RETURNING id
+	`
+
+	err := repo.db.QueryRow(
+		ctx,
+		query,
+		session.UserID,
+		session.DeviceID,
+		session.UserAgent,
+		session.IP,
+		session.CreatedAt,
+	).Scan(&session.ID)
+	if err != nil {
+		return postgres.PgxErrorToHTTPError(err)
+	}
+
+	return nil
+}
+
+// Touch records the last use of a session and the IP it was used from,
+// unless the session was already used less than a minute ago.
+func (repo SessionRepository) Touch(
+	ctx context.Context,
+	id string,
+	ip string,
+) error {
+	query := `
+		UPDATE sessions
+		SET last_used_at = $2, ip = $3
+		WHERE id = $1 AND last_used_at <= $4
+	`
+
+	now := repo.getTimeNowUTC()
+
+	_, err := repo.db.Exec(ctx, query, id, now, ip, now.Add(-sessionTouchInterval))
+	if err != nil {
+		return postgres.PgxErrorToHTTPError(err)
+	}
+
+	return nil
+}
+
+// Delete removes a session of a user, its tokens are deleted with it.
+func (repo SessionRepository) Delete(
+	ctx context.Context,
+	userID string,
+	id string,
+) error {
+	query := `
+		DELETE FROM sessions
+		WHERE id = $1 AND user_id = $2 AND device_id IS NULL
+	`
+
+	result, err := repo.db.Exec(ctx, query, id, userID)
+	if err != nil {
+		return postgres.PgxErrorToHTTPError(err)
+	}
+
+	rowsAffected := result.RowsAffected()
+	if rowsAffected == 0 {
+		return database.ErrResourceNotFound
+	}
+
+	return nil
+}
+
+// DeleteAll removes all sessions of a user except those of devices,
+// tokens issued before sessions existed are deleted as well.
+func (repo SessionRepository) DeleteAll(
+	ctx context.Context,
+	userID string,
+) error {
+	tx, err := repo.db.Begin(ctx)
+	if err != nil {
+		return postgres.PgxErrorToHTTPError(err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	_, err = tx.Exec(
+		ctx,
+		"DELETE FROM sessions WHERE user_id = $1 AND device_id IS NULL",
+		userID,
+	)
+	if err != nil {
+		return postgres.PgxErrorToHTTPError(err)
+	}
+
+	_, err = tx.Exec(
+		ctx,
+		"DELETE FROM tokens WHERE user_id = $1 AND device_id IS NULL",
+		userID,
+	)
+	if err != nil {
+		return postgres.PgxErrorToHTTPError(err)
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return postgres.PgxErrorToHTTPError(err)
+	}
+
+	return nil
+}
+
+// DeleteExpired removes sessions of which all tokens expired or were
+// deleted. Sessions are only removed a while after they were last used,
+// as their tokens are created right after the session.
+func (repo SessionRepository) DeleteExpired(ctx context.Context) error {
+	query := `
+		DELETE FROM sessions
+		WHERE last_used_at < $2
+		AND NOT EXISTS (
+		 SELECT 1 FROM tokens
+		 WHERE tokens.session_id = sessions.id AND tokens.expiry > $1
+		)
+	`
+
+	now := repo.getTimeNowUTC()
+
+	_, err := repo.db.Exec(ctx, query, now, now.Add(-time.Minute))
+	if err != nil {
+		return postgres.PgxErrorToHTTPError(err)
+	}
+
+	return nil
+}
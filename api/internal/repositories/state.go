@@ -7,6 +7,7 @@ import (
 
 	"github.com/XDoubleU/essentia/pkg/database"
 	"github.com/XDoubleU/essentia/pkg/database/postgres"
+	"github.com/jackc/pgx/v5/pgtype"
 
 	"check-in/api/internal/models"
 )
@@ -17,16 +18,18 @@ type StateRepository struct {
 
 func (repo StateRepository) Get(
 	ctx context.Context,
+	organisationID string,
 ) (*models.State, error) {
 	query := `
 		SELECT key, value
 		FROM states
+		WHERE organisation_id = $1
 	`
 
 	//nolint:exhaustruct //fields are initialized later
 	state := models.State{}
 
-	rows, err := repo.db.Query(ctx, query)
+	rows, err := repo.db.Query(ctx, query, organisationID)
 	if err != nil {
 		return nil, postgres.PgxErrorToHTTPError(err)
 	}
@@ -44,8 +47,13 @@ func (repo StateRepository) Get(
 			return nil, postgres.PgxErrorToHTTPError(err)
 		}
 
-		if key == models.IsMaintenanceKey {
+		switch key {
+		case models.IsMaintenanceKey:
 			state.IsMaintenance, err = strconv.ParseBool(value)
+		case models.MaintenanceStartKey:
+			state.MaintenanceStart, err = parseTimestamp(value)
+		case models.MaintenanceEndKey:
+			state.MaintenanceEnd, err = parseTimestamp(value)
 		}
 
 		if err != nil {
@@ -66,18 +74,44 @@ func (repo StateRepository) IsDatabaseActive(ctx context.Context) bool {
 	return repo.db.Ping(ctx2) == nil
 }
 
+// Create stores the initial state of a new organisation.
+func (repo StateRepository) Create(
+	ctx context.Context,
+	organisationID string,
+) error {
+	query := `
+		INSERT INTO states (organisation_id, key, value)
+		VALUES ($1, $2, 'false'), ($1, $3, ''), ($1, $4, '')
+	`
+
+	_, err := repo.db.Exec(
+		ctx,
+		query,
+		organisationID,
+		models.IsMaintenanceKey,
+		models.MaintenanceStartKey,
+		models.MaintenanceEndKey,
+	)
+	if err != nil {
+		return postgres.PgxErrorToHTTPError(err)
+	}
+
+	return nil
+}
+
 func (repo StateRepository) UpdateKey(
 	ctx context.Context,
+	organisationID string,
 	key models.StateKey,
 	value string,
 ) error {
 	query := `
 		UPDATE states
-		SET value = $2
-		WHERE key = $1
+		SET value = $3
+		WHERE organisation_id = $1 AND key = $2
 	`
 
-	result, err := repo.db.Exec(ctx, query, key, value)
+	result, err := repo.db.Exec(ctx, query, organisationID, key, value)
 	if err != nil {
 		return postgres.PgxErrorToHTTPError(err)
 	}
@@ -89,3 +123,35 @@ func (repo StateRepository) UpdateKey(
 
 	return nil
 }
+
+func parseTimestamp(value string) (pgtype.Timestamptz, error) {
+	//nolint:exhaustruct //other fields are optional
+	timestamp := pgtype.Timestamptz{}
+	if value == "" {
+		return timestamp, nil
+	}
+
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return timestamp, err
+	}
+
+	timestamp.Time = parsed.UTC()
+	timestamp.Valid = true
+
+	return timestamp, nil
+}
+
+func (repo StateRepository) UpdateTimestampKey(
+	ctx context.Context,
+	organisationID string,
+	key models.StateKey,
+	value pgtype.Timestamptz,
+) error {
+	formatted := ""
+	if value.Valid {
+		formatted = value.Time.UTC().Format(time.RFC3339)
+	}
+
+	return repo.UpdateKey(ctx, organisationID, key, formatted)
+}
@@ -0,0 +1,116 @@
+package repositories
+
+import (
+	"context"
+
+	contexttools "github.com/XDoubleU/essentia/pkg/context"
+	"github.com/XDoubleU/essentia/pkg/database/postgres"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"check-in/api/internal/constants"
+)
+
+type TransactionRepository struct {
+	db postgres.DB
+}
+
+// Run runs fn in a transaction. Repositories called with the context
+// passed to fn use this transaction, so their changes are only stored
+// if fn succeeds. Nested calls use the outer transaction.
+func (repo TransactionRepository) Run(
+	ctx context.Context,
+	fn func(ctx context.Context) error,
+) error {
+	if contexttools.GetValue[pgx.Tx](ctx, constants.TxContextKey) != nil {
+		return fn(ctx)
+	}
+
+	tx, err := repo.db.Begin(ctx)
+	if err != nil {
+		return postgres.PgxErrorToHTTPError(err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	err = fn(context.WithValue(ctx, constants.TxContextKey, tx))
+	if err != nil {
+		return err
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return postgres.PgxErrorToHTTPError(err)
+	}
+
+	return nil
+}
+
+// txDB uses the transaction started by TransactionRepository.Run
+// if the context has one and the database otherwise.
+type txDB struct {
+	db postgres.DB
+}
+
+func (db txDB) conn(ctx context.Context) postgres.DB {
+	tx := contexttools.GetValue[pgx.Tx](ctx, constants.TxContextKey)
+	if tx == nil {
+		return db.db
+	}
+
+	return txConn{Tx: *tx, db: db.db}
+}
+
+func (db txDB) Exec(
+	ctx context.Context,
+	sql string,
+	arguments ...any,
+) (pgconn.CommandTag, error) {
+	return db.conn(ctx).Exec(ctx, sql, arguments...)
+}
+
+func (db txDB) Query(
+	ctx context.Context,
+	sql string,
+	optionsAndArgs ...any,
+) (pgx.Rows, error) {
+	return db.conn(ctx).Query(ctx, sql, optionsAndArgs...)
+}
+
+func (db txDB) QueryRow(
+	ctx context.Context,
+	sql string,
+	optionsAndArgs ...any,
+) pgx.Row {
+	return db.conn(ctx).QueryRow(ctx, sql, optionsAndArgs...)
+}
+
+func (db txDB) SendBatch(ctx context.Context, b *pgx.Batch) pgx.BatchResults {
+	return db.conn(ctx).SendBatch(ctx, b)
+}
+
+func (db txDB) Begin(ctx context.Context) (pgx.Tx, error) {
+	return db.conn(ctx).Begin(ctx)
+}
+
+func (db txDB) BeginTx(ctx context.Context, txOptions pgx.TxOptions) (pgx.Tx, error) {
+	return db.conn(ctx).BeginTx(ctx, txOptions)
+}
+
+func (db txDB) Ping(ctx context.Context) error {
+	return db.db.Ping(ctx)
+}
+
+// txConn makes a transaction usable as a database. Transactions
+// started on it are savepoints of the outer transaction.
+type txConn struct {
+	pgx.Tx
+	db postgres.DB
+}
+
+func (conn txConn) BeginTx(ctx context.Context, _ pgx.TxOptions) (pgx.Tx, error) {
+	return conn.Tx.Begin(ctx)
+}
+
+func (conn txConn) Ping(ctx context.Context) error {
+	return conn.db.Ping(ctx)
+}
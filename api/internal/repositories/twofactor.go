@@ -0,0 +1,220 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/XDoubleU/essentia/pkg/database"
+	"github.com/XDoubleU/essentia/pkg/database/postgres"
+
+	"check-in/api/internal/models"
+	"check-in/api/internal/shared"
+)
+
+type TwoFactorRepository struct {
+	db            postgres.DB
+	getTimeNowUTC shared.UTCNowTimeProvider
+}
+
+// Get returns the TOTP configuration of a user which enrolled,
+// whether or not it's enabled already.
+func (repo TwoFactorRepository) Get(
+	ctx context.Context,
+	userID string,
+) (*models.TwoFactor, error) {
+	query := `
+		SELECT totp_secret, totp_enabled_at IS NOT NULL, totp_last_step
+		FROM users
+		WHERE id = $1 AND archived_at IS NULL AND totp_secret IS NOT NULL
+	`
+
+	//nolint:exhaustruct //other fields are optional
+	twoFactor := models.TwoFactor{
+		UserID: userID,
+	}
+
+	err := repo.db.QueryRow(ctx, query, userID).Scan(
+		&twoFactor.Secret,
+		&twoFactor.Enabled,
+		&twoFactor.LastStep,
+	)
+	if err != nil {
+		return nil, postgres.PgxErrorToHTTPError(err)
+	}
+
+	return &twoFactor, nil
+}
+
+// SetSecret stores the secret of a pending enrolment,
+// it replaces the secret of a previous pending enrolment.
+func (repo TwoFactorRepository) SetSecret(
+	ctx context.Context,
+	userID string,
+	secret []byte,
+) error {
+	query := `
+		UPDATE users
+		SET totp_secret = $2, totp_last_step = NULL
+		WHERE id = $1 AND archived_at IS NULL AND totp_enabled_at IS NULL
+	`
+
+	result, err := repo.db.Exec(ctx, query, userID, secret)
+	if err != nil {
+		return postgres.PgxErrorToHTTPError(err)
+	}
+
+	rowsAffected := result.RowsAffected()
+	if rowsAffected == 0 {
+		return database.ErrResourceNotFound
+	}
+
+	return nil
+}
+
+// Enable enables a pending enrolment and replaces the recovery codes
+// of the user in a single transaction.
+func (repo TwoFactorRepository) Enable(
+	ctx context.Context,
+	userID string,
+	step int64,
+	recoveryCodeHashes [][32]byte,
+) error {
+	tx, err := repo.db.Begin(ctx)
+	if err != nil {
+		return postgres.PgxErrorToHTTPError(err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	result, err := tx.Exec(
+		ctx,
+		`
+		UPDATE users
+		SET totp_enabled_at = $2, totp_last_step = $3
+		WHERE id = $1 AND totp_secret IS NOT NULL AND totp_enabled_at IS NULL
+		`,
+		userID,
+		repo.getTimeNowUTC(),
+		step,
+	)
+	if err != nil {
+		return postgres.PgxErrorToHTTPError(err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return database.ErrResourceNotFound
+	}
+
+	_, err = tx.Exec(ctx, "DELETE FROM recovery_codes WHERE user_id = $1", userID)
+	if err != nil {
+		return postgres.PgxErrorToHTTPError(err)
+	}
+
+	for _, hash := range recoveryCodeHashes {
+		_, err = tx.Exec(
+			ctx,
+			"INSERT INTO recovery_codes (hash, user_id) VALUES ($1, $2)",
+			hash[:],
+			userID,
+		)
+		if err != nil {
+			return postgres.PgxErrorToHTTPError(err)
+		}
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return postgres.PgxErrorToHTTPError(err)
+	}
+
+	return nil
+}
+
+// Disable removes the secret and the recovery codes of a user.
+func (repo TwoFactorRepository) Disable(
+	ctx context.Context,
+	userID string,
+) error {
+	tx, err := repo.db.Begin(ctx)
+	if err != nil {
+		return postgres.PgxErrorToHTTPError(err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	result, err := tx.Exec(
+		ctx,
+		`
+		UPDATE users
+		SET totp_secret = NULL, totp_enabled_at = NULL, totp_last_step = NULL
+		WHERE id = $1 AND totp_secret IS NOT NULL
+		`,
+		userID,
+	)
+	if err != nil {
+		return postgres.PgxErrorToHTTPError(err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return database.ErrResourceNotFound
+	}
+
+	_, err = tx.Exec(ctx, "DELETE FROM recovery_codes WHERE user_id = $1", userID)
+	if err != nil {
+		return postgres.PgxErrorToHTTPError(err)
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return postgres.PgxErrorToHTTPError(err)
+	}
+
+	return nil
+}
+
+// UseStep marks the time step of a TOTP code as used,
+// it fails when the step or a later one was used already.
+func (repo TwoFactorRepository) UseStep(
+	ctx context.Context,
+	userID string,
+	step int64,
+) error {
+	query := `
+		UPDATE users
+		SET totp_last_step = $2
+		WHERE id = $1 AND (totp_last_step IS NULL OR totp_last_step < $2)
+	`
+
+	result, err := repo.db.Exec(ctx, query, userID, step)
+	if err != nil {
+		return postgres.PgxErrorToHTTPError(err)
+	}
+
+	rowsAffected := result.RowsAffected()
+	if rowsAffected == 0 {
+		return database.ErrResourceNotFound
+	}
+
+	return nil
+}
+
+// UseRecoveryCode deletes a recovery code, so it can only be used once.
+func (repo TwoFactorRepository) UseRecoveryCode(
+	ctx context.Context,
+	userID string,
+	hash [32]byte,
+) error {
+	query := `
+		DELETE FROM recovery_codes
+		WHERE hash = $1 AND user_id = $2
+	`
+
+	result, err := repo.db.Exec(ctx, query, hash[:], userID)
+	if err != nil {
+		return postgres.PgxErrorToHTTPError(err)
+	}
+
+	rowsAffected := result.RowsAffected()
+	if rowsAffected == 0 {
+		return database.ErrResourceNotFound
+	}
+
+	return nil
+}
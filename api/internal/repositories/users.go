@@ -5,6 +5,7 @@ import (
 
 	"github.com/XDoubleU/essentia/pkg/database"
 	"github.com/XDoubleU/essentia/pkg/database/postgres"
+	"github.com/jackc/pgx/v5/pgtype"
 
 	"check-in/api/internal/dtos"
 	"check-in/api/internal/models"
@@ -14,16 +15,19 @@ type UserRepository struct {
 	db postgres.DB
 }
 
-func (repo UserRepository) GetTotalCount(ctx context.Context) (*int64, error) {
+func (repo UserRepository) GetTotalCount(
+	ctx context.Context,
+	organisationID string,
+) (*int64, error) {
 	query := `
 		SELECT COUNT(*)
 		FROM users
-		WHERE role = 'manager'
+		WHERE role = 'manager' AND organisation_id = $1 AND archived_at IS NULL
 	`
 
 	var total *int64
 
-	err := repo.db.QueryRow(ctx, query).Scan(&total)
+	err := repo.db.QueryRow(ctx, query, organisationID).Scan(&total)
 	if err != nil {
 		return nil, postgres.PgxErrorToHTTPError(err)
 	}
@@ -33,14 +37,16 @@ func (repo UserRepository) GetTotalCount(ctx context.Context) (*int64, error) {
 
 func (repo UserRepository) GetAll(
 	ctx context.Context,
+	organisationID string,
 ) ([]*models.User, error) {
 	query := `
-		SELECT id, username
+		SELECT id, username, custom_role_id::text,
+		 totp_enabled_at IS NOT NULL, two_factor_required
 		FROM users
-		WHERE role = 'manager'
+		WHERE role = 'manager' AND organisation_id = $1 AND archived_at IS NULL
 	`
 
-	rows, err := repo.db.Query(ctx, query)
+	rows, err := repo.db.Query(ctx, query, organisationID)
 	if err != nil {
 		return nil, postgres.PgxErrorToHTTPError(err)
 	}
@@ -50,12 +56,16 @@ func (repo UserRepository) GetAll(
 	for rows.Next() {
 		//nolint:exhaustruct //other fields are optional
 		user := models.User{
-			Role: models.ManagerRole,
+			Role:           models.ManagerRole,
+			OrganisationID: organisationID,
 		}
 
 		err = rows.Scan(
 			&user.ID,
 			&user.Username,
+			&user.CustomRoleID,
+			&user.TwoFactorEnabled,
+			&user.TwoFactorRequired,
 		)
 
 		if err != nil {
@@ -74,18 +84,20 @@ func (repo UserRepository) GetAll(
 
 func (repo UserRepository) GetAllPaginated(
 	ctx context.Context,
+	organisationID string,
 	limit int64,
 	offset int64,
 ) ([]*models.User, error) {
 	query := `
-		SELECT id, username
+		SELECT id, username, custom_role_id::text,
+		 totp_enabled_at IS NOT NULL, two_factor_required
 		FROM users
-		WHERE role = 'manager'
+		WHERE role = 'manager' AND organisation_id = $1 AND archived_at IS NULL
 		ORDER BY username ASC
-		LIMIT $1 OFFSET $2
+		LIMIT $2 OFFSET $3
 	`
 
-	rows, err := repo.db.Query(ctx, query, limit, offset)
+	rows, err := repo.db.Query(ctx, query, organisationID, limit, offset)
 	if err != nil {
 		return nil, postgres.PgxErrorToHTTPError(err)
 	}
@@ -95,12 +107,16 @@ func (repo UserRepository) GetAllPaginated(
 	for rows.Next() {
 		//nolint:exhaustruct //other fields are optional
 		user := models.User{
-			Role: models.ManagerRole,
+			Role:           models.ManagerRole,
+			OrganisationID: organisationID,
 		}
 
 		err = rows.Scan(
 			&user.ID,
 			&user.Username,
+			&user.CustomRoleID,
+			&user.TwoFactorEnabled,
+			&user.TwoFactorRequired,
 		)
 
 		if err != nil {
@@ -119,26 +135,37 @@ func (repo UserRepository) GetAllPaginated(
 
 func (repo UserRepository) GetByID(
 	ctx context.Context,
+	organisationID string,
 	id string,
 	role models.Role,
 ) (*models.User, error) {
 	query := `
-		SELECT users.username, users.password_hash
+		SELECT users.username, users.password_hash, users.custom_role_id::text,
+		 users.totp_enabled_at IS NOT NULL, users.two_factor_required
 		FROM users
-		WHERE users.id = $1 AND users.role = $2
+		WHERE users.id = $1 AND users.role = $2 AND users.organisation_id = $3
+		AND users.archived_at IS NULL
 	`
 
 	//nolint:exhaustruct //other fields are optional
 	user := models.User{
-		ID:   id,
-		Role: role,
+		ID:             id,
+		Role:           role,
+		OrganisationID: organisationID,
 	}
 	err := repo.db.QueryRow(
 		ctx,
 		query,
 		id,
 		role,
-	).Scan(&user.Username, &user.PasswordHash)
+		organisationID,
+	).Scan(
+		&user.Username,
+		&user.PasswordHash,
+		&user.CustomRoleID,
+		&user.TwoFactorEnabled,
+		&user.TwoFactorRequired,
+	)
 
 	if err != nil {
 		return nil, postgres.PgxErrorToHTTPError(err)
@@ -147,14 +174,17 @@ func (repo UserRepository) GetByID(
 	return &user, nil
 }
 
+// GetByUsername isn't scoped by organisation as usernames are unique
+// across organisations, signing in doesn't require an organisation.
 func (repo UserRepository) GetByUsername(
 	ctx context.Context,
 	username string,
 ) (*models.User, error) {
 	query := `
-		SELECT id, password_hash, role
+		SELECT id, password_hash, role, organisation_id,
+		 totp_enabled_at IS NOT NULL, two_factor_required
 		FROM users
-		WHERE username = $1
+		WHERE username = $1 AND archived_at IS NULL
 	`
 
 	//nolint:exhaustruct //other fields are optional
@@ -165,30 +195,137 @@ func (repo UserRepository) GetByUsername(
 		ctx,
 		query,
 		username,
-	).Scan(&user.ID, &user.PasswordHash, &user.Role)
+	).Scan(
+		&user.ID,
+		&user.PasswordHash,
+		&user.Role,
+		&user.OrganisationID,
+		&user.TwoFactorEnabled,
+		&user.TwoFactorRequired,
+	)
+
+	if err != nil {
+		return nil, postgres.PgxErrorToHTTPError(err)
+	}
+
+	return &user, nil
+}
+
+// GetByOIDCSubject returns the user which was provisioned
+// for the subject of an OpenID Connect issuer.
+func (repo UserRepository) GetByOIDCSubject(
+	ctx context.Context,
+	subject string,
+) (*models.User, error) {
+	query := `
+		SELECT id, username, role, organisation_id, custom_role_id::text
+		FROM users
+		WHERE oidc_subject = $1 AND archived_at IS NULL
+	`
+
+	//nolint:exhaustruct //other fields are optional
+	user := models.User{}
+
+	err := repo.db.QueryRow(ctx, query, subject).Scan(
+		&user.ID,
+		&user.Username,
+		&user.Role,
+		&user.OrganisationID,
+		&user.CustomRoleID,
+	)
+	if err != nil {
+		return nil, postgres.PgxErrorToHTTPError(err)
+	}
+
+	return &user, nil
+}
+
+// CreateForOIDCSubject creates a user without a password,
+// it can only sign in with the OpenID Connect issuer.
+func (repo UserRepository) CreateForOIDCSubject(
+	ctx context.Context,
+	organisationID string,
+	username string,
+	role models.Role,
+	subject string,
+) (*models.User, error) {
+	query := `
+		INSERT INTO users (username, role, organisation_id, oidc_subject)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id
+	`
+
+	//nolint:exhaustruct //other fields are optional
+	user := models.User{
+		Username:       username,
+		Role:           role,
+		OrganisationID: organisationID,
+	}
+
+	err := repo.db.QueryRow(
+		ctx,
+		query,
+		username,
+		role,
+		organisationID,
+		subject,
+	).Scan(&user.ID)
+	if err != nil {
+		return nil, postgres.PgxErrorToHTTPError(err)
+	}
+
+	return &user, nil
+}
+
+// SetRole changes the built-in role of a user,
+// the custom role is removed when the user is no longer a manager.
+func (repo UserRepository) SetRole(
+	ctx context.Context,
+	user models.User,
+	role models.Role,
+) (*models.User, error) {
+	query := `
+		UPDATE users
+		SET role = $2,
+		 custom_role_id = CASE WHEN $2 = 'manager' THEN custom_role_id END
+		WHERE id = $1 AND organisation_id = $3 AND archived_at IS NULL
+	`
 
+	result, err := repo.db.Exec(ctx, query, user.ID, role, user.OrganisationID)
 	if err != nil {
 		return nil, postgres.PgxErrorToHTTPError(err)
 	}
 
+	rowsAffected := result.RowsAffected()
+	if rowsAffected == 0 {
+		return nil, database.ErrResourceNotFound
+	}
+
+	if role != models.ManagerRole {
+		user.CustomRoleID = pgtype.Text{}
+	}
+	user.Role = role
+
 	return &user, nil
 }
 
 func (repo UserRepository) Create(
 	ctx context.Context,
+	organisationID string,
 	username string,
 	passwordHash []byte,
 	role models.Role,
 ) (*models.User, error) {
 	query := `
-		INSERT INTO users (username, password_hash, role)
-		VALUES ($1, $2, $3)
+		INSERT INTO users (username, password_hash, role, organisation_id)
+		VALUES ($1, $2, $3, $4)
 		RETURNING id
 	`
 	//nolint:exhaustruct //other fields are optional
 	user := models.User{
-		Username: username,
-		Role:     role,
+		Username:       username,
+		Role:           role,
+		OrganisationID: organisationID,
 	}
 
 	err := repo.db.QueryRow(
@@ -197,6 +334,7 @@ func (repo UserRepository) Create(
 		username,
 		passwordHash,
 		role,
+		organisationID,
 	).Scan(&user.ID)
 
 	if err != nil {
@@ -221,10 +359,14 @@ func (repo UserRepository) Update(
 		user.PasswordHash = passwordHash
 	}
 
+	if updateUserDto.TwoFactorRequired != nil {
+		user.TwoFactorRequired = *updateUserDto.TwoFactorRequired
+	}
+
 	query := `
 		UPDATE users
-		SET username = $3, password_hash = $4
-		WHERE id = $1 AND role = $2
+		SET username = $3, password_hash = $4, two_factor_required = $6
+		WHERE id = $1 AND role = $2 AND organisation_id = $5 AND archived_at IS NULL
 	`
 
 	result, err := repo.db.Exec(
@@ -234,6 +376,8 @@ func (repo UserRepository) Update(
 		role,
 		user.Username,
 		user.PasswordHash,
+		user.OrganisationID,
+		user.TwoFactorRequired,
 	)
 
 	if err != nil {
@@ -248,17 +392,20 @@ func (repo UserRepository) Update(
 	return &user, nil
 }
 
-func (repo UserRepository) Delete(
+// Archive hides a user, archived users can't sign in.
+func (repo UserRepository) Archive(
 	ctx context.Context,
+	organisationID string,
 	id string,
 	role models.Role,
 ) error {
 	query := `
-		DELETE FROM users
-		WHERE id = $1 AND role = $2
+		UPDATE users
+		SET archived_at = now()
+		WHERE id = $1 AND role = $2 AND organisation_id = $3 AND archived_at IS NULL
 	`
 
-	result, err := repo.db.Exec(ctx, query, id, role)
+	result, err := repo.db.Exec(ctx, query, id, role, organisationID)
 	if err != nil {
 		return postgres.PgxErrorToHTTPError(err)
 	}
@@ -270,3 +417,70 @@ func (repo UserRepository) Delete(
 
 	return nil
 }
+
+// SetCustomRole assigns a custom role to a manager,
+// an invalid role ID removes the custom role.
+func (repo UserRepository) SetCustomRole(
+	ctx context.Context,
+	user models.User,
+	customRoleID pgtype.Text,
+) (*models.User, error) {
+	query := `
+		UPDATE users
+		SET custom_role_id = $3
+		WHERE id = $1 AND role = $2 AND organisation_id = $4 AND archived_at IS NULL
+	`
+
+	result, err := repo.db.Exec(
+		ctx,
+		query,
+		user.ID,
+		models.ManagerRole,
+		customRoleID,
+		user.OrganisationID,
+	)
+	if err != nil {
+		return nil, postgres.PgxErrorToHTTPError(err)
+	}
+
+	rowsAffected := result.RowsAffected()
+	if rowsAffected == 0 {
+		return nil, database.ErrResourceNotFound
+	}
+
+	user.CustomRoleID = customRoleID
+
+	return &user, nil
+}
+
+// Restore makes an archived user visible again.
+func (repo UserRepository) Restore(
+	ctx context.Context,
+	organisationID string,
+	id string,
+	role models.Role,
+) (*models.User, error) {
+	query := `
+		UPDATE users
+		SET archived_at = NULL
+		WHERE id = $1 AND role = $2 AND organisation_id = $3
+		AND archived_at IS NOT NULL
+		RETURNING username
+	`
+
+	//nolint:exhaustruct //other fields are optional
+	user := models.User{
+		ID:             id,
+		Role:           role,
+		OrganisationID: organisationID,
+	}
+
+	err := repo.db.QueryRow(ctx, query, id, role, organisationID).Scan(
+		&user.Username,
+	)
+	if err != nil {
+		return nil, postgres.PgxErrorToHTTPError(err)
+	}
+
+	return &user, nil
+}
@@ -0,0 +1,261 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/XDoubleU/essentia/pkg/database/postgres"
+	"github.com/jackc/pgx/v5"
+
+	"check-in/api/internal/models"
+	"check-in/api/internal/shared"
+)
+
+type WaitlistRepository struct {
+	db            postgres.DB
+	getTimeNowUTC shared.UTCNowTimeProvider
+}
+
+// GetAllWaiting returns the entries of a location which are still waiting
+// within the provided range, the oldest entry first.
+func (repo WaitlistRepository) GetAllWaiting(
+	ctx context.Context,
+	locationID string,
+	startDate time.Time,
+	endDate time.Time,
+) ([]*models.WaitlistEntry, error) {
+	query := `
+		SELECT id, school_id, short_code, (created_at AT TIME ZONE 'utc')
+		FROM waitlist_entries
+		WHERE location_id = $1
+		AND promoted_at IS NULL
+		AND created_at >= $2
+		AND created_at <= $3
+		ORDER BY created_at, id
+	`
+
+	rows, err := repo.db.Query(ctx, query, locationID, startDate, endDate)
+	if err != nil {
+		return nil, postgres.PgxErrorToHTTPError(err)
+	}
+
+	entries := []*models.WaitlistEntry{}
+	for rows.Next() {
+		//nolint:exhaustruct //other fields are optional
+		entry := models.WaitlistEntry{
+			LocationID: locationID,
+		}
+
+		err = rows.Scan(
+			&entry.ID,
+			&entry.SchoolID,
+			&entry.ShortCode,
+			&entry.CreatedAt,
+		)
+		if err != nil {
+			return nil, postgres.PgxErrorToHTTPError(err)
+		}
+
+		entries = append(entries, &entry)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, postgres.PgxErrorToHTTPError(err)
+	}
+
+	return entries, nil
+}
+
+func (repo WaitlistRepository) Create(
+	ctx context.Context,
+	locationID string,
+	schoolID int64,
+	shortCode *string,
+) (*models.WaitlistEntry, error) {
+	query := `
+		INSERT INTO waitlist_entries (location_id, school_id, short_code, created_at)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, short_code, (created_at AT TIME ZONE 'utc')
+	`
+
+	//nolint:exhaustruct //other fields are optional
+	entry := models.WaitlistEntry{
+		LocationID: locationID,
+		SchoolID:   schoolID,
+	}
+
+	err := repo.db.QueryRow(
+		ctx,
+		query,
+		locationID,
+		schoolID,
+		shortCode,
+		repo.getTimeNowUTC(),
+	).Scan(&entry.ID, &entry.ShortCode, &entry.CreatedAt)
+	if err != nil {
+		return nil, postgres.PgxErrorToHTTPError(err)
+	}
+
+	return &entry, nil
+}
+
+// Delete removes an entry which is still waiting.
+func (repo WaitlistRepository) Delete(
+	ctx context.Context,
+	locationID string,
+	id int64,
+) (*models.WaitlistEntry, error) {
+	query := `
+		DELETE FROM waitlist_entries
+		WHERE id = $1 AND location_id = $2 AND promoted_at IS NULL
+		RETURNING school_id, short_code, (created_at AT TIME ZONE 'utc')
+	`
+
+	//nolint:exhaustruct //other fields are optional
+	entry := models.WaitlistEntry{
+		ID:         id,
+		LocationID: locationID,
+	}
+
+	err := repo.db.QueryRow(ctx, query, id, locationID).Scan(
+		&entry.SchoolID,
+		&entry.ShortCode,
+		&entry.CreatedAt,
+	)
+	if err != nil {
+		return nil, postgres.PgxErrorToHTTPError(err)
+	}
+
+	return &entry, nil
+}
+
+// Promote checks in the oldest waiting entries of the day returned
+// by dayBounds for as long as the location has available spots.
+// The location row is locked, so promotions can't exceed the capacity.
+func (repo WaitlistRepository) Promote(
+	ctx context.Context,
+	locationID string,
+	dayBounds shared.DayBoundsProvider,
+) ([]*models.WaitlistEntry, error) {
+	tx, err := repo.db.Begin(ctx)
+	if err != nil {
+		return nil, postgres.PgxErrorToHTTPError(err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	baseCapacity, err := lockLocation(ctx, tx, locationID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := repo.getTimeNowUTC()
+
+	capacity, err := capacityAt(ctx, tx, locationID, baseCapacity, now)
+	if err != nil {
+		return nil, err
+	}
+
+	promoted := []*models.WaitlistEntry{}
+	for {
+		var available bool
+		available, err = hasAvailableSpots(ctx, tx, locationID, capacity, now, dayBounds)
+		if err != nil {
+			return nil, err
+		}
+
+		if !available {
+			break
+		}
+
+		var entry *models.WaitlistEntry
+		entry, err = promoteOldest(ctx, tx, locationID, capacity, now, dayBounds)
+		if errors.Is(err, pgx.ErrNoRows) {
+			break
+		}
+
+		if err != nil {
+			return nil, postgres.PgxErrorToHTTPError(err)
+		}
+
+		err = addToRollup(ctx, tx, entry.CheckInID.Int64)
+		if err != nil {
+			return nil, err
+		}
+
+		promoted = append(promoted, entry)
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return nil, postgres.PgxErrorToHTTPError(err)
+	}
+
+	return promoted, nil
+}
+
+func promoteOldest(
+	ctx context.Context,
+	tx pgx.Tx,
+	locationID string,
+	capacity int64,
+	at time.Time,
+	dayBounds shared.DayBoundsProvider,
+) (*models.WaitlistEntry, error) {
+	query := `
+		WITH entry AS (
+			SELECT id, school_id
+			FROM waitlist_entries
+			WHERE location_id = $1
+			AND promoted_at IS NULL
+			AND created_at >= $2
+			AND created_at <= $3
+			ORDER BY created_at, id
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED
+		), check_in AS (
+			INSERT INTO check_ins (location_id, school_id, capacity, created_at)
+			SELECT $1, entry.school_id, $4, $5
+			FROM entry
+			RETURNING id
+		)
+		UPDATE waitlist_entries
+		SET promoted_at = $5, check_in_id = (SELECT id FROM check_in)
+		FROM entry
+		WHERE waitlist_entries.id = entry.id
+		RETURNING waitlist_entries.id, waitlist_entries.school_id,
+		 waitlist_entries.short_code,
+		 (waitlist_entries.created_at AT TIME ZONE 'utc'),
+		 (waitlist_entries.promoted_at AT TIME ZONE 'utc'),
+		 waitlist_entries.check_in_id
+	`
+
+	startOfDay, endOfDay := dayBounds(at)
+
+	//nolint:exhaustruct //other fields are optional
+	entry := models.WaitlistEntry{
+		LocationID: locationID,
+	}
+
+	err := tx.QueryRow(
+		ctx,
+		query,
+		locationID,
+		startOfDay,
+		endOfDay,
+		capacity,
+		at,
+	).Scan(
+		&entry.ID,
+		&entry.SchoolID,
+		&entry.ShortCode,
+		&entry.CreatedAt,
+		&entry.PromotedAt,
+		&entry.CheckInID,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &entry, nil
+}
@@ -0,0 +1,157 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+
+	"github.com/XDoubleU/essentia/pkg/database"
+	errortools "github.com/XDoubleU/essentia/pkg/errors"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"check-in/api/internal/dtos"
+	"check-in/api/internal/models"
+	"check-in/api/internal/repositories"
+	"check-in/api/internal/shared"
+)
+
+// apiKeyPrefix makes API keys recognizable, e.g. by secret scanners.
+const apiKeyPrefix = "ck_"
+
+type APIKeyService struct {
+	apiKeys       repositories.APIKeyRepository
+	audit         AuditService
+	getTimeNowUTC shared.UTCNowTimeProvider
+}
+
+func (service APIKeyService) GetAll(
+	ctx context.Context,
+	user *models.User,
+) ([]*models.APIKey, error) {
+	return service.apiKeys.GetAll(ctx, user.OrganisationID)
+}
+
+// GetUserByKey returns the user acting on behalf of a valid API key.
+func (service APIKeyService) GetUserByKey(
+	ctx context.Context,
+	key string,
+) (*models.User, error) {
+	apiKey, err := service.apiKeys.GetByHash(ctx, hashAPIKey(key))
+	if err != nil {
+		return nil, err
+	}
+
+	return apiKey.User(), nil
+}
+
+func (service APIKeyService) Create(
+	ctx context.Context,
+	user *models.User,
+	createAPIKeyDto dtos.CreateAPIKeyDto,
+) (*models.APIKey, error) {
+	err := checkGrantable(user, createAPIKeyDto.Scopes)
+	if err != nil {
+		return nil, err
+	}
+
+	//nolint:exhaustruct //other fields are optional
+	apiKey := &models.APIKey{
+		Name:           createAPIKeyDto.Name,
+		Scopes:         createAPIKeyDto.Scopes,
+		OrganisationID: user.OrganisationID,
+	}
+
+	if createAPIKeyDto.Expiry != nil {
+		if !createAPIKeyDto.Expiry.After(service.getTimeNowUTC()) {
+			return nil, errortools.NewBadRequestError(
+				errors.New("expiry of API key must be in the future"),
+			)
+		}
+
+		//nolint:exhaustruct //other fields are optional
+		apiKey.Expiry = pgtype.Timestamptz{
+			Time:  createAPIKeyDto.Expiry.UTC(),
+			Valid: true,
+		}
+	}
+
+	key, err := generateAPIKey()
+	if err != nil {
+		return nil, err
+	}
+
+	err = service.audit.InTransaction(ctx, func(ctx context.Context) error {
+		err = service.apiKeys.Create(ctx, apiKey, hashAPIKey(key))
+		if err != nil {
+			return err
+		}
+
+		return service.audit.Record(
+			ctx,
+			models.AuditCreate,
+			"apiKey",
+			apiKey.ID,
+			nil,
+			apiKey,
+		)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	apiKey.Key = key
+
+	return apiKey, nil
+}
+
+func (service APIKeyService) Delete(
+	ctx context.Context,
+	user *models.User,
+	id string,
+) (*models.APIKey, error) {
+	apiKey, err := service.apiKeys.GetByID(ctx, user.OrganisationID, id)
+	if err != nil {
+		if errors.Is(err, database.ErrResourceNotFound) {
+			return nil, errortools.NewNotFoundError("apiKey", id, "id")
+		}
+		return nil, err
+	}
+
+	err = service.audit.InTransaction(ctx, func(ctx context.Context) error {
+		err = service.apiKeys.Delete(ctx, user.OrganisationID, apiKey.ID)
+		if err != nil {
+			return err
+		}
+
+		return service.audit.Record(
+			ctx,
+			models.AuditDelete,
+			"apiKey",
+			apiKey.ID,
+			apiKey,
+			nil,
+		)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return apiKey, nil
+}
+
+func generateAPIKey() (string, error) {
+	randomBytes := make([]byte, 32) //nolint:mnd //no magic number
+
+	_, err := rand.Read(randomBytes)
+	if err != nil {
+		return "", err
+	}
+
+	return apiKeyPrefix + base64.RawURLEncoding.EncodeToString(randomBytes), nil
+}
+
+func hashAPIKey(key string) [32]byte {
+	return sha256.Sum256([]byte(key))
+}
@@ -0,0 +1,184 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+
+	contexttools "github.com/XDoubleU/essentia/pkg/context"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"check-in/api/internal/constants"
+	"check-in/api/internal/models"
+	"check-in/api/internal/repositories"
+)
+
+type AuditService struct {
+	audit        repositories.AuditRepository
+	transactions repositories.TransactionRepository
+}
+
+// InTransaction runs fn in a transaction, so the events recorded in fn
+// are only stored together with the mutations made in fn.
+func (service AuditService) InTransaction(
+	ctx context.Context,
+	fn func(ctx context.Context) error,
+) error {
+	return service.transactions.Run(ctx, fn)
+}
+
+func (service AuditService) GetTotalCount(
+	ctx context.Context,
+	user *models.User,
+	filter models.AuditEventFilter,
+) (*int64, error) {
+	return service.audit.GetTotalCount(ctx, user.OrganisationID, filter)
+}
+
+func (service AuditService) GetAllPaginated(
+	ctx context.Context,
+	user *models.User,
+	filter models.AuditEventFilter,
+	limit int64,
+	offset int64,
+) ([]*models.AuditEvent, error) {
+	return service.audit.GetAllPaginated(
+		ctx,
+		user.OrganisationID,
+		filter,
+		limit,
+		offset,
+	)
+}
+
+// Record stores a mutation of an entity. The actor is the user of the
+// request, if any. Before should be nil on creation, after on deletion.
+func (service AuditService) Record(
+	ctx context.Context,
+	action models.AuditAction,
+	entityType string,
+	entityID string,
+	before any,
+	after any,
+) error {
+	event, err := newAuditEvent(action, entityType, entityID, before, after)
+	if err != nil {
+		return err
+	}
+
+	actor := contexttools.GetValue[models.User](ctx, constants.UserContextKey)
+	if actor != nil {
+		event.ActorID.String, event.ActorID.Valid = actor.ID, true
+		event.ActorRole.String, event.ActorRole.Valid = string(actor.Role), true
+		event.OrganisationID.String, event.OrganisationID.Valid = actor.OrganisationID, true
+	}
+
+	return service.audit.Create(ctx, event)
+}
+
+// RecordForOrganisation stores an event which didn't happen on behalf
+// of a signed in user, e.g. while signing in, so it's still visible
+// to the organisation it concerns.
+func (service AuditService) RecordForOrganisation(
+	ctx context.Context,
+	organisationID pgtype.Text,
+	action models.AuditAction,
+	entityType string,
+	entityID string,
+	before any,
+	after any,
+) error {
+	event, err := newAuditEvent(action, entityType, entityID, before, after)
+	if err != nil {
+		return err
+	}
+
+	event.OrganisationID = organisationID
+
+	return service.audit.Create(ctx, event)
+}
+
+func newAuditEvent(
+	action models.AuditAction,
+	entityType string,
+	entityID string,
+	before any,
+	after any,
+) (*models.AuditEvent, error) {
+	beforeJSON, afterJSON, err := auditDiff(before, after)
+	if err != nil {
+		return nil, err
+	}
+
+	//nolint:exhaustruct //other fields are optional
+	return &models.AuditEvent{
+		Action:     action,
+		EntityType: entityType,
+		EntityID:   entityID,
+		Before:     beforeJSON,
+		After:      afterJSON,
+	}, nil
+}
+
+// auditDiff marshals before and after, only keeping the top-level
+// fields which differ when both are provided.
+func auditDiff(before any, after any) (json.RawMessage, json.RawMessage, error) {
+	beforeFields, err := toAuditFields(before)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	afterFields, err := toAuditFields(after)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if beforeFields != nil && afterFields != nil {
+		for key, value := range beforeFields {
+			if reflect.DeepEqual(value, afterFields[key]) {
+				delete(beforeFields, key)
+				delete(afterFields, key)
+			}
+		}
+	}
+
+	beforeJSON, err := marshalAuditFields(beforeFields)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	afterJSON, err := marshalAuditFields(afterFields)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return beforeJSON, afterJSON, nil
+}
+
+func toAuditFields(value any) (map[string]any, error) {
+	if value == nil || reflect.ValueOf(value).IsZero() {
+		return nil, nil //nolint:nilnil //no fields
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := make(map[string]any)
+
+	err = json.Unmarshal(data, &fields)
+	if err != nil {
+		return nil, err
+	}
+
+	return fields, nil
+}
+
+func marshalAuditFields(fields map[string]any) (json.RawMessage, error) {
+	if fields == nil {
+		return nil, nil
+	}
+
+	return json.Marshal(fields)
+}
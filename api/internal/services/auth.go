@@ -11,6 +11,7 @@ import (
 
 	"github.com/XDoubleU/essentia/pkg/database"
 	errortools "github.com/XDoubleU/essentia/pkg/errors"
+	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/xhit/go-str2duration/v2"
 
 	"check-in/api/internal/dtos"
@@ -21,6 +22,9 @@ import (
 
 type AuthService struct {
 	auth          repositories.AuthRepository
+	devices       DeviceService
+	lockouts      LockoutService
+	twoFactor     TwoFactorService
 	users         UserService
 	locations     LocationService
 	getTimeNowUTC shared.UTCNowTimeProvider
@@ -29,33 +33,184 @@ type AuthService struct {
 func (service AuthService) SignInUser(
 	ctx context.Context,
 	signInDto dtos.SignInDto,
+	ip string,
 ) (*models.User, error) {
+	keys := signInLockoutKeys(signInDto.Username, ip)
+
+	err := service.lockouts.Check(ctx, keys...)
+	if err != nil {
+		return nil, err
+	}
+
 	user, err := service.users.GetByUsername(ctx, signInDto.Username)
 	if err != nil {
 		if errors.Is(err, database.ErrResourceNotFound) {
-			return nil, errortools.NewUnauthorizedError(
-				errors.New("invalid credentials"),
-			)
+			return nil, service.failSignIn(ctx, pgtype.Text{}, keys)
 		}
 		return nil, err
 	}
 
+	organisationID := pgtype.Text{String: user.OrganisationID, Valid: true}
+
 	match, _ := user.CompareHashAndPassword(signInDto.Password)
 	if !match {
-		return nil, errortools.NewUnauthorizedError(errors.New("invalid credentials"))
+		return nil, service.failSignIn(ctx, organisationID, keys)
+	}
+
+	if user.TwoFactorEnabled {
+		if signInDto.Code == "" {
+			return nil, ErrTwoFactorPending
+		}
+
+		var valid bool
+		valid, err = service.twoFactor.Verify(ctx, user, signInDto.Code)
+		if err != nil {
+			return nil, err
+		}
+
+		if !valid {
+			return nil, service.failSignIn(ctx, organisationID, keys)
+		}
+	}
+
+	err = service.lockouts.Reset(ctx, keys...)
+	if err != nil {
+		return nil, err
 	}
 
 	if user.Role != models.DefaultRole {
 		return user, nil
 	}
 
-	userWithLocation, err := service.locations.GetDefaultUserByUserID(ctx, user.ID)
+	userWithLocation, err := service.locations.GetDefaultUserByUserID(
+		ctx,
+		user.OrganisationID,
+		user.ID,
+	)
 	if err != nil {
 		return nil, err
 	}
 	return userWithLocation, nil
 }
 
+// SignInDevice signs in a kiosk with the credentials of its device,
+// the returned user is the user of the location of the device.
+func (service AuthService) SignInDevice(
+	ctx context.Context,
+	signInDto dtos.SignInDto,
+	ip string,
+) (*models.User, error) {
+	keys := signInLockoutKeys(signInDto.Username, ip)
+
+	err := service.lockouts.Check(ctx, keys...)
+	if err != nil {
+		return nil, err
+	}
+
+	device, err := service.devices.GetByUsername(ctx, signInDto.Username)
+	if err != nil {
+		if errors.Is(err, database.ErrResourceNotFound) {
+			return nil, service.failSignIn(ctx, pgtype.Text{}, keys)
+		}
+		return nil, err
+	}
+
+	match, _ := device.CompareHashAndPassword(signInDto.Password)
+	if !match {
+		return nil, service.failSignIn(
+			ctx,
+			pgtype.Text{String: device.OrganisationID, Valid: true},
+			keys,
+		)
+	}
+
+	err = service.lockouts.Reset(ctx, keys...)
+	if err != nil {
+		return nil, err
+	}
+
+	return service.signInAsDevice(ctx, device)
+}
+
+// SignInWithPairingCode pairs a kiosk as a new device of the location
+// of the pairing code, the returned user is the user of that location.
+func (service AuthService) SignInWithPairingCode(
+	ctx context.Context,
+	pairDto dtos.PairDto,
+	ip string,
+) (*models.User, error) {
+	key := models.NewLockoutKey(models.IPLockout, ip)
+
+	err := service.lockouts.Check(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+
+	device, err := service.devices.Pair(ctx, pairDto.Code)
+	if err != nil {
+		var unauthorizedError errortools.UnauthorizedError
+		if !errors.As(err, &unauthorizedError) {
+			return nil, err
+		}
+
+		failErr := service.lockouts.RegisterFailure(ctx, pgtype.Text{}, key)
+		if failErr != nil {
+			return nil, failErr
+		}
+
+		return nil, err
+	}
+
+	err = service.lockouts.Reset(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+
+	return service.signInAsDevice(ctx, device)
+}
+
+// failSignIn registers a failed sign in attempt for the keys
+// and returns the error for invalid credentials.
+func (service AuthService) failSignIn(
+	ctx context.Context,
+	organisationID pgtype.Text,
+	keys []models.LockoutKey,
+) error {
+	err := service.lockouts.RegisterFailure(ctx, organisationID, keys...)
+	if err != nil {
+		return err
+	}
+
+	return errortools.NewUnauthorizedError(errors.New("invalid credentials"))
+}
+
+// signInLockoutKeys are the username and the IP of the request,
+// both are reset on a successful sign in.
+func signInLockoutKeys(username string, ip string) []models.LockoutKey {
+	return []models.LockoutKey{
+		models.NewLockoutKey(models.UsernameLockout, username),
+		models.NewLockoutKey(models.IPLockout, ip),
+	}
+}
+
+func (service AuthService) signInAsDevice(
+	ctx context.Context,
+	device *models.Device,
+) (*models.User, error) {
+	user, err := service.locations.GetDefaultUserByUserID(
+		ctx,
+		device.OrganisationID,
+		device.UserID,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	user.DeviceID = pgtype.Text{String: device.ID, Valid: true}
+
+	return user, nil
+}
+
 func (service AuthService) GetCookieName(scope models.Scope) string {
 	switch scope {
 	case models.AccessScope:
@@ -70,7 +225,7 @@ func (service AuthService) GetCookieName(scope models.Scope) string {
 func (service AuthService) CreateCookie(
 	ctx context.Context,
 	scope models.Scope,
-	userID string,
+	user *models.User,
 	expiry string,
 	secure bool,
 ) (*http.Cookie, error) {
@@ -79,7 +234,7 @@ func (service AuthService) CreateCookie(
 		return nil, err
 	}
 
-	token, err := service.generateToken(userID, ttl, scope)
+	token, err := service.generateToken(user, ttl, scope)
 	if err != nil {
 		return nil, err
 	}
@@ -117,16 +272,19 @@ func (service AuthService) DeleteCookie(
 		return nil, err
 	}
 
-	name := service.GetCookieName(scope)
+	return service.ExpiredCookie(scope), nil
+}
 
+// ExpiredCookie removes the cookie of a scope from the browser.
+func (service AuthService) ExpiredCookie(scope models.Scope) *http.Cookie {
 	return &http.Cookie{
-		Name:     name,
+		Name:     service.GetCookieName(scope),
 		Value:    "",
 		MaxAge:   -1,
 		SameSite: http.SameSiteStrictMode,
 		HttpOnly: true,
 		Path:     "/",
-	}, nil
+	}
 }
 
 func (service AuthService) DeleteExpiredTokens(ctx context.Context) error {
@@ -138,7 +296,7 @@ func (service AuthService) GetToken(
 	scope models.Scope,
 	tokenValue string,
 ) (*models.Token, *models.User, error) {
-	token, userID, userRole, err := service.auth.GetToken(
+	token, tokenUser, err := service.auth.GetToken(
 		ctx,
 		scope,
 		service.hashTokenValue(tokenValue),
@@ -147,11 +305,20 @@ func (service AuthService) GetToken(
 		return nil, nil, err
 	}
 
-	user, err := service.users.GetByID(ctx, *userID, *userRole)
+	user, err := service.users.GetByID(
+		ctx,
+		tokenUser.OrganisationID,
+		tokenUser.ID,
+		tokenUser.Role,
+	)
 	if err != nil {
 		return nil, nil, err
 	}
 
+	user.Permissions = tokenUser.Permissions
+	user.DeviceID = token.DeviceID
+	user.SessionID = token.SessionID
+
 	return token, user, nil
 }
 
@@ -162,6 +329,13 @@ func (service AuthService) DeleteAllTokensForUser(
 	return service.auth.DeleteAllTokensForUser(ctx, userID)
 }
 
+func (service AuthService) DeleteAllTokensForDevice(
+	ctx context.Context,
+	deviceID string,
+) error {
+	return service.auth.DeleteAllTokensForDevice(ctx, deviceID)
+}
+
 func (service AuthService) SetTokenAsUsed(
 	ctx context.Context,
 	tokenValue string,
@@ -170,15 +344,17 @@ func (service AuthService) SetTokenAsUsed(
 }
 
 func (service AuthService) generateToken(
-	userID string,
+	user *models.User,
 	ttl time.Duration,
 	scope models.Scope,
 ) (*models.Token, error) {
 	//nolint:exhaustruct //other fields are optional
 	token := &models.Token{
-		UserID: userID,
-		Expiry: service.getTimeNowUTC().Add(ttl),
-		Scope:  scope,
+		UserID:    user.ID,
+		Expiry:    service.getTimeNowUTC().Add(ttl),
+		Scope:     scope,
+		DeviceID:  user.DeviceID,
+		SessionID: user.SessionID,
 	}
 
 	randomBytes := make([]byte, 16) //nolint:mnd //no magic number
@@ -3,19 +3,35 @@ package services
 import (
 	"context"
 	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"time"
 
 	"github.com/XDoubleU/essentia/pkg/database"
 	errortools "github.com/XDoubleU/essentia/pkg/errors"
+	"github.com/jackc/pgx/v5/pgtype"
 
+	"check-in/api/internal/constants"
 	"check-in/api/internal/dtos"
 	"check-in/api/internal/models"
 	"check-in/api/internal/repositories"
+	"check-in/api/internal/shared"
 )
 
+// maxClockSkew is the margin given to kiosks whose clock runs ahead
+// when uploading check-ins that were queued offline.
+const maxClockSkew = time.Minute
+
+var errCheckInAlreadyCheckedOut = errors.New("checkIn is already checked out")
+
 type CheckInWriterService struct {
-	checkins  repositories.CheckInWriteRepository
-	locations LocationService
-	schools   SchoolService
+	checkins      repositories.CheckInWriteRepository
+	waitlist      repositories.WaitlistRepository
+	audit         AuditService
+	locations     LocationService
+	schools       SchoolService
+	getTimeNowUTC shared.UTCNowTimeProvider
 }
 
 func (service CheckInWriterService) GetAllSchoolsSortedByLocation(
@@ -30,7 +46,11 @@ func (service CheckInWriterService) GetAllSchoolsSortedByLocation(
 		return nil, err
 	}
 
-	return service.schools.GetAllSortedByLocation(ctx, location.ID)
+	return service.schools.GetAllSortedByLocation(
+		ctx,
+		location.OrganisationID,
+		location.ID,
+	)
 }
 
 func (service CheckInWriterService) Create(
@@ -45,6 +65,7 @@ func (service CheckInWriterService) Create(
 
 	school, err := service.schools.GetByID(
 		ctx,
+		user.OrganisationID,
 		createCheckInDto.SchoolID,
 	)
 	if err != nil {
@@ -58,26 +79,562 @@ func (service CheckInWriterService) Create(
 		return nil, err
 	}
 
+	open, err := service.locations.IsOpenAt(ctx, location, service.getTimeNowUTC())
+	if err != nil {
+		return nil, err
+	}
+
+	if !open {
+		return nil, errortools.NewBadRequestError(
+			errors.New("location is closed, check-ins aren't allowed outside opening hours"),
+		)
+	}
+
 	if location.Available <= 0 {
 		return nil, errortools.NewBadRequestError(
 			errors.New("location has no available spots"),
 		)
 	}
 
-	checkIn, err := service.checkins.Create(ctx, location, school)
+	var checkIn *models.CheckIn
+	err = service.audit.InTransaction(ctx, func(ctx context.Context) error {
+		checkIn, err = service.checkins.Create(
+			ctx,
+			location,
+			school,
+			user.DeviceID,
+			location.DayBoundsAt,
+		)
+		if err != nil {
+			return err
+		}
+
+		return service.audit.Record(
+			ctx,
+			models.AuditCreate,
+			"checkIn",
+			strconv.FormatInt(checkIn.ID, 10),
+			nil,
+			checkIn,
+		)
+	})
 	if err != nil {
+		if errors.Is(err, repositories.ErrNoAvailableSpots) {
+			return nil, errortools.NewBadRequestError(err)
+		}
 		return nil, err
 	}
 
 	service.locations.NewCheckIn(*location)
 
-	checkInDto := &dtos.CheckInDto{
-		ID:         checkIn.ID,
-		LocationID: checkIn.LocationID,
-		SchoolName: school.Name,
-		Capacity:   checkIn.Capacity,
-		CreatedAt:  checkIn.CreatedAt,
+	return dtos.NewCheckInDto(*checkIn, school.Name), nil
+}
+
+func (service CheckInWriterService) CreateBatch(
+	ctx context.Context,
+	createCheckInBatchDto dtos.CreateCheckInBatchDto,
+	user *models.User,
+) (*dtos.CheckInBatchResultDto, error) {
+	location, err := service.locations.GetByUser(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := []string{}
+	for _, batchCheckIn := range createCheckInBatchDto.CheckIns {
+		keys = append(keys, batchCheckIn.IdempotencyKey)
+	}
+
+	seenKeys, err := service.checkins.GetExistingIdempotencyKeys(
+		ctx,
+		location.ID,
+		keys,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	schoolIDNameMap, err := service.schools.SchoolIDNameMap(ctx, user.OrganisationID)
+	if err != nil {
+		return nil, err
+	}
+
+	batchCheckIns := slices.Clone(createCheckInBatchDto.CheckIns)
+	slices.SortStableFunc(batchCheckIns, func(a, b dtos.BatchCheckInDto) int {
+		return a.CreatedAt.Compare(b.CreatedAt)
+	})
+
+	result := &dtos.CheckInBatchResultDto{
+		Created:    []*dtos.CheckInDto{},
+		Duplicates: []string{},
+		Rejected:   make(map[string]string),
+	}
+
+	toCreate := []*models.CheckIn{}
+
+	for _, batchCheckIn := range batchCheckIns {
+		if seenKeys[batchCheckIn.IdempotencyKey] {
+			result.Duplicates = append(result.Duplicates, batchCheckIn.IdempotencyKey)
+			continue
+		}
+		seenKeys[batchCheckIn.IdempotencyKey] = true
+
+		var reason string
+		reason, err = service.validateBatchCheckIn(
+			ctx,
+			location,
+			batchCheckIn,
+			schoolIDNameMap,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		if reason != "" {
+			result.Rejected[batchCheckIn.IdempotencyKey] = reason
+			continue
+		}
+
+		toCreate = append(toCreate, &models.CheckIn{
+			ID:         0,
+			LocationID: location.ID,
+			SchoolID:   batchCheckIn.SchoolID,
+			Capacity:   location.Capacity,
+			//nolint:exhaustruct //other fields are optional
+			CreatedAt: pgtype.Timestamptz{
+				Time:  batchCheckIn.CreatedAt.UTC(),
+				Valid: true,
+			},
+			//nolint:exhaustruct //other fields are optional
+			IdempotencyKey: pgtype.Text{
+				String: batchCheckIn.IdempotencyKey,
+				Valid:  true,
+			},
+			DeviceID: user.DeviceID,
+		})
+	}
+
+	err = service.createBatch(ctx, user, location, toCreate, schoolIDNameMap, result)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (service CheckInWriterService) createBatch(
+	ctx context.Context,
+	user *models.User,
+	location *models.Location,
+	toCreate []*models.CheckIn,
+	schoolIDNameMap map[int64]string,
+	result *dtos.CheckInBatchResultDto,
+) error {
+	if len(toCreate) == 0 {
+		return nil
+	}
+
+	loc, err := time.LoadLocation(location.TimeZone)
+	if err != nil {
+		return err
+	}
+
+	var created []*models.CheckIn
+	err = service.audit.InTransaction(ctx, func(ctx context.Context) error {
+		var full []*models.CheckIn
+		created, full, err = service.checkins.CreateBatch(
+			ctx,
+			location,
+			toCreate,
+			location.DayBoundsAt,
+		)
+		if err != nil {
+			return err
+		}
+
+		for _, checkIn := range toCreate {
+			if slices.Contains(full, checkIn) {
+				result.Rejected[checkIn.IdempotencyKey.String] = fmt.Sprintf(
+					"location had no available spots on %s",
+					checkIn.CreatedAt.Time.In(loc).Format(constants.DateFormat),
+				)
+				continue
+			}
+
+			if !slices.Contains(created, checkIn) {
+				// inserted concurrently by a retry of the same kiosk
+				result.Duplicates = append(
+					result.Duplicates,
+					checkIn.IdempotencyKey.String,
+				)
+				continue
+			}
+
+			err = service.audit.Record(
+				ctx,
+				models.AuditCreate,
+				"checkIn",
+				strconv.FormatInt(checkIn.ID, 10),
+				nil,
+				checkIn,
+			)
+			if err != nil {
+				return err
+			}
+
+			result.Created = append(
+				result.Created,
+				dtos.NewCheckInDto(*checkIn, schoolIDNameMap[checkIn.SchoolID]),
+			)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if len(created) == 0 {
+		return nil
+	}
+
+	updatedLocation, err := service.locations.GetByUser(ctx, user)
+	if err != nil {
+		return err
+	}
+
+	service.locations.NewLocationState(*updatedLocation)
+
+	return nil
+}
+
+func (service CheckInWriterService) CheckOut(
+	ctx context.Context,
+	user *models.User,
+	checkInID int64,
+) (*dtos.CheckInDto, error) {
+	location, err := service.locations.GetByUser(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+
+	checkIn, err := service.locations.GetCheckInByID(ctx, location, checkInID)
+	if err != nil {
+		if errors.Is(err, database.ErrResourceNotFound) {
+			return nil, errortools.NewNotFoundError("checkIn", checkInID, "id")
+		}
+		return nil, err
+	}
+
+	now := service.getTimeNowUTC()
+	startOfToday, endOfToday := location.DayBoundsAt(now)
+
+	if checkIn.CreatedAt.Time.After(endOfToday) ||
+		checkIn.CreatedAt.Time.Before(startOfToday) {
+		return nil, errortools.NewBadRequestError(
+			errors.New("checkIn didn't occur today and thus can't be checked out"),
+		)
+	}
+
+	if checkIn.CheckedOutAt.Valid {
+		return nil, errortools.NewBadRequestError(errCheckInAlreadyCheckedOut)
+	}
+
+	err = service.audit.InTransaction(ctx, func(ctx context.Context) error {
+		err = service.checkins.CheckOut(ctx, checkIn, now)
+		if err != nil {
+			return err
+		}
+
+		return service.recordCheckOut(ctx, checkIn)
+	})
+	if err != nil {
+		if errors.Is(err, database.ErrResourceNotFound) {
+			// checked out concurrently
+			return nil, errortools.NewBadRequestError(errCheckInAlreadyCheckedOut)
+		}
+		return nil, err
+	}
+
+	return service.checkedOut(ctx, user, location, checkIn)
+}
+
+func (service CheckInWriterService) CheckOutAnonymous(
+	ctx context.Context,
+	user *models.User,
+) (*dtos.CheckInDto, error) {
+	location, err := service.locations.GetByUser(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+
+	var checkIn *models.CheckIn
+	err = service.audit.InTransaction(ctx, func(ctx context.Context) error {
+		checkIn, err = service.checkins.CheckOutOldest(
+			ctx,
+			location.ID,
+			location.DayBoundsAt,
+		)
+		if err != nil {
+			return err
+		}
+
+		return service.recordCheckOut(ctx, checkIn)
+	})
+	if err != nil {
+		if errors.Is(err, database.ErrResourceNotFound) {
+			return nil, errortools.NewBadRequestError(
+				errors.New("location has no checked in visitors"),
+			)
+		}
+		return nil, err
+	}
+
+	return service.checkedOut(ctx, user, location, checkIn)
+}
+
+func (service CheckInWriterService) recordCheckOut(
+	ctx context.Context,
+	checkIn *models.CheckIn,
+) error {
+	checkedInCheckIn := *checkIn
+	//nolint:exhaustruct //other fields are optional
+	checkedInCheckIn.CheckedOutAt = pgtype.Timestamptz{}
+
+	return service.audit.Record(
+		ctx,
+		models.AuditCheckOut,
+		"checkIn",
+		strconv.FormatInt(checkIn.ID, 10),
+		checkedInCheckIn,
+		checkIn,
+	)
+}
+
+func (service CheckInWriterService) checkedOut(
+	ctx context.Context,
+	user *models.User,
+	location *models.Location,
+	checkIn *models.CheckIn,
+) (*dtos.CheckInDto, error) {
+	err := service.locations.PromoteWaitlist(ctx, location)
+	if err != nil {
+		return nil, err
+	}
+
+	updatedLocation, err := service.locations.GetByUser(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+
+	service.locations.NewLocationState(*updatedLocation)
+
+	schoolIDNameMap, err := service.schools.SchoolIDNameMap(ctx, user.OrganisationID)
+	if err != nil {
+		return nil, err
+	}
+
+	return dtos.NewCheckInDto(*checkIn, schoolIDNameMap[checkIn.SchoolID]), nil
+}
+
+func (service CheckInWriterService) GetWaitlist(
+	ctx context.Context,
+	user *models.User,
+) ([]*dtos.WaitlistEntryDto, error) {
+	location, err := service.locations.GetByUser(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+
+	startOfToday, endOfToday := location.DayBoundsAt(service.getTimeNowUTC())
+
+	entries, err := service.waitlist.GetAllWaiting(
+		ctx,
+		location.ID,
+		startOfToday,
+		endOfToday,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	schoolIDNameMap, err := service.schools.SchoolIDNameMap(ctx, user.OrganisationID)
+	if err != nil {
+		return nil, err
+	}
+
+	entryDtos := []*dtos.WaitlistEntryDto{}
+	for i, entry := range entries {
+		entryDtos = append(entryDtos, dtos.NewWaitlistEntryDto(
+			*entry,
+			schoolIDNameMap[entry.SchoolID],
+			int64(i+1),
+		))
+	}
+
+	return entryDtos, nil
+}
+
+// JoinWaitlist registers a waiting visitor at the location of the user.
+// This is only possible when the location has no available spots.
+func (service CheckInWriterService) JoinWaitlist(
+	ctx context.Context,
+	createWaitlistEntryDto dtos.CreateWaitlistEntryDto,
+	user *models.User,
+) (*dtos.WaitlistEntryDto, error) {
+	location, err := service.locations.GetByUser(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+
+	school, err := service.schools.GetByID(
+		ctx,
+		user.OrganisationID,
+		createWaitlistEntryDto.SchoolID,
+	)
+	if err != nil {
+		if errors.Is(err, database.ErrResourceNotFound) {
+			return nil, errortools.NewNotFoundError(
+				"school",
+				createWaitlistEntryDto.SchoolID,
+				"schoolId",
+			)
+		}
+		return nil, err
+	}
+
+	if location.Available > 0 {
+		return nil, errortools.NewBadRequestError(
+			errors.New("location has available spots, check in instead"),
+		)
+	}
+
+	var entry *models.WaitlistEntry
+	err = service.audit.InTransaction(ctx, func(ctx context.Context) error {
+		entry, err = service.waitlist.Create(
+			ctx,
+			location.ID,
+			school.ID,
+			createWaitlistEntryDto.ShortCode,
+		)
+		if err != nil {
+			return err
+		}
+
+		return service.audit.Record(
+			ctx,
+			models.AuditCreate,
+			"waitlistEntry",
+			strconv.FormatInt(entry.ID, 10),
+			nil,
+			entry,
+		)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// a spot might have been freed in the meantime
+	err = service.locations.PromoteWaitlist(ctx, location)
+	if err != nil {
+		return nil, err
+	}
+
+	updatedLocation, err := service.locations.GetByUser(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+
+	service.locations.NewLocationState(*updatedLocation)
+
+	return dtos.NewWaitlistEntryDto(
+		*entry,
+		school.Name,
+		updatedLocation.WaitlistLength,
+	), nil
+}
+
+func (service CheckInWriterService) LeaveWaitlist(
+	ctx context.Context,
+	user *models.User,
+	entryID int64,
+) (*dtos.WaitlistEntryDto, error) {
+	location, err := service.locations.GetByUser(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+
+	var entry *models.WaitlistEntry
+	err = service.audit.InTransaction(ctx, func(ctx context.Context) error {
+		entry, err = service.waitlist.Delete(ctx, location.ID, entryID)
+		if err != nil {
+			return err
+		}
+
+		return service.audit.Record(
+			ctx,
+			models.AuditDelete,
+			"waitlistEntry",
+			strconv.FormatInt(entry.ID, 10),
+			entry,
+			nil,
+		)
+	})
+	if err != nil {
+		if errors.Is(err, database.ErrResourceNotFound) {
+			return nil, errortools.NewNotFoundError("waitlistEntry", entryID, "id")
+		}
+		return nil, err
+	}
+
+	location.WaitlistLength--
+	service.locations.NewLocationState(*location)
+
+	schoolIDNameMap, err := service.schools.SchoolIDNameMap(ctx, user.OrganisationID)
+	if err != nil {
+		return nil, err
+	}
+
+	return dtos.NewWaitlistEntryDto(*entry, schoolIDNameMap[entry.SchoolID], 0), nil
+}
+
+// validateBatchCheckIn returns the reason a queued check-in can't be
+// created or an empty string if it can. Capacity is checked when inserting.
+// Only check-ins of the current and the previous business day
+// that were made during opening hours are accepted.
+func (service CheckInWriterService) validateBatchCheckIn(
+	ctx context.Context,
+	location *models.Location,
+	batchCheckIn dtos.BatchCheckInDto,
+	schoolIDNameMap map[int64]string,
+) (string, error) {
+	now := service.getTimeNowUTC()
+
+	if batchCheckIn.CreatedAt.After(now.Add(maxClockSkew)) {
+		return "createdAt can't be in the future", nil
+	}
+
+	startOfToday, _ := location.DayBoundsAt(now)
+	if batchCheckIn.CreatedAt.Before(startOfToday.AddDate(0, 0, -1)) {
+		return "createdAt can't be before the previous business day", nil
+	}
+
+	if _, ok := schoolIDNameMap[batchCheckIn.SchoolID]; !ok {
+		return errortools.NewNotFoundError(
+			"school",
+			batchCheckIn.SchoolID,
+			"schoolId",
+		).Error(), nil
+	}
+
+	open, err := service.locations.IsOpenAt(ctx, location, batchCheckIn.CreatedAt)
+	if err != nil {
+		return "", err
+	}
+
+	if !open {
+		return "location was closed, check-ins aren't allowed outside opening hours", nil
 	}
 
-	return checkInDto, nil
+	return "", nil
 }
@@ -0,0 +1,335 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/XDoubleU/essentia/pkg/database"
+	errortools "github.com/XDoubleU/essentia/pkg/errors"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/xhit/go-str2duration/v2"
+
+	"check-in/api/internal/dtos"
+	"check-in/api/internal/models"
+	"check-in/api/internal/repositories"
+	"check-in/api/internal/shared"
+)
+
+// codeAlphabet leaves out characters which are easily confused
+// when typing a code, e.g. a pairing code on a touch screen.
+const codeAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+
+const pairingCodeLength = 8
+
+type DeviceService struct {
+	devices       repositories.DeviceRepository
+	pairingCodes  repositories.PairingCodeRepository
+	auth          repositories.AuthRepository
+	audit         AuditService
+	locations     LocationService
+	webURL        string
+	getTimeNowUTC shared.UTCNowTimeProvider
+}
+
+func (service DeviceService) GetAll(
+	ctx context.Context,
+	user *models.User,
+	locationID string,
+) ([]*models.Device, error) {
+	location, err := service.locations.GetByID(ctx, user, locationID)
+	if err != nil {
+		return nil, err
+	}
+
+	return service.devices.GetAll(ctx, location.ID)
+}
+
+func (service DeviceService) GetByID(
+	ctx context.Context,
+	user *models.User,
+	locationID string,
+	id string,
+) (*models.Device, error) {
+	location, err := service.locations.GetByID(ctx, user, locationID)
+	if err != nil {
+		return nil, err
+	}
+
+	device, err := service.devices.GetByID(ctx, location.ID, id)
+	if err != nil {
+		if errors.Is(err, database.ErrResourceNotFound) {
+			return nil, errortools.NewNotFoundError("device", id, "id")
+		}
+		return nil, err
+	}
+
+	return device, nil
+}
+
+func (service DeviceService) GetByUsername(
+	ctx context.Context,
+	username string,
+) (*models.Device, error) {
+	return service.devices.GetByUsername(ctx, username)
+}
+
+func (service DeviceService) Create(
+	ctx context.Context,
+	user *models.User,
+	locationID string,
+	createDeviceDto dtos.CreateDeviceDto,
+) (*models.Device, error) {
+	location, err := service.locations.GetByID(ctx, user, locationID)
+	if err != nil {
+		return nil, err
+	}
+
+	passwordHash, err := models.HashPassword(createDeviceDto.Password)
+	if err != nil {
+		return nil, err
+	}
+
+	var device *models.Device
+	err = service.audit.InTransaction(ctx, func(ctx context.Context) error {
+		device, err = service.devices.Create(
+			ctx,
+			location.ID,
+			createDeviceDto.Name,
+			pgtype.Text{String: createDeviceDto.Username, Valid: true},
+			passwordHash,
+		)
+		if err != nil {
+			return err
+		}
+
+		return service.audit.Record(
+			ctx,
+			models.AuditCreate,
+			"device",
+			device.ID,
+			nil,
+			device,
+		)
+	})
+	if err != nil {
+		if errors.Is(err, database.ErrResourceConflict) {
+			return nil, errortools.NewConflictError(
+				"device",
+				createDeviceDto.Username,
+				"username",
+			)
+		}
+		return nil, err
+	}
+
+	return device, nil
+}
+
+func (service DeviceService) Rename(
+	ctx context.Context,
+	user *models.User,
+	locationID string,
+	id string,
+	updateDeviceDto dtos.UpdateDeviceDto,
+) (*models.Device, error) {
+	device, err := service.GetByID(ctx, user, locationID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	oldDevice := *device
+
+	err = service.audit.InTransaction(ctx, func(ctx context.Context) error {
+		device, err = service.devices.Rename(ctx, *device, updateDeviceDto.Name)
+		if err != nil {
+			return err
+		}
+
+		return service.audit.Record(
+			ctx,
+			models.AuditUpdate,
+			"device",
+			device.ID,
+			oldDevice,
+			device,
+		)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return device, nil
+}
+
+// Revoke signs out a device and prevents it from signing in again,
+// other devices of the location stay signed in.
+func (service DeviceService) Revoke(
+	ctx context.Context,
+	user *models.User,
+	locationID string,
+	id string,
+) (*models.Device, error) {
+	device, err := service.GetByID(ctx, user, locationID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if device.RevokedAt.Valid {
+		return nil, errortools.NewBadRequestError(
+			errors.New("device is already revoked"),
+		)
+	}
+
+	oldDevice := *device
+
+	err = service.audit.InTransaction(ctx, func(ctx context.Context) error {
+		err = service.devices.Revoke(ctx, device)
+		if err != nil {
+			return err
+		}
+
+		err = service.auth.DeleteAllTokensForDevice(ctx, device.ID)
+		if err != nil {
+			return err
+		}
+
+		return service.audit.Record(
+			ctx,
+			models.AuditUpdate,
+			"device",
+			device.ID,
+			oldDevice,
+			device,
+		)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return device, nil
+}
+
+// CreatePairingCode generates a code which a kiosk exchanges once
+// for the tokens of a new device of the location.
+func (service DeviceService) CreatePairingCode(
+	ctx context.Context,
+	user *models.User,
+	locationID string,
+	createPairingCodeDto dtos.CreatePairingCodeDto,
+	expiry string,
+) (*models.PairingCode, error) {
+	location, err := service.locations.GetByID(ctx, user, locationID)
+	if err != nil {
+		return nil, err
+	}
+
+	ttl, err := str2duration.ParseDuration(expiry)
+	if err != nil {
+		return nil, err
+	}
+
+	err = service.pairingCodes.DeleteExpired(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	code, err := generateCode(pairingCodeLength)
+	if err != nil {
+		return nil, err
+	}
+
+	pairingCode := &models.PairingCode{
+		Code: code,
+		QRPayload: fmt.Sprintf(
+			"%s/pair?code=%s",
+			service.webURL,
+			url.QueryEscape(code),
+		),
+		LocationID: location.ID,
+		DeviceName: createPairingCodeDto.Name,
+		Expiry:     service.getTimeNowUTC().Add(ttl),
+	}
+
+	err = service.pairingCodes.Create(ctx, pairingCode, hashPairingCode(code))
+	if err != nil {
+		return nil, err
+	}
+
+	return pairingCode, nil
+}
+
+// Pair exchanges a pairing code for a new device without credentials.
+func (service DeviceService) Pair(
+	ctx context.Context,
+	code string,
+) (*models.Device, error) {
+	var device *models.Device
+	err := service.audit.InTransaction(ctx, func(ctx context.Context) error {
+		pairedDevice, err := service.pairingCodes.Consume(ctx, hashPairingCode(code))
+		if err != nil {
+			return err
+		}
+
+		//nolint:exhaustruct //other fields are optional
+		device, err = service.devices.Create(
+			ctx,
+			pairedDevice.LocationID,
+			pairedDevice.Name,
+			pgtype.Text{},
+			nil,
+		)
+		if err != nil {
+			return err
+		}
+
+		device.UserID = pairedDevice.UserID
+		device.OrganisationID = pairedDevice.OrganisationID
+
+		return service.audit.Record(
+			ctx,
+			models.AuditCreate,
+			"device",
+			device.ID,
+			nil,
+			device,
+		)
+	})
+	if err != nil {
+		// the pairing code is only consumed if the device was created
+		if errors.Is(err, database.ErrResourceNotFound) {
+			return nil, errortools.NewUnauthorizedError(
+				errors.New("invalid or expired pairing code"),
+			)
+		}
+		return nil, err
+	}
+
+	return device, nil
+}
+
+func generateCode(length int) (string, error) {
+	randomBytes := make([]byte, length)
+
+	_, err := rand.Read(randomBytes)
+	if err != nil {
+		return "", err
+	}
+
+	code := make([]byte, length)
+	for i, randomByte := range randomBytes {
+		code[i] = codeAlphabet[int(randomByte)%len(codeAlphabet)]
+	}
+
+	return string(code), nil
+}
+
+// hashPairingCode ignores the case of the code,
+// kiosks might not type it in upper case.
+func hashPairingCode(code string) [32]byte {
+	return sha256.Sum256([]byte(strings.ToUpper(code)))
+}
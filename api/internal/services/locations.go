@@ -3,36 +3,66 @@ package services
 import (
 	"context"
 	"errors"
+	"log/slog"
 	"slices"
+	"strconv"
 	"time"
 
+	contexttools "github.com/XDoubleU/essentia/pkg/context"
 	"github.com/XDoubleU/essentia/pkg/database"
 	errortools "github.com/XDoubleU/essentia/pkg/errors"
 	"github.com/XDoubleU/essentia/pkg/grapher"
+	"github.com/XDoubleU/essentia/pkg/logging"
+	"github.com/XDoubleU/essentia/pkg/sentry"
 	timetools "github.com/XDoubleU/essentia/pkg/time"
+	"github.com/jackc/pgx/v5/pgtype"
 
+	"check-in/api/internal/constants"
 	"check-in/api/internal/dtos"
 	"check-in/api/internal/models"
 	"check-in/api/internal/repositories"
 	"check-in/api/internal/shared"
 )
 
+const defaultBusinessDayStart = "00:00"
+
+const (
+	daysPerWeek = 7
+	hoursPerDay = 24
+)
+
 type LocationService struct {
-	locations     repositories.LocationRepository
-	checkins      repositories.CheckInRepository
-	schools       SchoolService
-	users         UserService
-	websocket     *WebSocketService
-	getTimeNowUTC shared.UTCNowTimeProvider
+	logger           *slog.Logger
+	locations        repositories.LocationRepository
+	managerLocations repositories.ManagerLocationRepository
+	checkins         repositories.CheckInRepository
+	openingHours     repositories.OpeningHoursRepository
+	capacities       repositories.CapacityRepository
+	waitlist         repositories.WaitlistRepository
+	audit            AuditService
+	schools          SchoolService
+	users            UserService
+	websocket        *WebSocketService
+	getTimeNowUTC    shared.UTCNowTimeProvider
 }
 
-func (service *LocationService) InitializeWS(ctx context.Context) error {
-	locations, err := service.GetAll(ctx, nil, true)
+// InitializeWS adds the all-locations topic of an organisation
+// and a topic for each of its locations.
+func (service *LocationService) InitializeWS(
+	ctx context.Context,
+	organisationID string,
+) error {
+	locations, err := service.getAll(ctx, organisationID, nil, true)
 	if err != nil {
 		return err
 	}
 
-	err = service.websocket.SetAllLocationsTopic(service.GetAllStates)
+	err = service.websocket.AddAllLocationsTopic(
+		organisationID,
+		func(ctx context.Context) ([]dtos.LocationStateDto, error) {
+			return service.GetAllStates(ctx, organisationID)
+		},
+	)
 	if err != nil {
 		return err
 	}
@@ -53,12 +83,15 @@ func (service LocationService) GetCheckInsEntriesDay(
 	locationIDs []string,
 	date time.Time,
 ) ([]string, map[string][]int, map[string][]int, error) {
-	_, checkIns, err := service.GetAllCheckInsOfDay(
+	_, _, checkIns, err := service.getAllCheckIns(
 		ctx,
+		user.OrganisationID,
 		user,
 		false,
 		locationIDs,
-		date,
+		func(location *models.Location) (time.Time, time.Time) {
+			return location.DayBounds(date)
+		},
 	)
 	if err != nil {
 		return nil, nil, nil, err
@@ -84,31 +117,57 @@ func (service LocationService) GetCheckInsEntriesDay(
 	return dateStrings, capacitiesMap, valueMap, nil
 }
 
+// GetCheckInsEntriesRange counts the check-ins per school in buckets
+// of the provided granularity. Buckets consist of whole business days of
+// the locations, the capacity of a bucket is the sum of the capacities
+// of its days and a location is only closed in a bucket if it was closed
+// on all of its days.
 func (service LocationService) GetCheckInsEntriesRange(
 	ctx context.Context,
 	user *models.User,
 	locationIDs []string,
 	startDate time.Time,
 	endDate time.Time,
-) ([]string, map[string][]int, map[string][]int, error) {
+	granularity models.Granularity,
+) ([]string, map[string][]int, map[string][]int, map[string][]bool, error) {
 	startDate = timetools.StartOfDay(startDate)
 	endDate = timetools.EndOfDay(endDate)
 
-	_, checkIns, err := service.GetAllCheckInsInRange(
+	locations, err := service.getAccessibleByIDs(
 		ctx,
+		user.OrganisationID,
 		user,
 		false,
 		locationIDs,
+	)
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+
+	checkInCounts, err := service.checkins.CountPerDayInRange(
+		ctx,
+		locationIDs,
 		startDate,
 		endDate,
 	)
 	if err != nil {
-		return nil, nil, nil, err
+		return nil, nil, nil, nil, err
+	}
+
+	closedMap, err := service.getClosedPerLocation(
+		ctx,
+		locations,
+		startDate,
+		endDate,
+		granularity,
+	)
+	if err != nil {
+		return nil, nil, nil, nil, err
 	}
 
-	schoolIDNameMap, err := service.schools.SchoolIDNameMap(ctx)
+	schoolIDNameMap, err := service.schools.SchoolIDNameMap(ctx, user.OrganisationID)
 	if err != nil {
-		return nil, nil, nil, err
+		return nil, nil, nil, nil, err
 	}
 
 	g := grapher.New[int](
@@ -118,95 +177,353 @@ func (service LocationService) GetCheckInsEntriesRange(
 		time.Second,
 	)
 	capacitiesGrapher := grapher.New[int](
-		grapher.Normal,
+		grapher.CumulativeSameDate,
 		grapher.None,
 		time.RFC3339,
 		time.Second,
 	)
 
+	capacityChanges, err := service.getCapacityChanges(ctx, locations)
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+
 	for i := startDate; i.Before(endDate); i = i.AddDate(0, 0, 1) {
+		bucket := granularity.Start(i)
+
 		for _, schoolName := range schoolIDNameMap {
-			g.AddPoint(i, 0, schoolName)
+			g.AddPoint(bucket, 0, schoolName)
 		}
 
-		for _, locationID := range locationIDs {
-			capacitiesGrapher.AddPoint(i, 0, locationID)
+		for _, location := range locations {
+			dayStart, _ := location.DayBounds(i)
+			capacity := models.CapacityAt(
+				capacityChanges[location.ID],
+				dayStart,
+				location.Capacity,
+			)
+			capacitiesGrapher.AddPoint(bucket, int(capacity), location.ID)
 		}
 	}
 
-	for i := range checkIns {
-		datetime := timetools.StartOfDay(checkIns[i].CreatedAt.Time)
-
-		g.AddPoint(datetime, 1, checkIns[i].SchoolName)
-		capacitiesGrapher.AddPoint(
-			datetime,
-			int(checkIns[i].Capacity),
-			checkIns[i].LocationID,
+	for _, checkInCount := range checkInCounts {
+		g.AddPoint(
+			granularity.Start(checkInCount.Date),
+			int(checkInCount.Count),
+			schoolIDNameMap[checkInCount.SchoolID],
 		)
 	}
 
 	dateStrings, valueMap := g.ToSlices()
 	_, capacitiesMap := capacitiesGrapher.ToSlices()
 
-	return dateStrings, capacitiesMap, valueMap, nil
+	return dateStrings, capacitiesMap, valueMap, closedMap, nil
 }
 
-func (service LocationService) GetAllCheckInsOfDay(
+// GetCheckInsHeatmap counts the check-ins of each location per weekday
+// and hour, using the wall clock time in the time zone of the location.
+// Weekdays are numbered like opening hours, starting at sunday.
+// The range contains whole days in the time zone of each location.
+func (service LocationService) GetCheckInsHeatmap(
 	ctx context.Context,
 	user *models.User,
-	allowAnonymous bool,
 	locationIDs []string,
-	date time.Time,
-) ([]*models.CheckIn, []*dtos.CheckInDto, error) {
-	return service.GetAllCheckInsInRange(
+	startDate time.Time,
+	endDate time.Time,
+) ([]*models.Location, map[string][][]int, error) {
+	locations, err := service.getAccessibleByIDs(
 		ctx,
+		user.OrganisationID,
 		user,
-		allowAnonymous,
+		false,
 		locationIDs,
-		timetools.StartOfDay(date),
-		timetools.EndOfDay(date),
 	)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	checkInCounts, err := service.checkins.CountPerHourInRange(
+		ctx,
+		locationIDs,
+		startDate,
+		endDate,
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	valuesPerLocation := make(map[string][][]int)
+	for _, location := range locations {
+		values := make([][]int, daysPerWeek)
+		for weekday := range values {
+			values[weekday] = make([]int, hoursPerDay)
+		}
+
+		valuesPerLocation[location.ID] = values
+	}
+
+	for _, checkInCount := range checkInCounts {
+		values := valuesPerLocation[checkInCount.LocationID]
+
+		values[checkInCount.Date.Weekday()][checkInCount.Hour] += int(
+			checkInCount.Count,
+		)
+	}
+
+	return locations, valuesPerLocation, nil
 }
 
-func (service LocationService) GetAllCheckInsInRange(
+// GetCheckInsComparison compares the check-ins of a period
+// with those of another period, in total, per school and per location.
+func (service LocationService) GetCheckInsComparison(
 	ctx context.Context,
 	user *models.User,
-	allowAnonymous bool,
 	locationIDs []string,
 	startDate time.Time,
 	endDate time.Time,
-) ([]*models.CheckIn, []*dtos.CheckInDto, error) {
-	if len(locationIDs) == 0 {
-		return make([]*models.CheckIn, 0), make([]*dtos.CheckInDto, 0), nil
+	compareStartDate time.Time,
+	compareEndDate time.Time,
+) ([]*models.Location, *dtos.CheckInsComparisonDto, error) {
+	locations, err := service.getAccessibleByIDs(
+		ctx,
+		user.OrganisationID,
+		user,
+		false,
+		locationIDs,
+	)
+	if err != nil {
+		return nil, nil, err
 	}
 
-	locations, err := service.getByIDs(ctx, locationIDs)
+	schoolIDNameMap, err := service.schools.SchoolIDNameMap(ctx, user.OrganisationID)
 	if err != nil {
-		if errors.Is(err, database.ErrResourceNotFound) {
-			return nil, nil, errortools.NewNotFoundError(
-				"locations",
-				locationIDs,
-				"ids",
-			)
-		}
 		return nil, nil, err
 	}
 
-	if !allowAnonymous && user.Role == models.DefaultRole {
-		for _, location := range locations {
-			if location.UserID != user.ID {
-				return nil, nil, errortools.NewNotFoundError(
-					"location",
-					location.ID,
-					"id",
-				)
+	period, err := service.getDailyCheckIns(
+		ctx,
+		locationIDs,
+		schoolIDNameMap,
+		startDate,
+		endDate,
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	comparePeriod, err := service.getDailyCheckIns(
+		ctx,
+		locationIDs,
+		schoolIDNameMap,
+		compareStartDate,
+		compareEndDate,
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	comparison := &dtos.CheckInsComparisonDto{
+		Total: dtos.NewComparisonTotalDto(
+			period.total,
+			comparePeriod.total,
+		),
+		TotalsPerSchool:        make(map[string]dtos.ComparisonTotalDto),
+		TotalsPerLocation:      make(map[string]dtos.ComparisonTotalDto),
+		Dates:                  period.dates,
+		CompareDates:           comparePeriod.dates,
+		ValuesPerSchool:        period.valuesPerSchool,
+		CompareValuesPerSchool: comparePeriod.valuesPerSchool,
+	}
+
+	for schoolName := range period.valuesPerSchool {
+		comparison.TotalsPerSchool[schoolName] = dtos.NewComparisonTotalDto(
+			period.totalPerSchool[schoolName],
+			comparePeriod.totalPerSchool[schoolName],
+		)
+	}
+
+	for _, location := range locations {
+		comparison.TotalsPerLocation[location.ID] = dtos.NewComparisonTotalDto(
+			period.totalPerLocation[location.ID],
+			comparePeriod.totalPerLocation[location.ID],
+		)
+	}
+
+	return locations, comparison, nil
+}
+
+// dailyCheckIns holds the check-ins of a period per day,
+// the i-th value of a school is the i-th day of the period.
+type dailyCheckIns struct {
+	dates            []string
+	valuesPerSchool  map[string][]int
+	totalPerSchool   map[string]int
+	totalPerLocation map[string]int
+	total            int
+}
+
+func (service LocationService) getDailyCheckIns(
+	ctx context.Context,
+	locationIDs []string,
+	schoolIDNameMap map[int64]string,
+	startDate time.Time,
+	endDate time.Time,
+) (*dailyCheckIns, error) {
+	startDate = timetools.StartOfDay(startDate)
+	endDate = timetools.StartOfDay(endDate)
+
+	checkInCounts, err := service.checkins.CountPerDayInRange(
+		ctx,
+		locationIDs,
+		startDate,
+		endDate,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	daily := &dailyCheckIns{
+		dates:            []string{},
+		valuesPerSchool:  make(map[string][]int),
+		totalPerSchool:   make(map[string]int),
+		totalPerLocation: make(map[string]int),
+		total:            0,
+	}
+
+	for i := startDate; !i.After(endDate); i = i.AddDate(0, 0, 1) {
+		daily.dates = append(daily.dates, i.Format(time.RFC3339))
+	}
+
+	for _, schoolName := range schoolIDNameMap {
+		daily.valuesPerSchool[schoolName] = make([]int, len(daily.dates))
+	}
+
+	for _, checkInCount := range checkInCounts {
+		schoolName := schoolIDNameMap[checkInCount.SchoolID]
+		if _, ok := daily.valuesPerSchool[schoolName]; !ok {
+			daily.valuesPerSchool[schoolName] = make([]int, len(daily.dates))
+		}
+
+		day := int(checkInCount.Date.Sub(startDate).Hours()) / hoursPerDay
+		count := int(checkInCount.Count)
+
+		daily.valuesPerSchool[schoolName][day] += count
+		daily.totalPerSchool[schoolName] += count
+		daily.totalPerLocation[checkInCount.LocationID] += count
+		daily.total += count
+	}
+
+	return daily, nil
+}
+
+func (service LocationService) getCapacityChanges(
+	ctx context.Context,
+	locations []*models.Location,
+) (map[string][]*models.CapacityChange, error) {
+	capacityChanges := make(map[string][]*models.CapacityChange)
+
+	for _, location := range locations {
+		changes, err := service.capacities.GetAll(ctx, location.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		capacityChanges[location.ID] = changes
+	}
+
+	return capacityChanges, nil
+}
+
+// getClosedPerLocation returns for every bucket in the range
+// whether each location was closed on all dates of that bucket.
+func (service LocationService) getClosedPerLocation(
+	ctx context.Context,
+	locations []*models.Location,
+	startDate time.Time,
+	endDate time.Time,
+	granularity models.Granularity,
+) (map[string][]bool, error) {
+	closedMap := make(map[string][]bool)
+
+	for _, location := range locations {
+		openingHours, err := service.openingHours.GetByLocationID(ctx, location.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		closed := []bool{}
+		var bucket time.Time
+		for i := startDate; i.Before(endDate); i = i.AddDate(0, 0, 1) {
+			if len(closed) == 0 || !granularity.Start(i).Equal(bucket) {
+				bucket = granularity.Start(i)
+				closed = append(closed, true)
 			}
+
+			closed[len(closed)-1] = closed[len(closed)-1] &&
+				openingHours.IsClosedOn(i)
 		}
+
+		closedMap[location.ID] = closed
+	}
+
+	return closedMap, nil
+}
+
+// GetAllCheckInsOfDay returns the check-ins of the business day
+// of each location which contains the provided time.
+func (service LocationService) GetAllCheckInsOfDay(
+	ctx context.Context,
+	organisationID string,
+	user *models.User,
+	allowAnonymous bool,
+	locationIDs []string,
+	at time.Time,
+) ([]*models.CheckIn, []*dtos.CheckInDto, error) {
+	_, checkIns, checkInDtos, err := service.getAllCheckIns(
+		ctx,
+		organisationID,
+		user,
+		allowAnonymous,
+		locationIDs,
+		func(location *models.Location) (time.Time, time.Time) {
+			return location.DayBoundsAt(at)
+		},
+	)
+
+	return checkIns, checkInDtos, err
+}
+
+func (service LocationService) getAllCheckIns(
+	ctx context.Context,
+	organisationID string,
+	user *models.User,
+	allowAnonymous bool,
+	locationIDs []string,
+	bounds func(location *models.Location) (time.Time, time.Time),
+) ([]*models.Location, []*models.CheckIn, []*dtos.CheckInDto, error) {
+	if len(locationIDs) == 0 {
+		return make([]*models.Location, 0),
+			make([]*models.CheckIn, 0),
+			make([]*dtos.CheckInDto, 0),
+			nil
+	}
+
+	locations, err := service.getAccessibleByIDs(
+		ctx,
+		organisationID,
+		user,
+		allowAnonymous,
+		locationIDs,
+	)
+	if err != nil {
+		return nil, nil, nil, err
 	}
 
 	checkIns := []*models.CheckIn{}
 
 	for _, location := range locations {
+		startDate, endDate := bounds(location)
+
 		var locationCheckIns []*models.CheckIn
 		locationCheckIns, err = service.checkins.GetAllInRange(
 			ctx,
@@ -215,7 +532,7 @@ func (service LocationService) GetAllCheckInsInRange(
 			endDate,
 		)
 		if err != nil {
-			return nil, nil, err
+			return nil, nil, nil, err
 		}
 
 		for _, checkIn := range locationCheckIns {
@@ -225,30 +542,592 @@ func (service LocationService) GetAllCheckInsInRange(
 			)
 			checkIns = append(checkIns, checkIn)
 		}
-	}
+	}
+
+	slices.SortFunc(checkIns, func(i, j *models.CheckIn) int {
+		return i.CreatedAt.Time.Compare(j.CreatedAt.Time)
+	})
+
+	schoolIDNameMap, err := service.schools.SchoolIDNameMap(ctx, organisationID)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	checkInDtos := make([]*dtos.CheckInDto, 0)
+	for _, checkIn := range checkIns {
+		checkInDtos = append(
+			checkInDtos,
+			dtos.NewCheckInDto(*checkIn, schoolIDNameMap[checkIn.SchoolID]),
+		)
+	}
+
+	return locations, checkIns, checkInDtos, nil
+}
+
+// getAccessibleByIDs returns the locations with the provided IDs,
+// a location to which the user has no access isn't found.
+func (service LocationService) getAccessibleByIDs(
+	ctx context.Context,
+	organisationID string,
+	user *models.User,
+	allowAnonymous bool,
+	locationIDs []string,
+) ([]*models.Location, error) {
+	locations, err := service.getByIDs(ctx, organisationID, locationIDs)
+	if err != nil {
+		if errors.Is(err, database.ErrResourceNotFound) {
+			return nil, errortools.NewNotFoundError(
+				"locations",
+				locationIDs,
+				"ids",
+			)
+		}
+		return nil, err
+	}
+
+	if allowAnonymous {
+		return locations, nil
+	}
+
+	for _, location := range locations {
+		var hasAccess bool
+
+		hasAccess, err = service.hasAccess(ctx, user, location)
+		if err != nil {
+			return nil, err
+		}
+
+		if !hasAccess {
+			return nil, errortools.NewNotFoundError(
+				"location",
+				location.ID,
+				"id",
+			)
+		}
+	}
+
+	return locations, nil
+}
+
+func (service LocationService) GetOpeningHours(
+	ctx context.Context,
+	user *models.User,
+	id string,
+) (*models.OpeningHours, error) {
+	location, err := service.GetByID(ctx, user, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return service.openingHours.GetByLocationID(ctx, location.ID)
+}
+
+func (service LocationService) UpdateOpeningHours(
+	ctx context.Context,
+	user *models.User,
+	id string,
+	openingHoursDto dtos.OpeningHoursDto,
+) (*models.OpeningHours, error) {
+	location, err := service.GetByID(ctx, user, id)
+	if err != nil {
+		return nil, err
+	}
+
+	openingHours := models.OpeningHours{
+		Periods:     []*models.OpeningPeriod{},
+		ClosingDays: []*models.ClosingDay{},
+	}
+	for _, period := range openingHoursDto.Periods {
+		openingHours.Periods = append(openingHours.Periods, &period)
+	}
+	for _, closingDay := range openingHoursDto.ClosingDays {
+		openingHours.ClosingDays = append(openingHours.ClosingDays, &closingDay)
+	}
+
+	oldOpeningHours, err := service.openingHours.GetByLocationID(ctx, location.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	var newOpeningHours *models.OpeningHours
+	err = service.audit.InTransaction(ctx, func(ctx context.Context) error {
+		err = service.openingHours.Replace(ctx, location.ID, openingHours)
+		if err != nil {
+			return err
+		}
+
+		newOpeningHours, err = service.openingHours.GetByLocationID(ctx, location.ID)
+		if err != nil {
+			return err
+		}
+
+		return service.audit.Record(
+			ctx,
+			models.AuditUpdate,
+			"openingHours",
+			location.ID,
+			oldOpeningHours,
+			newOpeningHours,
+		)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return newOpeningHours, nil
+}
+
+// IsOpenAt checks if a location is open at the provided time,
+// using the wall clock time in the time zone of the location.
+func (service LocationService) IsOpenAt(
+	ctx context.Context,
+	location *models.Location,
+	at time.Time,
+) (bool, error) {
+	openingHours, err := service.openingHours.GetByLocationID(ctx, location.ID)
+	if err != nil {
+		return false, err
+	}
+
+	loc, err := time.LoadLocation(location.TimeZone)
+	if err != nil {
+		return false, err
+	}
+
+	return openingHours.IsOpenAt(at.In(loc)), nil
+}
+
+// setLiveFields sets the capacity in force
+// and the length of the waitlist of the locations.
+func (service LocationService) setLiveFields(
+	ctx context.Context,
+	locations ...*models.Location,
+) error {
+	if len(locations) == 0 {
+		return nil
+	}
+
+	locationIDs := []string{}
+	for _, location := range locations {
+		locationIDs = append(locationIDs, location.ID)
+	}
+
+	now := service.getTimeNowUTC()
+
+	capacities, err := service.capacities.GetInForce(ctx, locationIDs, now)
+	if err != nil {
+		return err
+	}
+
+	for _, location := range locations {
+		if capacity, ok := capacities[location.ID]; ok {
+			location.Capacity = capacity
+		}
+
+		startOfDay, endOfDay := location.DayBoundsAt(now)
+
+		var entries []*models.WaitlistEntry
+		entries, err = service.waitlist.GetAllWaiting(
+			ctx,
+			location.ID,
+			startOfDay,
+			endOfDay,
+		)
+		if err != nil {
+			return err
+		}
+
+		location.WaitlistLength = int64(len(entries))
+	}
+
+	return nil
+}
+
+// PromoteWaitlist checks in the oldest waiters of a location for as long as
+// it has available spots and announces every promotion over its topic.
+func (service LocationService) PromoteWaitlist(
+	ctx context.Context,
+	location *models.Location,
+) error {
+	var promoted []*models.WaitlistEntry
+	err := service.audit.InTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		promoted, err = service.waitlist.Promote(ctx, location.ID, location.DayBoundsAt)
+		if err != nil {
+			return err
+		}
+
+		for _, entry := range promoted {
+			waitingEntry := *entry
+			//nolint:exhaustruct //other fields are optional
+			waitingEntry.PromotedAt = pgtype.Timestamptz{}
+			waitingEntry.CheckInID = pgtype.Int8{}
+
+			if contexttools.GetValue[models.User](ctx, constants.UserContextKey) == nil {
+				// promoted by the capacity polling, not on behalf of a user
+				err = service.audit.RecordForOrganisation(
+					ctx,
+					pgtype.Text{String: location.OrganisationID, Valid: true},
+					models.AuditUpdate,
+					"waitlistEntry",
+					strconv.FormatInt(entry.ID, 10),
+					waitingEntry,
+					entry,
+				)
+			} else {
+				err = service.audit.Record(
+					ctx,
+					models.AuditUpdate,
+					"waitlistEntry",
+					strconv.FormatInt(entry.ID, 10),
+					waitingEntry,
+					entry,
+				)
+			}
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if len(promoted) == 0 {
+		return nil
+	}
+
+	schoolIDNameMap, err := service.schools.SchoolIDNameMap(
+		ctx,
+		location.OrganisationID,
+	)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range promoted {
+		service.websocket.NewWaitlistPromotion(
+			*location,
+			*dtos.NewWaitlistEntryDto(*entry, schoolIDNameMap[entry.SchoolID], 0),
+		)
+	}
+
+	return nil
+}
+
+// StartPolling periodically applies scheduled capacity changes
+// which came in force.
+func (service LocationService) StartPolling(ctx context.Context) {
+	go service.startPolling(ctx, service.logger)
+}
+
+func (service LocationService) startPolling(ctx context.Context, logger *slog.Logger) {
+	sentry.GoRoutineWrapper(
+		ctx,
+		logger,
+		"Capacity Polling",
+		func(ctx context.Context, logger *slog.Logger) error {
+			for ctx.Err() != context.Canceled {
+				err := service.ApplyDueCapacityChanges(ctx)
+				if err != nil {
+					logger.Error(
+						"something went wrong while applying capacity changes",
+						logging.ErrAttr(err),
+					)
+				}
+
+				time.Sleep(10 * time.Second) //nolint:mnd //no magic number
+			}
+			return nil
+		},
+	)
+}
+
+// ApplyDueCapacityChanges promotes the waitlists of the locations
+// of which a scheduled capacity change came in force
+// and announces their new state.
+func (service LocationService) ApplyDueCapacityChanges(ctx context.Context) error {
+	changesPerOrganisation, err := service.capacities.GetDue(
+		ctx,
+		service.getTimeNowUTC(),
+	)
+	if err != nil {
+		return err
+	}
+
+	for organisationID, changes := range changesPerOrganisation {
+		changedLocationIDs := []string{}
+
+		for _, change := range changes {
+			var location *models.Location
+			location, err = service.locations.GetByID(
+				ctx,
+				organisationID,
+				change.LocationID,
+			)
+			if err != nil {
+				return err
+			}
+
+			err = service.PromoteWaitlist(ctx, location)
+			if err != nil {
+				return err
+			}
+
+			err = service.capacities.MarkApplied(ctx, change.ID)
+			if err != nil {
+				return err
+			}
+
+			changedLocationIDs = append(changedLocationIDs, location.ID)
+		}
+
+		var locations []*models.Location
+		locations, err = service.getAll(ctx, organisationID, nil, true)
+		if err != nil {
+			return err
+		}
+
+		for _, location := range locations {
+			if slices.Contains(changedLocationIDs, location.ID) {
+				service.NewLocationState(*location)
+			}
+		}
+	}
+
+	return nil
+}
+
+func (service LocationService) GetCapacityHistory(
+	ctx context.Context,
+	user *models.User,
+	id string,
+) ([]*models.CapacityChange, error) {
+	location, err := service.GetByID(ctx, user, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return service.capacities.GetAll(ctx, location.ID)
+}
+
+func (service LocationService) ScheduleCapacityChange(
+	ctx context.Context,
+	user *models.User,
+	id string,
+	capacityChangeDto dtos.CapacityChangeDto,
+) (*models.CapacityChange, error) {
+	location, err := service.GetByID(ctx, user, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if !capacityChangeDto.EffectiveFrom.After(service.getTimeNowUTC()) {
+		return nil, errortools.NewBadRequestError(
+			errors.New("capacity changes can only be scheduled in the future"),
+		)
+	}
+
+	var change *models.CapacityChange
+	err = service.audit.InTransaction(ctx, func(ctx context.Context) error {
+		change, err = service.capacities.Create(
+			ctx,
+			location.ID,
+			capacityChangeDto.Capacity,
+			capacityChangeDto.EffectiveFrom,
+		)
+		if err != nil {
+			return err
+		}
+
+		return service.audit.Record(
+			ctx,
+			models.AuditCreate,
+			"capacityChange",
+			strconv.FormatInt(change.ID, 10),
+			nil,
+			change,
+		)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return change, nil
+}
+
+func (service LocationService) DeleteCapacityChange(
+	ctx context.Context,
+	user *models.User,
+	locationID string,
+	capacityChangeID int64,
+) (*models.CapacityChange, error) {
+	location, err := service.GetByID(ctx, user, locationID)
+	if err != nil {
+		return nil, err
+	}
+
+	change, err := service.capacities.GetByID(ctx, location.ID, capacityChangeID)
+	if err != nil {
+		if errors.Is(err, database.ErrResourceNotFound) {
+			return nil, errortools.NewNotFoundError(
+				"capacityChange",
+				capacityChangeID,
+				"id",
+			)
+		}
+		return nil, err
+	}
+
+	if !change.EffectiveFrom.Time.After(service.getTimeNowUTC()) {
+		return nil, errortools.NewBadRequestError(
+			errors.New("capacity change is already in force and thus can't be deleted"),
+		)
+	}
+
+	err = service.audit.InTransaction(ctx, func(ctx context.Context) error {
+		err = service.capacities.Delete(ctx, change.ID)
+		if err != nil {
+			return err
+		}
+
+		return service.audit.Record(
+			ctx,
+			models.AuditDelete,
+			"capacityChange",
+			strconv.FormatInt(change.ID, 10),
+			change,
+			nil,
+		)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return change, nil
+}
+
+// GetAllOfManager returns the locations assigned to a manager.
+func (service LocationService) GetAllOfManager(
+	ctx context.Context,
+	user *models.User,
+	managerID string,
+) ([]*models.Location, error) {
+	manager, err := service.users.GetByID(
+		ctx,
+		user.OrganisationID,
+		managerID,
+		models.ManagerRole,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return service.getAll(ctx, manager.OrganisationID, manager, true)
+}
+
+// AssignManager lets a manager manage a location.
+func (service LocationService) AssignManager(
+	ctx context.Context,
+	user *models.User,
+	managerID string,
+	locationID string,
+) (*models.Location, error) {
+	manager, err := service.users.GetByID(
+		ctx,
+		user.OrganisationID,
+		managerID,
+		models.ManagerRole,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	location, err := service.GetByID(ctx, user, locationID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = service.audit.InTransaction(ctx, func(ctx context.Context) error {
+		err = service.managerLocations.Create(ctx, manager.ID, location.ID)
+		if err != nil {
+			return err
+		}
+
+		return service.audit.Record(
+			ctx,
+			models.AuditCreate,
+			"managerLocation",
+			manager.ID,
+			nil,
+			models.ManagerLocation{UserID: manager.ID, LocationID: location.ID},
+		)
+	})
+	if err != nil {
+		if errors.Is(err, database.ErrResourceConflict) {
+			return nil, errortools.NewConflictError(
+				"assignment",
+				location.ID,
+				"locationId",
+			)
+		}
+		return nil, err
+	}
+
+	return location, nil
+}
+
+// UnassignManager stops a manager from managing a location.
+func (service LocationService) UnassignManager(
+	ctx context.Context,
+	user *models.User,
+	managerID string,
+	locationID string,
+) (*models.Location, error) {
+	manager, err := service.users.GetByID(
+		ctx,
+		user.OrganisationID,
+		managerID,
+		models.ManagerRole,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	location, err := service.GetByID(ctx, user, locationID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = service.audit.InTransaction(ctx, func(ctx context.Context) error {
+		err = service.managerLocations.Delete(ctx, manager.ID, location.ID)
+		if err != nil {
+			return err
+		}
 
-	slices.SortFunc(checkIns, func(i, j *models.CheckIn) int {
-		return i.CreatedAt.Time.Compare(j.CreatedAt.Time)
+		return service.audit.Record(
+			ctx,
+			models.AuditDelete,
+			"managerLocation",
+			manager.ID,
+			models.ManagerLocation{UserID: manager.ID, LocationID: location.ID},
+			nil,
+		)
 	})
-
-	schoolIDNameMap, err := service.schools.SchoolIDNameMap(ctx)
 	if err != nil {
-		return nil, nil, err
-	}
-
-	checkInDtos := make([]*dtos.CheckInDto, 0)
-	for _, checkIn := range checkIns {
-		checkInDto := &dtos.CheckInDto{
-			ID:         checkIn.ID,
-			LocationID: checkIn.LocationID,
-			SchoolName: schoolIDNameMap[checkIn.SchoolID],
-			Capacity:   checkIn.Capacity,
-			CreatedAt:  checkIn.CreatedAt,
+		if errors.Is(err, database.ErrResourceNotFound) {
+			return nil, errortools.NewNotFoundError(
+				"assignment",
+				location.ID,
+				"locationId",
+			)
 		}
-		checkInDtos = append(checkInDtos, checkInDto)
+		return nil, err
 	}
 
-	return checkIns, checkInDtos, nil
+	return location, nil
 }
 
 func (service LocationService) GetCheckInByID(
@@ -281,9 +1160,7 @@ func (service LocationService) DeleteCheckIn(
 		return nil, err
 	}
 
-	today := service.getTimeNowUTC()
-	startOfToday := timetools.StartOfDay(today)
-	endOfToday := timetools.EndOfDay(today)
+	startOfToday, endOfToday := location.DayBoundsAt(service.getTimeNowUTC())
 
 	if checkIn.CreatedAt.Time.After(endOfToday) ||
 		checkIn.CreatedAt.Time.Before(startOfToday) {
@@ -292,34 +1169,68 @@ func (service LocationService) DeleteCheckIn(
 		)
 	}
 
-	err = service.checkins.Delete(ctx, checkInID)
+	err = service.audit.InTransaction(ctx, func(ctx context.Context) error {
+		err = service.checkins.Delete(ctx, checkInID)
+		if err != nil {
+			return err
+		}
+
+		return service.audit.Record(
+			ctx,
+			models.AuditDelete,
+			"checkIn",
+			strconv.FormatInt(checkIn.ID, 10),
+			checkIn,
+			nil,
+		)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = service.PromoteWaitlist(ctx, location)
 	if err != nil {
 		return nil, err
 	}
 
-	schoolIDNameMap, err := service.schools.SchoolIDNameMap(ctx)
+	updatedLocation, err := service.GetByID(ctx, user, location.ID)
 	if err != nil {
 		return nil, err
 	}
 
-	checkInDto := &dtos.CheckInDto{
-		ID:         checkIn.ID,
-		LocationID: checkIn.LocationID,
-		SchoolName: schoolIDNameMap[checkIn.SchoolID],
-		Capacity:   checkIn.Capacity,
-		CreatedAt:  checkIn.CreatedAt,
+	service.websocket.NewLocationState(*updatedLocation)
+
+	schoolIDNameMap, err := service.schools.SchoolIDNameMap(
+		ctx,
+		location.OrganisationID,
+	)
+	if err != nil {
+		return nil, err
 	}
 
-	return checkInDto, nil
+	return dtos.NewCheckInDto(*checkIn, schoolIDNameMap[checkIn.SchoolID]), nil
 }
 
 func (service LocationService) NewCheckIn(location models.Location) {
 	location.Available--
+	location.Occupancy++
+	location.CheckInsToday++
+	service.websocket.NewLocationState(location)
+}
+
+func (service LocationService) NewLocationState(location models.Location) {
 	service.websocket.NewLocationState(location)
 }
 
-func (service LocationService) GetTotalCount(ctx context.Context) (*int64, error) {
-	return service.locations.GetTotalCount(ctx)
+func (service LocationService) GetTotalCount(
+	ctx context.Context,
+	user *models.User,
+) (*int64, error) {
+	return service.locations.GetTotalCount(
+		ctx,
+		user.OrganisationID,
+		managerIDOf(user),
+	)
 }
 
 func (service LocationService) GetAll(
@@ -327,7 +1238,20 @@ func (service LocationService) GetAll(
 	user *models.User,
 	allowAnonymous bool,
 ) ([]*models.Location, error) {
-	locations, err := service.locations.GetAll(ctx)
+	return service.getAll(ctx, user.OrganisationID, user, allowAnonymous)
+}
+
+func (service LocationService) getAll(
+	ctx context.Context,
+	organisationID string,
+	user *models.User,
+	allowAnonymous bool,
+) ([]*models.Location, error) {
+	locations, err := service.locations.GetAll(
+		ctx,
+		organisationID,
+		managerIDOf(user),
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -339,6 +1263,7 @@ func (service LocationService) GetAll(
 
 	checkInsToday, _, err := service.GetAllCheckInsOfDay(
 		ctx,
+		organisationID,
 		user,
 		allowAnonymous,
 		locationIDs,
@@ -350,6 +1275,7 @@ func (service LocationService) GetAll(
 
 	checkInsYesterday, _, err := service.GetAllCheckInsOfDay(
 		ctx,
+		organisationID,
 		user,
 		allowAnonymous,
 		locationIDs,
@@ -359,6 +1285,11 @@ func (service LocationService) GetAll(
 		return nil, err
 	}
 
+	err = service.setLiveFields(ctx, locations...)
+	if err != nil {
+		return nil, err
+	}
+
 	for i := range locations {
 		err = locations[i].SetFields(checkInsToday, checkInsYesterday)
 		if err != nil {
@@ -371,8 +1302,9 @@ func (service LocationService) GetAll(
 
 func (service LocationService) GetAllStates(
 	ctx context.Context,
+	organisationID string,
 ) ([]dtos.LocationStateDto, error) {
-	locations, err := service.GetAll(ctx, nil, true)
+	locations, err := service.getAll(ctx, organisationID, nil, true)
 	if err != nil {
 		return nil, err
 	}
@@ -391,7 +1323,13 @@ func (service LocationService) GetAllPaginated(
 	limit int64,
 	offset int64,
 ) ([]*models.Location, error) {
-	locations, err := service.locations.GetAllPaginated(ctx, limit, offset)
+	locations, err := service.locations.GetAllPaginated(
+		ctx,
+		user.OrganisationID,
+		managerIDOf(user),
+		limit,
+		offset,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -403,6 +1341,7 @@ func (service LocationService) GetAllPaginated(
 
 	checkInsToday, _, err := service.GetAllCheckInsOfDay(
 		ctx,
+		user.OrganisationID,
 		user,
 		false,
 		locationIDs,
@@ -414,6 +1353,7 @@ func (service LocationService) GetAllPaginated(
 
 	checkInsYesterday, _, err := service.GetAllCheckInsOfDay(
 		ctx,
+		user.OrganisationID,
 		user,
 		false,
 		locationIDs,
@@ -423,6 +1363,11 @@ func (service LocationService) GetAllPaginated(
 		return nil, err
 	}
 
+	err = service.setLiveFields(ctx, locations...)
+	if err != nil {
+		return nil, err
+	}
+
 	for i := range locations {
 		err = locations[i].SetFields(checkInsToday, checkInsYesterday)
 		if err != nil {
@@ -435,9 +1380,10 @@ func (service LocationService) GetAllPaginated(
 
 func (service LocationService) getByIDs(
 	ctx context.Context,
+	organisationID string,
 	ids []string,
 ) ([]*models.Location, error) {
-	return service.locations.GetByIDs(ctx, ids)
+	return service.locations.GetByIDs(ctx, organisationID, ids)
 }
 
 func (service LocationService) GetByID(
@@ -445,7 +1391,7 @@ func (service LocationService) GetByID(
 	user *models.User,
 	id string,
 ) (*models.Location, error) {
-	location, err := service.locations.GetByID(ctx, id)
+	location, err := service.locations.GetByID(ctx, user.OrganisationID, id)
 	if err != nil {
 		if errors.Is(err, database.ErrResourceNotFound) {
 			return nil, errortools.NewNotFoundError("location", id, "id")
@@ -453,12 +1399,18 @@ func (service LocationService) GetByID(
 		return nil, err
 	}
 
-	if user.Role == models.DefaultRole && location.UserID != user.ID {
+	hasAccess, err := service.hasAccess(ctx, user, location)
+	if err != nil {
+		return nil, err
+	}
+
+	if !hasAccess {
 		return nil, errortools.NewNotFoundError("location", location.ID, "id")
 	}
 
 	checkInsToday, _, err := service.GetAllCheckInsOfDay(
 		ctx,
+		location.OrganisationID,
 		user,
 		false,
 		[]string{location.ID},
@@ -470,6 +1422,7 @@ func (service LocationService) GetByID(
 
 	checkInsYesterday, _, err := service.GetAllCheckInsOfDay(
 		ctx,
+		location.OrganisationID,
 		user,
 		false,
 		[]string{location.ID},
@@ -479,6 +1432,11 @@ func (service LocationService) GetByID(
 		return nil, err
 	}
 
+	err = service.setLiveFields(ctx, location)
+	if err != nil {
+		return nil, err
+	}
+
 	err = location.SetFields(checkInsToday, checkInsYesterday)
 	if err != nil {
 		return nil, err
@@ -487,17 +1445,45 @@ func (service LocationService) GetByID(
 	return location, nil
 }
 
+// hasAccess checks if a user can see a location. Default users only see
+// their own location and managers only see the locations assigned to them.
+func (service LocationService) hasAccess(
+	ctx context.Context,
+	user *models.User,
+	location *models.Location,
+) (bool, error) {
+	switch user.Role {
+	case models.DefaultRole:
+		return location.UserID == user.ID, nil
+	case models.ManagerRole:
+		return service.managerLocations.Exists(ctx, user.ID, location.ID)
+	default:
+		return true, nil
+	}
+}
+
+// managerIDOf returns the ID of the user when it is a manager,
+// otherwise locations aren't filtered on their assignment.
+func managerIDOf(user *models.User) string {
+	if user != nil && user.Role == models.ManagerRole {
+		return user.ID
+	}
+
+	return ""
+}
+
 func (service LocationService) GetByUser(
 	ctx context.Context,
 	user *models.User,
 ) (*models.Location, error) {
-	location, err := service.locations.GetByUserID(ctx, user.ID)
+	location, err := service.locations.GetByUserID(ctx, user.OrganisationID, user.ID)
 	if err != nil {
 		return nil, err
 	}
 
 	checkInsToday, _, err := service.GetAllCheckInsOfDay(
 		ctx,
+		location.OrganisationID,
 		user,
 		false,
 		[]string{location.ID},
@@ -509,6 +1495,7 @@ func (service LocationService) GetByUser(
 
 	checkInsYesterday, _, err := service.GetAllCheckInsOfDay(
 		ctx,
+		location.OrganisationID,
 		user,
 		false,
 		[]string{location.ID},
@@ -518,6 +1505,11 @@ func (service LocationService) GetByUser(
 		return nil, err
 	}
 
+	err = service.setLiveFields(ctx, location)
+	if err != nil {
+		return nil, err
+	}
+
 	err = location.SetFields(checkInsToday, checkInsYesterday)
 	if err != nil {
 		return nil, err
@@ -528,9 +1520,10 @@ func (service LocationService) GetByUser(
 
 func (service LocationService) GetDefaultUserByUserID(
 	ctx context.Context,
+	organisationID string,
 	id string,
 ) (*models.User, error) {
-	user, err := service.users.GetByID(ctx, id, models.DefaultRole)
+	user, err := service.users.GetByID(ctx, organisationID, id, models.DefaultRole)
 	if err != nil {
 		return nil, err
 	}
@@ -546,12 +1539,14 @@ func (service LocationService) GetDefaultUserByUserID(
 	return user, nil
 }
 
+// GetByName looks at all locations of the organisation of the user,
+// also the ones which aren't assigned to the user.
 func (service LocationService) GetByName(
 	ctx context.Context,
 	user *models.User,
 	name string,
 ) (*models.Location, error) {
-	locations, err := service.GetAll(ctx, user, false)
+	locations, err := service.getAll(ctx, user.OrganisationID, nil, true)
 	if err != nil {
 		return nil, err
 	}
@@ -582,76 +1577,129 @@ func (service LocationService) Create(
 		return nil, err
 	}
 
-	defaultUser, err := service.users.Create(ctx, dtos.CreateUserDto{
-		Username: createLocationDto.Username,
-		Password: createLocationDto.Password,
-	}, models.DefaultRole)
-	if err != nil {
-		return nil, err
+	businessDayStart := defaultBusinessDayStart
+	if createLocationDto.BusinessDayStart != nil {
+		businessDayStart = *createLocationDto.BusinessDayStart
 	}
 
-	location, err := service.locations.Create(
-		ctx,
-		createLocationDto.Name,
-		createLocationDto.Capacity,
-		createLocationDto.TimeZone,
-		defaultUser.ID,
-	)
+	var location *models.Location
+	err = service.audit.InTransaction(ctx, func(ctx context.Context) error {
+		var defaultUser *models.User
+		defaultUser, err = service.users.Create(
+			ctx,
+			user.OrganisationID,
+			dtos.CreateUserDto{
+				Username: createLocationDto.Username,
+				Password: createLocationDto.Password,
+			},
+			models.DefaultRole,
+		)
+		if err != nil {
+			return err
+		}
+
+		location, err = service.locations.Create(
+			ctx,
+			user.OrganisationID,
+			createLocationDto.Name,
+			createLocationDto.Capacity,
+			createLocationDto.TimeZone,
+			businessDayStart,
+			defaultUser.ID,
+		)
+		if err != nil {
+			return err
+		}
+
+		_, err = service.capacities.Create(
+			ctx,
+			location.ID,
+			location.Capacity,
+			service.getTimeNowUTC(),
+		)
+		if err != nil {
+			return err
+		}
+
+		if user.Role == models.ManagerRole {
+			err = service.managerLocations.Create(ctx, user.ID, location.ID)
+			if err != nil {
+				return err
+			}
+		}
+
+		err = service.setFields(ctx, user, location)
+		if err != nil {
+			return err
+		}
+
+		return service.audit.Record(
+			ctx,
+			models.AuditCreate,
+			"location",
+			location.ID,
+			nil,
+			location,
+		)
+	})
 	if err != nil {
-		_, err2 := service.users.Delete(ctx, defaultUser.ID, models.DefaultRole)
-		if err2 != nil {
-			return nil, err2
+		// location names are unique within an organisation
+		if errors.Is(err, database.ErrResourceConflict) {
+			return nil, errortools.NewConflictError(
+				"location",
+				createLocationDto.Name,
+				"name",
+			)
 		}
 
 		return nil, err
 	}
 
+	err = service.websocket.AddLocation(location)
+	if err != nil {
+		return nil, err
+	}
+
+	return location, nil
+}
+
+// setFields sets the fields of a location
+// which depend on its check-ins and waitlist.
+func (service LocationService) setFields(
+	ctx context.Context,
+	user *models.User,
+	location *models.Location,
+) error {
 	checkInsToday, _, err := service.GetAllCheckInsOfDay(
 		ctx,
+		location.OrganisationID,
 		user,
 		false,
 		[]string{location.ID},
 		service.getTimeNowUTC(),
 	)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
 	checkInsYesterday, _, err := service.GetAllCheckInsOfDay(
 		ctx,
+		location.OrganisationID,
 		user,
 		false,
 		[]string{location.ID},
 		service.getTimeNowUTC().Add(-24*time.Hour),
 	)
 	if err != nil {
-		return nil, err
-	}
-
-	err = location.SetFields(checkInsToday, checkInsYesterday)
-	if err != nil {
-		return nil, err
+		return err
 	}
 
-	err = service.websocket.AddLocation(location)
+	err = service.setLiveFields(ctx, location)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	return location, nil
-}
-
-func (service LocationService) Recreate(
-	ctx context.Context,
-	location *models.Location,
-) (*models.Location, error) {
-	return service.locations.Create(
-		ctx,
-		location.Name,
-		location.Capacity,
-		location.TimeZone,
-		location.UserID,
-	)
+	return location.SetFields(checkInsToday, checkInsYesterday)
 }
 
 func (service LocationService) checkForConflictsOnCreate(
@@ -700,54 +1748,76 @@ func (service LocationService) Update(
 		return nil, err
 	}
 
-	location, err := service.locations.Update(ctx, *oldLocation, updateLocationDto)
-	if err != nil {
-		return nil, err
-	}
+	var location *models.Location
+	err = service.audit.InTransaction(ctx, func(ctx context.Context) error {
+		location, err = service.locations.Update(ctx, *oldLocation, updateLocationDto)
+		if err != nil {
+			return err
+		}
 
-	_, err = service.users.Update(ctx, location.UserID, dtos.UpdateUserDto{
-		Username: updateLocationDto.Username,
-		Password: updateLocationDto.Password,
-	}, models.DefaultRole)
-	if err != nil {
-		//nolint:exhaustruct //other fields are optional
-		_, err2 := service.locations.Update(ctx, *location, dtos.UpdateLocationDto{
-			Name:     &oldLocation.Name,
-			Capacity: &oldLocation.Capacity,
-			TimeZone: &oldLocation.TimeZone,
-		})
-		if err2 != nil {
-			return nil, err2
+		_, err = service.users.Update(
+			ctx,
+			location.OrganisationID,
+			location.UserID,
+			dtos.UpdateUserDto{
+				Username:          updateLocationDto.Username,
+				Password:          updateLocationDto.Password,
+				TwoFactorRequired: nil,
+			},
+			models.DefaultRole,
+		)
+		if err != nil {
+			return err
 		}
 
-		return nil, err
-	}
+		if updateLocationDto.Capacity != nil {
+			_, err = service.capacities.Create(
+				ctx,
+				location.ID,
+				location.Capacity,
+				service.getTimeNowUTC(),
+			)
+			if err != nil {
+				return err
+			}
+		}
 
-	checkInsToday, _, err := service.GetAllCheckInsOfDay(
-		ctx,
-		user,
-		false,
-		[]string{location.ID},
-		service.getTimeNowUTC(),
-	)
-	if err != nil {
-		return nil, err
-	}
+		err = service.setFields(ctx, user, location)
+		if err != nil {
+			return err
+		}
 
-	checkInsYesterday, _, err := service.GetAllCheckInsOfDay(
-		ctx,
-		user,
-		false,
-		[]string{location.ID},
-		service.getTimeNowUTC().Add(-24*time.Hour),
-	)
+		return service.audit.Record(
+			ctx,
+			models.AuditUpdate,
+			"location",
+			location.ID,
+			oldLocation,
+			location,
+		)
+	})
 	if err != nil {
+		// location names are unique within an organisation
+		if errors.Is(err, database.ErrResourceConflict) {
+			return nil, errortools.NewConflictError(
+				"location",
+				*updateLocationDto.Name,
+				"name",
+			)
+		}
 		return nil, err
 	}
 
-	err = location.SetFields(checkInsToday, checkInsYesterday)
-	if err != nil {
-		return nil, err
+	if updateLocationDto.Capacity != nil {
+		err = service.PromoteWaitlist(ctx, location)
+		if err != nil {
+			return nil, err
+		}
+
+		err = service.setFields(ctx, user, location)
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	if updateLocationDto.Name != nil {
@@ -814,20 +1884,98 @@ func (service LocationService) Delete(
 		return nil, err
 	}
 
-	err = service.locations.Delete(ctx, location)
+	err = service.audit.InTransaction(ctx, func(ctx context.Context) error {
+		err = service.locations.Archive(ctx, location)
+		if err != nil {
+			return err
+		}
+
+		_, err = service.users.Delete(
+			ctx,
+			location.OrganisationID,
+			location.UserID,
+			models.DefaultRole,
+		)
+		if err != nil {
+			return err
+		}
+
+		return service.audit.Record(
+			ctx,
+			models.AuditDelete,
+			"location",
+			location.ID,
+			location,
+			nil,
+		)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = service.websocket.DeleteLocation(location)
 	if err != nil {
 		return nil, err
 	}
 
-	_, err = service.users.Delete(ctx, location.UserID, models.DefaultRole)
+	return location, nil
+}
+
+// Restore makes an archived location and its default user visible again.
+func (service LocationService) Restore(
+	ctx context.Context,
+	user *models.User,
+	id string,
+) (*models.Location, error) {
+	var location *models.Location
+	err := service.audit.InTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		location, err = service.locations.Restore(ctx, user.OrganisationID, id)
+		if err != nil {
+			return err
+		}
+
+		_, err = service.users.Restore(
+			ctx,
+			location.OrganisationID,
+			location.UserID,
+			models.DefaultRole,
+		)
+		if err != nil {
+			return err
+		}
+
+		location, err = service.GetByID(ctx, user, location.ID)
+		if err != nil {
+			return err
+		}
+
+		return service.audit.Record(
+			ctx,
+			models.AuditRestore,
+			"location",
+			location.ID,
+			nil,
+			location,
+		)
+	})
 	if err != nil {
-		_, err2 := service.Recreate(ctx, location)
-		if err2 != nil {
-			return nil, err2
+		switch {
+		case errors.Is(err, database.ErrResourceNotFound):
+			return nil, errortools.NewNotFoundError("location", id, "id")
+		case errors.Is(err, database.ErrResourceConflict):
+			return nil, errortools.NewBadRequestError(
+				errors.New("location can't be restored as its name is in use"),
+			)
+		default:
+			return nil, err
 		}
+	}
 
+	err = service.websocket.AddLocation(location)
+	if err != nil {
 		return nil, err
 	}
 
-	return location, service.websocket.DeleteLocation(location)
+	return location, nil
 }
@@ -0,0 +1,205 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/XDoubleU/essentia/pkg/database"
+	errortools "github.com/XDoubleU/essentia/pkg/errors"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"check-in/api/internal/models"
+	"check-in/api/internal/repositories"
+	"check-in/api/internal/shared"
+)
+
+const (
+	// maxFailedAttempts are allowed before a username or IP is locked out,
+	// every further failed attempt doubles the duration of the lockout.
+	maxFailedAttempts   = 5
+	baseLockoutDuration = 30 * time.Second
+	maxLockoutDuration  = time.Hour
+	// failedAttemptsWindow is how long failed attempts are remembered.
+	failedAttemptsWindow = 24 * time.Hour
+)
+
+// LockedOutError is returned when signing in while locked out.
+type LockedOutError struct {
+	RetryAfter time.Duration
+}
+
+func (err LockedOutError) Error() string {
+	return "too many failed sign in attempts, try again later"
+}
+
+type LockoutService struct {
+	lockouts      repositories.LockoutRepository
+	audit         AuditService
+	getTimeNowUTC shared.UTCNowTimeProvider
+}
+
+// GetAll only returns the lockouts which can't be attributed
+// to any organisation to super admins.
+func (service LockoutService) GetAll(
+	ctx context.Context,
+	user *models.User,
+) ([]*models.Lockout, error) {
+	return service.lockouts.GetAll(
+		ctx,
+		user.OrganisationID,
+		user.Role == models.SuperAdminRole,
+	)
+}
+
+// Check returns a LockedOutError when any of the keys is locked out.
+func (service LockoutService) Check(
+	ctx context.Context,
+	keys ...models.LockoutKey,
+) error {
+	var retryAfter time.Duration
+
+	for _, key := range keys {
+		lockedUntil, err := service.lockouts.GetLockedUntil(ctx, key)
+		if err != nil {
+			return err
+		}
+
+		if !lockedUntil.Valid {
+			continue
+		}
+
+		retryAfter = max(retryAfter, lockedUntil.Time.Sub(service.getTimeNowUTC()))
+	}
+
+	if retryAfter > 0 {
+		return LockedOutError{RetryAfter: retryAfter}
+	}
+
+	return nil
+}
+
+// RegisterFailure counts a failed sign in attempt for all keys
+// and locks out the keys which failed too many times.
+func (service LockoutService) RegisterFailure(
+	ctx context.Context,
+	organisationID pgtype.Text,
+	keys ...models.LockoutKey,
+) error {
+	now := service.getTimeNowUTC()
+
+	for _, key := range keys {
+		lockout, err := service.lockouts.RegisterFailure(
+			ctx,
+			key,
+			organisationID,
+			now.Add(-failedAttemptsWindow),
+		)
+		if err != nil {
+			return err
+		}
+
+		if lockout.FailedAttempts < maxFailedAttempts {
+			continue
+		}
+
+		//nolint:exhaustruct //other fields are optional
+		lockout.LockedUntil = pgtype.Timestamptz{
+			Time:  now.Add(lockoutDuration(lockout.FailedAttempts)),
+			Valid: true,
+		}
+
+		err = service.audit.InTransaction(ctx, func(ctx context.Context) error {
+			err = service.lockouts.Lock(ctx, lockout)
+			if err != nil {
+				return err
+			}
+
+			return service.audit.RecordForOrganisation(
+				ctx,
+				lockout.OrganisationID,
+				models.AuditLockout,
+				"lockout",
+				lockoutID(key),
+				nil,
+				lockout,
+			)
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Reset forgets the failed sign in attempts of the keys.
+func (service LockoutService) Reset(
+	ctx context.Context,
+	keys ...models.LockoutKey,
+) error {
+	for _, key := range keys {
+		err := service.lockouts.Reset(ctx, key)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Delete only clears lockouts which can't be attributed
+// to any organisation for super admins.
+func (service LockoutService) Delete(
+	ctx context.Context,
+	user *models.User,
+	key models.LockoutKey,
+) (*models.Lockout, error) {
+	var lockout *models.Lockout
+	err := service.audit.InTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		lockout, err = service.lockouts.Delete(
+			ctx,
+			user.OrganisationID,
+			user.Role == models.SuperAdminRole,
+			key,
+		)
+		if err != nil {
+			return err
+		}
+
+		return service.audit.Record(
+			ctx,
+			models.AuditDelete,
+			"lockout",
+			lockoutID(key),
+			lockout,
+			nil,
+		)
+	})
+	if err != nil {
+		if errors.Is(err, database.ErrResourceNotFound) {
+			return nil, errortools.NewNotFoundError("lockout", key.Value, "value")
+		}
+		return nil, err
+	}
+
+	return lockout, nil
+}
+
+// lockoutDuration doubles for every failed attempt past maxFailedAttempts.
+func lockoutDuration(failedAttempts int64) time.Duration {
+	duration := baseLockoutDuration
+	for range failedAttempts - maxFailedAttempts {
+		duration *= 2
+		if duration >= maxLockoutDuration {
+			return maxLockoutDuration
+		}
+	}
+
+	return duration
+}
+
+func lockoutID(key models.LockoutKey) string {
+	return string(key.Kind) + ":" + key.Value
+}
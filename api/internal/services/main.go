@@ -10,12 +10,21 @@ import (
 )
 
 type Services struct {
+	APIKeys        APIKeyService
+	Audit          AuditService
 	Auth           AuthService
 	CheckInsWriter CheckInWriterService
+	Devices        DeviceService
 	Locations      LocationService
+	Lockouts       LockoutService
+	OIDC           OIDCService
+	Organisations  OrganisationService
+	Roles          RoleService
 	Schools        SchoolService
+	Sessions       SessionService
 	Users          UserService
 	State          StateService
+	TwoFactor      TwoFactorService
 	WebSocket      *WebSocketService
 }
 
@@ -27,52 +36,138 @@ func New(
 	utcNowTimeProvider shared.UTCNowTimeProvider,
 ) Services {
 	websocket := NewWebSocketService(logger, []string{config.WebURL})
-	state := NewStateService(ctx, logger, repositories.State, websocket)
+	audit := AuditService{
+		audit:        repositories.Audit,
+		transactions: repositories.Transactions,
+	}
+	state := NewStateService(
+		logger,
+		repositories.State,
+		audit,
+		websocket,
+		utcNowTimeProvider,
+	)
 
+	apiKeys := APIKeyService{
+		apiKeys:       repositories.APIKeys,
+		audit:         audit,
+		getTimeNowUTC: utcNowTimeProvider,
+	}
 	users := UserService{
 		users: repositories.Users,
+		audit: audit,
+	}
+	sessions := SessionService{
+		sessions:      repositories.Sessions,
+		users:         users,
+		audit:         audit,
+		getTimeNowUTC: utcNowTimeProvider,
+	}
+	roles := RoleService{
+		roles: repositories.Roles,
+		users: repositories.Users,
+		audit: audit,
 	}
 	schools := SchoolService{
-		schools:         repositories.Schools,
-		schoolIDNameMap: make(map[int64]string),
+		schools:          repositories.Schools,
+		audit:            audit,
+		schoolIDNameMaps: make(map[string]map[int64]string),
 	}
 	locations := LocationService{
-		locations:     repositories.Locations,
-		checkins:      repositories.CheckIns,
-		schools:       schools,
+		logger:           logger,
+		locations:        repositories.Locations,
+		managerLocations: repositories.ManagerLocations,
+		checkins:         repositories.CheckIns,
+		openingHours:     repositories.OpeningHours,
+		capacities:       repositories.Capacities,
+		waitlist:         repositories.Waitlist,
+		audit:            audit,
+		schools:          schools,
+		users:            users,
+		websocket:        websocket,
+		getTimeNowUTC:    utcNowTimeProvider,
+	}
+	devices := DeviceService{
+		devices:       repositories.Devices,
+		pairingCodes:  repositories.PairingCodes,
+		auth:          repositories.Auth,
+		audit:         audit,
+		locations:     locations,
+		webURL:        config.WebURL,
+		getTimeNowUTC: utcNowTimeProvider,
+	}
+	lockouts := LockoutService{
+		lockouts:      repositories.Lockouts,
+		audit:         audit,
+		getTimeNowUTC: utcNowTimeProvider,
+	}
+	twoFactor := TwoFactorService{
+		twoFactor:     repositories.TwoFactor,
 		users:         users,
-		websocket:     websocket,
+		audit:         audit,
 		getTimeNowUTC: utcNowTimeProvider,
 	}
 	auth := AuthService{
 		auth:          repositories.Auth,
+		devices:       devices,
+		lockouts:      lockouts,
+		twoFactor:     twoFactor,
 		users:         users,
 		locations:     locations,
 		getTimeNowUTC: utcNowTimeProvider,
 	}
 	checkInsWriter := CheckInWriterService{
-		checkins:  repositories.CheckInsWriter,
-		locations: locations,
-		schools:   schools,
+		checkins:      repositories.CheckInsWriter,
+		waitlist:      repositories.Waitlist,
+		audit:         audit,
+		locations:     locations,
+		schools:       schools,
+		getTimeNowUTC: utcNowTimeProvider,
 	}
 
-	err := locations.InitializeWS(ctx)
-	if err != nil {
-		panic(err)
+	organisations := OrganisationService{
+		organisations: repositories.Organisations,
+		states:        repositories.State,
+		audit:         audit,
+		state:         state,
+		locations:     locations,
+		users:         users,
 	}
 
-	err = state.InitializeWS(ctx)
+	oidc := NewOIDCService(
+		logger,
+		config.OIDC,
+		repositories.OIDCLogins,
+		repositories.Users,
+		organisations,
+		audit,
+		utcNowTimeProvider,
+	)
+
+	err := organisations.InitializeWS(ctx)
 	if err != nil {
 		panic(err)
 	}
 
+	state.StartPolling(ctx)
+	locations.StartPolling(ctx)
+
 	return Services{
+		APIKeys:        apiKeys,
+		Audit:          audit,
 		Auth:           auth,
 		CheckInsWriter: checkInsWriter,
+		Devices:        devices,
 		Locations:      locations,
+		Lockouts:       lockouts,
+		OIDC:           oidc,
+		Organisations:  organisations,
+		Roles:          roles,
 		Schools:        schools,
+		Sessions:       sessions,
 		Users:          users,
 		State:          state,
+		TwoFactor:      twoFactor,
 		WebSocket:      websocket,
 	}
 }
@@ -0,0 +1,265 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"log/slog"
+	"slices"
+	"time"
+
+	"github.com/XDoubleU/essentia/pkg/database"
+	errortools "github.com/XDoubleU/essentia/pkg/errors"
+	"github.com/XDoubleU/essentia/pkg/logging"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"check-in/api/internal/config"
+	"check-in/api/internal/models"
+	"check-in/api/internal/oidc"
+	"check-in/api/internal/repositories"
+	"check-in/api/internal/shared"
+)
+
+// oidcLoginExpiry is how long users have to sign in at the issuer.
+const oidcLoginExpiry = 10 * time.Minute
+
+var errInvalidOIDCLogin = errors.New("invalid or expired single sign-on")
+
+// OIDCService signs in admins and managers with an OpenID Connect issuer.
+// Users are provisioned on their first sign in, their role is kept in
+// sync with their groups on every sign in.
+type OIDCService struct {
+	logger        *slog.Logger
+	provider      *oidc.Provider
+	config        config.OIDCConfig
+	oidcLogins    repositories.OIDCLoginRepository
+	users         repositories.UserRepository
+	organisations OrganisationService
+	audit         AuditService
+	getTimeNowUTC shared.UTCNowTimeProvider
+}
+
+func NewOIDCService(
+	logger *slog.Logger,
+	config config.OIDCConfig,
+	oidcLogins repositories.OIDCLoginRepository,
+	users repositories.UserRepository,
+	organisations OrganisationService,
+	audit AuditService,
+	getTimeNowUTC shared.UTCNowTimeProvider,
+) OIDCService {
+	var provider *oidc.Provider
+	if config.Issuer != "" {
+		provider = oidc.NewProvider(oidc.Config{
+			Issuer:       config.Issuer,
+			ClientID:     config.ClientID,
+			ClientSecret: config.ClientSecret,
+			RedirectURL:  config.RedirectURL,
+			Scopes:       config.Scopes,
+			GroupsClaim:  config.GroupsClaim,
+		})
+	}
+
+	return OIDCService{
+		logger:        logger,
+		provider:      provider,
+		config:        config,
+		oidcLogins:    oidcLogins,
+		users:         users,
+		organisations: organisations,
+		audit:         audit,
+		getTimeNowUTC: getTimeNowUTC,
+	}
+}
+
+func (service OIDCService) Enabled() bool {
+	return service.provider != nil
+}
+
+// Login starts a sign in and returns the URL of the issuer and the state,
+// which has to be returned to the callback by the same browser.
+func (service OIDCService) Login(
+	ctx context.Context,
+) (*models.OIDCLogin, string, error) {
+	state, err := oidc.RandomString()
+	if err != nil {
+		return nil, "", err
+	}
+
+	nonce, err := oidc.RandomString()
+	if err != nil {
+		return nil, "", err
+	}
+
+	codeVerifier, err := oidc.RandomString()
+	if err != nil {
+		return nil, "", err
+	}
+
+	login := &models.OIDCLogin{
+		State:        state,
+		CodeVerifier: codeVerifier,
+		Nonce:        nonce,
+		Expiry:       service.getTimeNowUTC().Add(oidcLoginExpiry),
+	}
+
+	authURL, err := service.provider.AuthCodeURL(ctx, state, nonce, codeVerifier)
+	if err != nil {
+		return nil, "", err
+	}
+
+	err = service.oidcLogins.Create(ctx, login, sha256.Sum256([]byte(state)))
+	if err != nil {
+		return nil, "", err
+	}
+
+	err = service.oidcLogins.DeleteExpired(ctx)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return login, authURL, nil
+}
+
+// Callback finishes a sign in and returns the provisioned user.
+func (service OIDCService) Callback(
+	ctx context.Context,
+	state string,
+	code string,
+) (*models.User, error) {
+	login, err := service.oidcLogins.Consume(ctx, sha256.Sum256([]byte(state)))
+	if err != nil {
+		if errors.Is(err, database.ErrResourceNotFound) {
+			return nil, errortools.NewUnauthorizedError(errInvalidOIDCLogin)
+		}
+		return nil, err
+	}
+
+	claims, err := service.provider.Exchange(
+		ctx,
+		code,
+		login.CodeVerifier,
+		login.Nonce,
+		service.getTimeNowUTC(),
+	)
+	if err != nil {
+		// the detail is only logged, it can reveal the setup of the issuer
+		service.logger.Warn(
+			"single sign-on failed at the issuer",
+			logging.ErrAttr(err),
+		)
+		return nil, errortools.NewUnauthorizedError(errInvalidOIDCLogin)
+	}
+
+	role, ok := service.roleForGroups(claims.Groups)
+	if !ok {
+		return nil, errortools.NewUnauthorizedError(
+			errors.New("user isn't a member of an admin or manager group"),
+		)
+	}
+
+	user, err := service.users.GetByOIDCSubject(ctx, claims.Subject)
+	if err != nil {
+		if errors.Is(err, database.ErrResourceNotFound) {
+			return service.provision(ctx, claims, role)
+		}
+		return nil, err
+	}
+
+	if user.Role == role {
+		return user, nil
+	}
+
+	oldUser := *user
+
+	err = service.audit.InTransaction(ctx, func(ctx context.Context) error {
+		user, err = service.users.SetRole(ctx, oldUser, role)
+		if err != nil {
+			return err
+		}
+
+		return service.audit.RecordForOrganisation(
+			ctx,
+			pgtype.Text{String: user.OrganisationID, Valid: true},
+			models.AuditUpdate,
+			"user",
+			user.ID,
+			oldUser,
+			user,
+		)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// roleForGroups returns the role of the groups of a user,
+// admin groups take precedence over manager groups.
+func (service OIDCService) roleForGroups(groups []string) (models.Role, bool) {
+	for _, group := range groups {
+		if slices.Contains(service.config.AdminGroups, group) {
+			return models.AdminRole, true
+		}
+	}
+
+	for _, group := range groups {
+		if slices.Contains(service.config.ManagerGroups, group) {
+			return models.ManagerRole, true
+		}
+	}
+
+	return "", false
+}
+
+func (service OIDCService) provision(
+	ctx context.Context,
+	claims *oidc.Claims,
+	role models.Role,
+) (*models.User, error) {
+	organisation, err := service.organisations.GetByName(
+		ctx,
+		service.config.Organisation,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	username := claims.PreferredUsername
+	if username == "" {
+		username = claims.Subject
+	}
+
+	var user *models.User
+	err = service.audit.InTransaction(ctx, func(ctx context.Context) error {
+		user, err = service.users.CreateForOIDCSubject(
+			ctx,
+			organisation.ID,
+			username,
+			role,
+			claims.Subject,
+		)
+		if err != nil {
+			return err
+		}
+
+		return service.audit.RecordForOrganisation(
+			ctx,
+			pgtype.Text{String: organisation.ID, Valid: true},
+			models.AuditCreate,
+			"user",
+			user.ID,
+			nil,
+			user,
+		)
+	})
+	if err != nil {
+		if errors.Is(err, database.ErrResourceConflict) {
+			return nil, errortools.NewConflictError("user", username, "username")
+		}
+		return nil, err
+	}
+
+	return user, nil
+}
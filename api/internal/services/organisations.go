@@ -0,0 +1,231 @@
+package services
+
+import (
+	"context"
+	"errors"
+
+	"github.com/XDoubleU/essentia/pkg/database"
+	errortools "github.com/XDoubleU/essentia/pkg/errors"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"check-in/api/internal/dtos"
+	"check-in/api/internal/models"
+	"check-in/api/internal/repositories"
+)
+
+type OrganisationService struct {
+	organisations repositories.OrganisationRepository
+	states        repositories.StateRepository
+	audit         AuditService
+	state         StateService
+	locations     LocationService
+	users         UserService
+}
+
+// InitializeWS adds the topics of every organisation.
+func (service OrganisationService) InitializeWS(ctx context.Context) error {
+	organisations, err := service.organisations.GetAll(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, organisation := range organisations {
+		err = service.addTopics(ctx, organisation.ID)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (service OrganisationService) addTopics(
+	ctx context.Context,
+	organisationID string,
+) error {
+	err := service.state.AddOrganisation(ctx, organisationID)
+	if err != nil {
+		return err
+	}
+
+	return service.locations.InitializeWS(ctx, organisationID)
+}
+
+func (service OrganisationService) GetTotalCount(
+	ctx context.Context,
+	_ *models.User,
+) (*int64, error) {
+	return service.organisations.GetTotalCount(ctx)
+}
+
+func (service OrganisationService) GetAllPaginated(
+	ctx context.Context,
+	_ *models.User,
+	limit int64,
+	offset int64,
+) ([]*models.Organisation, error) {
+	return service.organisations.GetAllPaginated(ctx, limit, offset)
+}
+
+func (service OrganisationService) GetByID(
+	ctx context.Context,
+	id string,
+) (*models.Organisation, error) {
+	organisation, err := service.organisations.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, database.ErrResourceNotFound) {
+			return nil, errortools.NewNotFoundError("organisation", id, "id")
+		}
+		return nil, err
+	}
+
+	return organisation, nil
+}
+
+func (service OrganisationService) GetByName(
+	ctx context.Context,
+	name string,
+) (*models.Organisation, error) {
+	organisation, err := service.organisations.GetByName(ctx, name)
+	if err != nil {
+		if errors.Is(err, database.ErrResourceNotFound) {
+			return nil, errortools.NewNotFoundError("organisation", name, "name")
+		}
+		return nil, err
+	}
+
+	return organisation, nil
+}
+
+func (service OrganisationService) Create(
+	ctx context.Context,
+	organisationDto dtos.OrganisationDto,
+) (*models.Organisation, error) {
+	var organisation *models.Organisation
+	err := service.audit.InTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		organisation, err = service.organisations.Create(ctx, organisationDto.Name)
+		if err != nil {
+			return err
+		}
+
+		err = service.states.Create(ctx, organisation.ID)
+		if err != nil {
+			return err
+		}
+
+		return service.audit.Record(
+			ctx,
+			models.AuditCreate,
+			"organisation",
+			organisation.ID,
+			nil,
+			organisation,
+		)
+	})
+	if err != nil {
+		if errors.Is(err, database.ErrResourceConflict) {
+			return nil, errortools.NewConflictError(
+				"organisation",
+				organisationDto.Name,
+				"name",
+			)
+		}
+		return nil, err
+	}
+
+	err = service.addTopics(ctx, organisation.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return organisation, nil
+}
+
+func (service OrganisationService) Update(
+	ctx context.Context,
+	id string,
+	organisationDto dtos.OrganisationDto,
+) (*models.Organisation, error) {
+	organisation, err := service.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	oldOrganisation := *organisation
+
+	err = service.audit.InTransaction(ctx, func(ctx context.Context) error {
+		organisation, err = service.organisations.Update(
+			ctx,
+			*organisation,
+			organisationDto,
+		)
+		if err != nil {
+			return err
+		}
+
+		return service.audit.Record(
+			ctx,
+			models.AuditUpdate,
+			"organisation",
+			organisation.ID,
+			oldOrganisation,
+			organisation,
+		)
+	})
+	if err != nil {
+		if errors.Is(err, database.ErrResourceConflict) {
+			return nil, errortools.NewConflictError(
+				"organisation",
+				organisationDto.Name,
+				"name",
+			)
+		}
+		return nil, err
+	}
+
+	return organisation, nil
+}
+
+// CreateAdmin adds an admin to an organisation,
+// this is how a new organisation gets its first user.
+// Besides the creation of the user in the audit log of the super admin,
+// the organisation gets its own record of the admin it was given.
+func (service OrganisationService) CreateAdmin(
+	ctx context.Context,
+	id string,
+	createUserDto dtos.CreateUserDto,
+) (*models.User, error) {
+	var user *models.User
+	err := service.audit.InTransaction(ctx, func(ctx context.Context) error {
+		organisation, err := service.GetByID(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		user, err = service.users.Create(
+			ctx,
+			organisation.ID,
+			createUserDto,
+			models.AdminRole,
+		)
+		if err != nil {
+			return err
+		}
+
+		return service.audit.RecordForOrganisation(
+			ctx,
+			pgtype.Text{String: organisation.ID, Valid: true},
+			models.AuditCreate,
+			"organisationAdmin",
+			user.ID,
+			nil,
+			user,
+		)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
@@ -0,0 +1,252 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/XDoubleU/essentia/pkg/database"
+	errortools "github.com/XDoubleU/essentia/pkg/errors"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"check-in/api/internal/dtos"
+	"check-in/api/internal/models"
+	"check-in/api/internal/repositories"
+)
+
+type RoleService struct {
+	roles repositories.RoleRepository
+	users repositories.UserRepository
+	audit AuditService
+}
+
+func (service RoleService) GetAll(
+	ctx context.Context,
+	user *models.User,
+) ([]*models.RoleDefinition, error) {
+	return service.roles.GetAll(ctx, user.OrganisationID)
+}
+
+func (service RoleService) GetByID(
+	ctx context.Context,
+	user *models.User,
+	id string,
+) (*models.RoleDefinition, error) {
+	role, err := service.roles.GetByID(ctx, user.OrganisationID, id)
+	if err != nil {
+		if errors.Is(err, database.ErrResourceNotFound) {
+			return nil, errortools.NewNotFoundError("role", id, "id")
+		}
+		return nil, err
+	}
+
+	return role, nil
+}
+
+func (service RoleService) Create(
+	ctx context.Context,
+	user *models.User,
+	roleDto dtos.RoleDto,
+) (*models.RoleDefinition, error) {
+	err := checkRoleGrantable(user, roleDto.Permissions)
+	if err != nil {
+		return nil, err
+	}
+
+	var role *models.RoleDefinition
+	err = service.audit.InTransaction(ctx, func(ctx context.Context) error {
+		role, err = service.roles.Create(
+			ctx,
+			user.OrganisationID,
+			roleDto.Name,
+			roleDto.Permissions,
+		)
+		if err != nil {
+			return err
+		}
+
+		return service.audit.Record(
+			ctx,
+			models.AuditCreate,
+			"role",
+			role.ID,
+			nil,
+			role,
+		)
+	})
+	if err != nil {
+		if errors.Is(err, database.ErrResourceConflict) {
+			return nil, errortools.NewConflictError("role", roleDto.Name, "name")
+		}
+		return nil, err
+	}
+
+	return role, nil
+}
+
+func (service RoleService) Update(
+	ctx context.Context,
+	user *models.User,
+	id string,
+	roleDto dtos.RoleDto,
+) (*models.RoleDefinition, error) {
+	err := checkRoleGrantable(user, roleDto.Permissions)
+	if err != nil {
+		return nil, err
+	}
+
+	role, err := service.GetByID(ctx, user, id)
+	if err != nil {
+		return nil, err
+	}
+
+	oldRole := *role
+
+	err = service.audit.InTransaction(ctx, func(ctx context.Context) error {
+		role, err = service.roles.Update(ctx, *role, roleDto)
+		if err != nil {
+			return err
+		}
+
+		return service.audit.Record(
+			ctx,
+			models.AuditUpdate,
+			"role",
+			role.ID,
+			oldRole,
+			role,
+		)
+	})
+	if err != nil {
+		if errors.Is(err, database.ErrResourceConflict) {
+			return nil, errortools.NewConflictError("role", roleDto.Name, "name")
+		}
+		return nil, err
+	}
+
+	return role, nil
+}
+
+// Delete removes a custom role, its managers fall back
+// on the permissions of the manager role.
+func (service RoleService) Delete(
+	ctx context.Context,
+	user *models.User,
+	id string,
+) (*models.RoleDefinition, error) {
+	role, err := service.GetByID(ctx, user, id)
+	if err != nil {
+		return nil, err
+	}
+
+	err = service.audit.InTransaction(ctx, func(ctx context.Context) error {
+		err = service.roles.Delete(ctx, user.OrganisationID, role.ID)
+		if err != nil {
+			return err
+		}
+
+		return service.audit.Record(
+			ctx,
+			models.AuditDelete,
+			"role",
+			role.ID,
+			role,
+			nil,
+		)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return role, nil
+}
+
+// SetManagerRole assigns a custom role to a manager,
+// without a role ID the manager gets the permissions of the manager role.
+func (service RoleService) SetManagerRole(
+	ctx context.Context,
+	user *models.User,
+	managerID string,
+	userRoleDto dtos.UserRoleDto,
+) (*models.User, error) {
+	manager, err := service.users.GetByID(
+		ctx,
+		user.OrganisationID,
+		managerID,
+		models.ManagerRole,
+	)
+	if err != nil {
+		if errors.Is(err, database.ErrResourceNotFound) {
+			return nil, errortools.NewNotFoundError("user", managerID, "id")
+		}
+		return nil, err
+	}
+
+	//nolint:exhaustruct //other fields are optional
+	customRoleID := pgtype.Text{}
+	if userRoleDto.RoleID != nil {
+		var role *models.RoleDefinition
+
+		role, err = service.GetByID(ctx, user, *userRoleDto.RoleID)
+		if err != nil {
+			return nil, err
+		}
+
+		err = checkGrantable(user, role.Permissions)
+		if err != nil {
+			return nil, err
+		}
+
+		customRoleID = pgtype.Text{String: role.ID, Valid: true}
+	}
+
+	oldManager := *manager
+
+	err = service.audit.InTransaction(ctx, func(ctx context.Context) error {
+		manager, err = service.users.SetCustomRole(ctx, *manager, customRoleID)
+		if err != nil {
+			return err
+		}
+
+		return service.audit.Record(
+			ctx,
+			models.AuditUpdate,
+			"user",
+			manager.ID,
+			oldManager,
+			manager,
+		)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return manager, nil
+}
+
+// checkRoleGrantable prevents custom roles from holding permissions
+// which can't be part of a custom role.
+func checkRoleGrantable(user *models.User, permissions []models.Permission) error {
+	for _, permission := range permissions {
+		if !permission.IsGrantable() {
+			return errortools.NewBadRequestError(
+				fmt.Errorf("permission '%s' can't be granted", permission),
+			)
+		}
+	}
+
+	return checkGrantable(user, permissions)
+}
+
+// checkGrantable prevents users from granting permissions they don't have.
+func checkGrantable(user *models.User, permissions []models.Permission) error {
+	for _, permission := range permissions {
+		if !user.HasPermission(permission) {
+			return errortools.NewBadRequestError(
+				fmt.Errorf("permission '%s' can't be granted", permission),
+			)
+		}
+	}
+
+	return nil
+}
@@ -3,6 +3,7 @@ package services
 import (
 	"context"
 	"errors"
+	"strconv"
 
 	"github.com/XDoubleU/essentia/pkg/database"
 	errortools "github.com/XDoubleU/essentia/pkg/errors"
@@ -13,79 +14,122 @@ import (
 )
 
 type SchoolService struct {
-	schools         repositories.SchoolRepository
-	schoolIDNameMap map[int64]string
+	schools repositories.SchoolRepository
+	audit   AuditService
+	// schoolIDNameMaps holds a school ID to name map per organisation
+	schoolIDNameMaps map[string]map[int64]string
 }
 
-func (service SchoolService) GetTotalCount(ctx context.Context) (*int64, error) {
-	return service.schools.GetTotalCount(ctx)
+func (service SchoolService) GetTotalCount(
+	ctx context.Context,
+	user *models.User,
+) (*int64, error) {
+	return service.schools.GetTotalCount(ctx, user.OrganisationID)
 }
 
 func (service SchoolService) SchoolIDNameMap(
 	ctx context.Context,
+	organisationID string,
 ) (map[int64]string, error) {
-	if len(service.schoolIDNameMap) != 0 {
-		return service.schoolIDNameMap, nil
+	if schoolIDNameMap, ok := service.schoolIDNameMaps[organisationID]; ok {
+		return schoolIDNameMap, nil
 	}
 
-	schools, err := service.GetAll(ctx)
+	// archived schools are included as historic check-ins refer to them
+	schools, err := service.schools.GetAllIncludingArchived(ctx, organisationID)
 	if err != nil {
 		return nil, err
 	}
 
+	schoolIDNameMap := make(map[int64]string)
 	for _, school := range schools {
-		service.schoolIDNameMap[school.ID] = school.Name
+		schoolIDNameMap[school.ID] = school.Name
 	}
 
-	return service.schoolIDNameMap, nil
+	service.schoolIDNameMaps[organisationID] = schoolIDNameMap
+
+	return schoolIDNameMap, nil
 }
 
-func (service SchoolService) GetAll(ctx context.Context) ([]*models.School, error) {
-	return service.schools.GetAll(ctx)
+func (service SchoolService) setSchoolName(
+	organisationID string,
+	school *models.School,
+) {
+	if schoolIDNameMap, ok := service.schoolIDNameMaps[organisationID]; ok {
+		schoolIDNameMap[school.ID] = school.Name
+	}
+}
+
+func (service SchoolService) GetAll(
+	ctx context.Context,
+	organisationID string,
+) ([]*models.School, error) {
+	return service.schools.GetAll(ctx, organisationID)
 }
 
 func (service SchoolService) GetAllSortedByLocation(
 	ctx context.Context,
+	organisationID string,
 	locationID string,
 ) ([]*models.School, error) {
-	return service.schools.GetAllSortedByLocation(ctx, locationID)
+	return service.schools.GetAllSortedByLocation(ctx, organisationID, locationID)
 }
 
 func (service SchoolService) GetAllPaginated(
 	ctx context.Context,
-	_ *models.User,
+	user *models.User,
 	limit int64,
 	offset int64,
 ) ([]*models.School, error) {
-	return service.schools.GetAllPaginated(ctx, limit, offset)
+	return service.schools.GetAllPaginated(ctx, user.OrganisationID, limit, offset)
 }
 
 func (service SchoolService) GetByID(
 	ctx context.Context,
+	organisationID string,
 	id int64,
 ) (*models.School, error) {
-	return service.schools.GetByID(ctx, id)
+	return service.schools.GetByID(ctx, organisationID, id)
 }
 
 func (service SchoolService) GetByName(
 	ctx context.Context,
+	organisationID string,
 	name string,
 ) (*models.School, error) {
-	return service.schools.GetByName(ctx, name)
+	return service.schools.GetByName(ctx, organisationID, name)
 }
 
 func (service SchoolService) GetByIDWithoutReadOnly(
 	ctx context.Context,
+	organisationID string,
 	id int64,
 ) (*models.School, error) {
-	return service.schools.GetByIDWithoutReadOnly(ctx, id)
+	return service.schools.GetByIDWithoutReadOnly(ctx, organisationID, id)
 }
 
 func (service SchoolService) Create(
 	ctx context.Context,
+	organisationID string,
 	schoolDto dtos.SchoolDto,
 ) (*models.School, error) {
-	school, err := service.schools.Create(ctx, schoolDto.Name)
+	var school *models.School
+	err := service.audit.InTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		school, err = service.schools.Create(ctx, organisationID, schoolDto.Name)
+		if err != nil {
+			return err
+		}
+
+		return service.audit.Record(
+			ctx,
+			models.AuditCreate,
+			"school",
+			strconv.FormatInt(school.ID, 10),
+			nil,
+			school,
+		)
+	})
 	if err != nil {
 		if errors.Is(err, database.ErrResourceConflict) {
 			return nil, errortools.NewConflictError("school", schoolDto.Name, "name")
@@ -93,17 +137,18 @@ func (service SchoolService) Create(
 		return nil, err
 	}
 
-	service.schoolIDNameMap[school.ID] = school.Name
+	service.setSchoolName(organisationID, school)
 
 	return school, nil
 }
 
 func (service SchoolService) Update(
 	ctx context.Context,
+	organisationID string,
 	id int64,
 	schoolDto dtos.SchoolDto,
 ) (*models.School, error) {
-	school, err := service.GetByIDWithoutReadOnly(ctx, id)
+	school, err := service.GetByIDWithoutReadOnly(ctx, organisationID, id)
 	if err != nil {
 		if errors.Is(err, database.ErrResourceNotFound) {
 			return nil, errortools.NewNotFoundError("school", id, "id")
@@ -111,7 +156,23 @@ func (service SchoolService) Update(
 		return nil, err
 	}
 
-	school, err = service.schools.Update(ctx, *school, schoolDto)
+	oldSchool := *school
+
+	err = service.audit.InTransaction(ctx, func(ctx context.Context) error {
+		school, err = service.schools.Update(ctx, organisationID, *school, schoolDto)
+		if err != nil {
+			return err
+		}
+
+		return service.audit.Record(
+			ctx,
+			models.AuditUpdate,
+			"school",
+			strconv.FormatInt(school.ID, 10),
+			oldSchool,
+			school,
+		)
+	})
 	if err != nil {
 		if errors.Is(err, database.ErrResourceConflict) {
 			return nil, errortools.NewConflictError("school", schoolDto.Name, "name")
@@ -119,16 +180,17 @@ func (service SchoolService) Update(
 		return nil, err
 	}
 
-	service.schoolIDNameMap[school.ID] = school.Name
+	service.setSchoolName(organisationID, school)
 
 	return school, nil
 }
 
 func (service SchoolService) Delete(
 	ctx context.Context,
+	organisationID string,
 	id int64,
 ) (*models.School, error) {
-	school, err := service.GetByIDWithoutReadOnly(ctx, id)
+	school, err := service.GetByIDWithoutReadOnly(ctx, organisationID, id)
 	if err != nil {
 		if errors.Is(err, database.ErrResourceNotFound) {
 			return nil, errortools.NewNotFoundError("school", id, "id")
@@ -136,12 +198,62 @@ func (service SchoolService) Delete(
 		return nil, err
 	}
 
-	err = service.schools.Delete(ctx, id)
+	err = service.audit.InTransaction(ctx, func(ctx context.Context) error {
+		err = service.schools.Archive(ctx, organisationID, id)
+		if err != nil {
+			return err
+		}
+
+		return service.audit.Record(
+			ctx,
+			models.AuditDelete,
+			"school",
+			strconv.FormatInt(school.ID, 10),
+			school,
+			nil,
+		)
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	delete(service.schoolIDNameMap, school.ID)
+	return school, nil
+}
+
+func (service SchoolService) Restore(
+	ctx context.Context,
+	organisationID string,
+	id int64,
+) (*models.School, error) {
+	var school *models.School
+	err := service.audit.InTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		school, err = service.schools.Restore(ctx, organisationID, id)
+		if err != nil {
+			return err
+		}
+
+		return service.audit.Record(
+			ctx,
+			models.AuditRestore,
+			"school",
+			strconv.FormatInt(school.ID, 10),
+			nil,
+			school,
+		)
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, database.ErrResourceNotFound):
+			return nil, errortools.NewNotFoundError("school", id, "id")
+		case errors.Is(err, database.ErrResourceConflict):
+			return nil, errortools.NewBadRequestError(
+				errors.New("school can't be restored as its name is in use"),
+			)
+		default:
+			return nil, err
+		}
+	}
 
 	return school, nil
 }
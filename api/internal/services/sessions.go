@@ -0,0 +1,220 @@
+package services
+
+import (
+	"context"
+
+	errortools "github.com/XDoubleU/essentia/pkg/errors"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"check-in/api/internal/models"
+	"check-in/api/internal/repositories"
+	"check-in/api/internal/shared"
+)
+
+// maxUserAgentLength is the length of the user_agent column.
+const maxUserAgentLength = 512
+
+type SessionService struct {
+	sessions      repositories.SessionRepository
+	users         UserService
+	audit         AuditService
+	getTimeNowUTC shared.UTCNowTimeProvider
+}
+
+// Start creates a session for a sign in,
+// the tokens created for the user afterwards belong to it.
+func (service SessionService) Start(
+	ctx context.Context,
+	user *models.User,
+	userAgent string,
+	ip string,
+) error {
+	now := service.getTimeNowUTC()
+
+	session := &models.Session{
+		ID:         "",
+		UserID:     user.ID,
+		DeviceID:   user.DeviceID,
+		UserAgent:  truncateUserAgent(userAgent),
+		IP:         ip,
+		CreatedAt:  now,
+		LastUsedAt: now,
+		Expiry:     now,
+		Current:    true,
+	}
+
+	err := service.sessions.Create(ctx, session)
+	if err != nil {
+		return err
+	}
+
+	user.SessionID = pgtype.Text{String: session.ID, Valid: true}
+
+	return nil
+}
+
+// Touch records the use of the session of a user, if it has one.
+// The user agent is the one of the sign in.
+func (service SessionService) Touch(
+	ctx context.Context,
+	user *models.User,
+	ip string,
+) error {
+	if !user.SessionID.Valid {
+		return nil
+	}
+
+	return service.sessions.Touch(ctx, user.SessionID.String, ip)
+}
+
+func (service SessionService) GetAll(
+	ctx context.Context,
+	user *models.User,
+) ([]*models.Session, error) {
+	sessions, err := service.sessions.GetAll(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, session := range sessions {
+		session.Current = user.SessionID.Valid &&
+			session.ID == user.SessionID.String
+	}
+
+	return sessions, nil
+}
+
+// Delete signs out a session of a user.
+func (service SessionService) Delete(
+	ctx context.Context,
+	user *models.User,
+	id string,
+) (*models.Session, error) {
+	sessions, err := service.GetAll(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, session := range sessions {
+		if session.ID != id {
+			continue
+		}
+
+		err = service.sessions.Delete(ctx, user.ID, id)
+		if err != nil {
+			return nil, err
+		}
+
+		return session, nil
+	}
+
+	return nil, errortools.NewNotFoundError("session", id, "id")
+}
+
+// DeleteAll signs out a user everywhere, except on its devices.
+func (service SessionService) DeleteAll(
+	ctx context.Context,
+	user *models.User,
+) error {
+	return service.sessions.DeleteAll(ctx, user.ID)
+}
+
+func (service SessionService) DeleteExpired(ctx context.Context) error {
+	return service.sessions.DeleteExpired(ctx)
+}
+
+func (service SessionService) GetAllForUser(
+	ctx context.Context,
+	organisationID string,
+	userID string,
+) ([]*models.Session, error) {
+	user, err := service.users.GetByID(ctx, organisationID, userID, models.ManagerRole)
+	if err != nil {
+		return nil, err
+	}
+
+	return service.GetAll(ctx, user)
+}
+
+func (service SessionService) DeleteForUser(
+	ctx context.Context,
+	organisationID string,
+	userID string,
+	id string,
+) (*models.Session, error) {
+	user, err := service.users.GetByID(ctx, organisationID, userID, models.ManagerRole)
+	if err != nil {
+		return nil, err
+	}
+
+	var session *models.Session
+	err = service.audit.InTransaction(ctx, func(ctx context.Context) error {
+		session, err = service.Delete(ctx, user, id)
+		if err != nil {
+			return err
+		}
+
+		return service.audit.Record(
+			ctx,
+			models.AuditDelete,
+			"session",
+			session.ID,
+			session,
+			nil,
+		)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return session, nil
+}
+
+func (service SessionService) DeleteAllForUser(
+	ctx context.Context,
+	organisationID string,
+	userID string,
+) error {
+	user, err := service.users.GetByID(ctx, organisationID, userID, models.ManagerRole)
+	if err != nil {
+		return err
+	}
+
+	return service.audit.InTransaction(ctx, func(ctx context.Context) error {
+		var sessions []*models.Session
+		sessions, err = service.GetAll(ctx, user)
+		if err != nil {
+			return err
+		}
+
+		err = service.DeleteAll(ctx, user)
+		if err != nil {
+			return err
+		}
+
+		for _, session := range sessions {
+			err = service.audit.Record(
+				ctx,
+				models.AuditDelete,
+				"session",
+				session.ID,
+				session,
+				nil,
+			)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+func truncateUserAgent(userAgent string) string {
+	runes := []rune(userAgent)
+	if len(runes) <= maxUserAgentLength {
+		return userAgent
+	}
+
+	return string(runes[:maxUserAgentLength])
+}
@@ -7,12 +7,15 @@ import (
 	"sync"
 	"time"
 
+	errortools "github.com/XDoubleU/essentia/pkg/errors"
 	"github.com/XDoubleU/essentia/pkg/logging"
 	"github.com/XDoubleU/essentia/pkg/sentry"
+	"github.com/jackc/pgx/v5/pgtype"
 
 	"check-in/api/internal/dtos"
 	"check-in/api/internal/models"
 	"check-in/api/internal/repositories"
+	"check-in/api/internal/shared"
 )
 
 type CurrentState struct {
@@ -20,93 +23,125 @@ type CurrentState struct {
 	mu    *sync.RWMutex
 }
 
+// StateService keeps the current state of every organisation.
 type StateService struct {
-	logger    *slog.Logger
-	state     repositories.StateRepository
-	websocket *WebSocketService
-	Current   *CurrentState
+	logger        *slog.Logger
+	state         repositories.StateRepository
+	audit         AuditService
+	websocket     *WebSocketService
+	getTimeNowUTC shared.UTCNowTimeProvider
+	current       map[string]*CurrentState
+	mu            *sync.RWMutex
 }
 
 func NewStateService(
-	ctx context.Context,
 	logger *slog.Logger,
 	repo repositories.StateRepository,
+	audit AuditService,
 	websocket *WebSocketService,
+	utcNowTimeProvider shared.UTCNowTimeProvider,
 ) StateService {
-	//nolint:exhaustruct //Current is set later
-	service := StateService{
-		logger:    logger,
-		state:     repo,
-		websocket: websocket,
+	return StateService{
+		logger:        logger,
+		state:         repo,
+		audit:         audit,
+		websocket:     websocket,
+		getTimeNowUTC: utcNowTimeProvider,
+		current:       make(map[string]*CurrentState),
+		mu:            &sync.RWMutex{},
 	}
+}
 
-	state, err := service.get(ctx, true)
+// AddOrganisation loads the persisted state of an organisation
+// and adds its state topic.
+func (service StateService) AddOrganisation(
+	ctx context.Context,
+	organisationID string,
+) error {
+	state, err := service.state.Get(ctx, organisationID)
 	if err != nil {
-		panic(err)
+		return err
 	}
 
-	service.Current = &CurrentState{
+	state.IsDatabaseActive = service.state.IsDatabaseActive(ctx)
+
+	service.mu.Lock()
+	service.current[organisationID] = &CurrentState{
 		value: *state,
 		mu:    &sync.RWMutex{},
 	}
+	service.mu.Unlock()
 
-	return service
+	return service.websocket.AddStateTopic(
+		organisationID,
+		func(ctx context.Context) (*models.State, error) {
+			return service.get(ctx, organisationID)
+		},
+	)
 }
 
-func (service *StateService) InitializeWS(ctx context.Context) error {
-	err := service.websocket.SetStateTopic(
-		func(ctx context.Context) (*models.State, error) { return service.get(ctx, false) },
-	)
+func (service StateService) StartPolling(ctx context.Context) {
+	go service.startPolling(ctx, service.logger)
+}
+
+// Get returns the current state of an organisation.
+func (service StateService) Get(organisationID string) (*models.State, error) {
+	current, err := service.getCurrent(organisationID)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	go service.startPolling(ctx, service.logger)
-	return nil
+	state := current.Get()
+	return &state, nil
+}
+
+func (service StateService) getCurrent(organisationID string) (*CurrentState, error) {
+	service.mu.RLock()
+	defer service.mu.RUnlock()
+
+	current, ok := service.current[organisationID]
+	if !ok {
+		return nil, errortools.NewNotFoundError("organisation", organisationID, "id")
+	}
+
+	return current, nil
+}
+
+func (service StateService) organisationIDs() []string {
+	service.mu.RLock()
+	defer service.mu.RUnlock()
+
+	organisationIDs := []string{}
+	for organisationID := range service.current {
+		organisationIDs = append(organisationIDs, organisationID)
+	}
+
+	return organisationIDs
 }
 
-func (service *StateService) get(
+func (service StateService) get(
 	ctx context.Context,
-	fetchPersistentState bool,
+	organisationID string,
 ) (*models.State, error) {
-	var state models.State
-	var err error
-
-	if fetchPersistentState {
-		var newState *models.State
-		newState, err = service.state.Get(ctx)
-		if err != nil {
-			return nil, err
-		}
-		state = *newState
-	} else {
-		state = service.Current.Get()
+	state, err := service.Get(organisationID)
+	if err != nil {
+		return nil, err
 	}
 
 	state.IsDatabaseActive = service.state.IsDatabaseActive(ctx)
 
-	return &state, nil
+	return state, nil
 }
 
-func (service *StateService) startPolling(ctx context.Context, logger *slog.Logger) {
+func (service StateService) startPolling(ctx context.Context, logger *slog.Logger) {
 	sentry.GoRoutineWrapper(
 		ctx,
 		logger,
 		"State Polling",
 		func(ctx context.Context, logger *slog.Logger) error {
 			for ctx.Err() != context.Canceled {
-				newState, err := service.get(ctx, false)
-				if err != nil {
-					logger.Error(
-						"something went wrong while fetching current state",
-						logging.ErrAttr(err),
-					)
-					continue
-				}
-
-				_, changed := service.Current.update(*newState)
-				if changed {
-					service.websocket.NewAppState(*newState)
+				for _, organisationID := range service.organisationIDs() {
+					service.poll(ctx, logger, organisationID)
 				}
 
 				time.Sleep(10 * time.Second) //nolint:mnd //no magic number
@@ -116,31 +151,156 @@ func (service *StateService) startPolling(ctx context.Context, logger *slog.Logg
 	)
 }
 
-func (service *StateService) UpdateState(
+func (service StateService) poll(
 	ctx context.Context,
+	logger *slog.Logger,
+	organisationID string,
+) {
+	newState, err := service.get(ctx, organisationID)
+	if err != nil {
+		logger.Error(
+			"something went wrong while fetching current state",
+			logging.ErrAttr(err),
+		)
+		return
+	}
+
+	scheduledState := service.applyMaintenanceWindow(*newState)
+	if scheduledState != *newState {
+		err = service.persist(ctx, organisationID, scheduledState)
+		if err != nil {
+			logger.Error(
+				"something went wrong while applying maintenance window",
+				logging.ErrAttr(err),
+			)
+		}
+	}
+
+	current, err := service.getCurrent(organisationID)
+	if err != nil {
+		return
+	}
+
+	_, changed := current.update(scheduledState)
+	if changed {
+		service.websocket.NewAppState(organisationID, scheduledState)
+	}
+}
+
+func (service StateService) UpdateState(
+	ctx context.Context,
+	organisationID string,
 	stateDto dtos.StateDto,
 ) (*models.State, error) {
-	err := service.state.UpdateKey(
-		ctx,
-		models.IsMaintenanceKey,
-		strconv.FormatBool(stateDto.IsMaintenance),
-	)
+	current, err := service.getCurrent(organisationID)
 	if err != nil {
 		return nil, err
 	}
 
-	newState, changed := service.Current.update(models.State{
+	newState := service.applyMaintenanceWindow(models.State{
 		IsMaintenance:    stateDto.IsMaintenance,
 		IsDatabaseActive: service.state.IsDatabaseActive(ctx),
+		MaintenanceStart: toTimestamptz(stateDto.MaintenanceStart),
+		MaintenanceEnd:   toTimestamptz(stateDto.MaintenanceEnd),
+	})
+
+	oldState := current.Get()
+
+	err = service.audit.InTransaction(ctx, func(ctx context.Context) error {
+		err = service.persist(ctx, organisationID, newState)
+		if err != nil {
+			return err
+		}
+
+		return service.audit.Record(
+			ctx,
+			models.AuditUpdate,
+			"state",
+			organisationID,
+			oldState,
+			newState,
+		)
 	})
+	if err != nil {
+		return nil, err
+	}
+
+	newState, changed := current.update(newState)
 
 	if changed {
-		service.websocket.NewAppState(newState)
+		service.websocket.NewAppState(organisationID, newState)
 	}
 
 	return &newState, nil
 }
 
+// applyMaintenanceWindow flips IsMaintenance when a scheduled boundary
+// has passed. Boundaries are cleared once applied so manual changes made
+// during the window aren't overridden on the next poll.
+func (service StateService) applyMaintenanceWindow(state models.State) models.State {
+	now := service.getTimeNowUTC()
+
+	if state.MaintenanceStart.Valid && !now.Before(state.MaintenanceStart.Time) {
+		state.IsMaintenance = true
+		//nolint:exhaustruct //other fields are optional
+		state.MaintenanceStart = pgtype.Timestamptz{}
+	}
+
+	if state.MaintenanceEnd.Valid && !now.Before(state.MaintenanceEnd.Time) {
+		state.IsMaintenance = false
+		//nolint:exhaustruct //other fields are optional
+		state.MaintenanceEnd = pgtype.Timestamptz{}
+	}
+
+	return state
+}
+
+func (service StateService) persist(
+	ctx context.Context,
+	organisationID string,
+	state models.State,
+) error {
+	err := service.state.UpdateKey(
+		ctx,
+		organisationID,
+		models.IsMaintenanceKey,
+		strconv.FormatBool(state.IsMaintenance),
+	)
+	if err != nil {
+		return err
+	}
+
+	err = service.state.UpdateTimestampKey(
+		ctx,
+		organisationID,
+		models.MaintenanceStartKey,
+		state.MaintenanceStart,
+	)
+	if err != nil {
+		return err
+	}
+
+	return service.state.UpdateTimestampKey(
+		ctx,
+		organisationID,
+		models.MaintenanceEndKey,
+		state.MaintenanceEnd,
+	)
+}
+
+func toTimestamptz(value *time.Time) pgtype.Timestamptz {
+	if value == nil {
+		//nolint:exhaustruct //other fields are optional
+		return pgtype.Timestamptz{}
+	}
+
+	//nolint:exhaustruct //other fields are optional
+	return pgtype.Timestamptz{
+		Time:  value.UTC(),
+		Valid: true,
+	}
+}
+
 func (state *CurrentState) Get() models.State {
 	state.mu.RLock()
 	defer state.mu.RUnlock()
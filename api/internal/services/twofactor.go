@@ -0,0 +1,273 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"strings"
+
+	"github.com/XDoubleU/essentia/pkg/database"
+	errortools "github.com/XDoubleU/essentia/pkg/errors"
+
+	"check-in/api/internal/models"
+	"check-in/api/internal/repositories"
+	"check-in/api/internal/shared"
+	"check-in/api/internal/totp"
+)
+
+// twoFactorIssuer is the name authenticator apps show for the codes.
+const twoFactorIssuer = "Check-In"
+
+const (
+	recoveryCodeCount = 10
+	// recoveryCodeLength is the length of each half of a recovery code.
+	recoveryCodeLength = 5
+)
+
+// ErrTwoFactorPending is returned when signing in with valid credentials,
+// but without the code of a user with two-factor authentication enabled.
+var ErrTwoFactorPending = errors.New("two-factor code is required")
+
+type TwoFactorService struct {
+	twoFactor     repositories.TwoFactorRepository
+	users         UserService
+	audit         AuditService
+	getTimeNowUTC shared.UTCNowTimeProvider
+}
+
+// Enrol creates a new secret for a user, two-factor authentication is
+// only enabled once a code generated with this secret is verified.
+func (service TwoFactorService) Enrol(
+	ctx context.Context,
+	user *models.User,
+) (*models.TwoFactorEnrolment, error) {
+	if user.Role == models.DefaultRole || user.Role == models.APIKeyRole {
+		return nil, errortools.NewBadRequestError(
+			errors.New("two-factor authentication isn't available for this user"),
+		)
+	}
+
+	if user.TwoFactorEnabled {
+		return nil, errortools.NewBadRequestError(
+			errors.New("two-factor authentication is already enabled"),
+		)
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	err = service.twoFactor.SetSecret(ctx, user.ID, secret)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.TwoFactorEnrolment{
+		Secret: totp.EncodeSecret(secret),
+		URI:    totp.URI(twoFactorIssuer, user.Username, secret),
+	}, nil
+}
+
+// Enable verifies a code of a pending enrolment and returns
+// the recovery codes of the user.
+func (service TwoFactorService) Enable(
+	ctx context.Context,
+	user *models.User,
+	code string,
+) (*models.RecoveryCodes, error) {
+	twoFactor, err := service.twoFactor.Get(ctx, user.ID)
+	if err != nil {
+		if errors.Is(err, database.ErrResourceNotFound) {
+			return nil, errortools.NewBadRequestError(
+				errors.New("enrol in two-factor authentication first"),
+			)
+		}
+		return nil, err
+	}
+
+	if twoFactor.Enabled {
+		return nil, errortools.NewBadRequestError(
+			errors.New("two-factor authentication is already enabled"),
+		)
+	}
+
+	step, ok := totp.Validate(twoFactor.Secret, code, service.getTimeNowUTC())
+	if !ok {
+		return nil, errortools.NewBadRequestError(
+			errors.New("invalid two-factor code"),
+		)
+	}
+
+	recoveryCodes := make([]string, recoveryCodeCount)
+	recoveryCodeHashes := make([][32]byte, recoveryCodeCount)
+	for i := range recoveryCodes {
+		recoveryCodes[i], err = generateRecoveryCode()
+		if err != nil {
+			return nil, err
+		}
+
+		recoveryCodeHashes[i] = hashRecoveryCode(recoveryCodes[i])
+	}
+
+	updatedUser := *user
+	updatedUser.TwoFactorEnabled = true
+
+	err = service.audit.InTransaction(ctx, func(ctx context.Context) error {
+		err = service.twoFactor.Enable(ctx, user.ID, step, recoveryCodeHashes)
+		if err != nil {
+			return err
+		}
+
+		return service.audit.Record(
+			ctx,
+			models.AuditUpdate,
+			"user",
+			user.ID,
+			user,
+			updatedUser,
+		)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.RecoveryCodes{RecoveryCodes: recoveryCodes}, nil
+}
+
+// Disable turns off two-factor authentication of the user itself,
+// this requires a valid code. It can't be turned off when it's required.
+func (service TwoFactorService) Disable(
+	ctx context.Context,
+	user *models.User,
+	code string,
+) error {
+	if user.TwoFactorRequired {
+		return errortools.NewBadRequestError(
+			errors.New("two-factor authentication is required for this user"),
+		)
+	}
+
+	valid, err := service.Verify(ctx, user, code)
+	if err != nil {
+		return err
+	}
+
+	if !valid {
+		return errortools.NewBadRequestError(errors.New("invalid two-factor code"))
+	}
+
+	return service.disable(ctx, user)
+}
+
+// Reset turns off two-factor authentication of a manager,
+// e.g. when the manager lost its authenticator app and recovery codes.
+func (service TwoFactorService) Reset(
+	ctx context.Context,
+	organisationID string,
+	id string,
+) (*models.User, error) {
+	user, err := service.users.GetByID(ctx, organisationID, id, models.ManagerRole)
+	if err != nil {
+		return nil, err
+	}
+
+	if !user.TwoFactorEnabled {
+		return nil, errortools.NewBadRequestError(
+			errors.New("two-factor authentication isn't enabled"),
+		)
+	}
+
+	err = service.disable(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+
+	user.TwoFactorEnabled = false
+
+	return user, nil
+}
+
+// Verify checks a TOTP code or a recovery code of a user, both can only
+// be used once. It's false when two-factor authentication isn't enabled.
+func (service TwoFactorService) Verify(
+	ctx context.Context,
+	user *models.User,
+	code string,
+) (bool, error) {
+	twoFactor, err := service.twoFactor.Get(ctx, user.ID)
+	if err != nil {
+		if errors.Is(err, database.ErrResourceNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	if !twoFactor.Enabled {
+		return false, nil
+	}
+
+	if step, ok := totp.Validate(twoFactor.Secret, code, service.getTimeNowUTC()); ok {
+		err = service.twoFactor.UseStep(ctx, user.ID, step)
+	} else {
+		err = service.twoFactor.UseRecoveryCode(ctx, user.ID, hashRecoveryCode(code))
+	}
+
+	if err != nil {
+		if errors.Is(err, database.ErrResourceNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (service TwoFactorService) disable(
+	ctx context.Context,
+	user *models.User,
+) error {
+	updatedUser := *user
+	updatedUser.TwoFactorEnabled = false
+
+	err := service.audit.InTransaction(ctx, func(ctx context.Context) error {
+		err := service.twoFactor.Disable(ctx, user.ID)
+		if err != nil {
+			return err
+		}
+
+		return service.audit.Record(
+			ctx,
+			models.AuditUpdate,
+			"user",
+			user.ID,
+			user,
+			updatedUser,
+		)
+	})
+	if err != nil {
+		if errors.Is(err, database.ErrResourceNotFound) {
+			return errortools.NewBadRequestError(
+				errors.New("two-factor authentication isn't enabled"),
+			)
+		}
+		return err
+	}
+
+	return nil
+}
+
+func generateRecoveryCode() (string, error) {
+	code, err := generateCode(2 * recoveryCodeLength) //nolint:mnd //two halves
+	if err != nil {
+		return "", err
+	}
+
+	return code[:recoveryCodeLength] + "-" + code[recoveryCodeLength:], nil
+}
+
+// hashRecoveryCode ignores the case and the dash of the code.
+func hashRecoveryCode(code string) [32]byte {
+	code = strings.ToUpper(strings.ReplaceAll(code, "-", ""))
+	return sha256.Sum256([]byte(code))
+}
@@ -14,33 +14,39 @@ import (
 
 type UserService struct {
 	users repositories.UserRepository
+	audit AuditService
 }
 
-func (service UserService) GetTotalCount(ctx context.Context) (*int64, error) {
-	return service.users.GetTotalCount(ctx)
+func (service UserService) GetTotalCount(
+	ctx context.Context,
+	user *models.User,
+) (*int64, error) {
+	return service.users.GetTotalCount(ctx, user.OrganisationID)
 }
 
 func (service UserService) GetAll(
 	ctx context.Context,
+	organisationID string,
 ) ([]*models.User, error) {
-	return service.users.GetAll(ctx)
+	return service.users.GetAll(ctx, organisationID)
 }
 
 func (service UserService) GetAllPaginated(
 	ctx context.Context,
-	_ *models.User,
+	user *models.User,
 	limit int64,
 	offset int64,
 ) ([]*models.User, error) {
-	return service.users.GetAllPaginated(ctx, limit, offset)
+	return service.users.GetAllPaginated(ctx, user.OrganisationID, limit, offset)
 }
 
 func (service UserService) GetByID(
 	ctx context.Context,
+	organisationID string,
 	id string,
 	role models.Role,
 ) (*models.User, error) {
-	user, err := service.users.GetByID(ctx, id, role)
+	user, err := service.users.GetByID(ctx, organisationID, id, role)
 	if err != nil {
 		if errors.Is(err, database.ErrResourceNotFound) {
 			return nil, errortools.NewNotFoundError("user", id, "id")
@@ -60,6 +66,7 @@ func (service UserService) GetByUsername(
 
 func (service UserService) Create(
 	ctx context.Context,
+	organisationID string,
 	createUserDto dtos.CreateUserDto,
 	role models.Role,
 ) (*models.User, error) {
@@ -68,7 +75,28 @@ func (service UserService) Create(
 		return nil, err
 	}
 
-	user, err := service.users.Create(ctx, createUserDto.Username, passwordHash, role)
+	var user *models.User
+	err = service.audit.InTransaction(ctx, func(ctx context.Context) error {
+		user, err = service.users.Create(
+			ctx,
+			organisationID,
+			createUserDto.Username,
+			passwordHash,
+			role,
+		)
+		if err != nil {
+			return err
+		}
+
+		return service.audit.Record(
+			ctx,
+			models.AuditCreate,
+			"user",
+			user.ID,
+			nil,
+			user,
+		)
+	})
 	if err != nil {
 		if errors.Is(err, database.ErrResourceConflict) {
 			return nil, errortools.NewConflictError(
@@ -85,11 +113,12 @@ func (service UserService) Create(
 
 func (service UserService) Update(
 	ctx context.Context,
+	organisationID string,
 	id string,
 	updateUserDto dtos.UpdateUserDto,
 	role models.Role,
 ) (*models.User, error) {
-	user, err := service.GetByID(ctx, id, role)
+	user, err := service.GetByID(ctx, organisationID, id, role)
 	if err != nil {
 		if errors.Is(err, database.ErrResourceNotFound) {
 			return nil, errortools.NewNotFoundError("user", id, "id")
@@ -97,7 +126,28 @@ func (service UserService) Update(
 		return nil, err
 	}
 
-	user, err = service.users.Update(ctx, *user, updateUserDto, role)
+	oldUser := *user
+
+	err = service.audit.InTransaction(ctx, func(ctx context.Context) error {
+		user, err = service.users.Update(ctx, *user, updateUserDto, role)
+		if err != nil {
+			return err
+		}
+
+		if updateUserDto.Username == nil && updateUserDto.Password == nil &&
+			updateUserDto.TwoFactorRequired == nil {
+			return nil
+		}
+
+		return service.audit.Record(
+			ctx,
+			models.AuditUpdate,
+			"user",
+			user.ID,
+			oldUser,
+			user,
+		)
+	})
 	if err != nil {
 		if errors.Is(err, database.ErrResourceConflict) {
 			return nil, errortools.NewConflictError(
@@ -114,10 +164,11 @@ func (service UserService) Update(
 
 func (service UserService) Delete(
 	ctx context.Context,
+	organisationID string,
 	id string,
 	role models.Role,
 ) (*models.User, error) {
-	user, err := service.GetByID(ctx, id, role)
+	user, err := service.GetByID(ctx, organisationID, id, role)
 	if err != nil {
 		if errors.Is(err, database.ErrResourceNotFound) {
 			return nil, errortools.NewNotFoundError("user", id, "id")
@@ -126,10 +177,63 @@ func (service UserService) Delete(
 		return nil, err
 	}
 
-	err = service.users.Delete(ctx, id, role)
+	err = service.audit.InTransaction(ctx, func(ctx context.Context) error {
+		err = service.users.Archive(ctx, organisationID, id, role)
+		if err != nil {
+			return err
+		}
+
+		return service.audit.Record(
+			ctx,
+			models.AuditDelete,
+			"user",
+			user.ID,
+			user,
+			nil,
+		)
+	})
 	if err != nil {
 		return nil, err
 	}
 
 	return user, nil
 }
+
+func (service UserService) Restore(
+	ctx context.Context,
+	organisationID string,
+	id string,
+	role models.Role,
+) (*models.User, error) {
+	var user *models.User
+	err := service.audit.InTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		user, err = service.users.Restore(ctx, organisationID, id, role)
+		if err != nil {
+			return err
+		}
+
+		return service.audit.Record(
+			ctx,
+			models.AuditRestore,
+			"user",
+			user.ID,
+			nil,
+			user,
+		)
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, database.ErrResourceNotFound):
+			return nil, errortools.NewNotFoundError("user", id, "id")
+		case errors.Is(err, database.ErrResourceConflict):
+			return nil, errortools.NewBadRequestError(
+				errors.New("user can't be restored as its username is in use"),
+			)
+		default:
+			return nil, err
+		}
+	}
+
+	return user, nil
+}
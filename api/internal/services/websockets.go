@@ -4,6 +4,7 @@ import (
 	"context"
 	"log/slog"
 	"net/http"
+	"sync"
 
 	wstools "github.com/XDoubleU/essentia/pkg/communication/ws"
 	errortools "github.com/XDoubleU/essentia/pkg/errors"
@@ -18,11 +19,12 @@ type GetAllLocationStatesFunc = func(ctx context.Context) ([]dtos.LocationStateD
 type GetStateFunc = func(ctx context.Context) (*models.State, error)
 
 type WebSocketService struct {
-	allowedOrigins    []string
-	handler           *wstools.WebSocketHandler[dtos.SubscribeMessageDto]
-	stateTopic        *wstools.Topic
-	allLocationsTopic *wstools.Topic
-	locationTopics    map[string]*wstools.Topic
+	allowedOrigins     []string
+	handler            *wstools.WebSocketHandler[dtos.SubscribeMessageDto]
+	stateTopics        map[string]*wstools.Topic
+	allLocationsTopics map[string]*wstools.Topic
+	locationTopics     map[string]*wstools.Topic
+	mu                 *sync.RWMutex
 }
 
 func NewWebSocketService(
@@ -30,11 +32,12 @@ func NewWebSocketService(
 	allowedOrigins []string,
 ) *WebSocketService {
 	service := WebSocketService{
-		allowedOrigins:    allowedOrigins,
-		handler:           nil,
-		stateTopic:        nil,
-		allLocationsTopic: nil,
-		locationTopics:    make(map[string]*wstools.Topic),
+		allowedOrigins:     allowedOrigins,
+		handler:            nil,
+		stateTopics:        make(map[string]*wstools.Topic),
+		allLocationsTopics: make(map[string]*wstools.Topic),
+		locationTopics:     make(map[string]*wstools.Topic),
+		mu:                 &sync.RWMutex{},
 	}
 
 	handler := wstools.CreateWebSocketHandler[dtos.SubscribeMessageDto](
@@ -51,9 +54,12 @@ func (service WebSocketService) Handler() http.HandlerFunc {
 	return service.handler.Handler()
 }
 
-func (service *WebSocketService) SetStateTopic(getState GetStateFunc) error {
+func (service *WebSocketService) AddStateTopic(
+	organisationID string,
+	getState GetStateFunc,
+) error {
 	topic, err := service.handler.AddTopic(
-		string(dtos.State),
+		dtos.StateTopic(organisationID),
 		service.allowedOrigins,
 		func(ctx context.Context, _ *wstools.Topic) (any, error) { return getState(ctx) },
 	)
@@ -61,15 +67,19 @@ func (service *WebSocketService) SetStateTopic(getState GetStateFunc) error {
 		return err
 	}
 
-	service.stateTopic = topic
+	service.mu.Lock()
+	service.stateTopics[organisationID] = topic
+	service.mu.Unlock()
+
 	return nil
 }
 
-func (service *WebSocketService) SetAllLocationsTopic(
+func (service *WebSocketService) AddAllLocationsTopic(
+	organisationID string,
 	getAllLocationStates GetAllLocationStatesFunc,
 ) error {
 	topic, err := service.handler.AddTopic(
-		"*",
+		dtos.AllLocationsTopic(organisationID),
 		[]string{"*"},
 		func(ctx context.Context, _ *wstools.Topic) (any, error) {
 			return getAllLocationStates(ctx)
@@ -79,13 +89,16 @@ func (service *WebSocketService) SetAllLocationsTopic(
 		return err
 	}
 
-	service.allLocationsTopic = topic
+	service.mu.Lock()
+	service.allLocationsTopics[organisationID] = topic
+	service.mu.Unlock()
+
 	return nil
 }
 
 func (service WebSocketService) AddLocation(location *models.Location) error {
 	topic, err := service.handler.AddTopic(
-		location.NormalizedName,
+		dtos.LocationTopic(location.OrganisationID, location.NormalizedName),
 		service.allowedOrigins,
 		nil,
 	)
@@ -93,27 +106,38 @@ func (service WebSocketService) AddLocation(location *models.Location) error {
 		return err
 	}
 
+	service.mu.Lock()
 	service.locationTopics[location.ID] = topic
+	service.mu.Unlock()
+
 	return nil
 }
 
 func (service WebSocketService) UpdateLocation(location *models.Location) error {
+	service.mu.Lock()
+	defer service.mu.Unlock()
+
 	topic, ok := service.locationTopics[location.ID]
 	if !ok {
 		return errortools.NewNotFoundError("location", location.ID, "id")
 	}
 
-	newTopic, err := service.handler.UpdateTopicName(topic, location.NormalizedName)
+	newTopic, err := service.handler.UpdateTopicName(
+		topic,
+		dtos.LocationTopic(location.OrganisationID, location.NormalizedName),
+	)
 	if err != nil {
 		return err
 	}
 
-	delete(service.locationTopics, location.ID)
 	service.locationTopics[location.ID] = newTopic
 	return nil
 }
 
 func (service WebSocketService) DeleteLocation(location *models.Location) error {
+	service.mu.Lock()
+	defer service.mu.Unlock()
+
 	topic, ok := service.locationTopics[location.ID]
 	if !ok {
 		return errortools.NewNotFoundError("location", location.ID, "id")
@@ -124,17 +148,43 @@ func (service WebSocketService) DeleteLocation(location *models.Location) error
 		return err
 	}
 
-	delete(service.locationTopics, topic.Name)
+	delete(service.locationTopics, location.ID)
 	return nil
 }
 
-func (service WebSocketService) NewAppState(state models.State) {
-	service.stateTopic.EnqueueEvent(state)
+func (service WebSocketService) NewAppState(
+	organisationID string,
+	state models.State,
+) {
+	service.mu.RLock()
+	topic := service.stateTopics[organisationID]
+	service.mu.RUnlock()
+
+	topic.EnqueueEvent(state)
 }
 
 func (service WebSocketService) NewLocationState(location models.Location) {
 	locationState := dtos.NewLocationStateDto(location)
 
-	service.allLocationsTopic.EnqueueEvent(locationState)
-	service.locationTopics[location.ID].EnqueueEvent(locationState)
+	service.mu.RLock()
+	allLocationsTopic := service.allLocationsTopics[location.OrganisationID]
+	locationTopic := service.locationTopics[location.ID]
+	service.mu.RUnlock()
+
+	allLocationsTopic.EnqueueEvent(locationState)
+	locationTopic.EnqueueEvent(locationState)
+}
+
+func (service WebSocketService) NewWaitlistPromotion(
+	location models.Location,
+	entry dtos.WaitlistEntryDto,
+) {
+	service.mu.RLock()
+	topic := service.locationTopics[location.ID]
+	service.mu.RUnlock()
+
+	topic.EnqueueEvent(dtos.WaitlistPromotionEventDto{
+		NormalizedName: location.NormalizedName,
+		Promoted:       entry,
+	})
 }
@@ -4,3 +4,4 @@ import "time"
 
 type LocalNowTimeProvider = func() time.Time
 type UTCNowTimeProvider = func() time.Time
+type DayBoundsProvider = func(time.Time) (time.Time, time.Time)